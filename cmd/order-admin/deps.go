@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"wbtest/internal/config"
+	"wbtest/internal/entity"
+	"wbtest/internal/exchange"
+	"wbtest/internal/repository"
+	"wbtest/internal/service"
+	"wbtest/pkg/cache"
+	"wbtest/pkg/logger"
+	"wbtest/pkg/metric"
+	"wbtest/pkg/storage/postgres"
+	"wbtest/pkg/storage/postgres/transaction"
+
+	"github.com/google/uuid"
+)
+
+type deps struct {
+	cfg *config.Config
+	log logger.Logger
+	db  *postgres.Postgres
+	svc *service.OrderService
+
+	schemas *service.SchemaService
+}
+
+func loadConfig(configPath string) (*config.Config, logger.Logger, error) {
+	const op = "order-admin.loadConfig"
+
+	if configPath == "" {
+		configPath = os.Getenv("CONFIG_PATH")
+	}
+	if configPath == "" {
+		return nil, nil, fmt.Errorf("%s: %w", op, entity.ErrConfigPathNotSet)
+	}
+
+	cfg, err := config.LoadPath(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log, err := logger.NewAdapter(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: logger: %w", op, err)
+	}
+
+	return cfg, log, nil
+}
+
+func loadDeps(configPath string) (*deps, error) {
+	const op = "order-admin.loadDeps"
+
+	cfg, log, err := loadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	db, err := postgres.NewPostgres(
+		&cfg.Postgres,
+		log.With("component", "database"),
+		postgres.MaxPoolSize(cfg.Postgres.PoolMax),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: database: %w", op, err)
+	}
+
+	metrics := metric.NewFactory()
+
+	txManager, err := transaction.NewManager(
+		db,
+		log.With("component", "transaction manager"),
+		metrics.Transaction(),
+	)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: transaction manager: %w", op, err)
+	}
+
+	orderCache, err := cache.NewLRUCache[uuid.UUID, *entity.Order](
+		cfg.Cache.Capacity,
+		log.With("component", "cache"),
+		metrics.Cache(),
+	)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: cache: %w", op, err)
+	}
+
+	summaryCache, err := cache.NewLRUCache[string, *entity.CustomerSummary](
+		cfg.Cache.SummaryCapacity,
+		log.With("component", "summary cache"),
+		metrics.Cache(),
+		cache.WithName("customer_summary"),
+	)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: summary cache: %w", op, err)
+	}
+
+	rates, err := exchange.NewStaticProvider(cfg.Exchange.RatesFile)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: exchange rates: %w", op, err)
+	}
+
+	svc := service.NewOrderService(service.OrderServiceDeps{
+		DeliveryRepo:      repository.NewDeliveryRepository(db),
+		ItemRepo:          repository.NewItemRepository(db),
+		OrderRepo:         repository.NewOrderRepository(db),
+		PaymentRepo:       repository.NewPaymentRepository(db),
+		AuditRepo:         repository.NewAuditRepository(db),
+		ArchiveRepo:       repository.NewArchiveRepository(db),
+		SearchRepo:        repository.NewSearchRepository(db),
+		CustomerRepo:      repository.NewCustomerRepository(db),
+		AnalyticsRepo:     repository.NewAnalyticsRepository(db),
+		RefundRepo:        repository.NewRefundRepository(db),
+		EventRepo:         repository.NewEventRepository(db),
+		WebhookQueue:      repository.NewWebhookRepository(db),
+		TxManager:         txManager,
+		Logger:            log.With("component", "order service"),
+		Cache:             orderCache,
+		CacheTTL:          cfg.Cache.TTL,
+		SummaryCache:      summaryCache,
+		SummaryTTL:        cfg.Cache.SummaryTTL,
+		Rates:             rates,
+		ReportingCurrency: cfg.Exchange.ReportingCurrency,
+	})
+
+	schemas := service.NewSchemaService(
+		repository.NewSchemaRepository(db),
+		txManager,
+		log.With("component", "schema service"),
+	)
+
+	return &deps{
+		cfg:     cfg,
+		log:     log,
+		db:      db,
+		svc:     svc,
+		schemas: schemas,
+	}, nil
+}
+
+func (d *deps) Close() {
+	d.db.Close()
+}
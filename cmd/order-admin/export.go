@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"wbtest/internal/entity"
+	"wbtest/internal/export"
+)
+
+func runExport(ctx context.Context, args []string) error {
+	const op = "order-admin.runExport"
+
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Path to config file (defaults to CONFIG_PATH)")
+	format := fs.String("format", export.FormatJSONL, "Output format: jsonl or csv")
+	fromStr := fs.String("from", "", "Export orders created at or after this time (RFC3339)")
+	toStr := fs.String("to", "", "Export orders created before this time (RFC3339)")
+	compress := fs.Bool("gzip", false, "Compress output with gzip")
+	outPath := fs.String("out", "-", "Output file, - for stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	from, err := parseTimeFlag(*fromStr)
+	if err != nil {
+		return fmt.Errorf("%s: from: %w", op, err)
+	}
+	to, err := parseTimeFlag(*toStr)
+	if err != nil {
+		return fmt.Errorf("%s: to: %w", op, err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "-" {
+		file, createErr := os.Create(*outPath)
+		if createErr != nil {
+			return fmt.Errorf("%s: create output: %w", op, createErr)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	buffered := bufio.NewWriter(out)
+	encoder, err := export.NewEncoder(buffered, *format, *compress)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	d, err := loadDeps(*configPath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer d.Close()
+
+	exported := 0
+	err = d.svc.ExportOrders(ctx, from, to, func(order *entity.Order) error {
+		if encErr := encoder.Encode(order); encErr != nil {
+			return encErr
+		}
+		exported++
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: exported %d orders before failure: %w", op, exported, err)
+	}
+	if err = encoder.Close(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = buffered.Flush(); err != nil {
+		return fmt.Errorf("%s: flush output: %w", op, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %d orders\n", exported)
+	return nil
+}
+
+func parseTimeFlag(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("order-admin.parseTimeFlag: %w", err)
+	}
+	return t, nil
+}
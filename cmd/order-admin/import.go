@@ -0,0 +1,383 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"wbtest/internal/entity"
+	"wbtest/internal/service"
+)
+
+const (
+	_importModeService = "service"
+	_importModeKafka   = "kafka"
+)
+
+var (
+	errInvalidImportFlags = errors.New("invalid import flags")
+	errCheckpointMismatch = errors.New("checkpoint belongs to another input")
+)
+
+type importRecord struct {
+	line  int
+	raw   []byte
+	order *entity.Order
+}
+
+type importStats struct {
+	mu       sync.Mutex
+	read     int
+	imported int
+	failed   int
+	skipped  int
+}
+
+type errorReport struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	out io.Closer
+}
+
+type errorReportLine struct {
+	Line     int    `json:"line"`
+	OrderUID string `json:"order_uid,omitempty"`
+	Error    string `json:"error"`
+}
+
+type importCheckpoint struct {
+	Input     string    `json:"input"`
+	Line      int       `json:"line"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func runImport(ctx context.Context, args []string) error {
+	const op = "order-admin.runImport"
+
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Path to config file (defaults to CONFIG_PATH)")
+	inPath := fs.String("in", "", "Input file with one entity.Order JSON per line, plain or gzip")
+	mode := fs.String("mode", _importModeService, "Write through the order service or publish to kafka")
+	workers := fs.Int("workers", 4, "Number of parallel writers within a batch")
+	batchSize := fs.Int("batch-size", 100, "Number of records per batch")
+	dryRun := fs.Bool("dry-run", false, "Only validate records, write nothing")
+	errorsPath := fs.String("errors", "import-errors.jsonl", "Per-line error report file, empty to disable")
+	checkpointPath := fs.String("checkpoint", "", "Checkpoint file for resuming (defaults to <in>.checkpoint)")
+	progressEvery := fs.Duration("progress", 5*time.Second, "Progress output interval")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if *inPath == "" {
+		return fmt.Errorf("%s: -in is required: %w", op, errInvalidImportFlags)
+	}
+	if *workers < 1 || *batchSize < 1 {
+		return fmt.Errorf("%s: workers and batch-size must be positive: %w", op, errInvalidImportFlags)
+	}
+	if *mode != _importModeService && *mode != _importModeKafka {
+		return fmt.Errorf("%s: unknown mode %q: %w", op, *mode, errInvalidImportFlags)
+	}
+	if *checkpointPath == "" {
+		*checkpointPath = *inPath + ".checkpoint"
+	}
+
+	in, err := openImportInput(*inPath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer in.Close()
+
+	resumeAfter := 0
+	if !*dryRun {
+		if resumeAfter, err = readCheckpoint(*checkpointPath, *inPath); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if resumeAfter > 0 {
+			fmt.Fprintf(os.Stderr, "resuming after line %d\n", resumeAfter)
+		}
+	}
+
+	report, err := newErrorReport(*errorsPath, resumeAfter > 0)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer report.Close()
+
+	sink, err := newImportSink(*configPath, *mode, *workers, *dryRun)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer sink.Close()
+
+	stats := &importStats{}
+	stopProgress := startProgress(stats, *progressEvery)
+	defer stopProgress()
+
+	flush := func(batch []*importRecord, lastLine int) error {
+		if len(batch) > 0 {
+			errs := sink.ImportBatch(ctx, batch)
+			for i, rec := range batch {
+				if errs[i] != nil && ctx.Err() != nil && errors.Is(errs[i], ctx.Err()) {
+					// aborted, not failed: the checkpoint is not advanced, so a resumed import retries it
+					continue
+				}
+				if errs[i] != nil {
+					stats.add(0, 0, 1, 0)
+					report.Write(rec.line, rec.order.OrderUID.String(), errs[i])
+					continue
+				}
+				stats.add(0, 1, 0, 0)
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if *dryRun {
+			return nil
+		}
+		return writeCheckpoint(*checkpointPath, *inPath, lastLine)
+	}
+
+	batch := make([]*importRecord, 0, *batchSize)
+	line := 0
+	for {
+		raw, readErr := in.ReadBytes('\n')
+		if len(raw) > 0 {
+			line++
+			if rec, ok := parseImportLine(line, raw, resumeAfter, stats, report); ok {
+				batch = append(batch, rec)
+			}
+		}
+
+		if len(batch) >= *batchSize || (readErr != nil && len(batch) > 0) {
+			if err = flush(batch, line); err != nil {
+				return fmt.Errorf("%s: line %d: %w", op, line, err)
+			}
+			batch = batch[:0]
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("%s: read line %d: %w", op, line+1, readErr)
+		}
+	}
+
+	if !*dryRun && line > resumeAfter {
+		if err = writeCheckpoint(*checkpointPath, *inPath, line); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	stopProgress()
+	if *dryRun {
+		fmt.Fprintf(os.Stderr, "dry run done, nothing written: %s\n", stats)
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "done: %s\n", stats)
+	return nil
+}
+
+func parseImportLine(
+	line int,
+	raw []byte,
+	resumeAfter int,
+	stats *importStats,
+	report *errorReport,
+) (*importRecord, bool) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, false
+	}
+	if line <= resumeAfter {
+		stats.add(1, 0, 0, 1)
+		return nil, false
+	}
+	stats.add(1, 0, 0, 0)
+
+	order := &entity.Order{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(order); err != nil {
+		stats.add(0, 0, 1, 0)
+		report.Write(line, "", fmt.Errorf("decode: %w", err))
+		return nil, false
+	}
+
+	if err := service.ValidateOrder(order); err != nil {
+		stats.add(0, 0, 1, 0)
+		report.Write(line, order.OrderUID.String(), fmt.Errorf("validate: %w", err))
+		return nil, false
+	}
+
+	return &importRecord{line: line, raw: raw, order: order}, true
+}
+
+type importInput struct {
+	*bufio.Reader
+	closers []io.Closer
+}
+
+func openImportInput(path string) (*importInput, error) {
+	const op = "order-admin.openImportInput"
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	input := &importInput{Reader: bufio.NewReader(file), closers: []io.Closer{file}}
+
+	magic, err := input.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, gzErr := gzip.NewReader(input.Reader)
+		if gzErr != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s: gzip: %w", op, gzErr)
+		}
+		input.Reader = bufio.NewReader(gz)
+		input.closers = append([]io.Closer{gz}, input.closers...)
+	}
+
+	return input, nil
+}
+
+func (i *importInput) Close() error {
+	var errs []error
+	for _, c := range i.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func newErrorReport(path string, appendMode bool) (*errorReport, error) {
+	if path == "" {
+		return &errorReport{}, nil
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendMode {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	file, err := os.OpenFile(path, flags, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("order-admin.newErrorReport: %w", err)
+	}
+	return &errorReport{enc: json.NewEncoder(file), out: file}, nil
+}
+
+func (r *errorReport) Write(line int, orderUID string, err error) {
+	if r.enc == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if encErr := r.enc.Encode(errorReportLine{Line: line, OrderUID: orderUID, Error: err.Error()}); encErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to write error report for line %d: %v\n", line, encErr)
+	}
+}
+
+func (r *errorReport) Close() error {
+	if r.out == nil {
+		return nil
+	}
+	return r.out.Close()
+}
+
+// readCheckpoint returns the last line imported from input, or 0 without a checkpoint. A
+// checkpoint left by an import of another file is an error rather than a reason to skip lines.
+func readCheckpoint(path, input string) (int, error) {
+	const op = "order-admin.readCheckpoint"
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var cp importCheckpoint
+	if err = json.Unmarshal(data, &cp); err != nil {
+		return 0, fmt.Errorf("%s: decode %s: %w", op, path, err)
+	}
+	if cp.Input != input {
+		return 0, fmt.Errorf("%s: %s was written for %q, not %q; remove it or pass another -checkpoint: %w",
+			op, path, cp.Input, input, errCheckpointMismatch)
+	}
+	return cp.Line, nil
+}
+
+func writeCheckpoint(path, input string, line int) error {
+	const op = "order-admin.writeCheckpoint"
+
+	data, err := json.Marshal(importCheckpoint{Input: input, Line: line, UpdatedAt: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *importStats) add(read, imported, failed, skipped int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.read += read
+	s.imported += imported
+	s.failed += failed
+	s.skipped += skipped
+}
+
+func (s *importStats) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return fmt.Sprintf("read=%d imported=%d failed=%d skipped=%d",
+		s.read, s.imported, s.failed, s.skipped)
+}
+
+func startProgress(stats *importStats, every time.Duration) func() {
+	done := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+
+		start := time.Now()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				fmt.Fprintf(os.Stderr, "progress: %s elapsed=%s\n",
+					stats, time.Since(start).Truncate(time.Second))
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
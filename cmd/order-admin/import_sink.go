@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"wbtest/internal/service"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type importSink interface {
+	ImportBatch(ctx context.Context, records []*importRecord) []error
+	Close() error
+}
+
+func newImportSink(configPath, mode string, workers int, dryRun bool) (importSink, error) {
+	const op = "order-admin.newImportSink"
+
+	if dryRun {
+		return dryRunSink{}, nil
+	}
+
+	if mode == _importModeKafka {
+		cfg, _, err := loadConfig(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return &kafkaSink{
+			writer: &kafka.Writer{
+				Addr:         kafka.TCP(cfg.Kafka.Brokers...),
+				Topic:        cfg.Kafka.Topic,
+				Balancer:     &kafka.Hash{},
+				RequiredAcks: kafka.RequireAll,
+			},
+		}, nil
+	}
+
+	d, err := loadDeps(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &serviceSink{deps: d, svc: d.svc, workers: workers}, nil
+}
+
+type dryRunSink struct{}
+
+func (dryRunSink) ImportBatch(_ context.Context, records []*importRecord) []error {
+	return make([]error, len(records))
+}
+
+func (dryRunSink) Close() error {
+	return nil
+}
+
+type serviceSink struct {
+	deps    *deps
+	svc     *service.OrderService
+	workers int
+}
+
+func (s *serviceSink) ImportBatch(ctx context.Context, records []*importRecord) []error {
+	errs := make([]error, len(records))
+	sem := make(chan struct{}, s.workers)
+
+	var wg sync.WaitGroup
+	for i, rec := range records {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if _, err := s.svc.CreateOrder(ctx, rec.order); err != nil {
+				errs[i] = err
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
+
+func (s *serviceSink) Close() error {
+	s.deps.Close()
+	return nil
+}
+
+type kafkaSink struct {
+	writer *kafka.Writer
+}
+
+func (s *kafkaSink) ImportBatch(ctx context.Context, records []*importRecord) []error {
+	msgs := make([]kafka.Message, len(records))
+	for i, rec := range records {
+		msgs[i] = kafka.Message{
+			Key:   []byte(rec.order.OrderUID.String()),
+			Value: rec.raw,
+		}
+	}
+
+	errs := make([]error, len(records))
+	err := s.writer.WriteMessages(ctx, msgs...)
+	if err == nil {
+		return errs
+	}
+
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) && len(writeErrs) == len(records) {
+		copy(errs, writeErrs)
+		return errs
+	}
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+func (s *kafkaSink) Close() error {
+	if err := s.writer.Close(); err != nil {
+		return fmt.Errorf("order-admin.kafkaSink.Close: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"wbtest/internal/entity"
+	"wbtest/internal/schema"
+	"wbtest/internal/service"
+)
+
+const _schemaUsage = `Usage: order-admin schema <generate|check|register> [flags]
+
+  generate  print the order JSON Schema derived from entity.Order
+  check     compare a schema with a file or with the latest registered version
+  register  store a schema as the next version of a subject
+`
+
+var errSchemaIncompatible = errors.New("schema is incompatible")
+
+func runSchema(ctx context.Context, args []string) error {
+	const op = "order-admin.runSchema"
+
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, _schemaUsage)
+		return fmt.Errorf("%s: %w", op, flag.ErrHelp)
+	}
+
+	var err error
+	switch sub := args[0]; sub {
+	case "generate":
+		err = runSchemaGenerate(args[1:])
+	case "check":
+		err = runSchemaCheck(ctx, args[1:])
+	case "register":
+		err = runSchemaRegister(ctx, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown schema command %q\n\n%s", sub, _schemaUsage)
+		return fmt.Errorf("%s: %w", op, flag.ErrHelp)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func runSchemaGenerate(args []string) error {
+	fs := flag.NewFlagSet("schema generate", flag.ContinueOnError)
+	outPath := fs.String("out", "-", "Output file, - for stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	s, err := service.GenerateOrderSchema()
+	if err != nil {
+		return err
+	}
+	data, err := s.Marshal()
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	if err = json.Indent(&out, data, "", "  "); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+
+	if *outPath == "-" {
+		_, err = out.WriteTo(os.Stdout)
+		return err
+	}
+	return os.WriteFile(*outPath, out.Bytes(), 0o600)
+}
+
+func runSchemaCheck(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("schema check", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Path to config file (defaults to CONFIG_PATH), used without -against")
+	filePath := fs.String("file", "", "Candidate schema, defaults to the generated order schema")
+	againstPath := fs.String("against", "", "Previous schema file; without it the latest registered version is used")
+	subject := fs.String("subject", entity.SchemaSubjectOrder, "Registry subject")
+	modeStr := fs.String("mode", string(schema.ModeBackward), "Compatibility mode: backward, forward or full")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	mode, err := schema.ParseMode(*modeStr)
+	if err != nil {
+		return err
+	}
+	next, err := loadSchema(*filePath)
+	if err != nil {
+		return err
+	}
+
+	var (
+		problems []string
+		against  string
+	)
+	if *againstPath != "" {
+		prev, loadErr := loadSchema(*againstPath)
+		if loadErr != nil {
+			return loadErr
+		}
+		problems = schema.Check(prev, next, mode)
+		against = *againstPath
+	} else {
+		d, loadErr := loadDeps(*configPath)
+		if loadErr != nil {
+			return loadErr
+		}
+		defer d.Close()
+
+		latest, checkProblems, checkErr := d.schemas.CheckCompatibility(ctx, *subject, next, mode)
+		if checkErr != nil {
+			return checkErr
+		}
+		if latest == nil {
+			fmt.Fprintf(os.Stderr, "subject %q has no registered versions\n", *subject)
+			return nil
+		}
+		problems = checkProblems
+		against = fmt.Sprintf("%s v%d", *subject, latest.Version)
+	}
+
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "%s check against %s failed:\n  %s\n", mode, against, strings.Join(problems, "\n  "))
+		return fmt.Errorf("%w: %d problems", errSchemaIncompatible, len(problems))
+	}
+
+	fmt.Fprintf(os.Stderr, "%s compatible with %s\n", mode, against)
+	return nil
+}
+
+func runSchemaRegister(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("schema register", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Path to config file (defaults to CONFIG_PATH)")
+	filePath := fs.String("file", "", "Schema to register, defaults to the generated order schema")
+	subject := fs.String("subject", entity.SchemaSubjectOrder, "Registry subject")
+	modeStr := fs.String("mode", string(schema.ModeBackward), "Compatibility mode: backward, forward or full")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	mode, err := schema.ParseMode(*modeStr)
+	if err != nil {
+		return err
+	}
+	s, err := loadSchema(*filePath)
+	if err != nil {
+		return err
+	}
+
+	d, err := loadDeps(*configPath)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	version, created, err := d.schemas.Register(ctx, *subject, s, mode)
+	if err != nil {
+		return err
+	}
+
+	if created {
+		fmt.Fprintf(os.Stderr, "registered %s v%d\n", version.Subject, version.Version)
+	} else {
+		fmt.Fprintf(os.Stderr, "schema already registered as %s v%d\n", version.Subject, version.Version)
+	}
+	return nil
+}
+
+func loadSchema(path string) (*schema.Schema, error) {
+	if path == "" {
+		return service.GenerateOrderSchema()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	s, err := schema.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
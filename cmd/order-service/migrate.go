@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"wbtest/internal/config"
+	"wbtest/internal/entity"
+	"wbtest/migrations"
+	"wbtest/pkg/logger"
+	"wbtest/pkg/storage/postgres"
+	"wbtest/pkg/storage/postgres/migrate"
+)
+
+const _migrateUsage = `Usage: order-service migrate [-config path] <command>
+
+Commands:
+  up             apply all pending migrations
+  down N         revert the last N migrations (default 1)
+  status         show the current version and pending migrations
+  force VERSION  mark VERSION as applied and clean without running SQL (0 clears it)
+`
+
+// _migratePoolSize covers the locked migration connection plus one spare.
+const _migratePoolSize = 2
+
+func runMigrate(ctx context.Context, args []string) error {
+	const op = "order-service.runMigrate"
+
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, _migrateUsage)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "", "Path to config file (defaults to CONFIG_PATH)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("%s: %w", op, flag.ErrHelp)
+	}
+
+	migrator, closeDB, err := newMigrator(*configPath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer closeDB()
+
+	switch cmd := fs.Arg(0); cmd {
+	case "up":
+		applied, upErr := migrator.Up(ctx)
+		if upErr != nil {
+			return fmt.Errorf("%s: %w", op, upErr)
+		}
+		fmt.Fprintf(os.Stderr, "applied %d migrations, now at version %d\n", applied, migrator.Latest())
+	case "down":
+		n := 1
+		if fs.NArg() > 1 {
+			if n, err = strconv.Atoi(fs.Arg(1)); err != nil {
+				return fmt.Errorf("%s: invalid N %q: %w", op, fs.Arg(1), err)
+			}
+		}
+		reverted, downErr := migrator.Down(ctx, n)
+		if downErr != nil {
+			return fmt.Errorf("%s: %w", op, downErr)
+		}
+		fmt.Fprintf(os.Stderr, "reverted %d migrations\n", reverted)
+	case "status":
+		status, statusErr := migrator.Status(ctx)
+		if statusErr != nil {
+			return fmt.Errorf("%s: %w", op, statusErr)
+		}
+		printStatus(status)
+	case "force":
+		if fs.NArg() < 2 {
+			return fmt.Errorf("%s: force needs a version", op)
+		}
+		version, parseErr := strconv.ParseInt(fs.Arg(1), 10, 64)
+		if parseErr != nil {
+			return fmt.Errorf("%s: invalid version %q: %w", op, fs.Arg(1), parseErr)
+		}
+		if err = migrator.Force(ctx, version); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		fmt.Fprintf(os.Stderr, "forced version %d\n", version)
+	default:
+		fs.Usage()
+		return fmt.Errorf("%s: unknown command %q: %w", op, cmd, flag.ErrHelp)
+	}
+
+	return nil
+}
+
+func newMigrator(configPath string) (*migrate.Migrator, func(), error) {
+	if configPath == "" {
+		configPath = os.Getenv("CONFIG_PATH")
+	}
+	if configPath == "" {
+		return nil, nil, entity.ErrConfigPathNotSet
+	}
+
+	cfg, err := config.LoadPath(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("config: %w", err)
+	}
+	log, err := logger.NewAdapter(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("logger: %w", err)
+	}
+
+	db, err := postgres.NewPostgres(&cfg.Postgres, log.With("component", "database"),
+		postgres.ApplicationName(cfg.App.Name+"-migrate"),
+		postgres.MaxPoolSize(_migratePoolSize),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("database: %w", err)
+	}
+
+	migrator, err := migrate.New(db.Pool, migrations.FS, log.With("component", "migrator"))
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return migrator, db.Close, nil
+}
+
+func printStatus(status *migrate.Status) {
+	state := "clean"
+	if status.Dirty {
+		state = "dirty"
+	}
+	fmt.Fprintf(os.Stdout, "version %d (%s), latest %d\n\n", status.Version, state, status.Latest)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, mig := range status.Applied {
+		fmt.Fprintf(w, "applied\t%d\t%s\n", mig.Version, mig.Name)
+	}
+	for _, mig := range status.Pending {
+		fmt.Fprintf(w, "pending\t%d\t%s\n", mig.Version, mig.Name)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"time"
+
+	"wbtest/internal/entity"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+)
+
+var (
+	_dateRangeStart = time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	_dateRangeEnd   = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type orderGenerator struct {
+	f *gofakeit.Faker
+}
+
+func newOrderGenerator(seed uint64) *orderGenerator {
+	return &orderGenerator{f: gofakeit.New(seed)}
+}
+
+func (g *orderGenerator) uuid() uuid.UUID {
+	return uuid.MustParse(g.f.UUID())
+}
+
+func (g *orderGenerator) delivery() *entity.Delivery {
+	return &entity.Delivery{
+		Name:    g.f.Name(),
+		Phone:   "+" + g.f.Numerify("7##########"),
+		Zip:     g.f.Zip(),
+		City:    g.f.City(),
+		Address: g.f.Address().Address,
+		Region:  g.f.State(),
+		Email:   g.f.Email(),
+	}
+}
+
+func (g *orderGenerator) payment(items []*entity.Item) *entity.Payment {
+	var goodsTotal int64
+	for _, item := range items {
+		goodsTotal += item.TotalPrice
+	}
+	deliveryCost := int64(g.f.UintRange(100, 500))
+	customFee := int64(g.f.UintRange(0, 100))
+
+	return &entity.Payment{
+		Transaction:  g.uuid(),
+		RequestID:    g.uuid(),
+		Currency:     g.f.CurrencyShort(),
+		Provider:     g.f.Word(),
+		Amount:       goodsTotal + deliveryCost + customFee,
+		PaymentDt:    g.f.DateRange(_dateRangeStart, _dateRangeEnd).Unix(),
+		Bank:         g.f.BS(),
+		DeliveryCost: deliveryCost,
+		GoodsTotal:   goodsTotal,
+		CustomFee:    customFee,
+	}
+}
+
+func (g *orderGenerator) item(trackNumber string) *entity.Item {
+	price := int64(g.f.UintRange(100, 1000))
+	sale := g.f.Number(0, 50)
+
+	return &entity.Item{
+		ChrtID:      uint64(g.f.UintRange(10000, 99999)),
+		TrackNumber: trackNumber,
+		Price:       price,
+		Rid:         g.uuid(),
+		Name:        g.f.ProductName(),
+		Sale:        sale,
+		Size:        g.f.Word(),
+		TotalPrice:  max(price*int64(100-sale)/100, 1),
+		NMID:        uint64(g.f.UintRange(1000000, 9999999)),
+		Brand:       g.f.Company(),
+		Status:      g.f.Number(1, 5),
+	}
+}
+
+func (g *orderGenerator) order(itemsCount int) *entity.Order {
+	if itemsCount <= 0 {
+		itemsCount = g.f.Number(1, 5)
+	}
+
+	trackNumber := g.f.LetterN(14)
+	items := make([]*entity.Item, 0, itemsCount)
+	for range itemsCount {
+		items = append(items, g.item(trackNumber))
+	}
+
+	return &entity.Order{
+		OrderUID:          g.uuid(),
+		TrackNumber:       trackNumber,
+		Entry:             g.f.LetterN(4),
+		Delivery:          g.delivery(),
+		Payment:           g.payment(items),
+		Items:             items,
+		Locale:            g.f.RandomString([]string{"en", "ru", "de", "fr", "es"}),
+		InternalSignature: g.f.UUID(),
+		CustomerID:        g.f.Username(),
+		DeliveryService:   g.f.Word(),
+		Shardkey:          g.f.Numerify("#"),
+		SmID:              g.f.Number(1, 10),
+		DateCreated:       g.f.DateRange(_dateRangeStart, _dateRangeEnd).UTC(),
+		OofShard:          g.f.Numerify("#"),
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _histogramBuckets = []time.Duration{
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+type latencyRecorder struct {
+	mu      sync.Mutex
+	samples []time.Duration
+}
+
+func newLatencyRecorder() *latencyRecorder {
+	return &latencyRecorder{}
+}
+
+func (l *latencyRecorder) record(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.samples = append(l.samples, d)
+}
+
+func (l *latencyRecorder) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.samples)
+}
+
+func (l *latencyRecorder) sorted() []time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	samples := slices.Clone(l.samples)
+	slices.Sort(samples)
+	return samples
+}
+
+func (l *latencyRecorder) percentiles() string {
+	samples := l.sorted()
+	if len(samples) == 0 {
+		return "no samples"
+	}
+
+	quantile := func(q float64) time.Duration {
+		return samples[int(q*float64(len(samples)-1))]
+	}
+
+	return fmt.Sprintf("p50=%v p90=%v p99=%v max=%v",
+		quantile(0.50).Round(time.Microsecond),
+		quantile(0.90).Round(time.Microsecond),
+		quantile(0.99).Round(time.Microsecond),
+		samples[len(samples)-1].Round(time.Microsecond),
+	)
+}
+
+func (l *latencyRecorder) histogram(w io.Writer) {
+	samples := l.sorted()
+	if len(samples) == 0 {
+		return
+	}
+
+	counts := make([]int, len(_histogramBuckets)+1)
+	for _, s := range samples {
+		i, _ := slices.BinarySearch(_histogramBuckets, s)
+		counts[i]++
+	}
+
+	const barWidth = 40
+	peak := slices.Max(counts)
+	for i, c := range counts {
+		label := "+Inf"
+		if i < len(_histogramBuckets) {
+			label = _histogramBuckets[i].String()
+		}
+		bar := strings.Repeat("#", c*barWidth/peak)
+		fmt.Fprintf(w, "  <= %-7s %7d %s\n", label, c, bar)
+	}
+}
+
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:     rate,
+		capacity: float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("producer.tokenBucket.Wait: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	_probeWorkers   = 8
+	_probeQueueSize = 10000
+)
+
+type loadOptions struct {
+	rate          float64
+	writers       int
+	duration      time.Duration
+	probe         bool
+	probeURL      string
+	probeInterval time.Duration
+	probeTimeout  time.Duration
+}
+
+type probeTarget struct {
+	orderUID string
+	ackedAt  time.Time
+}
+
+type loadRunner struct {
+	writer *kafka.Writer
+	scn    *scenario
+	scnMu  sync.Mutex
+	stats  *summary
+	opts   loadOptions
+
+	writeLatency *latencyRecorder
+	probeLatency *latencyRecorder
+	probeQueue   chan probeTarget
+	probeDropped atomic.Int64
+	probeTimeout atomic.Int64
+	client       *http.Client
+}
+
+// loadWrite travels with a message through the asynchronous writer to its completion.
+type loadWrite struct {
+	kind   messageKind
+	msg    kafka.Message
+	sentAt time.Time
+}
+
+// newLoadRunner makes the writer asynchronous, so writer goroutines keep producing while
+// batches fill up to the writer's batch size. Deliveries are accounted in the writer's
+// completion callback.
+func newLoadRunner(writer *kafka.Writer, scn *scenario, stats *summary, opts loadOptions) *loadRunner {
+	r := &loadRunner{
+		writer:       writer,
+		scn:          scn,
+		stats:        stats,
+		opts:         opts,
+		writeLatency: newLatencyRecorder(),
+		probeLatency: newLatencyRecorder(),
+		probeQueue:   make(chan probeTarget, _probeQueueSize),
+		client:       &http.Client{Timeout: opts.probeInterval * 4},
+	}
+	writer.Async = true
+	writer.Completion = r.completed
+
+	return r
+}
+
+func (r *loadRunner) run(ctx context.Context, out io.Writer) {
+	runCtx, cancel := context.WithTimeout(ctx, r.opts.duration)
+	defer cancel()
+
+	bucket := newTokenBucket(r.opts.rate, max(1, int(r.opts.rate/10)))
+
+	var probes sync.WaitGroup
+	if r.opts.probe {
+		for range _probeWorkers {
+			probes.Add(1)
+			go func() {
+				defer probes.Done()
+				r.probeLoop(ctx)
+			}()
+		}
+	}
+
+	log.Printf("Load test: rate=%.0f msg/s writers=%d duration=%v probe=%t",
+		r.opts.rate, r.opts.writers, r.opts.duration, r.opts.probe)
+
+	start := time.Now()
+	var writers sync.WaitGroup
+	for range r.opts.writers {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			r.writeLoop(runCtx, bucket)
+		}()
+	}
+	writers.Wait()
+	elapsed := time.Since(start)
+
+	// Close flushes the batches still filling up and waits for their completions.
+	if err := r.writer.Close(); err != nil {
+		log.Printf("Failed to close Kafka writer: %v", err)
+	}
+
+	close(r.probeQueue)
+	probes.Wait()
+
+	r.report(out, elapsed)
+}
+
+func (r *loadRunner) writeLoop(ctx context.Context, bucket *tokenBucket) {
+	for {
+		if err := bucket.Wait(ctx); err != nil {
+			return
+		}
+
+		r.scnMu.Lock()
+		kind, msg, err := r.scn.next()
+		r.scnMu.Unlock()
+		if err != nil {
+			r.stats.record(kind, err)
+			continue
+		}
+
+		msg.WriterData = &loadWrite{kind: kind, msg: msg, sentAt: time.Now()}
+		if err = r.writer.WriteMessages(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			r.stats.record(kind, err)
+			log.Printf("Failed to write %s message to Kafka: %v", kind, err)
+		}
+	}
+}
+
+// completed accounts a batch the writer delivered or failed to deliver.
+func (r *loadRunner) completed(messages []kafka.Message, err error) {
+	ackedAt := time.Now()
+	if err != nil {
+		log.Printf("Failed to write %d messages to Kafka: %v", len(messages), err)
+	}
+
+	for _, msg := range messages {
+		write, ok := msg.WriterData.(*loadWrite)
+		if !ok {
+			continue
+		}
+
+		r.stats.record(write.kind, err)
+		if err != nil {
+			continue
+		}
+		r.writeLatency.record(ackedAt.Sub(write.sentAt))
+
+		r.scnMu.Lock()
+		r.scn.delivered(write.msg)
+		r.scnMu.Unlock()
+
+		if r.opts.probe && write.kind == kindValid {
+			select {
+			case r.probeQueue <- probeTarget{orderUID: string(msg.Key), ackedAt: ackedAt}:
+			default:
+				r.probeDropped.Add(1)
+			}
+		}
+	}
+}
+
+func (r *loadRunner) probeLoop(ctx context.Context) {
+	for target := range r.probeQueue {
+		if ctx.Err() != nil {
+			continue
+		}
+		r.probeOne(ctx, target)
+	}
+}
+
+func (r *loadRunner) probeOne(ctx context.Context, target probeTarget) {
+	url := strings.TrimRight(r.opts.probeURL, "/") + "/orders/" + target.orderUID
+	deadline := target.ackedAt.Add(r.opts.probeTimeout)
+
+	ticker := time.NewTicker(r.opts.probeInterval)
+	defer ticker.Stop()
+
+	for {
+		if r.orderQueryable(ctx, url) {
+			r.probeLatency.record(time.Since(target.ackedAt))
+			return
+		}
+		if time.Now().After(deadline) {
+			r.probeTimeout.Add(1)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *loadRunner) orderQueryable(ctx context.Context, url string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode == http.StatusOK
+}
+
+func (r *loadRunner) report(out io.Writer, elapsed time.Duration) {
+	sent := r.writeLatency.count()
+
+	fmt.Fprintf(out, "\nLoad test results\n")
+	fmt.Fprintf(out, "elapsed: %v\n", elapsed.Truncate(time.Millisecond))
+	fmt.Fprintf(out, "messages acked: %d (%.1f msg/s)\n", sent, float64(sent)/elapsed.Seconds())
+	fmt.Fprintf(out, "write latency: %s\n", r.writeLatency.percentiles())
+
+	if !r.opts.probe {
+		return
+	}
+
+	fmt.Fprintf(out, "\nIngest-to-queryable latency (probe)\n")
+	fmt.Fprintf(out, "found: %d, timed out: %d, not probed (queue full): %d\n",
+		r.probeLatency.count(), r.probeTimeout.Load(), r.probeDropped.Load())
+	fmt.Fprintf(out, "latency: %s\n", r.probeLatency.percentiles())
+	r.probeLatency.histogram(out)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+	metadataAPI "github.com/segmentio/kafka-go/protocol/metadata"
+	produceAPI "github.com/segmentio/kafka-go/protocol/produce"
+)
+
+// fakeBroker acknowledges every produce request after a short delay, like a local broker.
+type fakeBroker struct {
+	topic    string
+	produced *atomic.Int64
+}
+
+func (b fakeBroker) RoundTrip(ctx context.Context, _ net.Addr, req kafka.Request) (protocol.Message, error) {
+	switch req.(type) {
+	case *metadataAPI.Request:
+		return &metadataAPI.Response{
+			Brokers: []metadataAPI.ResponseBroker{{NodeID: 1, Host: "localhost", Port: 9092}},
+			Topics: []metadataAPI.ResponseTopic{{
+				Name:       b.topic,
+				Partitions: []metadataAPI.ResponsePartition{{PartitionIndex: 0, LeaderID: 1}},
+			}},
+		}, nil
+	case *produceAPI.Request:
+		b.produced.Add(1)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+		return &produceAPI.Response{
+			Topics: []produceAPI.ResponseTopic{{
+				Topic:      b.topic,
+				Partitions: []produceAPI.ResponsePartition{{Partition: 0}},
+			}},
+		}, nil
+	default:
+		return nil, protocol.ErrNoRecord
+	}
+}
+
+func TestLoadRunner_TracksRate(t *testing.T) {
+	t.Parallel()
+
+	const (
+		rate     = 200.0
+		duration = 2 * time.Second
+	)
+
+	codec, err := newCodec("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scn, err := newScenario("valid", "", 1, 0, codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var produced atomic.Int64
+	// the same batching defaults as the -batch-size and -batch-timeout flags
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP("localhost:9092"),
+		Topic:        "orders-test",
+		Transport:    fakeBroker{topic: "orders-test", produced: &produced},
+		BatchSize:    100,
+		BatchTimeout: time.Second,
+	}
+	defer writer.Close()
+
+	runner := newLoadRunner(writer, scn, newSummary(), loadOptions{
+		rate:     rate,
+		writers:  4,
+		duration: duration,
+	})
+	runner.run(context.Background(), io.Discard)
+
+	got := float64(runner.writeLatency.count()) / duration.Seconds()
+	if got < rate*0.8 || got > rate*1.2 {
+		t.Fatalf("expected about %.0f msg/s, got %.1f msg/s", rate, got)
+	}
+	if requests := produced.Load(); requests*10 > int64(runner.writeLatency.count()) {
+		t.Fatalf("expected messages written in batches, got %d produce requests for %d messages",
+			requests, runner.writeLatency.count())
+	}
+}
@@ -3,20 +3,26 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
+	"math/rand/v2"
+	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
-	"wbtest/internal/entity"
+	kafkat "wbtest/internal/transport/kafka"
 
-	"github.com/brianvoe/gofakeit/v7"
-	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
 )
 
+const (
+	_modeInterval = "interval"
+	_modeLoad     = "load"
+)
+
 func main() {
 	kafkaBrokers := flag.String(
 		"brokers",
@@ -26,33 +32,127 @@ func main() {
 	kafkaTopic := flag.String("topic", "orders-dev", "Kafka topic to write messages to")
 	numMessages := flag.Int("count", 1, "Number of messages to send")
 	interval := flag.Duration("interval", 1*time.Second, "Interval between sending messages")
+	profile := flag.String("scenario", "valid", "Scenario profile: "+profileNames())
+	mix := flag.String(
+		"mix",
+		"",
+		"Custom mix overriding the profile, e.g. valid=80,conflict=10,truncated=10",
+	)
+	seed := flag.Uint64("seed", 0, "Seed for reproducible runs (0 picks a random seed)")
+	oversizedItems := flag.Int("oversized-items", 1000, "Number of items in oversized orders")
+	format := flag.String("format", "json", "Message format: json, protobuf, avro")
+	schemaVersion := flag.Int("schema-version", 0, "Set the schema-version header so the consumer validates against it (0 disables)")
+	mode := flag.String("mode", _modeInterval, "Run mode: interval (-count messages every -interval) or load")
+	batchSize := flag.Int("batch-size", 100, "kafka.Writer batch size")
+	batchTimeout := flag.Duration("batch-timeout", time.Second, "kafka.Writer batch timeout")
+	compression := flag.String("compression", "none", "Compression codec: none, gzip, snappy, lz4, zstd")
+	rate := flag.Float64("rate", 100, "Load mode: target rate in messages per second")
+	writers := flag.Int("writers", 4, "Load mode: number of concurrent writers")
+	duration := flag.Duration("duration", 30*time.Second, "Load mode: run duration")
+	probe := flag.Bool("probe", false, "Load mode: poll the HTTP API until each valid order is queryable")
+	probeURL := flag.String("probe-url", "http://localhost:8080", "Load mode: order-service base URL for probing")
+	probeInterval := flag.Duration("probe-interval", 50*time.Millisecond, "Load mode: probe polling interval")
+	probeTimeout := flag.Duration("probe-timeout", 30*time.Second, "Load mode: give up probing an order after this long")
+	fromFile := flag.String("from-file", "", "Replay: publish orders from a JSON Lines file (plain or gzip) instead of generating them")
+	fromDLQ := flag.String("from-dlq", "", "Replay: DLQ topic to read, unwrap and republish to -topic")
+	errorContains := flag.String("error-contains", "", "DLQ replay: only messages whose error contains this substring")
+	minRetries := flag.Int("min-retries", -1, "DLQ replay: only messages with at least this retry count")
+	maxRetries := flag.Int("max-retries", -1, "DLQ replay: only messages with at most this retry count")
+	since := flag.String("since", "", "DLQ replay: only messages dead-lettered at or after this RFC3339 time")
+	until := flag.String("until", "", "DLQ replay: only messages dead-lettered before this RFC3339 time")
+	dryRun := flag.Bool("dry-run", false, "Replay: print what would be published without writing")
 
 	flag.Parse()
 
+	compressionCodec, err := parseCompression(*compression)
+	if err != nil {
+		log.Fatalf("Invalid compression: %v", err)
+	}
+	if *mode != _modeInterval && *mode != _modeLoad {
+		log.Fatalf("Invalid mode %q, expected %s or %s", *mode, _modeInterval, _modeLoad)
+	}
+	if *mode == _modeLoad && (*rate <= 0 || *writers < 1) {
+		log.Fatalf("Load mode requires -rate > 0 and -writers >= 1")
+	}
+
+	if *fromFile != "" && *fromDLQ != "" {
+		log.Fatalf("-from-file and -from-dlq are mutually exclusive")
+	}
+	filter, err := newReplayFilter(*errorContains, *minRetries, *maxRetries, *since, *until)
+	if err != nil {
+		log.Fatalf("Invalid replay filter: %v", err)
+	}
+
+	if *seed == 0 {
+		*seed = rand.Uint64()
+	}
+
+	codec, err := newCodec(*format)
+	if err != nil {
+		log.Fatalf("Invalid format: %v", err)
+	}
+
+	scn, err := newScenario(*profile, *mix, *seed, *oversizedItems, codec)
+	if err != nil {
+		log.Fatalf("Invalid scenario: %v", err)
+	}
+	scn.schemaVersion = *schemaVersion
+
+	brokers := strings.Split(*kafkaBrokers, ",")
 	writer := &kafka.Writer{
-		Addr:     kafka.TCP(*kafkaBrokers),
-		Topic:    *kafkaTopic,
-		Balancer: &kafka.LeastBytes{},
+		Addr:         kafka.TCP(brokers...),
+		Topic:        *kafkaTopic,
+		Balancer:     &kafka.LeastBytes{},
+		BatchSize:    *batchSize,
+		BatchTimeout: *batchTimeout,
+		Compression:  compressionCodec,
 	}
 	defer writer.Close()
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	if *fromFile != "" || *fromDLQ != "" {
+		rp := &replayer{writer: writer, filter: filter, dryRun: *dryRun, batchSize: max(1, *batchSize)}
+		if err = runReplay(ctx, rp, brokers, *fromFile, *fromDLQ); err != nil {
+			log.Printf("Replay failed: %v", err)
+		}
+		rp.report(os.Stdout)
+		return
+	}
+
 	log.Printf(
-		"Starting Kafka producer. Will send %d messages to topic '%s' at broker(s) '%s' every %v\n",
+		"Starting Kafka producer. Will send %d messages to topic '%s' at broker(s) '%s' every %v (scenario=%s, seed=%d)\n",
 		*numMessages,
 		*kafkaTopic,
 		*kafkaBrokers,
 		*interval,
+		*profile,
+		*seed,
 	)
 
+	stats := newSummary()
+	defer stats.print(os.Stdout, scn, *profile, *seed)
+
+	if *mode == _modeLoad {
+		newLoadRunner(writer, scn, stats, loadOptions{
+			rate:          *rate,
+			writers:       *writers,
+			duration:      *duration,
+			probe:         *probe,
+			probeURL:      *probeURL,
+			probeInterval: *probeInterval,
+			probeTimeout:  *probeTimeout,
+		}).run(ctx, os.Stdout)
+		return
+	}
+
 	ticker := time.NewTicker(*interval)
 	defer ticker.Stop()
 
 	messagesSent := 0
 
-	sendMessage(ctx, writer)
+	sendMessage(ctx, writer, scn, stats)
 
 	messagesSent++
 	if messagesSent >= *numMessages {
@@ -66,7 +166,7 @@ func main() {
 			log.Println("Shutting down producer...")
 			return
 		case <-ticker.C:
-			sendMessage(ctx, writer)
+			sendMessage(ctx, writer, scn, stats)
 			messagesSent++
 			if messagesSent >= *numMessages {
 				log.Printf("Sent all %d messages. Exiting.\n", *numMessages)
@@ -76,96 +176,96 @@ func main() {
 	}
 }
 
-func sendMessage(ctx context.Context, writer *kafka.Writer) {
-	order := generateFakeOrder()
-	orderBytes, err := json.Marshal(order)
-	if err != nil {
-		log.Printf("Failed to marshal order: %v", err)
-		return
-	}
+func newReplayFilter(errorContains string, minRetries, maxRetries int, since, until string) (replayFilter, error) {
+	filter := replayFilter{errorContains: errorContains, minRetries: minRetries, maxRetries: maxRetries}
 
-	msg := kafka.Message{
-		Key:   []byte(order.OrderUID.String()),
-		Value: orderBytes,
+	var err error
+	if since != "" {
+		if filter.since, err = time.Parse(time.RFC3339, since); err != nil {
+			return filter, fmt.Errorf("producer.newReplayFilter: since: %w", err)
+		}
+	}
+	if until != "" {
+		if filter.until, err = time.Parse(time.RFC3339, until); err != nil {
+			return filter, fmt.Errorf("producer.newReplayFilter: until: %w", err)
+		}
 	}
 
-	writeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+	return filter, nil
+}
 
-	err = writer.WriteMessages(writeCtx, msg)
+func runReplay(ctx context.Context, rp *replayer, brokers []string, fromFile, fromDLQ string) error {
+	var (
+		src replaySource
+		err error
+	)
+	if fromFile != "" {
+		log.Printf("Replaying orders from file %s to topic '%s' (dry-run=%t)", fromFile, rp.writer.Topic, rp.dryRun)
+		src, err = newFileSource(fromFile)
+	} else {
+		log.Printf("Replaying DLQ topic '%s' to topic '%s' (dry-run=%t)", fromDLQ, rp.writer.Topic, rp.dryRun)
+		src, err = newDLQSource(ctx, brokers, fromDLQ)
+	}
 	if err != nil {
-		log.Printf("Failed to write message to Kafka: %v", err)
+		return err
 	}
+	defer src.Close()
 
-	log.Printf("Successfully sent order UID: %s", order.OrderUID.String())
+	return rp.run(ctx, src, os.Stdout)
 }
 
-func generateFakeDelivery() *entity.Delivery {
-	return &entity.Delivery{
-		Name:    gofakeit.Name(),
-		Phone:   gofakeit.Phone(),
-		Zip:     gofakeit.Zip(),
-		City:    gofakeit.City(),
-		Address: gofakeit.Address().Address,
-		Region:  gofakeit.State(),
-		Email:   gofakeit.Email(),
+func newCodec(format string) (kafkat.Codec, error) {
+	switch format {
+	case "json":
+		return kafkat.JSONCodec{}, nil
+	case "protobuf":
+		return kafkat.ProtobufCodec{}, nil
+	case "avro":
+		codec, err := kafkat.NewAvroCodec()
+		if err != nil {
+			return nil, fmt.Errorf("producer.newCodec: %w", err)
+		}
+		return codec, nil
+	default:
+		return nil, fmt.Errorf("producer.newCodec: unknown format %q", format)
 	}
 }
 
-func generateFakePayment() *entity.Payment {
-	return &entity.Payment{
-		Transaction:  uuid.New(),
-		RequestID:    uuid.New(),
-		Currency:     gofakeit.CurrencyShort(),
-		Provider:     gofakeit.Word(),
-		Amount:       uint64(gofakeit.UintRange(1000, 10000)),
-		PaymentDt:    gofakeit.DateRange(time.Now().AddDate(-1, 0, 0), time.Now()).Unix(),
-		Bank:         gofakeit.BS(),
-		DeliveryCost: uint64(gofakeit.UintRange(100, 500)),
-		GoodsTotal:   uint64(gofakeit.UintRange(500, 9000)),
-		CustomFee:    uint64(gofakeit.UintRange(0, 100)),
+func parseCompression(name string) (kafka.Compression, error) {
+	switch name {
+	case "none", "":
+		return 0, nil
+	case "gzip":
+		return kafka.Gzip, nil
+	case "snappy":
+		return kafka.Snappy, nil
+	case "lz4":
+		return kafka.Lz4, nil
+	case "zstd":
+		return kafka.Zstd, nil
+	default:
+		return 0, fmt.Errorf("producer.parseCompression: unknown codec %q", name)
 	}
 }
 
-func generateFakeItem() *entity.Item {
-	return &entity.Item{
-		ChrtID:      uint64(gofakeit.UintRange(10000, 99999)),
-		TrackNumber: gofakeit.UUID(),
-		Price:       uint64(gofakeit.UintRange(100, 1000)),
-		Rid:         uuid.New(),
-		Name:        gofakeit.ProductName(),
-		Sale:        gofakeit.Number(0, 50),
-		Size:        gofakeit.Word(),
-		TotalPrice:  uint64(gofakeit.UintRange(50, 950)),
-		NMID:        uint64(gofakeit.UintRange(1000000, 9999999)),
-		Brand:       gofakeit.Company(),
-		Status:      gofakeit.Number(1, 5),
+func sendMessage(ctx context.Context, writer *kafka.Writer, scn *scenario, stats *summary) {
+	kind, msg, err := scn.next()
+	if err != nil {
+		log.Printf("Failed to build %s message: %v", kind, err)
+		stats.record(kind, err)
+		return
 	}
-}
 
-func generateFakeOrder() *entity.Order {
-	orderUID := uuid.New()
-	itemsCount := gofakeit.Number(1, 5)
-	items := make([]*entity.Item, 0, itemsCount)
-
-	for range itemsCount {
-		items = append(items, generateFakeItem())
-	}
-
-	return &entity.Order{
-		OrderUID:          orderUID,
-		TrackNumber:       gofakeit.UUID(),
-		Entry:             gofakeit.LetterN(10),
-		Delivery:          generateFakeDelivery(),
-		Payment:           generateFakePayment(),
-		Items:             items,
-		Locale:            gofakeit.LetterN(2),
-		InternalSignature: gofakeit.UUID(),
-		CustomerID:        gofakeit.Username(),
-		DeliveryService:   gofakeit.Word(),
-		Shardkey:          gofakeit.Word(),
-		SmID:              gofakeit.Number(1, 10),
-		DateCreated:       gofakeit.Date(),
-		OofShard:          gofakeit.LetterN(1),
+	writeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err = writer.WriteMessages(writeCtx, msg)
+	stats.record(kind, err)
+	if err != nil {
+		log.Printf("Failed to write %s message to Kafka: %v", kind, err)
+		return
 	}
+	scn.delivered(msg)
+
+	log.Printf("Successfully sent %s order UID: %s", kind, string(msg.Key))
 }
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	kafkat "wbtest/internal/transport/kafka"
+	"wbtest/pkg/kafka/dlq"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	_maxReplayLineSize = 16 << 20
+	// _replayIdleTimeout ends a DLQ partition that stops returning messages before its high
+	// watermark, e.g. when the last offsets are transaction markers or were deleted by retention.
+	_replayIdleTimeout = 10 * time.Second
+)
+
+var errSkipRecord = errors.New("record skipped")
+
+type replayFilter struct {
+	errorContains string
+	minRetries    int
+	maxRetries    int
+	since         time.Time
+	until         time.Time
+}
+
+// match applies the filter to DLQ metadata; a negative retry bound or a zero time is not checked.
+func (f replayFilter) match(meta *dlqMetadata) bool {
+	if meta == nil {
+		return true
+	}
+	if f.errorContains != "" && !strings.Contains(meta.Error, f.errorContains) {
+		return false
+	}
+	if f.minRetries >= 0 && meta.RetryCount < f.minRetries {
+		return false
+	}
+	if f.maxRetries >= 0 && meta.RetryCount > f.maxRetries {
+		return false
+	}
+	if !f.since.IsZero() && meta.Timestamp.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && !meta.Timestamp.Before(f.until) {
+		return false
+	}
+	return true
+}
+
+// dlqMetadata mirrors the metadata object written by dlq.DLQ.Send.
+type dlqMetadata struct {
+	OriginalTopic string    `json:"original_topic"`
+	ContentType   string    `json:"content_type"`
+	RequestID     string    `json:"request_id"`
+	Partition     int       `json:"partition"`
+	Offset        int64     `json:"offset"`
+	RetryCount    int       `json:"retry_count"`
+	Error         string    `json:"error"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+type dlqEnvelope struct {
+	Metadata        *dlqMetadata `json:"metadata"`
+	Payload         *string      `json:"payload"`
+	PayloadEncoding string       `json:"payload_encoding"`
+}
+
+type replayRecord struct {
+	ref  string
+	msg  kafka.Message
+	meta *dlqMetadata
+}
+
+type replaySource interface {
+	// next returns io.EOF when the source is exhausted and errSkipRecord for records that cannot be replayed.
+	next(ctx context.Context) (replayRecord, error)
+	Close() error
+}
+
+type replayStats struct {
+	read      int
+	filtered  int
+	skipped   int
+	published int
+	failed    int
+}
+
+type replayer struct {
+	writer    *kafka.Writer
+	filter    replayFilter
+	dryRun    bool
+	batchSize int
+
+	batch []replayRecord
+	stats replayStats
+}
+
+func (r *replayer) run(ctx context.Context, src replaySource, out io.Writer) error {
+	const op = "producer.replayer.run"
+
+	for {
+		rec, err := src.next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, errSkipRecord) {
+			r.stats.read++
+			r.stats.skipped++
+			log.Printf("Skipping %v", err)
+			continue
+		}
+		if err != nil {
+			r.flush(ctx)
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		r.stats.read++
+		if !r.filter.match(rec.meta) {
+			r.stats.filtered++
+			continue
+		}
+
+		if r.dryRun {
+			r.stats.published++
+			fmt.Fprintln(out, describeRecord(rec))
+			continue
+		}
+
+		r.batch = append(r.batch, rec)
+		if len(r.batch) >= r.batchSize {
+			r.flush(ctx)
+		}
+	}
+
+	r.flush(ctx)
+	return nil
+}
+
+func (r *replayer) flush(ctx context.Context) {
+	if len(r.batch) == 0 {
+		return
+	}
+
+	msgs := make([]kafka.Message, len(r.batch))
+	for i, rec := range r.batch {
+		msgs[i] = rec.msg
+	}
+
+	err := r.writer.WriteMessages(ctx, msgs...)
+
+	var writeErrs kafka.WriteErrors
+	switch {
+	case err == nil:
+		r.stats.published += len(msgs)
+	case errors.As(err, &writeErrs):
+		for i, werr := range writeErrs {
+			if werr != nil {
+				r.stats.failed++
+				log.Printf("Failed to replay %s: %v", r.batch[i].ref, werr)
+				continue
+			}
+			r.stats.published++
+		}
+	default:
+		r.stats.failed += len(msgs)
+		log.Printf("Failed to replay batch of %d messages: %v", len(msgs), err)
+	}
+
+	r.batch = r.batch[:0]
+}
+
+func (r *replayer) report(out io.Writer) {
+	verb := "published"
+	if r.dryRun {
+		verb = "would replay"
+	}
+	fmt.Fprintf(out, "\nReplay summary\nread: %d, filtered out: %d, skipped: %d, %s: %d, failed: %d\n",
+		r.stats.read, r.stats.filtered, r.stats.skipped, verb, r.stats.published, r.stats.failed)
+}
+
+func describeRecord(rec replayRecord) string {
+	line := fmt.Sprintf("%s key=%s size=%d", rec.ref, rec.msg.Key, len(rec.msg.Value))
+	if rec.meta != nil {
+		line += fmt.Sprintf(" retries=%d at=%s error=%q",
+			rec.meta.RetryCount, rec.meta.Timestamp.Format(time.RFC3339), rec.meta.Error)
+	}
+	return line
+}
+
+type fileSource struct {
+	file   *os.File
+	gz     *gzip.Reader
+	reader *bufio.Reader
+	line   int
+}
+
+func newFileSource(path string) (*fileSource, error) {
+	const op = "producer.newFileSource"
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	src := &fileSource{file: file, reader: bufio.NewReader(file)}
+
+	magic, err := src.reader.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		src.gz, err = gzip.NewReader(src.reader)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s: gzip: %w", op, err)
+		}
+		src.reader = bufio.NewReader(src.gz)
+	}
+
+	return src, nil
+}
+
+func (s *fileSource) next(_ context.Context) (replayRecord, error) {
+	for {
+		raw, err := s.reader.ReadBytes('\n')
+		if len(raw) == 0 && err != nil {
+			if errors.Is(err, io.EOF) {
+				return replayRecord{}, io.EOF
+			}
+			return replayRecord{}, fmt.Errorf("producer.fileSource.next: %w", err)
+		}
+		s.line++
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			continue
+		}
+		ref := fmt.Sprintf("line %d", s.line)
+		if len(raw) > _maxReplayLineSize {
+			return replayRecord{}, fmt.Errorf("%s: line exceeds %d bytes: %w", ref, _maxReplayLineSize, errSkipRecord)
+		}
+
+		var head struct {
+			OrderUID string `json:"order_uid"`
+		}
+		if err = json.Unmarshal(raw, &head); err != nil {
+			return replayRecord{}, fmt.Errorf("%s: not a JSON order: %w", ref, errSkipRecord)
+		}
+
+		return replayRecord{
+			ref: ref,
+			msg: kafka.Message{
+				Key:     []byte(head.OrderUID),
+				Value:   bytes.Clone(raw),
+				Headers: []kafka.Header{kafkat.ContentTypeHeader(kafkat.JSONCodec{})},
+			},
+		}, nil
+	}
+}
+
+func (s *fileSource) Close() error {
+	var errs []error
+	if s.gz != nil {
+		errs = append(errs, s.gz.Close())
+	}
+	errs = append(errs, s.file.Close())
+	return errors.Join(errs...)
+}
+
+type partitionRange struct {
+	id    int
+	first int64
+	last  int64
+}
+
+// dlqSource reads every partition of the DLQ topic from the first offset up to the
+// high watermark observed at start, so a replay always terminates. A partition that returns
+// nothing for _replayIdleTimeout before reaching it is done as well.
+type dlqSource struct {
+	brokers     []string
+	topic       string
+	ranges      []partitionRange
+	current     *kafka.Reader
+	idleTimeout time.Duration
+}
+
+func newDLQSource(ctx context.Context, brokers []string, topic string) (*dlqSource, error) {
+	const op = "producer.newDLQSource"
+
+	conn, err := dialAny(brokers, func(broker string) (*kafka.Conn, error) {
+		return kafka.DialContext(ctx, "tcp", broker)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: dial: %w", op, err)
+	}
+	partitions, err := conn.ReadPartitions(topic)
+	conn.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: read partitions: %w", op, err)
+	}
+
+	src := &dlqSource{brokers: brokers, topic: topic, idleTimeout: _replayIdleTimeout}
+	for _, p := range partitions {
+		leader, dialErr := dialAny(brokers, func(broker string) (*kafka.Conn, error) {
+			return kafka.DialLeader(ctx, "tcp", broker, topic, p.ID)
+		})
+		if dialErr != nil {
+			return nil, fmt.Errorf("%s: dial leader of partition %d: %w", op, p.ID, dialErr)
+		}
+		first, last, offErr := leader.ReadOffsets()
+		leader.Close()
+		if offErr != nil {
+			return nil, fmt.Errorf("%s: read offsets of partition %d: %w", op, p.ID, offErr)
+		}
+		if last > first {
+			src.ranges = append(src.ranges, partitionRange{id: p.ID, first: first, last: last})
+		}
+	}
+
+	return src, nil
+}
+
+// dialAny dials the brokers in turn and returns the first connection that succeeds.
+func dialAny(brokers []string, dial func(broker string) (*kafka.Conn, error)) (*kafka.Conn, error) {
+	var errs []error
+	for _, broker := range brokers {
+		conn, err := dial(broker)
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", broker, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (s *dlqSource) next(ctx context.Context) (replayRecord, error) {
+	msg, err := s.nextMessage(ctx)
+	if err != nil {
+		return replayRecord{}, err
+	}
+
+	ref := fmt.Sprintf("partition %d offset %d", msg.Partition, msg.Offset)
+
+	var envelope dlqEnvelope
+	if err = json.Unmarshal(msg.Value, &envelope); err != nil || envelope.Metadata == nil {
+		return replayRecord{}, fmt.Errorf("%s: not a DLQ envelope: %w", ref, errSkipRecord)
+	}
+	if envelope.Payload == nil {
+		return replayRecord{}, fmt.Errorf("%s: envelope has no payload: %w", ref, errSkipRecord)
+	}
+
+	payload := []byte(*envelope.Payload)
+	if envelope.PayloadEncoding == dlq.PayloadEncodingBase64 {
+		if payload, err = base64.StdEncoding.DecodeString(*envelope.Payload); err != nil {
+			return replayRecord{}, fmt.Errorf("%s: payload is not valid base64: %w", ref, errSkipRecord)
+		}
+	}
+
+	out := kafka.Message{Key: msg.Key, Value: payload}
+	if envelope.Metadata.ContentType != "" {
+		out.Headers = []kafka.Header{{Key: kafkat.HeaderContentType, Value: []byte(envelope.Metadata.ContentType)}}
+	}
+	if envelope.Metadata.RequestID != "" {
+		out.Headers = append(out.Headers, kafka.Header{Key: kafkat.HeaderRequestID, Value: []byte(envelope.Metadata.RequestID)})
+	}
+
+	return replayRecord{
+		ref:  ref,
+		msg:  out,
+		meta: envelope.Metadata,
+	}, nil
+}
+
+// nextMessage reads the next message of the current partition, moving on to the next
+// partition once the current one reaches its high watermark or stays idle.
+func (s *dlqSource) nextMessage(ctx context.Context) (kafka.Message, error) {
+	const op = "producer.dlqSource.next"
+
+	for len(s.ranges) > 0 {
+		part := s.ranges[0]
+
+		if s.current == nil {
+			s.current = kafka.NewReader(kafka.ReaderConfig{
+				Brokers:   s.brokers,
+				Topic:     s.topic,
+				Partition: part.id,
+				MaxBytes:  _maxReplayLineSize,
+			})
+			if err := s.current.SetOffset(part.first); err != nil {
+				return kafka.Message{}, fmt.Errorf("%s: set offset: %w", op, err)
+			}
+		}
+
+		readCtx, cancel := context.WithTimeout(ctx, s.idleTimeout)
+		msg, err := s.current.ReadMessage(readCtx)
+		cancel()
+
+		switch {
+		case err == nil:
+			if msg.Offset+1 >= part.last || msg.Offset+1 >= msg.HighWaterMark {
+				s.nextPartition()
+			}
+			return msg, nil
+		case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+			log.Printf("Partition %d idle for %s before offset %d, moving on", part.id, s.idleTimeout, part.last)
+			s.nextPartition()
+		default:
+			return kafka.Message{}, fmt.Errorf("%s: partition %d: %w", op, part.id, err)
+		}
+	}
+
+	return kafka.Message{}, io.EOF
+}
+
+func (s *dlqSource) nextPartition() {
+	s.current.Close()
+	s.current = nil
+	s.ranges = s.ranges[1:]
+}
+
+func (s *dlqSource) Close() error {
+	if s.current == nil {
+		return nil
+	}
+	if err := s.current.Close(); err != nil {
+		return fmt.Errorf("producer.dlqSource.Close: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"wbtest/internal/entity"
+	kafkat "wbtest/internal/transport/kafka"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type messageKind string
+
+const (
+	kindValid            messageKind = "valid"
+	kindInvalidEmail     messageKind = "invalid_email"
+	kindInvalidPhone     messageKind = "invalid_phone"
+	kindMismatchedTotals messageKind = "mismatched_totals"
+	kindDuplicate        messageKind = "duplicate"
+	kindConflict         messageKind = "conflict"
+	kindTruncated        messageKind = "truncated"
+	kindOversized        messageKind = "oversized"
+)
+
+const (
+	_historySize = 100
+)
+
+var errInvalidMix = errors.New("invalid scenario mix")
+
+// _expectedOutcome describes what the order-service consumer currently does with each kind of
+// message without a schema-version header.
+var _expectedOutcome = map[messageKind]string{
+	kindValid:            "stored",
+	kindInvalidEmail:     "stored (fields are not validated)",
+	kindInvalidPhone:     "stored (fields are not validated)",
+	kindMismatchedTotals: "stored (totals are not validated)",
+	kindDuplicate:        "no-op (same payload hash)",
+	kindConflict:         "dlq (conflicting payload)",
+	kindTruncated:        "dlq (unmarshal error after retries)",
+	kindOversized:        "stored",
+}
+
+// _expectedSchemaOutcome overrides _expectedOutcome for messages carrying a schema-version
+// header: the registered schema checks the email and phone formats, but not the totals.
+var _expectedSchemaOutcome = map[messageKind]string{
+	kindInvalidEmail: "dlq (schema validation)",
+	kindInvalidPhone: "dlq (schema validation)",
+}
+
+var _profiles = map[string]string{
+	"valid":      "valid=1",
+	"invalid":    "invalid_email=1,invalid_phone=1,mismatched_totals=1",
+	"duplicates": "valid=2,duplicate=1,conflict=1",
+	"malformed":  "valid=2,truncated=1,oversized=1",
+	"mixed": "valid=70,invalid_email=5,invalid_phone=5,mismatched_totals=5," +
+		"duplicate=5,conflict=4,truncated=3,oversized=3",
+}
+
+type weightedKind struct {
+	kind   messageKind
+	weight int
+}
+
+type scenario struct {
+	gen            *orderGenerator
+	codec          kafkat.Codec
+	weights        []weightedKind
+	totalWeight    int
+	oversizedItems int
+	history        []*entity.Order
+	// schemaVersion, when set, asks the consumer to validate against that registered version.
+	schemaVersion int
+}
+
+func newScenario(profile, mix string, seed uint64, oversizedItems int, codec kafkat.Codec) (*scenario, error) {
+	const op = "producer.newScenario"
+
+	if mix == "" {
+		var ok bool
+		if mix, ok = _profiles[profile]; !ok {
+			return nil, fmt.Errorf("%s: unknown profile %q: %w", op, profile, errInvalidMix)
+		}
+	}
+
+	weights, err := parseMix(mix)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s := &scenario{
+		gen:            newOrderGenerator(seed),
+		codec:          codec,
+		weights:        weights,
+		oversizedItems: oversizedItems,
+	}
+	for _, w := range weights {
+		s.totalWeight += w.weight
+	}
+
+	return s, nil
+}
+
+func parseMix(mix string) ([]weightedKind, error) {
+	var weights []weightedKind
+	for part := range strings.SplitSeq(mix, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("%q: expected kind=weight: %w", part, errInvalidMix)
+		}
+
+		kind := messageKind(name)
+		if _, known := _expectedOutcome[kind]; !known {
+			return nil, fmt.Errorf("unknown kind %q: %w", name, errInvalidMix)
+		}
+
+		weight, err := strconv.Atoi(value)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("%q: weight must be a non-negative integer: %w", part, errInvalidMix)
+		}
+		if weight > 0 {
+			weights = append(weights, weightedKind{kind: kind, weight: weight})
+		}
+	}
+
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("all weights are zero: %w", errInvalidMix)
+	}
+
+	sort.Slice(weights, func(i, j int) bool { return weights[i].kind < weights[j].kind })
+	return weights, nil
+}
+
+func (s *scenario) pick() messageKind {
+	n := s.gen.f.IntN(s.totalWeight)
+	for _, w := range s.weights {
+		if n < w.weight {
+			return w.kind
+		}
+		n -= w.weight
+	}
+	return kindValid
+}
+
+// expectedOutcome describes what the consumer does with messages of kind from this scenario.
+func (s *scenario) expectedOutcome(kind messageKind) string {
+	if outcome, ok := _expectedSchemaOutcome[kind]; ok && s.schemaVersion > 0 {
+		return outcome
+	}
+	return _expectedOutcome[kind]
+}
+
+func (s *scenario) next() (messageKind, kafka.Message, error) {
+	kind := s.pick()
+	if (kind == kindDuplicate || kind == kindConflict) && len(s.history) == 0 {
+		kind = kindValid
+	}
+
+	var order *entity.Order
+	switch kind {
+	case kindDuplicate:
+		order = s.history[s.gen.f.IntN(len(s.history))]
+	case kindConflict:
+		order = withDeliveryCopy(s.history[s.gen.f.IntN(len(s.history))])
+		order.Delivery.City = s.gen.f.City() + " " + s.gen.f.LetterN(4)
+	case kindOversized:
+		order = s.gen.order(s.oversizedItems)
+	default:
+		order = s.gen.order(0)
+	}
+
+	switch kind {
+	case kindInvalidEmail:
+		order.Delivery.Email = s.gen.f.Username() + ".example.com"
+	case kindInvalidPhone:
+		order.Delivery.Phone = s.gen.f.LetterN(8)
+	case kindMismatchedTotals:
+		order.Payment.GoodsTotal += int64(s.gen.f.UintRange(1, 1000))
+	case kindValid, kindDuplicate, kindConflict, kindTruncated, kindOversized:
+	}
+
+	value, err := s.codec.Marshal(order)
+	if err != nil {
+		return kind, kafka.Message{}, fmt.Errorf("producer.scenario.next: marshal: %w", err)
+	}
+	if kind == kindTruncated {
+		value = value[:1+s.gen.f.IntN(len(value)-1)]
+	}
+	headers := []kafka.Header{
+		kafkat.ContentTypeHeader(s.codec),
+		{Key: kafkat.HeaderRequestID, Value: []byte(s.gen.f.UUID())},
+	}
+	if s.schemaVersion > 0 {
+		headers = append(headers, kafka.Header{
+			Key:   entity.SchemaVersionHeader,
+			Value: []byte(strconv.Itoa(s.schemaVersion)),
+		})
+	}
+
+	msg := kafka.Message{
+		Key:     []byte(order.OrderUID.String()),
+		Value:   value,
+		Headers: headers,
+	}
+	if kind == kindValid {
+		msg.WriterData = order
+	}
+	return kind, msg, nil
+}
+
+// delivered keeps the valid order carried by msg, if any, as a source for later duplicates
+// and conflicts.
+func (s *scenario) delivered(msg kafka.Message) {
+	order, ok := msg.WriterData.(*entity.Order)
+	if !ok {
+		return
+	}
+	if len(s.history) < _historySize {
+		s.history = append(s.history, order)
+	} else {
+		s.history[s.gen.f.IntN(_historySize)] = order
+	}
+}
+
+func withDeliveryCopy(order *entity.Order) *entity.Order {
+	clone := *order
+	delivery := *order.Delivery
+	clone.Delivery = &delivery
+	return &clone
+}
+
+func profileNames() string {
+	names := make([]string, 0, len(_profiles))
+	for name := range _profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestScenario_ExpectedOutcome(t *testing.T) {
+	t.Parallel()
+
+	codec, err := newCodec("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scn, err := newScenario("invalid", "", 1, 0, codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := scn.expectedOutcome(kindInvalidEmail); got != _expectedOutcome[kindInvalidEmail] {
+		t.Fatalf("expected %q without a schema version, got %q", _expectedOutcome[kindInvalidEmail], got)
+	}
+
+	scn.schemaVersion = 1
+	tests := map[messageKind]string{
+		kindInvalidEmail:     "dlq (schema validation)",
+		kindInvalidPhone:     "dlq (schema validation)",
+		kindMismatchedTotals: _expectedOutcome[kindMismatchedTotals],
+	}
+	for kind, want := range tests {
+		if got := scn.expectedOutcome(kind); got != want {
+			t.Errorf("%s: expected %q with a schema version, got %q", kind, want, got)
+		}
+	}
+}
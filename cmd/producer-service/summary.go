@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+)
+
+type summary struct {
+	mu     sync.Mutex
+	sent   map[messageKind]int
+	failed map[messageKind]int
+}
+
+func newSummary() *summary {
+	return &summary{
+		sent:   make(map[messageKind]int),
+		failed: make(map[messageKind]int),
+	}
+}
+
+func (s *summary) record(kind messageKind, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.failed[kind]++
+		return
+	}
+	s.sent[kind]++
+}
+
+func (s *summary) print(w io.Writer, scn *scenario, profile string, seed uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kinds := make([]messageKind, 0, len(_expectedOutcome))
+	for kind := range _expectedOutcome {
+		if s.sent[kind]+s.failed[kind] > 0 {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+
+	fmt.Fprintf(w, "\nScenario summary (profile=%s, seed=%d)\n", profile, seed)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tSENT\tFAILED\tEXPECTED CONSUMER OUTCOME")
+
+	var totalSent, totalFailed int
+	for _, kind := range kinds {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", kind, s.sent[kind], s.failed[kind], scn.expectedOutcome(kind))
+		totalSent += s.sent[kind]
+		totalFailed += s.failed[kind]
+	}
+	fmt.Fprintf(tw, "total\t%d\t%d\t\n", totalSent, totalFailed)
+	tw.Flush()
+}
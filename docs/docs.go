@@ -23,9 +23,848 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/analytics/orders": {
+            "get": {
+                "description": "Возвращает число заказов и выручку по периодам (в минимальных единицах валюты, отдельно по каждой валюте), а также самые продаваемые товары и бренды. Границы периода округляются до целых часов в UTC, недели начинаются с понедельника",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analytics"
+                ],
+                "summary": "Аналитика заказов",
+                "parameters": [
+                    {
+                        "enum": [
+                            "hour",
+                            "day",
+                            "week"
+                        ],
+                        "type": "string",
+                        "default": "day",
+                        "description": "Размер периода",
+                        "name": "bucket",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало интервала (RFC3339), по умолчанию за 7 дней до to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец интервала (RFC3339), не включительно, по умолчанию текущее время",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Служба доставки",
+                        "name": "delivery_service",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Локаль заказа",
+                        "name": "locale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта оплаты",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Платёжный провайдер",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Банк",
+                        "name": "bank",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Размер топов товаров и брендов (1–100)",
+                        "name": "top",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта отчётности (ISO 4217): выручка пересчитывается по текущему курсу и ряды объединяются; по умолчанию EXCHANGE_REPORTING_CURRENCY",
+                        "name": "reporting_currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Аналитика заказов",
+                        "schema": {
+                            "$ref": "#/definitions/entity.OrderAnalytics"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Нет курса для пересчёта в валюту отчётности",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{customer_id}/orders": {
+            "get": {
+                "description": "Возвращает заказы покупателя, начиная с последнего",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Заказы покупателя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор покупателя",
+                        "name": "customer_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы (1–100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Страница заказов покупателя",
+                        "schema": {
+                            "$ref": "#/definitions/entity.OrderPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный customer_id или параметры пагинации",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{customer_id}/summary": {
+            "get": {
+                "description": "Возвращает число заказов, сумму оплат по валютам, даты первого и последнего заказа, самые частые бренды и города доставки",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Сводка по покупателю",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор покупателя",
+                        "name": "customer_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта отчётности (ISO 4217), в которую пересчитывается total; по умолчанию EXCHANGE_REPORTING_CURRENCY",
+                        "name": "reporting_currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сводка по покупателю",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CustomerSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный customer_id или reporting_currency",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "У покупателя нет заказов",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Нет курса для пересчёта в валюту отчётности",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/export": {
+            "get": {
+                "description": "Потоково выгружает заказы за период в формате JSON Lines (формат entity.Order) или CSV (по строке на товар)",
+                "produces": [
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/gzip"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Выгрузить заказы",
+                "parameters": [
+                    {
+                        "enum": [
+                            "jsonl",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "default": "jsonl",
+                        "description": "Формат выгрузки",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода по date_created (RFC3339), включительно",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода по date_created (RFC3339), не включительно",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Сжать выгрузку gzip",
+                        "name": "gzip",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Файл выгрузки",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры выгрузки",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/search": {
+            "get": {
+                "description": "Ищет заказы по имени получателя, городу, адресу, названиям товаров и брендам (полнотекстово), а также по телефону, email и трек-номеру (по подстроке и с опечатками). Результаты отсортированы по релевантности",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Поиск заказов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Поисковый запрос, до 200 символов",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы (1–100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Страница найденных заказов",
+                        "schema": {
+                            "$ref": "#/definitions/entity.OrderPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или параметры пагинации",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/stream": {
+            "get": {
+                "description": "Server-Sent Events: каждый заказ, созданный после подключения, приходит событием order (id — order_uid, data — entity.Order). Простаивающий поток раз в FEED_KEEP_ALIVE получает комментарий-пинг. Клиент, отставший больше чем на FEED_BUFFER_SIZE заказов, получает событие disconnect с причиной slow и отключается; EventSource переподключается сам",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Поток новых заказов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Только заказы этой службы доставки",
+                        "name": "delivery_service",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Только заказы этого покупателя",
+                        "name": "customer_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Поток событий order",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Order"
+                        }
+                    },
+                    "503": {
+                        "description": "Слишком много подключенных клиентов или сервис останавливается",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/orders/{order_uid}": {
             "get": {
-                "description": "Возвращает заказ по уникальному идентификатору",
+                "description": "Возвращает заказ по уникальному идентификатору вместе с его возвратами и суммой к оплате за вычетом возвратов",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Получить заказ",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Уникальный идентификатор заказа",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешный ответ с данными заказа",
+                        "schema": {
+                            "$ref": "#/definitions/entity.OrderDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат order_uid",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет доставку, оплату и/или состав заказа. Требует заголовок If-Match с ETag текущей версии",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Изменить заказ",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Уникальный идентификатор заказа",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag текущей версии заказа",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные заказа",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.OrderUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновленный заказ",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Конфликт уникальных данных",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Версия заказа устарела",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Помечает заказ как удаленный (мягкое удаление). Удаленный заказ больше не возвращается API",
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Удалить заказ",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Уникальный идентификатор заказа",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Заказ удален"
+                    },
+                    "400": {
+                        "description": "Неверный формат order_uid",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{order_uid}/refunds": {
+            "post": {
+                "description": "Возвращает товары заказа по rid (на сумму их total_price) либо произвольную сумму в валюте оплаты. Сумма всех возвратов не может превышать сумму оплаты",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Оформить возврат",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Уникальный идентификатор заказа",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Товары (items) или сумма (amount) возврата",
+                        "name": "refund",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.RefundRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданный возврат",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Refund"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса или неизвестный rid",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Товар уже возвращен или заказ изменен параллельно",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Сумма возвратов превышает сумму оплаты",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schemas/{subject}/versions": {
+            "get": {
+                "description": "Возвращает зарегистрированные версии JSON Schema для subject (без самих схем)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schemas"
+                ],
+                "summary": "Список версий схемы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subject, например order",
+                        "name": "subject",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Версии схемы",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.SchemaVersion"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Subject не найден",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schemas/{subject}/versions/{version}": {
+            "get": {
+                "description": "Возвращает JSON Schema указанной версии; вместо номера можно передать latest",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schemas"
+                ],
+                "summary": "Получить схему",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subject, например order",
+                        "name": "subject",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Номер версии или latest",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JSON Schema",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный номер версии",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Схема не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Возвращает все подписки (без секретов), включая отключенные",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Список подписок на вебхуки",
+                "responses": {
+                    "200": {
+                        "description": "Подписки",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.WebhookSubscription"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Подписывает URL на события заказов (OrderCreated, OrderUpdated, OrderDeleted, RefundCreated). Тело запроса подписывается HMAC-SHA256 с секретом подписки; если секрет не передан, он генерируется и возвращается только в этом ответе",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Создать подписку на вебхуки",
+                "parameters": [
+                    {
+                        "description": "URL, типы событий и секрет (16–256 символов)",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.WebhookSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданная подписка вместе с секретом",
+                        "schema": {
+                            "$ref": "#/definitions/entity.WebhookSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный URL, тип события или секрет",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{subscription_id}": {
+            "get": {
+                "description": "Возвращает подписку (без секрета) с числом неудачных доставок подряд",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Получить подписку на вебхуки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор подписки",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка",
+                        "schema": {
+                            "$ref": "#/definitions/entity.WebhookSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный идентификатор подписки",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет URL, типы событий и состояние подписки. Секрет меняется, только если передан новый. Включение подписки сбрасывает счетчик неудачных доставок",
                 "consumes": [
                     "application/json"
                 ],
@@ -33,33 +872,153 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Webhooks"
                 ],
-                "summary": "Получить заказ",
+                "summary": "Изменить подписку на вебхуки",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Уникальный идентификатор заказа",
-                        "name": "order_uid",
+                        "description": "Идентификатор подписки",
+                        "name": "subscription_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "URL, типы событий, новый секрет и enabled",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.WebhookSubscriptionRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешный ответ с данными заказа",
+                        "description": "Измененная подписка",
                         "schema": {
-                            "$ref": "#/definitions/entity.Order"
+                            "$ref": "#/definitions/entity.WebhookSubscription"
                         }
                     },
                     "400": {
-                        "description": "Неверный формат order_uid",
+                        "description": "Неверный URL, тип события или секрет",
                         "schema": {
                             "$ref": "#/definitions/httpt.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Заказ не найден",
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет подписку вместе с журналом ее доставок",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Удалить подписку на вебхуки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор подписки",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Подписка удалена"
+                    },
+                    "400": {
+                        "description": "Неверный идентификатор подписки",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{subscription_id}/deliveries": {
+            "get": {
+                "description": "Возвращает доставки подписки, начиная с последней: статус, число попыток, время следующей попытки, код ответа и последнюю ошибку",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Журнал доставок вебхука",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор подписки",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "succeeded",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Статус доставки",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы (1–100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Страница доставок",
+                        "schema": {
+                            "$ref": "#/definitions/entity.WebhookDeliveryPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный идентификатор, статус или параметры пагинации",
+                        "schema": {
+                            "$ref": "#/definitions/httpt.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
                         "schema": {
                             "$ref": "#/definitions/httpt.ErrorResponse"
                         }
@@ -75,6 +1034,72 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "entity.AnalyticsPoint": {
+            "type": "object",
+            "properties": {
+                "bucket": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "orders": {
+                    "type": "integer"
+                },
+                "revenue": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.BrandCount": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.CustomerSummary": {
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "type": "string"
+                },
+                "delivery_cities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "first_order_at": {
+                    "type": "string"
+                },
+                "last_order_at": {
+                    "type": "string"
+                },
+                "order_count": {
+                    "type": "integer"
+                },
+                "top_brands": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.BrandCount"
+                    }
+                },
+                "total": {
+                    "$ref": "#/definitions/entity.Money"
+                },
+                "total_spent": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Money"
+                    }
+                }
+            }
+        },
         "entity.Delivery": {
             "type": "object",
             "required": [
@@ -130,44 +1155,139 @@ const docTemplate = `{
                 "track_number"
             ],
             "properties": {
-                "brand": {
+                "brand": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "chrt_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "nm_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "price": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "rid": {
+                    "type": "string"
+                },
+                "sale": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "size": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "total_price": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "track_number": {
+                    "type": "string",
+                    "maxLength": 50
+                }
+            }
+        },
+        "entity.ItemCount": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "integer"
+                },
+                "nm_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.Money": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Order": {
+            "type": "object",
+            "required": [
+                "customer_id",
+                "date_created",
+                "delivery",
+                "delivery_service",
+                "entry",
+                "items",
+                "locale",
+                "oof_shard",
+                "order_uid",
+                "payment",
+                "shardkey",
+                "sm_id",
+                "track_number"
+            ],
+            "properties": {
+                "customer_id": {
                     "type": "string",
-                    "maxLength": 100
+                    "maxLength": 50
                 },
-                "chrt_id": {
-                    "type": "integer",
-                    "minimum": 1
+                "date_created": {
+                    "type": "string"
                 },
-                "name": {
+                "delivery": {
+                    "$ref": "#/definitions/entity.Delivery"
+                },
+                "delivery_service": {
                     "type": "string",
-                    "maxLength": 255
+                    "maxLength": 50
                 },
-                "nm_id": {
-                    "type": "integer",
-                    "minimum": 1
+                "entry": {
+                    "type": "string",
+                    "maxLength": 10
                 },
-                "price": {
-                    "type": "integer",
-                    "minimum": 1
+                "internal_signature": {
+                    "type": "string",
+                    "maxLength": 255
                 },
-                "rid": {
+                "items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/entity.Item"
+                    }
+                },
+                "locale": {
                     "type": "string"
                 },
-                "sale": {
-                    "type": "integer",
-                    "maximum": 100,
-                    "minimum": 0
+                "oof_shard": {
+                    "type": "string"
                 },
-                "size": {
+                "order_uid": {
                     "type": "string"
                 },
-                "status": {
-                    "type": "integer",
-                    "minimum": 0
+                "payment": {
+                    "$ref": "#/definitions/entity.Payment"
                 },
-                "total_price": {
+                "shardkey": {
+                    "type": "string",
+                    "maxLength": 10
+                },
+                "sm_id": {
                     "type": "integer",
-                    "minimum": 1
+                    "minimum": 0
                 },
                 "track_number": {
                     "type": "string",
@@ -175,7 +1295,42 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.Order": {
+        "entity.OrderAnalytics": {
+            "type": "object",
+            "properties": {
+                "bucket": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "reporting_currency": {
+                    "type": "string"
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.AnalyticsPoint"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                },
+                "top_brands": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.BrandCount"
+                    }
+                },
+                "top_items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ItemCount"
+                    }
+                }
+            }
+        },
+        "entity.OrderDetails": {
             "type": "object",
             "required": [
                 "customer_id",
@@ -225,6 +1380,9 @@ const docTemplate = `{
                 "locale": {
                     "type": "string"
                 },
+                "net_amount": {
+                    "type": "integer"
+                },
                 "oof_shard": {
                     "type": "string"
                 },
@@ -234,6 +1392,15 @@ const docTemplate = `{
                 "payment": {
                     "$ref": "#/definitions/entity.Payment"
                 },
+                "refunded_amount": {
+                    "type": "integer"
+                },
+                "refunds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Refund"
+                    }
+                },
                 "shardkey": {
                     "type": "string",
                     "maxLength": 10
@@ -248,6 +1415,44 @@ const docTemplate = `{
                 }
             }
         },
+        "entity.OrderPage": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Order"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.OrderUpdate": {
+            "type": "object",
+            "properties": {
+                "delivery": {
+                    "$ref": "#/definitions/entity.Delivery"
+                },
+                "items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/entity.Item"
+                    }
+                },
+                "payment": {
+                    "$ref": "#/definitions/entity.Payment"
+                }
+            }
+        },
         "entity.Payment": {
             "type": "object",
             "required": [
@@ -300,6 +1505,194 @@ const docTemplate = `{
                 }
             }
         },
+        "entity.Refund": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "order_uid": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "refund_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.RefundRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.SchemaVersion": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "definition": {
+                    "type": "object"
+                },
+                "fingerprint": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivery_id": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "order_uid": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "response_status": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.WebhookDeliveryPage": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.WebhookDelivery"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.WebhookSubscription": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled_at": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.WebhookSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
         "httpt.ErrorResponse": {
             "type": "object",
             "properties": {
@@ -5,36 +5,60 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"time"
 
 	"wbtest/internal/config"
 	"wbtest/internal/entity"
+	"wbtest/internal/exchange"
+	"wbtest/internal/feed"
 	"wbtest/internal/repository"
+	"wbtest/internal/schema"
 	"wbtest/internal/service"
 	httpt "wbtest/internal/transport/http"
 	kafkat "wbtest/internal/transport/kafka"
+	"wbtest/internal/webhook"
+	"wbtest/internal/worker"
+	"wbtest/migrations"
 	"wbtest/pkg/cache"
 	"wbtest/pkg/kafka"
 	"wbtest/pkg/kafka/dlq"
 	"wbtest/pkg/logger"
 	"wbtest/pkg/metric"
 	"wbtest/pkg/storage/postgres"
+	"wbtest/pkg/storage/postgres/migrate"
 	"wbtest/pkg/storage/postgres/transaction"
+	"wbtest/pkg/tracing"
 
 	"github.com/google/uuid"
+	kafkago "github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"golang.org/x/sync/errgroup"
 )
 
+const _tracingShutdownTimeout = 5 * time.Second
+
 func Run(ctx context.Context, cfg *config.Config, log logger.Logger) error {
 	eg, ctx := errgroup.WithContext(ctx)
 
 	metrics := initMetrics(eg, &cfg.Metrics, log)
 
-	db, dbErr := initDatabase(&cfg.Postgres, log)
+	tracerProvider, tracingErr := initTracing(ctx, cfg)
+	if tracingErr != nil {
+		return tracingErr
+	}
+	defer shutdownTracing(tracerProvider, log)
+
+	db, dbErr := initDatabase(&cfg.Postgres, cfg.App.Name, log, metrics)
 	if dbErr != nil {
 		return dbErr
 	}
 	defer closeDB(db)
 
+	if err := initSchemaVersion(ctx, &cfg.Migrations, db, log); err != nil {
+		return err
+	}
+
 	txManager, txErr := initTransactionManager(
 		db,
 		log,
@@ -50,11 +74,29 @@ func Run(ctx context.Context, cfg *config.Config, log logger.Logger) error {
 	}
 	defer stopCache(orderCache)
 
+	summaryCache, cacheErr := initSummaryCache(&cfg.Cache, log, metrics)
+	if cacheErr != nil {
+		return cacheErr
+	}
+	defer stopCache(summaryCache)
+
+	rates, ratesErr := initExchangeRates(&cfg.Exchange, log)
+	if ratesErr != nil {
+		return ratesErr
+	}
+
+	webhookRepo := repository.NewWebhookRepository(db)
+	orderFeed := feed.NewHub(cfg.Feed.BufferSize, cfg.Feed.MaxClients, metrics.Feed())
+
 	orderService := initOrderService(
 		cfg,
 		db,
 		txManager,
 		orderCache,
+		summaryCache,
+		rates,
+		webhookRepo,
+		orderFeed,
 		log,
 	)
 
@@ -62,14 +104,36 @@ func Run(ctx context.Context, cfg *config.Config, log logger.Logger) error {
 		log.Errorw("failed to restore cache from database", "error", err)
 	}
 
-	if serverErr := initHTTPServer(ctx, eg, &cfg.HTTP, orderService, log, metrics); serverErr != nil {
+	schemaService := initSchemaService(ctx, db, txManager, log)
+	webhookService := initWebhookService(&cfg.Webhooks, webhookRepo, txManager, log)
+
+	serverErr := initHTTPServer(
+		ctx,
+		eg,
+		cfg,
+		orderService,
+		schemaService,
+		webhookService,
+		orderFeed,
+		log,
+		metrics,
+	)
+	if serverErr != nil {
 		return serverErr
 	}
 
-	if kafkaErr := initKafkaComponents(ctx, eg, cfg, orderService, log, metrics); kafkaErr != nil {
+	if kafkaErr := initKafkaComponents(ctx, eg, cfg, orderService, schemaService, log, metrics); kafkaErr != nil {
 		return kafkaErr
 	}
 
+	initRetentionWorker(ctx, eg, &cfg.Retention, orderService, log)
+
+	if err := initEventRelay(ctx, eg, cfg, orderService, log); err != nil {
+		return err
+	}
+
+	initWebhookDispatcher(ctx, eg, cfg, webhookService, log)
+
 	return waitForShutdown(eg)
 }
 
@@ -100,11 +164,49 @@ func initMetrics(
 	return metrics
 }
 
-func initDatabase(cfg *config.Postgres, log logger.Logger) (*postgres.Postgres, error) {
+func initTracing(ctx context.Context, cfg *config.Config) (*sdktrace.TracerProvider, error) {
+	provider, err := tracing.NewProvider(ctx, cfg.Tracing, cfg.App, cfg.Env)
+	if err != nil {
+		return nil, fmt.Errorf("app.initTracing: %w", err)
+	}
+	return provider, nil
+}
+
+// shutdownTracing flushes buffered spans; it gets its own context because the run context
+// is already canceled by the time Run returns.
+func shutdownTracing(provider *sdktrace.TracerProvider, log logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), _tracingShutdownTimeout)
+	defer cancel()
+
+	if err := provider.Shutdown(ctx); err != nil {
+		log.Errorw("failed to shut down tracer provider", "error", err)
+	}
+}
+
+func initDatabase(
+	cfg *config.Postgres,
+	appName string,
+	log logger.Logger,
+	metrics metric.Factory,
+) (*postgres.Postgres, error) {
+	dbLog := log.With("component", "database")
+
 	db, err := postgres.NewPostgres(
 		cfg,
-		log.With("component", "database"),
+		dbLog,
+		postgres.ApplicationName(appName),
 		postgres.MaxPoolSize(cfg.PoolMax),
+		postgres.MinPoolSize(cfg.PoolMin),
+		postgres.MaxConnLifetime(cfg.MaxConnLifetime),
+		postgres.MaxConnIdleTime(cfg.MaxConnIdleTime),
+		postgres.HealthCheckPeriod(cfg.HealthCheckPeriod),
+		postgres.Tracer(postgres.NewQueryTracer(otel.GetTracerProvider())),
+		postgres.Tracer(postgres.NewStatementTracer(metrics.Database(), dbLog, cfg.SlowQueryThreshold)),
+		postgres.PoolMetrics(metrics.Database()),
+		postgres.Replicas(cfg.Replicas...),
+		postgres.ReplicaSelection(cfg.ReplicaSelection),
+		postgres.ReplicaMaxLag(cfg.ReplicaMaxLag),
+		postgres.ReplicaCheckInterval(cfg.ReplicaCheckInterval),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("app.initDatabase: %w", err)
@@ -112,6 +214,38 @@ func initDatabase(cfg *config.Postgres, log logger.Logger) (*postgres.Postgres,
 	return db, nil
 }
 
+// initSchemaVersion optionally migrates the database, then makes sure its schema is the one
+// this binary was built with.
+func initSchemaVersion(
+	ctx context.Context,
+	cfg *config.Migrations,
+	db *postgres.Postgres,
+	log logger.Logger,
+) error {
+	const op = "app.initSchemaVersion"
+
+	migrator, err := migrate.New(db.Pool, migrations.FS, log.With("component", "migrator"))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if cfg.Auto {
+		applied, upErr := migrator.Up(ctx)
+		if upErr != nil {
+			return fmt.Errorf("%s: %w", op, upErr)
+		}
+		log.Infow("database schema is up to date", "applied", applied, "version", migrator.Latest())
+	}
+
+	if cfg.Verify {
+		if err = migrator.Check(ctx); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
 func closeDB(db *postgres.Postgres) {
 	if db != nil {
 		db.Close()
@@ -151,49 +285,151 @@ func initCache(
 	return orderCache, nil
 }
 
-func stopCache(orderCache cache.Cache[uuid.UUID, *entity.Order]) {
-	if orderCache != nil {
-		orderCache.StopCleanup()
+// initSummaryCache holds customer summaries apart from orders, so that aggregate lookups
+// never evict orders.
+func initSummaryCache(
+	cfg *config.Cache,
+	log logger.Logger,
+	metrics metric.Factory,
+) (cache.Cache[string, *entity.CustomerSummary], error) {
+	summaryCache, err := cache.NewLRUCache[string, *entity.CustomerSummary](
+		cfg.SummaryCapacity,
+		log.With("component", "summary cache"),
+		metrics.Cache(),
+		cache.WithName("customer_summary"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("app.initSummaryCache: %w", err)
+	}
+	summaryCache.StartCleanup(cfg.CleanupInterval)
+	return summaryCache, nil
+}
+
+func stopCache[K comparable, V any](c cache.Cache[K, V]) {
+	if c != nil {
+		c.StopCleanup()
 	}
 }
 
+func initExchangeRates(cfg *config.Exchange, log logger.Logger) (*exchange.StaticProvider, error) {
+	rates, err := exchange.NewStaticProvider(cfg.RatesFile)
+	if err != nil {
+		return nil, fmt.Errorf("app.initExchangeRates: %w", err)
+	}
+
+	log.Infow("exchange rates loaded",
+		"base", rates.Base(),
+		"date", rates.Date(),
+		"currencies", rates.Currencies(),
+		"reporting_currency", cfg.ReportingCurrency,
+	)
+
+	return rates, nil
+}
+
 func initOrderService(
 	cfg *config.Config,
 	db *postgres.Postgres,
 	txManager transaction.Manager,
 	orderCache cache.Cache[uuid.UUID, *entity.Order],
+	summaryCache cache.Cache[string, *entity.CustomerSummary],
+	rates service.RateProvider,
+	webhookQueue service.WebhookQueue,
+	publisher service.OrderPublisher,
 	log logger.Logger,
 ) *service.OrderService {
 	orderRepo := repository.NewOrderRepository(db)
 	deliveryRepo := repository.NewDeliveryRepository(db)
 	paymentRepo := repository.NewPaymentRepository(db)
 	itemRepo := repository.NewItemRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	archiveRepo := repository.NewArchiveRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	customerRepo := repository.NewCustomerRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+
+	orderService := service.NewOrderService(service.OrderServiceDeps{
+		DeliveryRepo:      deliveryRepo,
+		ItemRepo:          itemRepo,
+		OrderRepo:         orderRepo,
+		PaymentRepo:       paymentRepo,
+		AuditRepo:         auditRepo,
+		ArchiveRepo:       archiveRepo,
+		SearchRepo:        searchRepo,
+		CustomerRepo:      customerRepo,
+		AnalyticsRepo:     analyticsRepo,
+		RefundRepo:        refundRepo,
+		EventRepo:         eventRepo,
+		WebhookQueue:      webhookQueue,
+		Publisher:         publisher,
+		TxManager:         txManager,
+		Logger:            log.With("component", "order service"),
+		Cache:             orderCache,
+		CacheTTL:          cfg.Cache.TTL,
+		SummaryCache:      summaryCache,
+		SummaryTTL:        cfg.Cache.SummaryTTL,
+		Rates:             rates,
+		ReportingCurrency: cfg.Exchange.ReportingCurrency,
+	})
+
+	return orderService
+}
 
-	orderService := service.NewOrderService(
-		deliveryRepo,
-		itemRepo,
-		orderRepo,
-		paymentRepo,
+// initSchemaService registers the schema generated from entity.Order so producers can reference
+// it by version. An incompatible change is logged rather than fatal: messages without a
+// schema-version header are still accepted.
+func initSchemaService(
+	ctx context.Context,
+	db *postgres.Postgres,
+	txManager transaction.Manager,
+	log logger.Logger,
+) *service.SchemaService {
+	schemaService := service.NewSchemaService(
+		repository.NewSchemaRepository(db),
 		txManager,
-		log.With("component", "order service"),
-		orderCache,
-		cfg.Cache.TTL,
+		log.With("component", "schema service"),
 	)
 
-	return orderService
+	orderSchema, err := service.GenerateOrderSchema()
+	if err != nil {
+		log.Errorw("failed to generate order schema", "error", err)
+		return schemaService
+	}
+
+	version, _, err := schemaService.Register(ctx, entity.SchemaSubjectOrder, orderSchema, schema.ModeBackward)
+	if err != nil {
+		log.Errorw("failed to register order schema", "error", err)
+		return schemaService
+	}
+	log.Infow("order schema registered", "version", version.Version)
+
+	return schemaService
 }
 
 func initHTTPServer(
 	ctx context.Context,
 	eg *errgroup.Group,
-	cfg *config.HTTP,
+	cfg *config.Config,
 	orderService *service.OrderService,
+	schemaService *service.SchemaService,
+	webhookService *service.WebhookService,
+	orderFeed *feed.Hub,
 	log logger.Logger,
 	metrics metric.Factory,
 ) error {
 	httpServer, err := httpt.NewHTTPServer(
-		httpt.NewOrderHandler(orderService, log, metrics.HTTP()),
-		cfg,
+		httpt.NewOrderHandler(
+			orderService,
+			schemaService,
+			webhookService,
+			orderFeed,
+			cfg.Feed.KeepAlive,
+			log,
+			metrics.HTTP(),
+		),
+		&cfg.HTTP,
 		log.With("component", "http server"),
 	)
 	if err != nil {
@@ -211,6 +447,7 @@ func initKafkaComponents(
 	eg *errgroup.Group,
 	cfg *config.Config,
 	orderService *service.OrderService,
+	schemaService *service.SchemaService,
 	log logger.Logger,
 	metrics metric.Factory,
 ) error {
@@ -224,10 +461,17 @@ func initKafkaComponents(
 		return fmt.Errorf("app.initKafkaComponents: dead letter queue creation: %w", err)
 	}
 
+	codecs, err := kafkat.DefaultCodecs()
+	if err != nil {
+		return fmt.Errorf("app.initKafkaComponents: message codecs: %w", err)
+	}
+
 	orderConsumer := kafkat.NewOrderConsumer(
 		kafkaReader,
 		deadLetterQueue,
 		orderService,
+		codecs,
+		schemaService,
 		metrics.Kafka(),
 		log,
 	)
@@ -239,6 +483,7 @@ func initKafkaComponents(
 		kafkaReader,
 		deadLetterQueue,
 		orderService,
+		codecs,
 		cfg.DLQ.MaxRetryCount,
 		log,
 	)
@@ -249,6 +494,120 @@ func initKafkaComponents(
 	return nil
 }
 
+func initRetentionWorker(
+	ctx context.Context,
+	eg *errgroup.Group,
+	cfg *config.Retention,
+	orderService *service.OrderService,
+	log logger.Logger,
+) {
+	if !cfg.Enabled {
+		return
+	}
+
+	retentionWorker := worker.NewRetentionWorker(
+		orderService,
+		*cfg,
+		log.With("component", "retention worker"),
+	)
+	eg.Go(func() error {
+		return retentionWorker.Start(ctx)
+	})
+}
+
+// initEventRelay drains the order event outbox into webhook deliveries and, when enabled, to
+// Kafka. The writer is closed once the relay has stopped, flushing what it buffered.
+func initEventRelay(
+	ctx context.Context,
+	eg *errgroup.Group,
+	cfg *config.Config,
+	orderService *service.OrderService,
+	log logger.Logger,
+) error {
+	if !cfg.Events.Enabled && !cfg.Webhooks.Enabled {
+		return nil
+	}
+
+	var (
+		writer      worker.EventWriter
+		kafkaWriter *kafkago.Writer
+	)
+	if cfg.Events.Enabled {
+		var err error
+		kafkaWriter, err = kafka.NewKafkaWriter(cfg.Kafka.Brokers, cfg.Events, log.With("component", "kafka writer"))
+		if err != nil {
+			return fmt.Errorf("app.initEventRelay: kafka writer creation: %w", err)
+		}
+		writer = kafkaWriter
+	}
+
+	eventRelay := worker.NewEventRelay(
+		orderService,
+		writer,
+		cfg.Events,
+		log.With("component", "event relay"),
+	)
+	eg.Go(func() error {
+		defer func() {
+			if kafkaWriter == nil {
+				return
+			}
+			if closeErr := kafkaWriter.Close(); closeErr != nil {
+				log.Errorw("failed to close kafka writer", "error", closeErr)
+			}
+		}()
+		return eventRelay.Start(ctx)
+	})
+
+	return nil
+}
+
+// initWebhookService leases claimed deliveries for as long as sending a whole batch may take,
+// plus one timeout of slack, so a slow batch is not claimed again by another instance.
+func initWebhookService(
+	cfg *config.Webhooks,
+	repo service.WebhookRepository,
+	txManager transaction.Manager,
+	log logger.Logger,
+) *service.WebhookService {
+	rounds := (cfg.BatchSize + cfg.Concurrency - 1) / cfg.Concurrency
+
+	return service.NewWebhookService(
+		repo,
+		txManager,
+		log.With("component", "webhook service"),
+		service.WebhookPolicy{
+			MaxAttempts:    cfg.MaxAttempts,
+			BaseRetryDelay: cfg.BaseRetryDelay,
+			MaxRetryDelay:  cfg.MaxRetryDelay,
+			DisableAfter:   cfg.DisableAfter,
+			Lease:          cfg.Timeout * time.Duration(rounds+1),
+		},
+	)
+}
+
+func initWebhookDispatcher(
+	ctx context.Context,
+	eg *errgroup.Group,
+	cfg *config.Config,
+	webhookService *service.WebhookService,
+	log logger.Logger,
+) {
+	if !cfg.Webhooks.Enabled {
+		return
+	}
+
+	dispatcher := worker.NewWebhookDispatcher(
+		webhookService,
+		webhook.NewSender(cfg.Webhooks.Timeout, cfg.App.Name+"/"+cfg.App.Version, cfg.Webhooks.AllowedNetworks),
+		cfg.Webhooks,
+		log.With("component", "webhook dispatcher"),
+	)
+	eg.Go(func() error {
+		return dispatcher.Start(ctx)
+	})
+}
+
 func waitForShutdown(eg *errgroup.Group) error {
 	if err := eg.Wait(); err != nil && !isShutdownSignal(err) {
 		return fmt.Errorf("app.waitForShutdown: application failed: %w", err)
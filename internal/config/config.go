@@ -4,6 +4,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net/netip"
 	"os"
 	"strings"
 	"time"
@@ -16,15 +17,22 @@ import (
 
 type (
 	Config struct {
-		App      App      `env-prefix:"APP_"`
-		Logger   Logger   `env-prefix:"LOGGER_"`
-		Postgres Postgres `env-prefix:"DB_"`
-		HTTP     HTTP     `env-prefix:"HTTP_"`
-		Cache    Cache    `env-prefix:"CACHE_"`
-		Kafka    Kafka    `env-prefix:"KAFKA_"`
-		DLQ      DLQ      `env-prefix:"DLQ_"`
-		Metrics  Metrics  `env-prefix:"METRICS_"`
-		Env      string   `env:"ENV" env-default:"local" validate:"oneof=local dev staging prod"`
+		App        App        `env-prefix:"APP_"`
+		Logger     Logger     `env-prefix:"LOGGER_"`
+		Postgres   Postgres   `env-prefix:"DB_"`
+		HTTP       HTTP       `env-prefix:"HTTP_"`
+		Cache      Cache      `env-prefix:"CACHE_"`
+		Kafka      Kafka      `env-prefix:"KAFKA_"`
+		DLQ        DLQ        `env-prefix:"DLQ_"`
+		Metrics    Metrics    `env-prefix:"METRICS_"`
+		Retention  Retention  `env-prefix:"RETENTION_"`
+		Tracing    Tracing    `env-prefix:"TRACING_"`
+		Migrations Migrations `env-prefix:"MIGRATIONS_"`
+		Exchange   Exchange   `env-prefix:"EXCHANGE_"`
+		Events     Events     `env-prefix:"EVENTS_"`
+		Webhooks   Webhooks   `env-prefix:"WEBHOOKS_"`
+		Feed       Feed       `env-prefix:"FEED_"`
+		Env        string     `env:"ENV" env-default:"local" validate:"oneof=local dev staging prod"`
 	}
 
 	App struct {
@@ -38,12 +46,29 @@ type (
 		Port           string        `env:"PORT"             validate:"required,gte=1,lte=65535"`
 		Name           string        `env:"NAME"             validate:"required"`
 		User           string        `env:"USER"             validate:"required"`
-		Password       string        `env:"PASSWORD"         validate:"required"`
+		Password       string        `env:"PASSWORD"         validate:"required_without=PasswordFile"`
 		SSLMode        string        `env:"SSL_MODE"         validate:"required"`
 		PoolMax        int32         `env:"POOL_MAX"         validate:"min=1,max=100"                             env-default:"20"`
 		ConnAttempts   int           `env:"CONN_ATTEMPTS"    validate:"min=1,max=10"                              env-default:"5"`
 		BaseRetryDelay time.Duration `env:"BASE_RETRY_DELAY" validate:"gte=10ms,lte=10s"                          env-default:"100ms"`
 		MaxRetryDelay  time.Duration `env:"MAX_RETRY_DELAY"  validate:"gte=100ms,lte=30s,gtefield=BaseRetryDelay" env-default:"5s"`
+
+		PasswordFile       string `env:"PASSWORD_FILE"        validate:"omitempty,file"`
+		SSLRootCert        string `env:"SSL_ROOT_CERT"        validate:"omitempty,file"`
+		SSLCert            string `env:"SSL_CERT"             validate:"required_with=SSLKey,omitempty,file"`
+		SSLKey             string `env:"SSL_KEY"              validate:"required_with=SSLCert,omitempty,file"`
+		TargetSessionAttrs string `env:"TARGET_SESSION_ATTRS" validate:"oneof=any read-write read-only primary standby prefer-standby" env-default:"any"`
+
+		PoolMin            int32         `env:"POOL_MIN"             validate:"min=0,ltefield=PoolMax" env-default:"0"`
+		MaxConnLifetime    time.Duration `env:"MAX_CONN_LIFETIME"    validate:"gte=1m,lte=24h"         env-default:"1h"`
+		MaxConnIdleTime    time.Duration `env:"MAX_CONN_IDLE_TIME"   validate:"gte=10s,lte=24h"        env-default:"30m"`
+		HealthCheckPeriod  time.Duration `env:"HEALTH_CHECK_PERIOD"  validate:"gte=1s,lte=1h"          env-default:"1m"`
+		SlowQueryThreshold time.Duration `env:"SLOW_QUERY_THRESHOLD" validate:"gte=0s,lte=1m"          env-default:"200ms"`
+
+		Replicas             []string      `env:"REPLICAS"               validate:"dive,required"                env-separator:","`
+		ReplicaSelection     string        `env:"REPLICA_SELECTION"      validate:"oneof=round_robin least_conn" env-default:"round_robin"`
+		ReplicaMaxLag        time.Duration `env:"REPLICA_MAX_LAG"        validate:"gt=0s,lte=1h"                 env-default:"5s"`
+		ReplicaCheckInterval time.Duration `env:"REPLICA_CHECK_INTERVAL" validate:"gte=100ms,lte=5m"             env-default:"5s"`
 	}
 
 	HTTP struct {
@@ -60,6 +85,9 @@ type (
 		Capacity        int           `env:"CAPACITY"         validate:"required,min=1,max=1000000"`
 		TTL             time.Duration `env:"TTL"              validate:"required,gt=0s,lte=24h"     env-default:"5m"`
 		CleanupInterval time.Duration `env:"CLEANUP_INTERVAL" validate:"gt=0s,lte=24h"              env-default:"10s"`
+
+		SummaryCapacity int           `env:"SUMMARY_CAPACITY" validate:"min=1,max=1000000"          env-default:"10000"`
+		SummaryTTL      time.Duration `env:"SUMMARY_TTL"      validate:"gt=0s,lte=24h"              env-default:"5m"`
 	}
 
 	Kafka struct {
@@ -88,6 +116,75 @@ type (
 		ReadHeaderTimeout time.Duration `env:"READ_HEADER_TIMEOUT" validate:"gte=10ms,lte=30s"         env-default:"5s"`
 	}
 
+	// Retention purges orders soft-deleted more than MaxAge ago, BatchSize at a time every
+	// Interval. Live orders are never purged.
+	Retention struct {
+		Enabled   bool          `env:"ENABLED"    env-default:"false"`
+		MaxAge    time.Duration `env:"MAX_AGE"    validate:"gte=1h"                env-default:"8760h"`
+		Interval  time.Duration `env:"INTERVAL"   validate:"gte=1s,lte=168h"       env-default:"1h"`
+		BatchSize int           `env:"BATCH_SIZE" validate:"min=1,max=10000"       env-default:"500"`
+		Mode      string        `env:"MODE"       validate:"oneof=delete archive" env-default:"archive"`
+	}
+
+	// Auto applies pending migrations on start; Verify refuses to start unless the database
+	// is at the newest embedded migration.
+	Migrations struct {
+		Auto   bool `env:"AUTO"   env-default:"false"`
+		Verify bool `env:"VERIFY" env-default:"true"`
+	}
+
+	// Exchange rates normalize analytics and customer summaries to ReportingCurrency. Without
+	// RatesFile the rates built into the binary are used.
+	Exchange struct {
+		RatesFile         string `env:"RATES_FILE"         validate:"omitempty,file"`
+		ReportingCurrency string `env:"REPORTING_CURRENCY" validate:"omitempty,iso4217"`
+	}
+
+	// Events publishes the order event outbox to Topic on the Kafka brokers. The outbox is
+	// relayed while either Kafka publishing or webhooks are enabled; otherwise events
+	// accumulate in it.
+	Events struct {
+		Enabled      bool          `env:"ENABLED"       env-default:"false"`
+		Topic        string        `env:"TOPIC"         validate:"required_if=Enabled true" env-default:"order-events"`
+		Interval     time.Duration `env:"INTERVAL"      validate:"gte=100ms,lte=1h"         env-default:"1s"`
+		BatchSize    int           `env:"BATCH_SIZE"    validate:"min=1,max=1000"           env-default:"100"`
+		WriteTimeout time.Duration `env:"WRITE_TIMEOUT" validate:"gte=1ms,lte=30s"          env-default:"5s"`
+	}
+
+	// Webhooks sends order events to webhook subscriptions. A failed delivery is retried after
+	// BaseRetryDelay, doubling up to MaxRetryDelay, MaxAttempts times in all. A subscription is
+	// disabled after DisableAfter failed deliveries in a row. Deliveries to loopback, private and
+	// link-local addresses are refused unless they fall into one of AllowedNetworks.
+	Webhooks struct {
+		Enabled        bool          `env:"ENABLED"          env-default:"true"`
+		Interval       time.Duration `env:"INTERVAL"         validate:"gte=100ms,lte=1h"          env-default:"1s"`
+		BatchSize      int           `env:"BATCH_SIZE"       validate:"min=1,max=1000"            env-default:"100"`
+		Concurrency    int           `env:"CONCURRENCY"      validate:"min=1,max=100"             env-default:"8"`
+		Timeout        time.Duration `env:"TIMEOUT"          validate:"gte=100ms,lte=1m"          env-default:"5s"`
+		MaxAttempts    int           `env:"MAX_ATTEMPTS"     validate:"min=1,max=100"             env-default:"10"`
+		BaseRetryDelay time.Duration `env:"BASE_RETRY_DELAY" validate:"gte=1s,lte=1h"             env-default:"10s"`
+		MaxRetryDelay  time.Duration `env:"MAX_RETRY_DELAY"  validate:"gtefield=BaseRetryDelay"   env-default:"1h"`
+		DisableAfter   int           `env:"DISABLE_AFTER"    validate:"min=1,max=1000"            env-default:"20"`
+
+		AllowedNetworks []netip.Prefix `env:"ALLOWED_NETWORKS" env-separator:","`
+	}
+
+	// Feed streams newly created orders to at most MaxClients clients of GET /orders/stream.
+	// A client more than BufferSize orders behind is disconnected. KeepAlive is how often an
+	// idle stream is pinged.
+	Feed struct {
+		BufferSize int           `env:"BUFFER_SIZE" validate:"min=1,max=10000" env-default:"64"`
+		MaxClients int           `env:"MAX_CLIENTS" validate:"min=1,max=10000" env-default:"100"`
+		KeepAlive  time.Duration `env:"KEEP_ALIVE"  validate:"gte=1s,lte=5m"   env-default:"15s"`
+	}
+
+	Tracing struct {
+		Exporter    string  `env:"EXPORTER"     validate:"oneof=none stdout otlp"        env-default:"none"`
+		Endpoint    string  `env:"ENDPOINT"     validate:"required_if=Exporter otlp"     env-default:"localhost:4318"`
+		Insecure    bool    `env:"INSECURE"                                              env-default:"true"`
+		SampleRatio float64 `env:"SAMPLE_RATIO" validate:"gte=0,lte=1"                   env-default:"1"`
+	}
+
 	Logger struct {
 		Level      string `env:"LEVEL"       env-default:"info"                     validate:"oneof=debug info warn error"`
 		Filename   string `env:"FILENAME"    env-default:"./logs/order-service.log"`
@@ -105,6 +202,21 @@ func Load() (*Config, error) {
 	return LoadPath(path)
 }
 
+// _secretFields are never echoed back in validation errors; replica DSNs may embed passwords.
+var _secretFields = []string{
+	"Config.Postgres.Password",
+	"Config.Postgres.Replicas",
+}
+
+func isSecret(namespace string) bool {
+	for _, field := range _secretFields {
+		if strings.HasPrefix(namespace, field) {
+			return true
+		}
+	}
+	return false
+}
+
 func LoadPath(configPath string) (*Config, error) {
 	const op = "config.LoadPath"
 
@@ -126,8 +238,12 @@ func LoadPath(configPath string) (*Config, error) {
 		var validationErrs validator.ValidationErrors
 		if errors.As(err, &validationErrs) {
 			for _, ve := range validationErrs {
+				value := ve.Value()
+				if isSecret(ve.StructNamespace()) {
+					value = "<redacted>"
+				}
 				validationErrors = append(validationErrors,
-					fmt.Sprintf("%s=%v must satisfy '%s'", ve.Field(), ve.Value(), ve.Tag()))
+					fmt.Sprintf("%s=%v must satisfy '%s'", ve.Field(), value, ve.Tag()))
 			}
 			return nil, fmt.Errorf(
 				"%s: config validation: %v", op,
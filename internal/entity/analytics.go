@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+const (
+	BucketHour = "hour"
+	BucketDay  = "day"
+	BucketWeek = "week"
+)
+
+// AnalyticsFilter selects the orders created in [From, To). Empty string fields match any value.
+type AnalyticsFilter struct {
+	From            time.Time
+	To              time.Time
+	Bucket          string
+	DeliveryService string
+	Locale          string
+	Currency        string
+	Provider        string
+	Bank            string
+	Top             int
+	// ReportingCurrency, if set, merges the series into one currency.
+	ReportingCurrency string
+}
+
+// OrderAnalytics is built from hourly rollups; bucket boundaries are in UTC and weeks start
+// on Monday. Revenue is in the minor units of its currency, so series are split by currency
+// unless a reporting currency is requested.
+type OrderAnalytics struct {
+	Bucket            string           `json:"bucket"`
+	From              time.Time        `json:"from"`
+	To                time.Time        `json:"to"`
+	ReportingCurrency string           `json:"reporting_currency,omitempty"`
+	Series            []AnalyticsPoint `json:"series"`
+	TopItems          []ItemCount      `json:"top_items"`
+	TopBrands         []BrandCount     `json:"top_brands"`
+}
+
+type AnalyticsPoint struct {
+	Bucket   time.Time `json:"bucket"`
+	Currency string    `json:"currency"`
+	Orders   int64     `json:"orders"`
+	Revenue  int64     `json:"revenue"`
+}
+
+type ItemCount struct {
+	NMID  uint64 `json:"nm_id"`
+	Items int64  `json:"items"`
+}
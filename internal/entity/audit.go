@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	AuditActionRefund = "refund"
+)
+
+type AuditRecord struct {
+	OrderUID  uuid.UUID       `json:"order_uid"`
+	Version   int             `json:"version"`
+	Action    string          `json:"action"`
+	Diff      string          `json:"diff"`
+	Snapshot  json.RawMessage `json:"snapshot"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// CustomerSummary aggregates a customer's orders that are not deleted. TotalSpent is net of
+// refunds. Total is TotalSpent converted to a reporting currency and is only set when one is
+// requested.
+type CustomerSummary struct {
+	CustomerID     string       `json:"customer_id"`
+	OrderCount     int64        `json:"order_count"`
+	TotalSpent     []Money      `json:"total_spent"`
+	Total          *Money       `json:"total,omitempty"`
+	FirstOrderAt   time.Time    `json:"first_order_at"`
+	LastOrderAt    time.Time    `json:"last_order_at"`
+	TopBrands      []BrandCount `json:"top_brands"`
+	DeliveryCities []string     `json:"delivery_cities"`
+}
+
+type BrandCount struct {
+	Brand string `json:"brand"`
+	Items int64  `json:"items"`
+}
@@ -2,11 +2,21 @@ package entity
 
 import (
 	"errors"
+	"fmt"
 )
 
 var (
 	ErrDataNotFound     = errors.New("data not found")
 	ErrConflictingData  = errors.New("data conflicts with existing data in unique column")
 	ErrInvalidData      = errors.New("invalid data")
+	ErrVersionMismatch  = errors.New("data version does not match the current version")
 	ErrConfigPathNotSet = errors.New("CONFIG_PATH not set and -config flag not provided")
+	ErrIncompatible     = errors.New("schema is incompatible with the latest version")
+	ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+	ErrAmountOverflow   = errors.New("amount does not fit in 64 bits")
+	ErrNoExchangeRate   = errors.New("exchange rate not available")
+	ErrRefundExceeded   = errors.New("refund exceeds the amount left to refund")
+	// ErrOrderDeleted is returned when an order is created again after it has been deleted.
+	// It wraps ErrConflictingData, so it is handled as a conflict unless checked for first.
+	ErrOrderDeleted = fmt.Errorf("order has been deleted: %w", ErrConflictingData)
 )
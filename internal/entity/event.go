@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventOrderCreated  = "OrderCreated"
+	EventOrderUpdated  = "OrderUpdated"
+	EventOrderDeleted  = "OrderDeleted"
+	EventRefundCreated = "RefundCreated"
+)
+
+// EventTypes lists every event type, in the order they are documented.
+var EventTypes = []string{
+	EventOrderCreated,
+	EventOrderUpdated,
+	EventOrderDeleted,
+	EventRefundCreated,
+}
+
+// Event is a change to an order published to other services. It is stored in the outbox in
+// the same transaction as the change and relayed afterwards, so it is delivered at least once.
+type Event struct {
+	EventID   uuid.UUID       `json:"event_id"`
+	Type      string          `json:"type"`
+	OrderUID  uuid.UUID       `json:"order_uid"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,117 @@
+package entity
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// Money is an amount in the minor units of an ISO 4217 currency, e.g. cents for USD and
+// yen for JPY. Payment and Item keep their flat amount fields for wire compatibility;
+// Money is what code computing with amounts works on.
+type Money struct {
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+}
+
+// _minorUnits holds the active ISO 4217 codes and the number of digits after the decimal
+// separator. Codes without a minor unit (precious metals, SDR, test codes) are listed with 0.
+var _minorUnits = map[string]int{
+	"AED": 2, "AFN": 2, "ALL": 2, "AMD": 2, "ANG": 2, "AOA": 2, "ARS": 2, "AUD": 2, "AWG": 2, "AZN": 2,
+	"BAM": 2, "BBD": 2, "BDT": 2, "BGN": 2, "BHD": 3, "BIF": 0, "BMD": 2, "BND": 2, "BOB": 2, "BOV": 2,
+	"BRL": 2, "BSD": 2, "BTN": 2, "BWP": 2, "BYN": 2, "BZD": 2, "CAD": 2, "CDF": 2, "CHE": 2, "CHF": 2,
+	"CHW": 2, "CLF": 4, "CLP": 0, "CNY": 2, "COP": 2, "COU": 2, "CRC": 2, "CUC": 2, "CUP": 2, "CVE": 2,
+	"CZK": 2, "DJF": 0, "DKK": 2, "DOP": 2, "DZD": 2, "EGP": 2, "ERN": 2, "ETB": 2, "EUR": 2, "FJD": 2,
+	"FKP": 2, "GBP": 2, "GEL": 2, "GHS": 2, "GIP": 2, "GMD": 2, "GNF": 0, "GTQ": 2, "GYD": 2, "HKD": 2,
+	"HNL": 2, "HTG": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "IQD": 3, "IRR": 2, "ISK": 0, "JMD": 2,
+	"JOD": 3, "JPY": 0, "KES": 2, "KGS": 2, "KHR": 2, "KMF": 0, "KPW": 2, "KRW": 0, "KWD": 3, "KYD": 2,
+	"KZT": 2, "LAK": 2, "LBP": 2, "LKR": 2, "LRD": 2, "LSL": 2, "LYD": 3, "MAD": 2, "MDL": 2, "MGA": 2,
+	"MKD": 2, "MMK": 2, "MNT": 2, "MOP": 2, "MRU": 2, "MUR": 2, "MVR": 2, "MWK": 2, "MXN": 2, "MXV": 2,
+	"MYR": 2, "MZN": 2, "NAD": 2, "NGN": 2, "NIO": 2, "NOK": 2, "NPR": 2, "NZD": 2, "OMR": 3, "PAB": 2,
+	"PEN": 2, "PGK": 2, "PHP": 2, "PKR": 2, "PLN": 2, "PYG": 0, "QAR": 2, "RON": 2, "RSD": 2, "RUB": 2,
+	"RWF": 0, "SAR": 2, "SBD": 2, "SCR": 2, "SDG": 2, "SEK": 2, "SGD": 2, "SHP": 2, "SLE": 2, "SLL": 2,
+	"SOS": 2, "SRD": 2, "SSP": 2, "STN": 2, "SVC": 2, "SYP": 2, "SZL": 2, "THB": 2, "TJS": 2, "TMT": 2,
+	"TND": 3, "TOP": 2, "TRY": 2, "TTD": 2, "TWD": 2, "TZS": 2, "UAH": 2, "UGX": 0, "USD": 2, "USN": 2,
+	"UYI": 0, "UYU": 2, "UYW": 4, "UZS": 2, "VED": 2, "VES": 2, "VND": 0, "VUV": 0, "WST": 2, "XAF": 0,
+	"XAG": 0, "XAU": 0, "XBA": 0, "XBB": 0, "XBC": 0, "XBD": 0, "XCD": 2, "XCG": 2, "XDR": 0, "XOF": 0,
+	"XPD": 0, "XPF": 0, "XPT": 0, "XSU": 0, "XTS": 0, "XUA": 0, "XXX": 0, "YER": 2, "ZAR": 2, "ZMW": 2,
+	"ZWG": 2, "ZWL": 2,
+}
+
+// IsCurrency reports whether code is an ISO 4217 alphabetic code. Codes are upper case.
+func IsCurrency(code string) bool {
+	_, ok := _minorUnits[code]
+	return ok
+}
+
+// MinorUnits returns the number of minor unit digits of currency, 2 for USD.
+func MinorUnits(currency string) (int, error) {
+	digits, ok := _minorUnits[currency]
+	if !ok {
+		return 0, fmt.Errorf("unknown currency %q: %w", currency, ErrInvalidData)
+	}
+	return digits, nil
+}
+
+// Add returns m + other; both must be in the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("add %s to %s: %w", other.Currency, m.Currency, ErrCurrencyMismatch)
+	}
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) ||
+		(other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return Money{}, fmt.Errorf("add %s to %s: %w", other, m, ErrAmountOverflow)
+	}
+	return Money{Currency: m.Currency, Amount: m.Amount + other.Amount}, nil
+}
+
+// Convert returns m in currency to, where rate is the price of one major unit of m.Currency
+// in major units of to. The result is rounded half away from zero.
+func (m Money) Convert(rate *big.Rat, to string) (Money, error) {
+	fromDigits, err := MinorUnits(m.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+	toDigits, err := MinorUnits(to)
+	if err != nil {
+		return Money{}, err
+	}
+
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), rate)
+	value.Mul(value, new(big.Rat).SetFrac(pow10(toDigits), pow10(fromDigits)))
+
+	amount, ok := roundHalfAway(value)
+	if !ok {
+		return Money{}, fmt.Errorf("convert %s to %s: %w", m, to, ErrAmountOverflow)
+	}
+	return Money{Currency: to, Amount: amount}, nil
+}
+
+// String formats m in major units, e.g. "12.34 USD".
+func (m Money) String() string {
+	digits, ok := _minorUnits[m.Currency]
+	if !ok || digits == 0 {
+		return strconv.FormatInt(m.Amount, 10) + " " + m.Currency
+	}
+	return new(big.Rat).SetFrac(big.NewInt(m.Amount), pow10(digits)).FloatString(digits) + " " + m.Currency
+}
+
+func roundHalfAway(r *big.Rat) (int64, bool) {
+	num := new(big.Int).Abs(r.Num())
+	quo, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(r.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		quo.Neg(quo)
+	}
+	if !quo.IsInt64() {
+		return 0, false
+	}
+	return quo.Int64(), true
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
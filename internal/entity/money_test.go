@@ -0,0 +1,81 @@
+package entity_test
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"wbtest/internal/entity"
+)
+
+func TestMoney_Convert(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc  string
+		money entity.Money
+		rate  *big.Rat
+		to    string
+		want  int64
+	}{
+		{desc: "SameExponent", money: entity.Money{Currency: "USD", Amount: 1000}, rate: big.NewRat(87, 100), to: "EUR", want: 870},
+		{desc: "ToZeroDigits", money: entity.Money{Currency: "USD", Amount: 1999}, rate: big.NewRat(150, 1), to: "JPY", want: 2999},
+		{desc: "FromZeroDigits", money: entity.Money{Currency: "JPY", Amount: 1000}, rate: big.NewRat(1, 150), to: "USD", want: 667},
+		{desc: "ToThreeDigits", money: entity.Money{Currency: "USD", Amount: 100}, rate: big.NewRat(307, 1000), to: "KWD", want: 307},
+		{desc: "HalfAwayFromZero", money: entity.Money{Currency: "USD", Amount: -5}, rate: big.NewRat(1, 10), to: "EUR", want: -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tc.money.Convert(tc.rate, tc.to)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got.Currency != tc.to || got.Amount != tc.want {
+				t.Fatalf("expected %d %s, got %+v", tc.want, tc.to, got)
+			}
+		})
+	}
+
+	_, err := entity.Money{Currency: "USD", Amount: math.MaxInt64}.Convert(big.NewRat(2, 1), "EUR")
+	if !errors.Is(err, entity.ErrAmountOverflow) {
+		t.Fatalf("expected ErrAmountOverflow, got %v", err)
+	}
+	if _, err = (entity.Money{Currency: "XYZ", Amount: 1}).Convert(big.NewRat(1, 1), "USD"); !errors.Is(err, entity.ErrInvalidData) {
+		t.Fatalf("expected ErrInvalidData for an unknown currency, got %v", err)
+	}
+}
+
+func TestMoney_Add(t *testing.T) {
+	t.Parallel()
+
+	sum, err := entity.Money{Currency: "EUR", Amount: 150}.Add(entity.Money{Currency: "EUR", Amount: 250})
+	if err != nil || sum.Amount != 400 {
+		t.Fatalf("expected 400 EUR, got %+v (%v)", sum, err)
+	}
+
+	if _, err = (entity.Money{Currency: "EUR", Amount: 1}).Add(entity.Money{Currency: "USD", Amount: 1}); !errors.Is(err, entity.ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	if _, err = (entity.Money{Currency: "EUR", Amount: math.MaxInt64}).Add(entity.Money{Currency: "EUR", Amount: 1}); !errors.Is(err, entity.ErrAmountOverflow) {
+		t.Fatalf("expected ErrAmountOverflow, got %v", err)
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	t.Parallel()
+
+	for want, money := range map[string]entity.Money{
+		"12.34 USD": {Currency: "USD", Amount: 1234},
+		"-0.05 EUR": {Currency: "EUR", Amount: -5},
+		"1500 JPY":  {Currency: "JPY", Amount: 1500},
+		"1.234 KWD": {Currency: "KWD", Amount: 1234},
+	} {
+		if got := money.String(); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
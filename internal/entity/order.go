@@ -21,4 +21,21 @@ type Order struct {
 	SmID              int       `json:"sm_id"              validate:"required,gte=0"`
 	DateCreated       time.Time `json:"date_created"       validate:"required"`
 	OofShard          string    `json:"oof_shard"          validate:"required,len=1"`
+	PayloadHash       string    `json:"-"`
+	Version           int       `json:"-"`
+}
+
+type OrderUpdate struct {
+	Delivery *Delivery `json:"delivery" validate:"omitempty"`
+	Payment  *Payment  `json:"payment"  validate:"omitempty"`
+	Items    []*Item   `json:"items"    validate:"omitempty,min=1,dive"`
+}
+
+// OrderPage is one page of a paginated order listing. Total counts every match, not only
+// the orders on this page.
+type OrderPage struct {
+	Orders []*Order `json:"orders"`
+	Total  int64    `json:"total"`
+	Limit  int      `json:"limit"`
+	Offset int      `json:"offset"`
 }
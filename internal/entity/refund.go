@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ItemStatusRefunded marks an item returned through a refund. Item statuses otherwise come
+// from the producer as is, so this one is reserved: new orders carrying it are rejected.
+const ItemStatusRefunded = 410
+
+// Refund returns part of an order's payment, in minor units of the payment currency. Items
+// lists the rids of refunded items and is empty when an arbitrary amount was refunded.
+type Refund struct {
+	RefundID  uuid.UUID   `json:"refund_id"`
+	OrderUID  uuid.UUID   `json:"order_uid"`
+	Amount    int64       `json:"amount"`
+	Currency  string      `json:"currency"`
+	Items     []uuid.UUID `json:"items"`
+	Reason    string      `json:"reason,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// RefundRequest asks to refund either the items with the given rids, for their total price,
+// or an amount; exactly one of them must be set.
+type RefundRequest struct {
+	Items  []uuid.UUID `json:"items"`
+	Amount int64       `json:"amount"`
+	Reason string      `json:"reason"`
+}
+
+// OrderDetails is an order together with its refunds. NetAmount is the payment amount less
+// everything refunded.
+type OrderDetails struct {
+	*Order
+	Refunds        []*Refund `json:"refunds"`
+	RefundedAmount int64     `json:"refunded_amount"`
+	NetAmount      int64     `json:"net_amount"`
+}
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	SchemaSubjectOrder = "order"
+
+	// SchemaVersionHeader names the schema version a Kafka message claims to follow.
+	SchemaVersionHeader = "schema-version"
+)
+
+type SchemaVersion struct {
+	Subject     string          `json:"subject"`
+	Version     int             `json:"version"`
+	Fingerprint string          `json:"fingerprint"`
+	Definition  json.RawMessage `json:"definition,omitempty" swaggertype:"object"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// WebhookSubscription pushes events of EventTypes to URL. Secret signs every delivery and is
+// only returned when the subscription is created or the secret is rotated. A subscription is
+// disabled after too many consecutive failed attempts and stays so until it is re-enabled.
+type WebhookSubscription struct {
+	SubscriptionID      uuid.UUID  `json:"subscription_id"`
+	URL                 string     `json:"url"`
+	EventTypes          []string   `json:"event_types"`
+	Secret              string     `json:"secret,omitempty"`
+	Enabled             bool       `json:"enabled"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// WebhookSubscriptionRequest creates or replaces a subscription. Without Secret a random one
+// is generated on create and the current one is kept on replace. Enabled defaults to true;
+// enabling a subscription resets its failure count.
+type WebhookSubscriptionRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+	Enabled    *bool    `json:"enabled"`
+}
+
+// WebhookDelivery is one event sent, or to be sent, to one subscription.
+type WebhookDelivery struct {
+	DeliveryID     uuid.UUID       `json:"delivery_id"`
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	OrderUID       uuid.UUID       `json:"order_uid"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	OccurredAt     time.Time       `json:"occurred_at"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// Event returns the envelope sent to the subscriber, the same one published to Kafka.
+func (d *WebhookDelivery) Event() *Event {
+	return &Event{
+		EventID:   d.EventID,
+		Type:      d.EventType,
+		OrderUID:  d.OrderUID,
+		Payload:   d.Payload,
+		CreatedAt: d.OccurredAt,
+	}
+}
+
+// OutgoingWebhook is a due delivery together with where to send it and the secret to sign it.
+type OutgoingWebhook struct {
+	Delivery *WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// DeliveryAttempt is the outcome of sending a delivery once. Err is nil when the subscriber
+// answered with a 2xx status.
+type DeliveryAttempt struct {
+	ResponseStatus int
+	Err            error
+}
+
+type WebhookDeliveryPage struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+	Total      int64              `json:"total"`
+	Limit      int                `json:"limit"`
+	Offset     int                `json:"offset"`
+}
@@ -0,0 +1,120 @@
+// Package exchange provides currency exchange rates for reporting. Rates are indicative and
+// must not be used to settle payments.
+package exchange
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"wbtest/internal/entity"
+)
+
+// _defaultRates is used when no rates file is configured.
+//
+//go:embed rates.json
+var _defaultRates []byte
+
+// StaticProvider serves rates loaded once from a JSON file of the form
+//
+//	{"base": "USD", "date": "2025-08-01", "rates": {"EUR": "0.87", "JPY": "150.5"}}
+//
+// where each rate is the price of one unit of base in that currency. Rates are strings so
+// they are read without float rounding.
+type StaticProvider struct {
+	base  string
+	date  string
+	rates map[string]*big.Rat
+}
+
+type ratesFile struct {
+	Base  string            `json:"base"`
+	Date  string            `json:"date"`
+	Rates map[string]string `json:"rates"`
+}
+
+// NewStaticProvider loads rates from path, or the rates built into the binary if path is empty.
+func NewStaticProvider(path string) (*StaticProvider, error) {
+	const op = "exchange.NewStaticProvider"
+
+	data := _defaultRates
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	p, err := ParseRates(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return p, nil
+}
+
+func ParseRates(data []byte) (*StaticProvider, error) {
+	var file ratesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decode rates: %w", err)
+	}
+	if !entity.IsCurrency(file.Base) {
+		return nil, fmt.Errorf("unknown base currency %q", file.Base)
+	}
+
+	p := &StaticProvider{
+		base:  file.Base,
+		date:  file.Date,
+		rates: map[string]*big.Rat{file.Base: big.NewRat(1, 1)},
+	}
+	for currency, value := range file.Rates {
+		if !entity.IsCurrency(currency) {
+			return nil, fmt.Errorf("unknown currency %q", currency)
+		}
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("rate %q for %s must be a positive number", value, currency)
+		}
+		if currency == file.Base && rate.Cmp(big.NewRat(1, 1)) != 0 {
+			return nil, fmt.Errorf("rate for base currency %s must be 1", currency)
+		}
+		p.rates[currency] = rate
+	}
+
+	return p, nil
+}
+
+// Rate returns the price of one unit of from in units of to.
+func (p *StaticProvider) Rate(_ context.Context, from, to string) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return nil, fmt.Errorf("exchange.Rate: %s to %s: %w", from, to, entity.ErrNoExchangeRate)
+	}
+	toRate, ok := p.rates[to]
+	if !ok {
+		return nil, fmt.Errorf("exchange.Rate: %s to %s: %w", from, to, entity.ErrNoExchangeRate)
+	}
+
+	return new(big.Rat).Quo(toRate, fromRate), nil
+}
+
+// Base returns the currency the rates are quoted against.
+func (p *StaticProvider) Base() string {
+	return p.base
+}
+
+// Date returns the date the rates were taken on, as written in the file.
+func (p *StaticProvider) Date() string {
+	return p.date
+}
+
+// Currencies returns the number of currencies with a known rate, including the base.
+func (p *StaticProvider) Currencies() int {
+	return len(p.rates)
+}
@@ -0,0 +1,95 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"wbtest/internal/entity"
+)
+
+func TestStaticProvider_Rate(t *testing.T) {
+	t.Parallel()
+
+	p, err := ParseRates([]byte(`{"base": "USD", "rates": {"EUR": "0.8", "JPY": "150"}}`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	testCases := []struct {
+		desc string
+		from string
+		to   string
+		want *big.Rat
+	}{
+		{desc: "FromBase", from: "USD", to: "EUR", want: big.NewRat(4, 5)},
+		{desc: "ToBase", from: "EUR", to: "USD", want: big.NewRat(5, 4)},
+		{desc: "Cross", from: "EUR", to: "JPY", want: big.NewRat(375, 2)},
+		{desc: "Same", from: "GBP", to: "GBP", want: big.NewRat(1, 1)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := p.Rate(context.Background(), tc.from, tc.to)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got.Cmp(tc.want) != 0 {
+				t.Fatalf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+
+	if _, err = p.Rate(context.Background(), "GBP", "USD"); !errors.Is(err, entity.ErrNoExchangeRate) {
+		t.Fatalf("expected ErrNoExchangeRate, got %v", err)
+	}
+}
+
+func TestParseRates_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for desc, data := range map[string]string{
+		"Malformed":       `{"base": "USD", "rates": `,
+		"UnknownBase":     `{"base": "ABC", "rates": {}}`,
+		"UnknownCurrency": `{"base": "USD", "rates": {"usd": "1"}}`,
+		"NotANumber":      `{"base": "USD", "rates": {"EUR": "about one"}}`,
+		"Zero":            `{"base": "USD", "rates": {"EUR": "0"}}`,
+		"BaseNotOne":      `{"base": "USD", "rates": {"USD": "2"}}`,
+	} {
+		if _, err := ParseRates([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", desc)
+		}
+	}
+}
+
+func TestNewStaticProvider(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewStaticProvider("")
+	if err != nil {
+		t.Fatalf("expected the built-in rates to load, got %v", err)
+	}
+	if p.Base() != "USD" || p.Currencies() < 2 {
+		t.Fatalf("unexpected built-in rates: base %s, %d currencies", p.Base(), p.Currencies())
+	}
+
+	path := filepath.Join(t.TempDir(), "rates.json")
+	if err = os.WriteFile(path, []byte(`{"base": "EUR", "date": "2025-01-02", "rates": {"USD": "1.1"}}`), 0o600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if p, err = NewStaticProvider(path); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if p.Base() != "EUR" || p.Date() != "2025-01-02" {
+		t.Fatalf("unexpected rates: base %s, date %s", p.Base(), p.Date())
+	}
+
+	if _, err = NewStaticProvider(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected error for a missing file")
+	}
+}
@@ -0,0 +1,230 @@
+package export
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"wbtest/internal/entity"
+)
+
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+var _csvHeader = []string{
+	"order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id",
+	"delivery_service", "shardkey", "sm_id", "date_created", "oof_shard",
+	"delivery_name", "delivery_phone", "delivery_zip", "delivery_city",
+	"delivery_address", "delivery_region", "delivery_email",
+	"payment_transaction", "payment_request_id", "payment_currency", "payment_provider",
+	"payment_amount", "payment_dt", "payment_bank", "payment_delivery_cost",
+	"payment_goods_total", "payment_custom_fee",
+	"item_chrt_id", "item_track_number", "item_price", "item_rid", "item_name",
+	"item_sale", "item_size", "item_total_price", "item_nm_id", "item_brand", "item_status",
+}
+
+type Encoder interface {
+	Encode(order *entity.Order) error
+	Flush() error
+	Close() error
+}
+
+func NewEncoder(w io.Writer, format string, compress bool) (Encoder, error) {
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+
+	switch format {
+	case FormatJSONL:
+		return &jsonlEncoder{enc: json.NewEncoder(w), gz: gz}, nil
+	case FormatCSV:
+		return &csvEncoder{w: csv.NewWriter(w), gz: gz}, nil
+	default:
+		return nil, fmt.Errorf("export.NewEncoder: %q: %w", format, ErrUnsupportedFormat)
+	}
+}
+
+func ContentType(format string, compress bool) string {
+	switch {
+	case compress:
+		return "application/gzip"
+	case format == FormatCSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
+func FileName(format string, compress bool) string {
+	name := "orders." + format
+	if compress {
+		name += ".gz"
+	}
+	return name
+}
+
+type jsonlEncoder struct {
+	enc *json.Encoder
+	gz  *gzip.Writer
+}
+
+func (e *jsonlEncoder) Encode(order *entity.Order) error {
+	if err := e.enc.Encode(order); err != nil {
+		return fmt.Errorf("export.jsonl.Encode: %w", err)
+	}
+	return nil
+}
+
+func (e *jsonlEncoder) Flush() error {
+	return flushGzip(e.gz)
+}
+
+func (e *jsonlEncoder) Close() error {
+	return closeGzip(e.gz)
+}
+
+type csvEncoder struct {
+	w             *csv.Writer
+	gz            *gzip.Writer
+	headerWritten bool
+}
+
+func (e *csvEncoder) Encode(order *entity.Order) error {
+	if !e.headerWritten {
+		if err := e.w.Write(_csvHeader); err != nil {
+			return fmt.Errorf("export.csv.Encode: write header: %w", err)
+		}
+		e.headerWritten = true
+	}
+
+	base := orderColumns(order)
+	if len(order.Items) == 0 {
+		if err := e.w.Write(append(base, make([]string, len(_csvHeader)-len(base))...)); err != nil {
+			return fmt.Errorf("export.csv.Encode: %w", err)
+		}
+		return nil
+	}
+
+	for _, item := range order.Items {
+		row := append(append(make([]string, 0, len(_csvHeader)), base...), itemColumns(item)...)
+		if err := e.w.Write(row); err != nil {
+			return fmt.Errorf("export.csv.Encode: %w", err)
+		}
+	}
+	return nil
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		return fmt.Errorf("export.csv.Flush: %w", err)
+	}
+	return flushGzip(e.gz)
+}
+
+func (e *csvEncoder) Close() error {
+	if !e.headerWritten {
+		if err := e.w.Write(_csvHeader); err != nil {
+			return fmt.Errorf("export.csv.Close: write header: %w", err)
+		}
+	}
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		return fmt.Errorf("export.csv.Close: %w", err)
+	}
+	return closeGzip(e.gz)
+}
+
+func orderColumns(order *entity.Order) []string {
+	columns := []string{
+		order.OrderUID.String(),
+		order.TrackNumber,
+		order.Entry,
+		order.Locale,
+		order.InternalSignature,
+		order.CustomerID,
+		order.DeliveryService,
+		order.Shardkey,
+		strconv.Itoa(order.SmID),
+		order.DateCreated.UTC().Format(time.RFC3339Nano),
+		order.OofShard,
+	}
+
+	delivery := order.Delivery
+	if delivery == nil {
+		delivery = &entity.Delivery{}
+	}
+	columns = append(columns,
+		delivery.Name,
+		delivery.Phone,
+		delivery.Zip,
+		delivery.City,
+		delivery.Address,
+		delivery.Region,
+		delivery.Email,
+	)
+
+	payment := order.Payment
+	if payment == nil {
+		payment = &entity.Payment{}
+	}
+	return append(columns,
+		payment.Transaction.String(),
+		payment.RequestID.String(),
+		payment.Currency,
+		payment.Provider,
+		strconv.FormatInt(payment.Amount, 10),
+		strconv.FormatInt(payment.PaymentDt, 10),
+		payment.Bank,
+		strconv.FormatInt(payment.DeliveryCost, 10),
+		strconv.FormatInt(payment.GoodsTotal, 10),
+		strconv.FormatInt(payment.CustomFee, 10),
+	)
+}
+
+func itemColumns(item *entity.Item) []string {
+	return []string{
+		strconv.FormatUint(item.ChrtID, 10),
+		item.TrackNumber,
+		strconv.FormatInt(item.Price, 10),
+		item.Rid.String(),
+		item.Name,
+		strconv.Itoa(item.Sale),
+		item.Size,
+		strconv.FormatInt(item.TotalPrice, 10),
+		strconv.FormatUint(item.NMID, 10),
+		item.Brand,
+		strconv.Itoa(item.Status),
+	}
+}
+
+func flushGzip(gz *gzip.Writer) error {
+	if gz == nil {
+		return nil
+	}
+	if err := gz.Flush(); err != nil {
+		return fmt.Errorf("export.flushGzip: %w", err)
+	}
+	return nil
+}
+
+func closeGzip(gz *gzip.Writer) error {
+	if gz == nil {
+		return nil
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("export.closeGzip: %w", err)
+	}
+	return nil
+}
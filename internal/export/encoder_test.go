@@ -0,0 +1,195 @@
+package export_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"wbtest/internal/entity"
+	"wbtest/internal/export"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+)
+
+func generateFakeOrder(itemsCount int) *entity.Order {
+	orderUID := uuid.New()
+	items := make([]*entity.Item, 0, itemsCount)
+	for range itemsCount {
+		items = append(items, &entity.Item{
+			ChrtID:      uint64(gofakeit.UintRange(10000, 99999)),
+			TrackNumber: gofakeit.UUID(),
+			Price:       int64(gofakeit.UintRange(100, 1000)),
+			Rid:         uuid.New(),
+			Name:        gofakeit.ProductName(),
+			Sale:        gofakeit.Number(0, 50),
+			Size:        gofakeit.Word(),
+			TotalPrice:  int64(gofakeit.UintRange(50, 950)),
+			NMID:        uint64(gofakeit.UintRange(1000000, 9999999)),
+			Brand:       gofakeit.Company(),
+			Status:      gofakeit.Number(1, 5),
+		})
+	}
+
+	return &entity.Order{
+		OrderUID:    orderUID,
+		TrackNumber: gofakeit.UUID(),
+		Entry:       "WBIL",
+		Delivery: &entity.Delivery{
+			Name:    gofakeit.Name(),
+			Phone:   gofakeit.Phone(),
+			Zip:     gofakeit.Zip(),
+			City:    gofakeit.City(),
+			Address: gofakeit.Address().Address,
+			Region:  gofakeit.State(),
+			Email:   gofakeit.Email(),
+		},
+		Payment: &entity.Payment{
+			Transaction:  orderUID,
+			RequestID:    uuid.New(),
+			Currency:     gofakeit.CurrencyShort(),
+			Provider:     gofakeit.Word(),
+			Amount:       int64(gofakeit.UintRange(1000, 10000)),
+			PaymentDt:    time.Now().Unix(),
+			Bank:         gofakeit.BS(),
+			DeliveryCost: int64(gofakeit.UintRange(100, 500)),
+			GoodsTotal:   int64(gofakeit.UintRange(500, 9000)),
+		},
+		Items:           items,
+		Locale:          "en",
+		CustomerID:      gofakeit.Username(),
+		DeliveryService: gofakeit.Company(),
+		Shardkey:        "9",
+		SmID:            99,
+		DateCreated:     time.Now().UTC().Truncate(time.Second),
+		OofShard:        "1",
+	}
+}
+
+func TestEncoder_JSONL(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc     string
+		compress bool
+	}{
+		{desc: "Plain", compress: false},
+		{desc: "Gzip", compress: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			orders := []*entity.Order{generateFakeOrder(2), generateFakeOrder(1)}
+
+			var buf bytes.Buffer
+			encoder, err := export.NewEncoder(&buf, export.FormatJSONL, tc.compress)
+			if err != nil {
+				t.Fatalf("NewEncoder() error = %v", err)
+			}
+			for _, order := range orders {
+				if err = encoder.Encode(order); err != nil {
+					t.Fatalf("Encode() error = %v", err)
+				}
+			}
+			if err = encoder.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			var r io.Reader = &buf
+			if tc.compress {
+				if r, err = gzip.NewReader(&buf); err != nil {
+					t.Fatalf("gzip.NewReader() error = %v", err)
+				}
+			}
+
+			scanner := bufio.NewScanner(r)
+			scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+			i := 0
+			for ; scanner.Scan(); i++ {
+				var got entity.Order
+				if err = json.Unmarshal(scanner.Bytes(), &got); err != nil {
+					t.Fatalf("line %d: unmarshal error = %v", i, err)
+				}
+				if !reflect.DeepEqual(&got, orders[i]) {
+					t.Errorf("line %d: order does not round-trip", i)
+				}
+			}
+			if i != len(orders) {
+				t.Errorf("got %d lines; want %d", i, len(orders))
+			}
+		})
+	}
+}
+
+func TestEncoder_CSV(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc     string
+		orders   []*entity.Order
+		wantRows int
+	}{
+		{
+			desc:     "OneRowPerItem",
+			orders:   []*entity.Order{generateFakeOrder(3), generateFakeOrder(1)},
+			wantRows: 4,
+		},
+		{
+			desc:     "HeaderOnly",
+			orders:   nil,
+			wantRows: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			encoder, err := export.NewEncoder(&buf, export.FormatCSV, false)
+			if err != nil {
+				t.Fatalf("NewEncoder() error = %v", err)
+			}
+			for _, order := range tc.orders {
+				if err = encoder.Encode(order); err != nil {
+					t.Fatalf("Encode() error = %v", err)
+				}
+			}
+			if err = encoder.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			records, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if len(records) != tc.wantRows+1 {
+				t.Fatalf("got %d records; want %d", len(records), tc.wantRows+1)
+			}
+			if records[0][0] != "order_uid" {
+				t.Errorf("header[0] = %q; want order_uid", records[0][0])
+			}
+			if tc.wantRows > 0 && records[1][0] != tc.orders[0].OrderUID.String() {
+				t.Errorf("row 1 order_uid = %q; want %q", records[1][0], tc.orders[0].OrderUID)
+			}
+		})
+	}
+}
+
+func TestEncoder_UnsupportedFormat(t *testing.T) {
+	t.Parallel()
+
+	_, err := export.NewEncoder(io.Discard, "xml", false)
+	if !errors.Is(err, export.ErrUnsupportedFormat) {
+		t.Fatalf("NewEncoder() error = %v; want %v", err, export.ErrUnsupportedFormat)
+	}
+}
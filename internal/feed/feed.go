@@ -0,0 +1,171 @@
+// Package feed fans newly created orders out to live feed clients.
+package feed
+
+import (
+	"errors"
+	"sync"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/metric"
+)
+
+// Reasons a client leaves the feed, as reported to metric.Feed.
+const (
+	ReasonClosed   = "closed"
+	ReasonSlow     = "slow"
+	ReasonShutdown = "shutdown"
+)
+
+var (
+	ErrTooManyClients = errors.New("feed: too many clients")
+	ErrClosed         = errors.New("feed: hub closed")
+)
+
+// Filter selects the orders a client receives. Empty fields match any order.
+type Filter struct {
+	DeliveryService string
+	CustomerID      string
+}
+
+func (f Filter) Match(order *entity.Order) bool {
+	return (f.DeliveryService == "" || f.DeliveryService == order.DeliveryService) &&
+		(f.CustomerID == "" || f.CustomerID == order.CustomerID)
+}
+
+// Hub is an in-process publish/subscribe hub. Publish never blocks: a client whose buffer is
+// full is disconnected rather than slowing down order processing or silently missing orders,
+// and is expected to reconnect.
+type Hub struct {
+	mu         sync.RWMutex
+	clients    map[*Client]struct{}
+	closed     bool
+	buffer     int
+	maxClients int
+	metrics    metric.Feed
+}
+
+func NewHub(buffer, maxClients int, metrics metric.Feed) *Hub {
+	return &Hub{
+		clients:    make(map[*Client]struct{}),
+		buffer:     buffer,
+		maxClients: maxClients,
+		metrics:    metrics,
+	}
+}
+
+// Subscribe connects a client receiving the orders matching filter. The client has to be
+// closed once it is done.
+func (h *Hub) Subscribe(filter Filter) (*Client, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, ErrClosed
+	}
+	if len(h.clients) >= h.maxClients {
+		return nil, ErrTooManyClients
+	}
+
+	c := &Client{
+		hub:    h,
+		filter: filter,
+		orders: make(chan *entity.Order, h.buffer),
+		done:   make(chan struct{}),
+	}
+	h.clients[c] = struct{}{}
+	h.metrics.Clients(len(h.clients))
+
+	return c, nil
+}
+
+// Publish hands order to every client whose filter matches it. The order is shared between
+// clients and must not be modified.
+func (h *Hub) Publish(order *entity.Order) {
+	var slow []*Client
+
+	h.mu.RLock()
+	for c := range h.clients {
+		if !c.filter.Match(order) {
+			continue
+		}
+		select {
+		case c.orders <- order:
+		default:
+			slow = append(slow, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	h.metrics.Published()
+	for _, c := range slow {
+		h.remove(c, ReasonSlow)
+	}
+}
+
+// Close disconnects every client and rejects new ones.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	clients := make([]*Client, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.Unlock()
+
+	for _, c := range clients {
+		h.remove(c, ReasonShutdown)
+	}
+}
+
+// Clients returns the number of connected clients.
+func (h *Hub) Clients() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.clients)
+}
+
+func (h *Hub) remove(c *Client, reason string) {
+	h.mu.Lock()
+	_, ok := h.clients[c]
+	if ok {
+		delete(h.clients, c)
+		h.metrics.Clients(len(h.clients))
+	}
+	h.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	c.reason = reason
+	close(c.done)
+	h.metrics.Disconnected(reason)
+}
+
+// Client is one connection to the feed. Orders is never closed; Done is closed once the client
+// is disconnected, by Close or by the hub.
+type Client struct {
+	hub    *Hub
+	filter Filter
+	orders chan *entity.Order
+	done   chan struct{}
+	reason string
+}
+
+func (c *Client) Orders() <-chan *entity.Order {
+	return c.orders
+}
+
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Reason tells why the client was disconnected. It is only valid once Done is closed.
+func (c *Client) Reason() string {
+	return c.reason
+}
+
+func (c *Client) Close() {
+	c.hub.remove(c, ReasonClosed)
+}
@@ -0,0 +1,137 @@
+package feed_test
+
+import (
+	"errors"
+	"testing"
+
+	"wbtest/internal/entity"
+	"wbtest/internal/feed"
+	mock_metric "wbtest/pkg/metric/mock"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+)
+
+func newOrder(deliveryService, customerID string) *entity.Order {
+	return &entity.Order{
+		OrderUID:        uuid.New(),
+		DeliveryService: deliveryService,
+		CustomerID:      customerID,
+	}
+}
+
+func newMetrics(ctrl *gomock.Controller) *mock_metric.MockFeed {
+	metrics := mock_metric.NewMockFeed(ctrl)
+	metrics.EXPECT().Clients(gomock.Any()).AnyTimes()
+	metrics.EXPECT().Published().AnyTimes()
+	return metrics
+}
+
+func isDone(c *feed.Client) bool {
+	select {
+	case <-c.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestHub_PublishFilters(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	metrics := newMetrics(ctrl)
+	metrics.EXPECT().Disconnected(feed.ReasonClosed).Times(3)
+
+	hub := feed.NewHub(10, 10, metrics)
+
+	all, _ := hub.Subscribe(feed.Filter{})
+	byDelivery, _ := hub.Subscribe(feed.Filter{DeliveryService: "meest"})
+	byBoth, _ := hub.Subscribe(feed.Filter{DeliveryService: "meest", CustomerID: "test"})
+	defer all.Close()
+	defer byDelivery.Close()
+	defer byBoth.Close()
+
+	hub.Publish(newOrder("meest", "test"))
+	hub.Publish(newOrder("meest", "other"))
+	hub.Publish(newOrder("dhl", "test"))
+
+	for _, tc := range []struct {
+		desc   string
+		client *feed.Client
+		want   int
+	}{
+		{desc: "All", client: all, want: 3},
+		{desc: "ByDeliveryService", client: byDelivery, want: 2},
+		{desc: "ByDeliveryServiceAndCustomer", client: byBoth, want: 1},
+	} {
+		if got := len(tc.client.Orders()); got != tc.want {
+			t.Errorf("%s: expected %d orders, got %d", tc.desc, tc.want, got)
+		}
+	}
+}
+
+func TestHub_DisconnectsSlowClient(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	metrics := newMetrics(ctrl)
+	metrics.EXPECT().Disconnected(feed.ReasonSlow).Times(1)
+	metrics.EXPECT().Disconnected(feed.ReasonClosed).Times(1)
+
+	hub := feed.NewHub(2, 10, metrics)
+
+	slow, _ := hub.Subscribe(feed.Filter{})
+	fast, _ := hub.Subscribe(feed.Filter{})
+	defer fast.Close()
+
+	for range 3 {
+		hub.Publish(newOrder("meest", "test"))
+		<-fast.Orders()
+	}
+
+	if !isDone(slow) || slow.Reason() != feed.ReasonSlow {
+		t.Fatalf("expected the slow client to be disconnected, got done=%v reason=%q", isDone(slow), slow.Reason())
+	}
+	if isDone(fast) {
+		t.Fatal("expected the fast client to stay connected")
+	}
+	if hub.Clients() != 1 {
+		t.Fatalf("expected 1 client, got %d", hub.Clients())
+	}
+
+	// closing a client the hub already dropped is a no-op
+	slow.Close()
+}
+
+func TestHub_MaxClientsAndClose(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	metrics := mock_metric.NewMockFeed(ctrl)
+	gomock.InOrder(
+		metrics.EXPECT().Clients(1),
+		metrics.EXPECT().Clients(2),
+	)
+	metrics.EXPECT().Clients(gomock.Any()).Times(2)
+	metrics.EXPECT().Disconnected(feed.ReasonShutdown).Times(2)
+
+	hub := feed.NewHub(1, 2, metrics)
+
+	first, _ := hub.Subscribe(feed.Filter{})
+	second, _ := hub.Subscribe(feed.Filter{})
+	if _, err := hub.Subscribe(feed.Filter{}); !errors.Is(err, feed.ErrTooManyClients) {
+		t.Fatalf("expected ErrTooManyClients, got %v", err)
+	}
+
+	hub.Close()
+
+	if !isDone(first) || !isDone(second) || first.Reason() != feed.ReasonShutdown {
+		t.Fatal("expected every client to be disconnected on close")
+	}
+	if _, err := hub.Subscribe(feed.Filter{}); !errors.Is(err, feed.ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+
+	first.Close()
+}
@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/storage/postgres"
+
+	"github.com/Masterminds/squirrel"
+)
+
+const (
+	_analyticsOrdersTable = "analytics_orders_hourly"
+	_analyticsItemsTable  = "analytics_items_hourly"
+
+	_analyticsOrdersConflict = `ON CONFLICT (bucket, delivery_service, locale, currency, provider, bank)
+DO UPDATE SET orders = analytics_orders_hourly.orders + EXCLUDED.orders,
+              revenue = analytics_orders_hourly.revenue + EXCLUDED.revenue`
+
+	_analyticsItemsConflict = `ON CONFLICT (bucket, delivery_service, locale, currency, provider, bank, nm_id, brand)
+DO UPDATE SET items = analytics_items_hourly.items + EXCLUDED.items,
+              revenue = analytics_items_hourly.revenue + EXCLUDED.revenue`
+)
+
+type AnalyticsRepository struct {
+	db *postgres.Postgres
+}
+
+func NewAnalyticsRepository(db *postgres.Postgres) *AnalyticsRepository {
+	return &AnalyticsRepository{db}
+}
+
+// RecordOrder adds order to the hourly rollups of its creation time.
+func (ar *AnalyticsRepository) RecordOrder(
+	ctx context.Context,
+	order *entity.Order,
+) error {
+	const op = "repository.analytics.RecordOrder"
+
+	if err := ar.apply(postgres.WithOperation(ctx, op), order, 1); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// RemoveOrder subtracts what RecordOrder added for order, which must be the order as it
+// was recorded.
+func (ar *AnalyticsRepository) RemoveOrder(
+	ctx context.Context,
+	order *entity.Order,
+) error {
+	const op = "repository.analytics.RemoveOrder"
+
+	if err := ar.apply(postgres.WithOperation(ctx, op), order, -1); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+type itemRollupKey struct {
+	nmID  uint64
+	brand string
+}
+
+type itemRollup struct {
+	items   int64
+	revenue int64
+}
+
+func (ar *AnalyticsRepository) apply(
+	ctx context.Context,
+	order *entity.Order,
+	sign int64,
+) error {
+	if order.Payment == nil {
+		return fmt.Errorf("order %s has no payment", order.OrderUID)
+	}
+
+	bucket := order.DateCreated.UTC().Truncate(time.Hour)
+	dims := []any{
+		bucket,
+		order.DeliveryService,
+		order.Locale,
+		order.Payment.Currency,
+		order.Payment.Provider,
+		order.Payment.Bank,
+	}
+
+	sql, args, err := ar.db.Builder.Insert(_analyticsOrdersTable).
+		Columns("bucket", "delivery_service", "locale", "currency", "provider", "bank", "orders", "revenue").
+		Values(append(dims, sign, sign*int64(order.Payment.Amount))...).
+		Suffix(_analyticsOrdersConflict).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building orders query: %w", err)
+	}
+	if _, err = ar.db.Executer(ctx).Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("exec orders rollup: %w", err)
+	}
+
+	if len(order.Items) == 0 {
+		return nil
+	}
+
+	// one row per key: a single INSERT ... ON CONFLICT cannot touch the same row twice
+	rollups := make(map[itemRollupKey]*itemRollup, len(order.Items))
+	keys := make([]itemRollupKey, 0, len(order.Items))
+	for _, item := range order.Items {
+		key := itemRollupKey{nmID: item.NMID, brand: item.Brand}
+		r, ok := rollups[key]
+		if !ok {
+			r = &itemRollup{}
+			rollups[key] = r
+			keys = append(keys, key)
+		}
+		r.items += sign
+		r.revenue += sign * int64(item.TotalPrice)
+	}
+
+	query := ar.db.Builder.Insert(_analyticsItemsTable).
+		Columns("bucket", "delivery_service", "locale", "currency", "provider", "bank",
+			"nm_id", "brand", "items", "revenue").
+		Suffix(_analyticsItemsConflict)
+	for _, key := range keys {
+		r := rollups[key]
+		query = query.Values(append(dims[:len(dims):len(dims)], key.nmID, key.brand, r.items, r.revenue)...)
+	}
+
+	if sql, args, err = query.ToSql(); err != nil {
+		return fmt.Errorf("building items query: %w", err)
+	}
+	if _, err = ar.db.Executer(ctx).Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("exec items rollup: %w", err)
+	}
+	return nil
+}
+
+func (ar *AnalyticsRepository) GetOrderAnalytics(
+	ctx context.Context,
+	filter *entity.AnalyticsFilter,
+) (*entity.OrderAnalytics, error) {
+	const op = "repository.analytics.GetOrderAnalytics"
+	ctx = postgres.WithOperation(ctx, op)
+
+	result := &entity.OrderAnalytics{
+		Bucket: filter.Bucket,
+		From:   filter.From,
+		To:     filter.To,
+	}
+
+	var err error
+	if result.Series, err = ar.series(ctx, filter); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if result.TopItems, err = ar.topItems(ctx, filter); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if result.TopBrands, err = ar.topBrands(ctx, filter); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return result, nil
+}
+
+func analyticsWhere(filter *entity.AnalyticsFilter) squirrel.And {
+	where := squirrel.And{
+		squirrel.GtOrEq{"bucket": filter.From},
+		squirrel.Lt{"bucket": filter.To},
+	}
+	for column, value := range map[string]string{
+		"delivery_service": filter.DeliveryService,
+		"locale":           filter.Locale,
+		"currency":         filter.Currency,
+		"provider":         filter.Provider,
+		"bank":             filter.Bank,
+	} {
+		if value != "" {
+			where = append(where, squirrel.Eq{column: value})
+		}
+	}
+	return where
+}
+
+func (ar *AnalyticsRepository) series(
+	ctx context.Context,
+	filter *entity.AnalyticsFilter,
+) ([]entity.AnalyticsPoint, error) {
+	// the bucket name is validated by the service, date_trunc still gets it as a parameter
+	bucketExpr := squirrel.Expr(
+		"date_trunc(?, bucket AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS period", filter.Bucket,
+	)
+
+	sql, args, err := ar.db.Builder.
+		Select("currency", "SUM(orders)", "SUM(revenue)").
+		Column(bucketExpr).
+		From(_analyticsOrdersTable).
+		Where(analyticsWhere(filter)).
+		GroupBy("period", "currency").
+		Having("SUM(orders) <> 0").
+		OrderBy("period", "currency").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building series query: %w", err)
+	}
+
+	rows, err := ar.db.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query series: %w", err)
+	}
+	defer rows.Close()
+
+	series := make([]entity.AnalyticsPoint, 0)
+	for rows.Next() {
+		var point entity.AnalyticsPoint
+		if err = rows.Scan(&point.Currency, &point.Orders, &point.Revenue, &point.Bucket); err != nil {
+			return nil, fmt.Errorf("scan series: %w", err)
+		}
+		point.Bucket = point.Bucket.UTC()
+		series = append(series, point)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("series rows: %w", err)
+	}
+	return series, nil
+}
+
+func (ar *AnalyticsRepository) topItems(
+	ctx context.Context,
+	filter *entity.AnalyticsFilter,
+) ([]entity.ItemCount, error) {
+	sql, args, err := ar.db.Builder.
+		Select("nm_id", "SUM(items) AS total").
+		From(_analyticsItemsTable).
+		Where(analyticsWhere(filter)).
+		GroupBy("nm_id").
+		Having("SUM(items) > 0").
+		OrderBy("total DESC", "nm_id").
+		Limit(uint64(filter.Top)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building top items query: %w", err)
+	}
+
+	rows, err := ar.db.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query top items: %w", err)
+	}
+	defer rows.Close()
+
+	top := make([]entity.ItemCount, 0, filter.Top)
+	for rows.Next() {
+		var item entity.ItemCount
+		if err = rows.Scan(&item.NMID, &item.Items); err != nil {
+			return nil, fmt.Errorf("scan top items: %w", err)
+		}
+		top = append(top, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("top items rows: %w", err)
+	}
+	return top, nil
+}
+
+func (ar *AnalyticsRepository) topBrands(
+	ctx context.Context,
+	filter *entity.AnalyticsFilter,
+) ([]entity.BrandCount, error) {
+	sql, args, err := ar.db.Builder.
+		Select("brand", "SUM(items) AS total").
+		From(_analyticsItemsTable).
+		Where(analyticsWhere(filter)).
+		GroupBy("brand").
+		Having("SUM(items) > 0").
+		OrderBy("total DESC", "brand").
+		Limit(uint64(filter.Top)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building top brands query: %w", err)
+	}
+
+	rows, err := ar.db.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query top brands: %w", err)
+	}
+	defer rows.Close()
+
+	top := make([]entity.BrandCount, 0, filter.Top)
+	for rows.Next() {
+		var brand entity.BrandCount
+		if err = rows.Scan(&brand.Brand, &brand.Items); err != nil {
+			return nil, fmt.Errorf("scan top brands: %w", err)
+		}
+		top = append(top, brand)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("top brands rows: %w", err)
+	}
+	return top, nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"wbtest/pkg/storage/postgres"
+
+	"github.com/google/uuid"
+)
+
+const _archiveOrdersQuery = `
+INSERT INTO orders_archive (order_uid, date_created, deleted_at, payload)
+SELECT o.order_uid, o.date_created, o.deleted_at, ` + _orderDocumentExpr + `
+FROM ` + _orderDocumentFrom + `
+WHERE o.order_uid = ANY($1)
+ON CONFLICT (order_uid) DO NOTHING`
+
+type ArchiveRepository struct {
+	db *postgres.Postgres
+}
+
+func NewArchiveRepository(db *postgres.Postgres) *ArchiveRepository {
+	return &ArchiveRepository{db}
+}
+
+func (ar *ArchiveRepository) ArchiveOrders(
+	ctx context.Context,
+	orderUIDs []uuid.UUID,
+) (int64, error) {
+	const op = "repository.archive.ArchiveOrders"
+	ctx = postgres.WithOperation(ctx, op)
+
+	tag, err := ar.db.Executer(ctx).Exec(ctx, _archiveOrdersQuery, orderUIDs)
+	if err != nil {
+		return 0, fmt.Errorf("%s: exec: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/storage/postgres"
+)
+
+type AuditRepository struct {
+	db *postgres.Postgres
+}
+
+func NewAuditRepository(db *postgres.Postgres) *AuditRepository {
+	return &AuditRepository{db}
+}
+
+func (ar *AuditRepository) Create(
+	ctx context.Context,
+	record *entity.AuditRecord,
+) error {
+	const op = "repository.audit.Create"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := ar.db.Builder.Insert("order_audit").
+		Columns("order_uid", "version", "action", "diff", "snapshot").
+		Values(
+			record.OrderUID,
+			record.Version,
+			record.Action,
+			record.Diff,
+			record.Snapshot,
+		)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	if _, err = ar.db.Executer(ctx).Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("%s: exec: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/storage/postgres"
+
+	"github.com/google/uuid"
+)
+
+const _customerOrdersQuery = `
+SELECT order_uid, COUNT(*) OVER () AS total
+FROM orders
+WHERE customer_id = $1 AND deleted_at IS NULL
+ORDER BY date_created DESC, order_uid
+LIMIT $2 OFFSET $3`
+
+// _customerSummaryQuery computes the whole summary in one round trip. The customer's orders
+// are materialized once and joined to payment, items and delivery by primary key. Spending is
+// net of refunds, which are in the payment currency.
+const _customerSummaryQuery = `
+WITH customer_orders AS (
+    SELECT order_uid, date_created
+    FROM orders
+    WHERE customer_id = $1 AND deleted_at IS NULL
+)
+SELECT
+    (SELECT COUNT(*) FROM customer_orders),
+    (SELECT MIN(date_created) FROM customer_orders),
+    (SELECT MAX(date_created) FROM customer_orders),
+    COALESCE((
+        SELECT json_agg(json_build_object('currency', t.currency, 'amount', t.amount) ORDER BY t.currency)
+        FROM (
+            SELECT p.currency, SUM(p.amount - COALESCE(r.amount, 0)) AS amount
+            FROM payment p
+            JOIN customer_orders co ON co.order_uid = p.order_uid
+            LEFT JOIN LATERAL (
+                SELECT SUM(amount) AS amount FROM refunds WHERE refunds.order_uid = p.order_uid
+            ) r ON TRUE
+            GROUP BY p.currency
+        ) t
+    ), '[]'),
+    COALESCE((
+        SELECT json_agg(json_build_object('brand', t.brand, 'items', t.items) ORDER BY t.items DESC, t.brand)
+        FROM (
+            SELECT i.brand, COUNT(*) AS items
+            FROM items i
+            JOIN customer_orders co ON co.order_uid = i.order_uid
+            GROUP BY i.brand
+            ORDER BY items DESC, i.brand
+            LIMIT $2
+        ) t
+    ), '[]'),
+    COALESCE((
+        SELECT array_agg(DISTINCT d.city ORDER BY d.city)
+        FROM delivery d
+        JOIN customer_orders co ON co.order_uid = d.order_uid
+    ), '{}')`
+
+type CustomerRepository struct {
+	db *postgres.Postgres
+}
+
+func NewCustomerRepository(db *postgres.Postgres) *CustomerRepository {
+	return &CustomerRepository{db}
+}
+
+// ListOrders returns one page of the customer's order UIDs, newest first, and the total
+// number of orders. Total is 0 when offset is past the last order.
+func (cr *CustomerRepository) ListOrders(
+	ctx context.Context,
+	customerID string,
+	limit, offset int,
+) ([]uuid.UUID, int64, error) {
+	const op = "repository.customer.ListOrders"
+	ctx = postgres.WithOperation(ctx, op)
+
+	rows, err := cr.db.Reader(ctx).Query(ctx, _customerOrdersQuery, customerID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var (
+		uids  []uuid.UUID
+		total int64
+	)
+	for rows.Next() {
+		var uid uuid.UUID
+		if err = rows.Scan(&uid, &total); err != nil {
+			return nil, 0, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		uids = append(uids, uid)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return uids, total, nil
+}
+
+func (cr *CustomerRepository) GetSummary(
+	ctx context.Context,
+	customerID string,
+	topBrands int,
+) (*entity.CustomerSummary, error) {
+	const op = "repository.customer.GetSummary"
+	ctx = postgres.WithOperation(ctx, op)
+
+	summary := &entity.CustomerSummary{CustomerID: customerID}
+	var firstOrderAt, lastOrderAt *time.Time
+
+	err := cr.db.Reader(ctx).QueryRow(ctx, _customerSummaryQuery, customerID, topBrands).Scan(
+		&summary.OrderCount,
+		&firstOrderAt,
+		&lastOrderAt,
+		&summary.TotalSpent,
+		&summary.TopBrands,
+		&summary.DeliveryCities,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query row: %w", op, err)
+	}
+	if summary.OrderCount == 0 {
+		return nil, entity.ErrDataNotFound
+	}
+
+	summary.FirstOrderAt = *firstOrderAt
+	summary.LastOrderAt = *lastOrderAt
+	return summary, nil
+}
@@ -10,8 +10,8 @@ import (
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
-	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type DeliveryRepository struct {
@@ -24,11 +24,11 @@ func NewDeliveryRepository(db *postgres.Postgres) *DeliveryRepository {
 
 func (dr *DeliveryRepository) Create(
 	ctx context.Context,
-	queryExecuter postgres.QueryExecuter,
 	orderUID uuid.UUID,
 	delivery *entity.Delivery,
 ) (*entity.Delivery, error) {
 	const op = "repository.delivery.Create"
+	ctx = postgres.WithOperation(ctx, op)
 
 	query := dr.db.Builder.Insert("delivery").
 		Columns("order_uid", "name", "phone", "zip", "city", "address", "region", "email").
@@ -50,7 +50,7 @@ func (dr *DeliveryRepository) Create(
 	}
 
 	result := &entity.Delivery{}
-	err = queryExecuter.QueryRow(ctx, sql, args...).Scan(
+	err = dr.db.Executer(ctx).QueryRow(ctx, sql, args...).Scan(
 		&result.Name,
 		&result.Phone,
 		&result.Zip,
@@ -75,6 +75,7 @@ func (dr *DeliveryRepository) GetByOrderUID(
 	orderUID uuid.UUID,
 ) (*entity.Delivery, error) {
 	const op = "repository.delivery.Get"
+	ctx = postgres.WithOperation(ctx, op)
 
 	query := dr.db.Builder.Select("*").
 		From("delivery").
@@ -87,7 +88,7 @@ func (dr *DeliveryRepository) GetByOrderUID(
 	}
 
 	result := &entity.Delivery{}
-	err = dr.db.Pool.QueryRow(ctx, sql, args...).Scan(
+	err = dr.db.Reader(ctx).QueryRow(ctx, sql, args...).Scan(
 		&orderUID,
 		&result.Name,
 		&result.Phone,
@@ -106,3 +107,49 @@ func (dr *DeliveryRepository) GetByOrderUID(
 
 	return result, nil
 }
+
+func (dr *DeliveryRepository) Update(
+	ctx context.Context,
+	orderUID uuid.UUID,
+	delivery *entity.Delivery,
+) (*entity.Delivery, error) {
+	const op = "repository.delivery.Update"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := dr.db.Builder.Update("delivery").
+		SetMap(map[string]interface{}{
+			"name":    delivery.Name,
+			"phone":   delivery.Phone,
+			"zip":     delivery.Zip,
+			"city":    delivery.City,
+			"address": delivery.Address,
+			"region":  delivery.Region,
+			"email":   delivery.Email,
+		}).
+		Where(squirrel.Eq{"order_uid": orderUID}).
+		Suffix("RETURNING name, phone, zip, city, address, region, email")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	result := &entity.Delivery{}
+	err = dr.db.Executer(ctx).QueryRow(ctx, sql, args...).Scan(
+		&result.Name,
+		&result.Phone,
+		&result.Zip,
+		&result.City,
+		&result.Address,
+		&result.Region,
+		&result.Email,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrDataNotFound
+		}
+		return nil, fmt.Errorf("%s: query row: %w", op, err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,19 @@
+package repository
+
+// _orderDocumentExpr builds a single order in the entity.Order JSON shape,
+// expecting orders, delivery and payment to be aliased as o, d and p.
+const _orderDocumentExpr = `jsonb_strip_nulls(
+	(to_jsonb(o) - 'payload_hash' - 'version' - 'deleted_at') || jsonb_build_object(
+		'delivery', to_jsonb(d) - 'order_uid',
+		'payment', to_jsonb(p) - 'order_uid',
+		'items', COALESCE(
+			(SELECT jsonb_agg(to_jsonb(i) - 'order_uid' - 'items_id' ORDER BY i.chrt_id)
+			FROM items i WHERE i.order_uid = o.order_uid),
+			'[]'::jsonb
+		)
+	)
+)`
+
+const _orderDocumentFrom = `orders o
+LEFT JOIN delivery d ON d.order_uid = o.order_uid
+LEFT JOIN payment p ON p.order_uid = o.order_uid`
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/storage/postgres"
+
+	"github.com/google/uuid"
+)
+
+// EventRepository is the transactional outbox of order events.
+type EventRepository struct {
+	db *postgres.Postgres
+}
+
+func NewEventRepository(db *postgres.Postgres) *EventRepository {
+	return &EventRepository{db}
+}
+
+func (er *EventRepository) Create(
+	ctx context.Context,
+	event *entity.Event,
+) error {
+	const op = "repository.event.Create"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := er.db.Builder.Insert("order_events").
+		Columns("event_id", "type", "order_uid", "payload").
+		Values(
+			event.EventID,
+			event.Type,
+			event.OrderUID,
+			event.Payload,
+		)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	if _, err = er.db.Executer(ctx).Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("%s: exec: %w", op, err)
+	}
+
+	return nil
+}
+
+// _claimPendingEventsQuery leases the oldest events that are not leased or whose lease has
+// expired by moving their lease to $2, so that no other relay picks them up meanwhile.
+const _claimPendingEventsQuery = `
+WITH pending AS (
+    SELECT event_id
+    FROM order_events
+    WHERE leased_until IS NULL OR leased_until <= NOW()
+    ORDER BY created_at, event_id
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+), claimed AS (
+    UPDATE order_events e
+    SET leased_until = $2
+    FROM pending
+    WHERE e.event_id = pending.event_id
+    RETURNING e.event_id, e.type, e.order_uid, e.payload, e.created_at
+)
+SELECT event_id, type, order_uid, payload, created_at
+FROM claimed
+ORDER BY created_at, event_id`
+
+// ClaimPending returns up to limit unpublished events, oldest first, and leases them until
+// leaseUntil. An event that is not deleted before the lease expires is claimed again.
+func (er *EventRepository) ClaimPending(
+	ctx context.Context,
+	limit int,
+	leaseUntil time.Time,
+) ([]*entity.Event, error) {
+	const op = "repository.event.ClaimPending"
+	ctx = postgres.WithOperation(ctx, op)
+
+	rows, err := er.db.Executer(ctx).Query(ctx, _claimPendingEventsQuery, limit, leaseUntil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	result := make([]*entity.Event, 0, limit)
+	for rows.Next() {
+		event := &entity.Event{}
+		err = rows.Scan(
+			&event.EventID,
+			&event.Type,
+			&event.OrderUID,
+			&event.Payload,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: rows scan: %w", op, err)
+		}
+		result = append(result, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return result, nil
+}
+
+func (er *EventRepository) DeleteByIDs(
+	ctx context.Context,
+	eventIDs []uuid.UUID,
+) error {
+	const op = "repository.event.DeleteByIDs"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := er.db.Builder.Delete("order_events").
+		Where("event_id = ANY(?)", eventIDs)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	if _, err = er.db.Executer(ctx).Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("%s: exec: %w", op, err)
+	}
+
+	return nil
+}
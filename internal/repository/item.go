@@ -10,8 +10,8 @@ import (
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
-	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type ItemRepository struct {
@@ -24,11 +24,11 @@ func NewItemRepository(db *postgres.Postgres) *ItemRepository {
 
 func (dr *ItemRepository) Create(
 	ctx context.Context,
-	queryExecuter postgres.QueryExecuter,
 	orderUID uuid.UUID,
 	items []*entity.Item,
 ) error {
 	const op = "repository.item.Create"
+	ctx = postgres.WithOperation(ctx, op)
 
 	if len(items) == 0 {
 		return nil
@@ -53,9 +53,9 @@ func (dr *ItemRepository) Create(
 		})
 	}
 
-	tx, ok := queryExecuter.(*postgres.TxQueryExecuter)
+	tx, ok := postgres.TxFromContext(ctx)
 	if !ok {
-		return fmt.Errorf("%s: queryExecuter is not a transaction", op)
+		return fmt.Errorf("%s: not in a transaction", op)
 	}
 
 	columnNames := []string{
@@ -63,7 +63,7 @@ func (dr *ItemRepository) Create(
 		"name", "sale", "size", "total_price", "nm_id", "brand", "status",
 	}
 
-	_, err := tx.Tx.CopyFrom(
+	_, err := tx.CopyFrom(
 		ctx,
 		pgx.Identifier{"items"},
 		columnNames,
@@ -80,17 +80,42 @@ func (dr *ItemRepository) Create(
 	return nil
 }
 
+func (dr *ItemRepository) ReplaceByOrderUID(
+	ctx context.Context,
+	orderUID uuid.UUID,
+	items []*entity.Item,
+) error {
+	const op = "repository.item.ReplaceByOrderUID"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := dr.db.Builder.Delete("items").
+		Where(squirrel.Eq{"order_uid": orderUID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	if _, err = dr.db.Executer(ctx).Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("%s: exec: %w", op, err)
+	}
+
+	return dr.Create(ctx, orderUID, items)
+}
+
 func (dr *ItemRepository) GetListByOrderUID(
 	ctx context.Context,
 	orderUID uuid.UUID,
 ) ([]*entity.Item, error) {
 	const op = "repository.item.GetListByOrderUID"
+	ctx = postgres.WithOperation(ctx, op)
 
 	var itemID uuid.UUID
 
 	query := dr.db.Builder.Select("*").
 		From("items").
-		Where(squirrel.Eq{"order_uid": orderUID})
+		Where(squirrel.Eq{"order_uid": orderUID}).
+		OrderBy("rid", "chrt_id")
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -98,7 +123,7 @@ func (dr *ItemRepository) GetListByOrderUID(
 	}
 
 	result := make([]*entity.Item, 0)
-	rows, err := dr.db.Pool.Query(ctx, sql, args...)
+	rows, err := dr.db.Reader(ctx).Query(ctx, sql, args...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, entity.ErrDataNotFound
@@ -133,3 +158,47 @@ func (dr *ItemRepository) GetListByOrderUID(
 
 	return result, nil
 }
+
+// UpdateStatus sets status on the order's items with the given rids and returns the items it
+// changed; items that already have status are left out.
+func (dr *ItemRepository) UpdateStatus(
+	ctx context.Context,
+	orderUID uuid.UUID,
+	rids []uuid.UUID,
+	status int,
+) ([]*entity.Item, error) {
+	const op = "repository.item.UpdateStatus"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := dr.db.Builder.Update("items").
+		Set("status", status).
+		Where(squirrel.Eq{"order_uid": orderUID}).
+		Where("rid = ANY(?)", rids).
+		Where(squirrel.NotEq{"status": status}).
+		Suffix("RETURNING rid, total_price")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	rows, err := dr.db.Executer(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	result := make([]*entity.Item, 0, len(rids))
+	for rows.Next() {
+		item := &entity.Item{Status: status}
+		if err = rows.Scan(&item.Rid, &item.TotalPrice); err != nil {
+			return nil, fmt.Errorf("%s: rows scan: %w", op, err)
+		}
+		result = append(result, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return result, nil
+}
@@ -11,9 +11,10 @@ package mock_repository
 
 import (
 	context "context"
+	big "math/big"
 	reflect "reflect"
+	time "time"
 	entity "wbtest/internal/entity"
-	postgres "wbtest/pkg/storage/postgres"
 
 	uuid "github.com/google/uuid"
 	gomock "go.uber.org/mock/gomock"
@@ -44,18 +45,18 @@ func (m *MockDeliveryRepository) EXPECT() *MockDeliveryRepositoryMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockDeliveryRepository) Create(ctx context.Context, queryExecuter postgres.QueryExecuter, orderUID uuid.UUID, delivery *entity.Delivery) (*entity.Delivery, error) {
+func (m *MockDeliveryRepository) Create(ctx context.Context, orderUID uuid.UUID, delivery *entity.Delivery) (*entity.Delivery, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, queryExecuter, orderUID, delivery)
+	ret := m.ctrl.Call(m, "Create", ctx, orderUID, delivery)
 	ret0, _ := ret[0].(*entity.Delivery)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockDeliveryRepositoryMockRecorder) Create(ctx, queryExecuter, orderUID, delivery any) *gomock.Call {
+func (mr *MockDeliveryRepositoryMockRecorder) Create(ctx, orderUID, delivery any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDeliveryRepository)(nil).Create), ctx, queryExecuter, orderUID, delivery)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDeliveryRepository)(nil).Create), ctx, orderUID, delivery)
 }
 
 // GetByOrderUID mocks base method.
@@ -73,6 +74,21 @@ func (mr *MockDeliveryRepositoryMockRecorder) GetByOrderUID(ctx, orderUID any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderUID", reflect.TypeOf((*MockDeliveryRepository)(nil).GetByOrderUID), ctx, orderUID)
 }
 
+// Update mocks base method.
+func (m *MockDeliveryRepository) Update(ctx context.Context, orderUID uuid.UUID, delivery *entity.Delivery) (*entity.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, orderUID, delivery)
+	ret0, _ := ret[0].(*entity.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDeliveryRepositoryMockRecorder) Update(ctx, orderUID, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDeliveryRepository)(nil).Update), ctx, orderUID, delivery)
+}
+
 // MockItemRepository is a mock of ItemRepository interface.
 type MockItemRepository struct {
 	ctrl     *gomock.Controller
@@ -98,17 +114,17 @@ func (m *MockItemRepository) EXPECT() *MockItemRepositoryMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockItemRepository) Create(ctx context.Context, queryExecuter postgres.QueryExecuter, orderUID uuid.UUID, items []*entity.Item) error {
+func (m *MockItemRepository) Create(ctx context.Context, orderUID uuid.UUID, items []*entity.Item) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, queryExecuter, orderUID, items)
+	ret := m.ctrl.Call(m, "Create", ctx, orderUID, items)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockItemRepositoryMockRecorder) Create(ctx, queryExecuter, orderUID, items any) *gomock.Call {
+func (mr *MockItemRepositoryMockRecorder) Create(ctx, orderUID, items any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockItemRepository)(nil).Create), ctx, queryExecuter, orderUID, items)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockItemRepository)(nil).Create), ctx, orderUID, items)
 }
 
 // GetListByOrderUID mocks base method.
@@ -126,6 +142,35 @@ func (mr *MockItemRepositoryMockRecorder) GetListByOrderUID(ctx, orderUID any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListByOrderUID", reflect.TypeOf((*MockItemRepository)(nil).GetListByOrderUID), ctx, orderUID)
 }
 
+// ReplaceByOrderUID mocks base method.
+func (m *MockItemRepository) ReplaceByOrderUID(ctx context.Context, orderUID uuid.UUID, items []*entity.Item) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceByOrderUID", ctx, orderUID, items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceByOrderUID indicates an expected call of ReplaceByOrderUID.
+func (mr *MockItemRepositoryMockRecorder) ReplaceByOrderUID(ctx, orderUID, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceByOrderUID", reflect.TypeOf((*MockItemRepository)(nil).ReplaceByOrderUID), ctx, orderUID, items)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockItemRepository) UpdateStatus(ctx context.Context, orderUID uuid.UUID, rids []uuid.UUID, status int) ([]*entity.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, orderUID, rids, status)
+	ret0, _ := ret[0].([]*entity.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockItemRepositoryMockRecorder) UpdateStatus(ctx, orderUID, rids, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockItemRepository)(nil).UpdateStatus), ctx, orderUID, rids, status)
+}
+
 // MockOrderRepository is a mock of OrderRepository interface.
 type MockOrderRepository struct {
 	ctrl     *gomock.Controller
@@ -151,18 +196,33 @@ func (m *MockOrderRepository) EXPECT() *MockOrderRepositoryMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockOrderRepository) Create(ctx context.Context, queryExecuter postgres.QueryExecuter, order *entity.Order) (*entity.Order, error) {
+func (m *MockOrderRepository) Create(ctx context.Context, order *entity.Order) (*entity.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, queryExecuter, order)
+	ret := m.ctrl.Call(m, "Create", ctx, order)
 	ret0, _ := ret[0].(*entity.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockOrderRepositoryMockRecorder) Create(ctx, queryExecuter, order any) *gomock.Call {
+func (mr *MockOrderRepositoryMockRecorder) Create(ctx, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrderRepository)(nil).Create), ctx, order)
+}
+
+// DeleteByOrderUIDs mocks base method.
+func (m *MockOrderRepository) DeleteByOrderUIDs(ctx context.Context, orderUIDs []uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByOrderUIDs", ctx, orderUIDs)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByOrderUIDs indicates an expected call of DeleteByOrderUIDs.
+func (mr *MockOrderRepositoryMockRecorder) DeleteByOrderUIDs(ctx, orderUIDs any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrderRepository)(nil).Create), ctx, queryExecuter, order)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByOrderUIDs", reflect.TypeOf((*MockOrderRepository)(nil).DeleteByOrderUIDs), ctx, orderUIDs)
 }
 
 // GetAllOrderUIDs mocks base method.
@@ -180,6 +240,22 @@ func (mr *MockOrderRepositoryMockRecorder) GetAllOrderUIDs(ctx any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllOrderUIDs", reflect.TypeOf((*MockOrderRepository)(nil).GetAllOrderUIDs), ctx)
 }
 
+// GetAnyByOrderUID mocks base method.
+func (m *MockOrderRepository) GetAnyByOrderUID(ctx context.Context, orderUID uuid.UUID) (*entity.Order, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAnyByOrderUID", ctx, orderUID)
+	ret0, _ := ret[0].(*entity.Order)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAnyByOrderUID indicates an expected call of GetAnyByOrderUID.
+func (mr *MockOrderRepositoryMockRecorder) GetAnyByOrderUID(ctx, orderUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAnyByOrderUID", reflect.TypeOf((*MockOrderRepository)(nil).GetAnyByOrderUID), ctx, orderUID)
+}
+
 // GetByOrderUID mocks base method.
 func (m *MockOrderRepository) GetByOrderUID(ctx context.Context, orderUID uuid.UUID) (*entity.Order, error) {
 	m.ctrl.T.Helper()
@@ -195,6 +271,65 @@ func (mr *MockOrderRepositoryMockRecorder) GetByOrderUID(ctx, orderUID any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderUID", reflect.TypeOf((*MockOrderRepository)(nil).GetByOrderUID), ctx, orderUID)
 }
 
+// IncrementVersion mocks base method.
+func (m *MockOrderRepository) IncrementVersion(ctx context.Context, orderUID uuid.UUID, expectedVersion int, payloadHash string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementVersion", ctx, orderUID, expectedVersion, payloadHash)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementVersion indicates an expected call of IncrementVersion.
+func (mr *MockOrderRepositoryMockRecorder) IncrementVersion(ctx, orderUID, expectedVersion, payloadHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementVersion", reflect.TypeOf((*MockOrderRepository)(nil).IncrementVersion), ctx, orderUID, expectedVersion, payloadHash)
+}
+
+// ListExpired mocks base method.
+func (m *MockOrderRepository) ListExpired(ctx context.Context, cutoff time.Time, limit int) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpired", ctx, cutoff, limit)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpired indicates an expected call of ListExpired.
+func (mr *MockOrderRepositoryMockRecorder) ListExpired(ctx, cutoff, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpired", reflect.TypeOf((*MockOrderRepository)(nil).ListExpired), ctx, cutoff, limit)
+}
+
+// SoftDelete mocks base method.
+func (m *MockOrderRepository) SoftDelete(ctx context.Context, orderUID uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", ctx, orderUID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDelete indicates an expected call of SoftDelete.
+func (mr *MockOrderRepositoryMockRecorder) SoftDelete(ctx, orderUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockOrderRepository)(nil).SoftDelete), ctx, orderUID)
+}
+
+// StreamByDateRange mocks base method.
+func (m *MockOrderRepository) StreamByDateRange(ctx context.Context, from, to time.Time, fetchSize int, fn func(*entity.Order) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamByDateRange", ctx, from, to, fetchSize, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamByDateRange indicates an expected call of StreamByDateRange.
+func (mr *MockOrderRepositoryMockRecorder) StreamByDateRange(ctx, from, to, fetchSize, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamByDateRange", reflect.TypeOf((*MockOrderRepository)(nil).StreamByDateRange), ctx, from, to, fetchSize, fn)
+}
+
 // MockPaymentRepository is a mock of PaymentRepository interface.
 type MockPaymentRepository struct {
 	ctrl     *gomock.Controller
@@ -220,18 +355,18 @@ func (m *MockPaymentRepository) EXPECT() *MockPaymentRepositoryMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockPaymentRepository) Create(ctx context.Context, queryExecuter postgres.QueryExecuter, orderUID uuid.UUID, payment *entity.Payment) (*entity.Payment, error) {
+func (m *MockPaymentRepository) Create(ctx context.Context, orderUID uuid.UUID, payment *entity.Payment) (*entity.Payment, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, queryExecuter, orderUID, payment)
+	ret := m.ctrl.Call(m, "Create", ctx, orderUID, payment)
 	ret0, _ := ret[0].(*entity.Payment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockPaymentRepositoryMockRecorder) Create(ctx, queryExecuter, orderUID, payment any) *gomock.Call {
+func (mr *MockPaymentRepositoryMockRecorder) Create(ctx, orderUID, payment any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPaymentRepository)(nil).Create), ctx, queryExecuter, orderUID, payment)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPaymentRepository)(nil).Create), ctx, orderUID, payment)
 }
 
 // GetByOrderUID mocks base method.
@@ -248,3 +383,823 @@ func (mr *MockPaymentRepositoryMockRecorder) GetByOrderUID(ctx, orderUID any) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderUID", reflect.TypeOf((*MockPaymentRepository)(nil).GetByOrderUID), ctx, orderUID)
 }
+
+// GetForUpdate mocks base method.
+func (m *MockPaymentRepository) GetForUpdate(ctx context.Context, orderUID uuid.UUID) (*entity.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForUpdate", ctx, orderUID)
+	ret0, _ := ret[0].(*entity.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForUpdate indicates an expected call of GetForUpdate.
+func (mr *MockPaymentRepositoryMockRecorder) GetForUpdate(ctx, orderUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForUpdate", reflect.TypeOf((*MockPaymentRepository)(nil).GetForUpdate), ctx, orderUID)
+}
+
+// Update mocks base method.
+func (m *MockPaymentRepository) Update(ctx context.Context, orderUID uuid.UUID, payment *entity.Payment) (*entity.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, orderUID, payment)
+	ret0, _ := ret[0].(*entity.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockPaymentRepositoryMockRecorder) Update(ctx, orderUID, payment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPaymentRepository)(nil).Update), ctx, orderUID, payment)
+}
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAuditRepository) Create(ctx context.Context, record *entity.AuditRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAuditRepositoryMockRecorder) Create(ctx, record any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuditRepository)(nil).Create), ctx, record)
+}
+
+// MockArchiveRepository is a mock of ArchiveRepository interface.
+type MockArchiveRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockArchiveRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockArchiveRepositoryMockRecorder is the mock recorder for MockArchiveRepository.
+type MockArchiveRepositoryMockRecorder struct {
+	mock *MockArchiveRepository
+}
+
+// NewMockArchiveRepository creates a new mock instance.
+func NewMockArchiveRepository(ctrl *gomock.Controller) *MockArchiveRepository {
+	mock := &MockArchiveRepository{ctrl: ctrl}
+	mock.recorder = &MockArchiveRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArchiveRepository) EXPECT() *MockArchiveRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ArchiveOrders mocks base method.
+func (m *MockArchiveRepository) ArchiveOrders(ctx context.Context, orderUIDs []uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveOrders", ctx, orderUIDs)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArchiveOrders indicates an expected call of ArchiveOrders.
+func (mr *MockArchiveRepositoryMockRecorder) ArchiveOrders(ctx, orderUIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveOrders", reflect.TypeOf((*MockArchiveRepository)(nil).ArchiveOrders), ctx, orderUIDs)
+}
+
+// MockSearchRepository is a mock of SearchRepository interface.
+type MockSearchRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSearchRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSearchRepositoryMockRecorder is the mock recorder for MockSearchRepository.
+type MockSearchRepositoryMockRecorder struct {
+	mock *MockSearchRepository
+}
+
+// NewMockSearchRepository creates a new mock instance.
+func NewMockSearchRepository(ctrl *gomock.Controller) *MockSearchRepository {
+	mock := &MockSearchRepository{ctrl: ctrl}
+	mock.recorder = &MockSearchRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSearchRepository) EXPECT() *MockSearchRepositoryMockRecorder {
+	return m.recorder
+}
+
+// SearchOrders mocks base method.
+func (m *MockSearchRepository) SearchOrders(ctx context.Context, query string, limit, offset int) ([]uuid.UUID, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchOrders", ctx, query, limit, offset)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchOrders indicates an expected call of SearchOrders.
+func (mr *MockSearchRepositoryMockRecorder) SearchOrders(ctx, query, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchOrders", reflect.TypeOf((*MockSearchRepository)(nil).SearchOrders), ctx, query, limit, offset)
+}
+
+// MockCustomerRepository is a mock of CustomerRepository interface.
+type MockCustomerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCustomerRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockCustomerRepositoryMockRecorder is the mock recorder for MockCustomerRepository.
+type MockCustomerRepositoryMockRecorder struct {
+	mock *MockCustomerRepository
+}
+
+// NewMockCustomerRepository creates a new mock instance.
+func NewMockCustomerRepository(ctrl *gomock.Controller) *MockCustomerRepository {
+	mock := &MockCustomerRepository{ctrl: ctrl}
+	mock.recorder = &MockCustomerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCustomerRepository) EXPECT() *MockCustomerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetSummary mocks base method.
+func (m *MockCustomerRepository) GetSummary(ctx context.Context, customerID string, topBrands int) (*entity.CustomerSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSummary", ctx, customerID, topBrands)
+	ret0, _ := ret[0].(*entity.CustomerSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSummary indicates an expected call of GetSummary.
+func (mr *MockCustomerRepositoryMockRecorder) GetSummary(ctx, customerID, topBrands any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSummary", reflect.TypeOf((*MockCustomerRepository)(nil).GetSummary), ctx, customerID, topBrands)
+}
+
+// ListOrders mocks base method.
+func (m *MockCustomerRepository) ListOrders(ctx context.Context, customerID string, limit, offset int) ([]uuid.UUID, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrders", ctx, customerID, limit, offset)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockCustomerRepositoryMockRecorder) ListOrders(ctx, customerID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockCustomerRepository)(nil).ListOrders), ctx, customerID, limit, offset)
+}
+
+// MockAnalyticsRepository is a mock of AnalyticsRepository interface.
+type MockAnalyticsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnalyticsRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAnalyticsRepositoryMockRecorder is the mock recorder for MockAnalyticsRepository.
+type MockAnalyticsRepositoryMockRecorder struct {
+	mock *MockAnalyticsRepository
+}
+
+// NewMockAnalyticsRepository creates a new mock instance.
+func NewMockAnalyticsRepository(ctrl *gomock.Controller) *MockAnalyticsRepository {
+	mock := &MockAnalyticsRepository{ctrl: ctrl}
+	mock.recorder = &MockAnalyticsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnalyticsRepository) EXPECT() *MockAnalyticsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetOrderAnalytics mocks base method.
+func (m *MockAnalyticsRepository) GetOrderAnalytics(ctx context.Context, filter *entity.AnalyticsFilter) (*entity.OrderAnalytics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderAnalytics", ctx, filter)
+	ret0, _ := ret[0].(*entity.OrderAnalytics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderAnalytics indicates an expected call of GetOrderAnalytics.
+func (mr *MockAnalyticsRepositoryMockRecorder) GetOrderAnalytics(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderAnalytics", reflect.TypeOf((*MockAnalyticsRepository)(nil).GetOrderAnalytics), ctx, filter)
+}
+
+// RecordOrder mocks base method.
+func (m *MockAnalyticsRepository) RecordOrder(ctx context.Context, order *entity.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordOrder", ctx, order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordOrder indicates an expected call of RecordOrder.
+func (mr *MockAnalyticsRepositoryMockRecorder) RecordOrder(ctx, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordOrder", reflect.TypeOf((*MockAnalyticsRepository)(nil).RecordOrder), ctx, order)
+}
+
+// RemoveOrder mocks base method.
+func (m *MockAnalyticsRepository) RemoveOrder(ctx context.Context, order *entity.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveOrder", ctx, order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveOrder indicates an expected call of RemoveOrder.
+func (mr *MockAnalyticsRepositoryMockRecorder) RemoveOrder(ctx, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveOrder", reflect.TypeOf((*MockAnalyticsRepository)(nil).RemoveOrder), ctx, order)
+}
+
+// MockRefundRepository is a mock of RefundRepository interface.
+type MockRefundRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefundRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRefundRepositoryMockRecorder is the mock recorder for MockRefundRepository.
+type MockRefundRepositoryMockRecorder struct {
+	mock *MockRefundRepository
+}
+
+// NewMockRefundRepository creates a new mock instance.
+func NewMockRefundRepository(ctrl *gomock.Controller) *MockRefundRepository {
+	mock := &MockRefundRepository{ctrl: ctrl}
+	mock.recorder = &MockRefundRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefundRepository) EXPECT() *MockRefundRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRefundRepository) Create(ctx context.Context, refund *entity.Refund) (*entity.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, refund)
+	ret0, _ := ret[0].(*entity.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRefundRepositoryMockRecorder) Create(ctx, refund any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRefundRepository)(nil).Create), ctx, refund)
+}
+
+// ListByOrderUID mocks base method.
+func (m *MockRefundRepository) ListByOrderUID(ctx context.Context, orderUID uuid.UUID) ([]*entity.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByOrderUID", ctx, orderUID)
+	ret0, _ := ret[0].([]*entity.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByOrderUID indicates an expected call of ListByOrderUID.
+func (mr *MockRefundRepositoryMockRecorder) ListByOrderUID(ctx, orderUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByOrderUID", reflect.TypeOf((*MockRefundRepository)(nil).ListByOrderUID), ctx, orderUID)
+}
+
+// SumByOrderUID mocks base method.
+func (m *MockRefundRepository) SumByOrderUID(ctx context.Context, orderUID uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumByOrderUID", ctx, orderUID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumByOrderUID indicates an expected call of SumByOrderUID.
+func (mr *MockRefundRepositoryMockRecorder) SumByOrderUID(ctx, orderUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumByOrderUID", reflect.TypeOf((*MockRefundRepository)(nil).SumByOrderUID), ctx, orderUID)
+}
+
+// MockEventRepository is a mock of EventRepository interface.
+type MockEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockEventRepositoryMockRecorder is the mock recorder for MockEventRepository.
+type MockEventRepositoryMockRecorder struct {
+	mock *MockEventRepository
+}
+
+// NewMockEventRepository creates a new mock instance.
+func NewMockEventRepository(ctrl *gomock.Controller) *MockEventRepository {
+	mock := &MockEventRepository{ctrl: ctrl}
+	mock.recorder = &MockEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventRepository) EXPECT() *MockEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimPending mocks base method.
+func (m *MockEventRepository) ClaimPending(ctx context.Context, limit int, leaseUntil time.Time) ([]*entity.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPending", ctx, limit, leaseUntil)
+	ret0, _ := ret[0].([]*entity.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPending indicates an expected call of ClaimPending.
+func (mr *MockEventRepositoryMockRecorder) ClaimPending(ctx, limit, leaseUntil any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPending", reflect.TypeOf((*MockEventRepository)(nil).ClaimPending), ctx, limit, leaseUntil)
+}
+
+// Create mocks base method.
+func (m *MockEventRepository) Create(ctx context.Context, event *entity.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockEventRepositoryMockRecorder) Create(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockEventRepository)(nil).Create), ctx, event)
+}
+
+// DeleteByIDs mocks base method.
+func (m *MockEventRepository) DeleteByIDs(ctx context.Context, eventIDs []uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByIDs", ctx, eventIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByIDs indicates an expected call of DeleteByIDs.
+func (mr *MockEventRepositoryMockRecorder) DeleteByIDs(ctx, eventIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByIDs", reflect.TypeOf((*MockEventRepository)(nil).DeleteByIDs), ctx, eventIDs)
+}
+
+// MockWebhookQueue is a mock of WebhookQueue interface.
+type MockWebhookQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookQueueMockRecorder
+	isgomock struct{}
+}
+
+// MockWebhookQueueMockRecorder is the mock recorder for MockWebhookQueue.
+type MockWebhookQueueMockRecorder struct {
+	mock *MockWebhookQueue
+}
+
+// NewMockWebhookQueue creates a new mock instance.
+func NewMockWebhookQueue(ctrl *gomock.Controller) *MockWebhookQueue {
+	mock := &MockWebhookQueue{ctrl: ctrl}
+	mock.recorder = &MockWebhookQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookQueue) EXPECT() *MockWebhookQueueMockRecorder {
+	return m.recorder
+}
+
+// EnqueueDeliveries mocks base method.
+func (m *MockWebhookQueue) EnqueueDeliveries(ctx context.Context, eventIDs []uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueDeliveries", ctx, eventIDs)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueDeliveries indicates an expected call of EnqueueDeliveries.
+func (mr *MockWebhookQueueMockRecorder) EnqueueDeliveries(ctx, eventIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueDeliveries", reflect.TypeOf((*MockWebhookQueue)(nil).EnqueueDeliveries), ctx, eventIDs)
+}
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimDue mocks base method.
+func (m *MockWebhookRepository) ClaimDue(ctx context.Context, limit int, leaseUntil time.Time) ([]*entity.OutgoingWebhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDue", ctx, limit, leaseUntil)
+	ret0, _ := ret[0].([]*entity.OutgoingWebhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDue indicates an expected call of ClaimDue.
+func (mr *MockWebhookRepositoryMockRecorder) ClaimDue(ctx, limit, leaseUntil any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDue", reflect.TypeOf((*MockWebhookRepository)(nil).ClaimDue), ctx, limit, leaseUntil)
+}
+
+// CreateSubscription mocks base method.
+func (m *MockWebhookRepository) CreateSubscription(ctx context.Context, sub *entity.WebhookSubscription) (*entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", ctx, sub)
+	ret0, _ := ret[0].(*entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockWebhookRepositoryMockRecorder) CreateSubscription(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockWebhookRepository)(nil).CreateSubscription), ctx, sub)
+}
+
+// DeleteSubscription mocks base method.
+func (m *MockWebhookRepository) DeleteSubscription(ctx context.Context, subscriptionID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, subscriptionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubscription indicates an expected call of DeleteSubscription.
+func (mr *MockWebhookRepositoryMockRecorder) DeleteSubscription(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockWebhookRepository)(nil).DeleteSubscription), ctx, subscriptionID)
+}
+
+// EnqueueDeliveries mocks base method.
+func (m *MockWebhookRepository) EnqueueDeliveries(ctx context.Context, eventIDs []uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueDeliveries", ctx, eventIDs)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueDeliveries indicates an expected call of EnqueueDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) EnqueueDeliveries(ctx, eventIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).EnqueueDeliveries), ctx, eventIDs)
+}
+
+// GetSubscription mocks base method.
+func (m *MockWebhookRepository) GetSubscription(ctx context.Context, subscriptionID uuid.UUID) (*entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscription", ctx, subscriptionID)
+	ret0, _ := ret[0].(*entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscription indicates an expected call of GetSubscription.
+func (mr *MockWebhookRepositoryMockRecorder) GetSubscription(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscription", reflect.TypeOf((*MockWebhookRepository)(nil).GetSubscription), ctx, subscriptionID)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string, limit, offset int) ([]*entity.WebhookDelivery, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", ctx, subscriptionID, status, limit, offset)
+	ret0, _ := ret[0].([]*entity.WebhookDelivery)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) ListDeliveries(ctx, subscriptionID, status, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).ListDeliveries), ctx, subscriptionID, status, limit, offset)
+}
+
+// ListSubscriptions mocks base method.
+func (m *MockWebhookRepository) ListSubscriptions(ctx context.Context) ([]*entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptions", ctx)
+	ret0, _ := ret[0].([]*entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscriptions indicates an expected call of ListSubscriptions.
+func (mr *MockWebhookRepositoryMockRecorder) ListSubscriptions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptions", reflect.TypeOf((*MockWebhookRepository)(nil).ListSubscriptions), ctx)
+}
+
+// RecordFailure mocks base method.
+func (m *MockWebhookRepository) RecordFailure(ctx context.Context, subscriptionID uuid.UUID, disableAfter int) (int, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailure", ctx, subscriptionID, disableAfter)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RecordFailure indicates an expected call of RecordFailure.
+func (mr *MockWebhookRepositoryMockRecorder) RecordFailure(ctx, subscriptionID, disableAfter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailure", reflect.TypeOf((*MockWebhookRepository)(nil).RecordFailure), ctx, subscriptionID, disableAfter)
+}
+
+// ResetFailures mocks base method.
+func (m *MockWebhookRepository) ResetFailures(ctx context.Context, subscriptionID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetFailures", ctx, subscriptionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetFailures indicates an expected call of ResetFailures.
+func (mr *MockWebhookRepositoryMockRecorder) ResetFailures(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetFailures", reflect.TypeOf((*MockWebhookRepository)(nil).ResetFailures), ctx, subscriptionID)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockWebhookRepository) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) UpdateDelivery(ctx, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).UpdateDelivery), ctx, delivery)
+}
+
+// UpdateSubscription mocks base method.
+func (m *MockWebhookRepository) UpdateSubscription(ctx context.Context, sub *entity.WebhookSubscription) (*entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubscription", ctx, sub)
+	ret0, _ := ret[0].(*entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSubscription indicates an expected call of UpdateSubscription.
+func (mr *MockWebhookRepositoryMockRecorder) UpdateSubscription(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscription", reflect.TypeOf((*MockWebhookRepository)(nil).UpdateSubscription), ctx, sub)
+}
+
+// MockOrderPublisher is a mock of OrderPublisher interface.
+type MockOrderPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderPublisherMockRecorder
+	isgomock struct{}
+}
+
+// MockOrderPublisherMockRecorder is the mock recorder for MockOrderPublisher.
+type MockOrderPublisherMockRecorder struct {
+	mock *MockOrderPublisher
+}
+
+// NewMockOrderPublisher creates a new mock instance.
+func NewMockOrderPublisher(ctrl *gomock.Controller) *MockOrderPublisher {
+	mock := &MockOrderPublisher{ctrl: ctrl}
+	mock.recorder = &MockOrderPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderPublisher) EXPECT() *MockOrderPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockOrderPublisher) Publish(order *entity.Order) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Publish", order)
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockOrderPublisherMockRecorder) Publish(order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockOrderPublisher)(nil).Publish), order)
+}
+
+// MockRateProvider is a mock of RateProvider interface.
+type MockRateProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockRateProviderMockRecorder is the mock recorder for MockRateProvider.
+type MockRateProviderMockRecorder struct {
+	mock *MockRateProvider
+}
+
+// NewMockRateProvider creates a new mock instance.
+func NewMockRateProvider(ctrl *gomock.Controller) *MockRateProvider {
+	mock := &MockRateProvider{ctrl: ctrl}
+	mock.recorder = &MockRateProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateProvider) EXPECT() *MockRateProviderMockRecorder {
+	return m.recorder
+}
+
+// Rate mocks base method.
+func (m *MockRateProvider) Rate(ctx context.Context, from, to string) (*big.Rat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rate", ctx, from, to)
+	ret0, _ := ret[0].(*big.Rat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rate indicates an expected call of Rate.
+func (mr *MockRateProviderMockRecorder) Rate(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rate", reflect.TypeOf((*MockRateProvider)(nil).Rate), ctx, from, to)
+}
+
+// MockSchemaRepository is a mock of SchemaRepository interface.
+type MockSchemaRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSchemaRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSchemaRepositoryMockRecorder is the mock recorder for MockSchemaRepository.
+type MockSchemaRepositoryMockRecorder struct {
+	mock *MockSchemaRepository
+}
+
+// NewMockSchemaRepository creates a new mock instance.
+func NewMockSchemaRepository(ctrl *gomock.Controller) *MockSchemaRepository {
+	mock := &MockSchemaRepository{ctrl: ctrl}
+	mock.recorder = &MockSchemaRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSchemaRepository) EXPECT() *MockSchemaRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockSchemaRepository) Create(ctx context.Context, schema *entity.SchemaVersion) (*entity.SchemaVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, schema)
+	ret0, _ := ret[0].(*entity.SchemaVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSchemaRepositoryMockRecorder) Create(ctx, schema any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSchemaRepository)(nil).Create), ctx, schema)
+}
+
+// GetByFingerprint mocks base method.
+func (m *MockSchemaRepository) GetByFingerprint(ctx context.Context, subject, fingerprint string) (*entity.SchemaVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByFingerprint", ctx, subject, fingerprint)
+	ret0, _ := ret[0].(*entity.SchemaVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByFingerprint indicates an expected call of GetByFingerprint.
+func (mr *MockSchemaRepositoryMockRecorder) GetByFingerprint(ctx, subject, fingerprint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByFingerprint", reflect.TypeOf((*MockSchemaRepository)(nil).GetByFingerprint), ctx, subject, fingerprint)
+}
+
+// GetLatest mocks base method.
+func (m *MockSchemaRepository) GetLatest(ctx context.Context, subject string) (*entity.SchemaVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatest", ctx, subject)
+	ret0, _ := ret[0].(*entity.SchemaVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatest indicates an expected call of GetLatest.
+func (mr *MockSchemaRepositoryMockRecorder) GetLatest(ctx, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatest", reflect.TypeOf((*MockSchemaRepository)(nil).GetLatest), ctx, subject)
+}
+
+// GetVersion mocks base method.
+func (m *MockSchemaRepository) GetVersion(ctx context.Context, subject string, version int) (*entity.SchemaVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion", ctx, subject, version)
+	ret0, _ := ret[0].(*entity.SchemaVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersion indicates an expected call of GetVersion.
+func (mr *MockSchemaRepositoryMockRecorder) GetVersion(ctx, subject, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockSchemaRepository)(nil).GetVersion), ctx, subject, version)
+}
+
+// ListVersions mocks base method.
+func (m *MockSchemaRepository) ListVersions(ctx context.Context, subject string) ([]*entity.SchemaVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVersions", ctx, subject)
+	ret0, _ := ret[0].([]*entity.SchemaVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVersions indicates an expected call of ListVersions.
+func (mr *MockSchemaRepositoryMockRecorder) ListVersions(ctx, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVersions", reflect.TypeOf((*MockSchemaRepository)(nil).ListVersions), ctx, subject)
+}
+
+// LockSubject mocks base method.
+func (m *MockSchemaRepository) LockSubject(ctx context.Context, subject string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockSubject", ctx, subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockSubject indicates an expected call of LockSubject.
+func (mr *MockSchemaRepositoryMockRecorder) LockSubject(ctx, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockSubject", reflect.TypeOf((*MockSchemaRepository)(nil).LockSubject), ctx, subject)
+}
@@ -2,16 +2,18 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"wbtest/internal/entity"
 	"wbtest/pkg/storage/postgres"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
-	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type OrderRepository struct {
@@ -24,13 +26,13 @@ func NewOrderRepository(db *postgres.Postgres) *OrderRepository {
 
 func (dr *OrderRepository) Create(
 	ctx context.Context,
-	queryExecuter postgres.QueryExecuter,
 	order *entity.Order,
 ) (*entity.Order, error) {
 	const op = "repository.order.Create"
+	ctx = postgres.WithOperation(ctx, op)
 
 	query := dr.db.Builder.Insert(`"orders"`).
-		Columns("order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id", "delivery_service", "shardkey", "sm_id", "date_created", "oof_shard").
+		Columns("order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id", "delivery_service", "shardkey", "sm_id", "date_created", "oof_shard", "payload_hash").
 		Values(
 			order.OrderUID,
 			order.TrackNumber,
@@ -43,8 +45,9 @@ func (dr *OrderRepository) Create(
 			order.SmID,
 			order.DateCreated,
 			order.OofShard,
+			order.PayloadHash,
 		).
-		Suffix("RETURNING order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard")
+		Suffix("RETURNING order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, COALESCE(payload_hash, ''), version")
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -52,7 +55,7 @@ func (dr *OrderRepository) Create(
 	}
 
 	result := &entity.Order{}
-	err = queryExecuter.QueryRow(ctx, sql, args...).Scan(
+	err = dr.db.Executer(ctx).QueryRow(ctx, sql, args...).Scan(
 		&result.OrderUID,
 		&result.TrackNumber,
 		&result.Entry,
@@ -64,6 +67,8 @@ func (dr *OrderRepository) Create(
 		&result.SmID,
 		&result.DateCreated,
 		&result.OofShard,
+		&result.PayloadHash,
+		&result.Version,
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -81,10 +86,15 @@ func (dr *OrderRepository) GetByOrderUID(
 	orderUID uuid.UUID,
 ) (*entity.Order, error) {
 	const op = "repository.order.Get"
+	ctx = postgres.WithOperation(ctx, op)
 
-	query := dr.db.Builder.Select("*").
+	query := dr.db.Builder.Select(
+		"order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id",
+		"delivery_service", "shardkey", "sm_id", "date_created", "oof_shard", "COALESCE(payload_hash, '')",
+		"version",
+	).
 		From(`"orders"`).
-		Where(squirrel.Eq{"order_uid": orderUID}).
+		Where(squirrel.Eq{"order_uid": orderUID, "deleted_at": nil}).
 		Limit(1)
 
 	sql, args, err := query.ToSql()
@@ -93,7 +103,7 @@ func (dr *OrderRepository) GetByOrderUID(
 	}
 
 	result := &entity.Order{}
-	err = dr.db.Pool.QueryRow(ctx, sql, args...).Scan(
+	err = dr.db.Reader(ctx).QueryRow(ctx, sql, args...).Scan(
 		&result.OrderUID,
 		&result.TrackNumber,
 		&result.Entry,
@@ -105,6 +115,8 @@ func (dr *OrderRepository) GetByOrderUID(
 		&result.SmID,
 		&result.DateCreated,
 		&result.OofShard,
+		&result.PayloadHash,
+		&result.Version,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -116,17 +128,198 @@ func (dr *OrderRepository) GetByOrderUID(
 	return result, nil
 }
 
+// GetAnyByOrderUID reads the order whether or not it has been soft-deleted and reports which.
+func (dr *OrderRepository) GetAnyByOrderUID(
+	ctx context.Context,
+	orderUID uuid.UUID,
+) (*entity.Order, bool, error) {
+	const op = "repository.order.GetAny"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := dr.db.Builder.Select(
+		"order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id",
+		"delivery_service", "shardkey", "sm_id", "date_created", "oof_shard", "COALESCE(payload_hash, '')",
+		"version", "deleted_at IS NOT NULL",
+	).
+		From(`"orders"`).
+		Where(squirrel.Eq{"order_uid": orderUID}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	var deleted bool
+	result := &entity.Order{}
+	err = dr.db.Reader(ctx).QueryRow(ctx, sql, args...).Scan(
+		&result.OrderUID,
+		&result.TrackNumber,
+		&result.Entry,
+		&result.Locale,
+		&result.InternalSignature,
+		&result.CustomerID,
+		&result.DeliveryService,
+		&result.Shardkey,
+		&result.SmID,
+		&result.DateCreated,
+		&result.OofShard,
+		&result.PayloadHash,
+		&result.Version,
+		&deleted,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, entity.ErrDataNotFound
+		}
+		return nil, false, fmt.Errorf("%s: query row: %w", op, err)
+	}
+
+	return result, deleted, nil
+}
+
+// IncrementVersion bumps the version of a live order still at expectedVersion and stores
+// payloadHash, the hash replays of the order are compared with from now on.
+func (dr *OrderRepository) IncrementVersion(
+	ctx context.Context,
+	orderUID uuid.UUID,
+	expectedVersion int,
+	payloadHash string,
+) (int, error) {
+	const op = "repository.order.IncrementVersion"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := dr.db.Builder.Update(`"orders"`).
+		Set("version", squirrel.Expr("version + 1")).
+		Set("payload_hash", payloadHash).
+		Where(squirrel.Eq{"order_uid": orderUID, "version": expectedVersion, "deleted_at": nil}).
+		Suffix("RETURNING version")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	var version int
+	if err = dr.db.Executer(ctx).QueryRow(ctx, sql, args...).Scan(&version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, entity.ErrVersionMismatch
+		}
+		return 0, fmt.Errorf("%s: query row: %w", op, err)
+	}
+
+	return version, nil
+}
+
+func (dr *OrderRepository) SoftDelete(
+	ctx context.Context,
+	orderUID uuid.UUID,
+) (int, error) {
+	const op = "repository.order.SoftDelete"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := dr.db.Builder.Update(`"orders"`).
+		Set("deleted_at", squirrel.Expr("NOW()")).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"order_uid": orderUID, "deleted_at": nil}).
+		Suffix("RETURNING version")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	var version int
+	if err = dr.db.Executer(ctx).QueryRow(ctx, sql, args...).Scan(&version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, entity.ErrDataNotFound
+		}
+		return 0, fmt.Errorf("%s: query row: %w", op, err)
+	}
+
+	return version, nil
+}
+
+// ListExpired locks up to limit orders soft-deleted before cutoff. Live orders never expire.
+func (dr *OrderRepository) ListExpired(
+	ctx context.Context,
+	cutoff time.Time,
+	limit int,
+) ([]uuid.UUID, error) {
+	const op = "repository.order.ListExpired"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := dr.db.Builder.Select("order_uid").
+		From(`"orders"`).
+		Where(squirrel.Lt{"deleted_at": cutoff}).
+		OrderBy("deleted_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	rows, err := dr.db.Executer(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var uids []uuid.UUID
+	for rows.Next() {
+		var uid uuid.UUID
+		if err = rows.Scan(&uid); err != nil {
+			return nil, fmt.Errorf("%s: row scan: %w", op, err)
+		}
+		uids = append(uids, uid)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: rows final error: %w", op, rows.Err())
+	}
+
+	return uids, nil
+}
+
+func (dr *OrderRepository) DeleteByOrderUIDs(
+	ctx context.Context,
+	orderUIDs []uuid.UUID,
+) (int64, error) {
+	const op = "repository.order.DeleteByOrderUIDs"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := dr.db.Builder.Delete(`"orders"`).
+		Where(squirrel.Eq{"order_uid": orderUIDs})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	tag, err := dr.db.Executer(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: exec: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
 func (dr *OrderRepository) GetAllOrderUIDs(ctx context.Context) ([]uuid.UUID, error) {
 	const op = "repository.order.GetAllOrderUIDs"
+	ctx = postgres.WithOperation(ctx, op)
 
-	query := dr.db.Builder.Select("order_uid").From(`"orders"`)
+	query := dr.db.Builder.Select("order_uid").
+		From(`"orders"`).
+		Where(squirrel.Eq{"deleted_at": nil})
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("%s: building query: %w", op, err)
 	}
 
-	rows, err := dr.db.Pool.Query(ctx, sql, args...)
+	rows, err := dr.db.Reader(ctx).Query(ctx, sql, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%s: query: %w", op, err)
 	}
@@ -147,3 +340,93 @@ func (dr *OrderRepository) GetAllOrderUIDs(ctx context.Context) ([]uuid.UUID, er
 
 	return uids, nil
 }
+
+// StreamByDateRange passes the orders created in [from, to) to fn in creation order, reading
+// them through a server-side cursor fetchSize at a time. The export runs on the primary on
+// purpose: the cursor stays open for the whole export, which a replica may cancel on a
+// recovery conflict, and once orders were passed to fn it cannot be retried elsewhere.
+func (dr *OrderRepository) StreamByDateRange(
+	ctx context.Context,
+	from, to time.Time,
+	fetchSize int,
+	fn func(order *entity.Order) error,
+) error {
+	const op = "repository.order.StreamByDateRange"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := dr.db.Builder.Select(_orderDocumentExpr).
+		From(_orderDocumentFrom).
+		Where(squirrel.Eq{"o.deleted_at": nil}).
+		OrderBy("o.date_created", "o.order_uid")
+	if !from.IsZero() {
+		query = query.Where(squirrel.GtOrEq{"o.date_created": from})
+	}
+	if !to.IsZero() {
+		query = query.Where(squirrel.Lt{"o.date_created": to})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	tx, err := dr.db.Pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+	}()
+
+	if _, err = tx.Exec(ctx, "DECLARE order_export NO SCROLL CURSOR FOR "+sql, args...); err != nil {
+		return fmt.Errorf("%s: declare cursor: %w", op, err)
+	}
+
+	fetchSQL := fmt.Sprintf("FETCH FORWARD %d FROM order_export", fetchSize)
+	for {
+		fetched, fetchErr := dr.fetchDocuments(ctx, tx, fetchSQL, fn)
+		if fetchErr != nil {
+			return fmt.Errorf("%s: %w", op, fetchErr)
+		}
+		if fetched < fetchSize {
+			return nil
+		}
+	}
+}
+
+func (dr *OrderRepository) fetchDocuments(
+	ctx context.Context,
+	tx pgx.Tx,
+	fetchSQL string,
+	fn func(order *entity.Order) error,
+) (int, error) {
+	rows, err := tx.Query(ctx, fetchSQL)
+	if err != nil {
+		return 0, fmt.Errorf("fetch: %w", err)
+	}
+	defer rows.Close()
+
+	fetched := 0
+	for rows.Next() {
+		var document []byte
+		if err = rows.Scan(&document); err != nil {
+			return fetched, fmt.Errorf("row scan: %w", err)
+		}
+
+		order := &entity.Order{}
+		if err = json.Unmarshal(document, order); err != nil {
+			return fetched, fmt.Errorf("decode order: %w", err)
+		}
+
+		if err = fn(order); err != nil {
+			return fetched, err
+		}
+		fetched++
+	}
+
+	if rows.Err() != nil {
+		return fetched, fmt.Errorf("rows final error: %w", rows.Err())
+	}
+
+	return fetched, nil
+}
@@ -10,8 +10,8 @@ import (
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
-	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type PaymentRepository struct {
@@ -24,11 +24,11 @@ func NewPaymentRepository(db *postgres.Postgres) *PaymentRepository {
 
 func (dr *PaymentRepository) Create(
 	ctx context.Context,
-	queryExecuter postgres.QueryExecuter,
 	orderUID uuid.UUID,
 	payment *entity.Payment,
 ) (*entity.Payment, error) {
 	const op = "repository.payment.Create"
+	ctx = postgres.WithOperation(ctx, op)
 
 	query := dr.db.Builder.Insert("payment").
 		Columns("order_uid", "transaction", "request_id", "currency", "provider", "amount", "payment_dt", "bank", "delivery_cost", "goods_total", "custom_fee").
@@ -53,7 +53,7 @@ func (dr *PaymentRepository) Create(
 	}
 
 	result := &entity.Payment{}
-	err = queryExecuter.QueryRow(ctx, sql, args...).Scan(
+	err = dr.db.Executer(ctx).QueryRow(ctx, sql, args...).Scan(
 		&result.Transaction,
 		&result.RequestID,
 		&result.Currency,
@@ -81,6 +81,7 @@ func (dr *PaymentRepository) GetByOrderUID(
 	orderUID uuid.UUID,
 ) (*entity.Payment, error) {
 	const op = "repository.payment.GetByOrderUID"
+	ctx = postgres.WithOperation(ctx, op)
 
 	query := dr.db.Builder.Select("*").
 		From("payment").
@@ -93,7 +94,7 @@ func (dr *PaymentRepository) GetByOrderUID(
 	}
 
 	result := &entity.Payment{}
-	err = dr.db.Pool.QueryRow(ctx, sql, args...).Scan(
+	err = dr.db.Reader(ctx).QueryRow(ctx, sql, args...).Scan(
 		&orderUID,
 		&result.Transaction,
 		&result.RequestID,
@@ -115,3 +116,101 @@ func (dr *PaymentRepository) GetByOrderUID(
 
 	return result, nil
 }
+
+func (dr *PaymentRepository) Update(
+	ctx context.Context,
+	orderUID uuid.UUID,
+	payment *entity.Payment,
+) (*entity.Payment, error) {
+	const op = "repository.payment.Update"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := dr.db.Builder.Update("payment").
+		SetMap(map[string]interface{}{
+			"transaction":   payment.Transaction,
+			"request_id":    payment.RequestID,
+			"currency":      payment.Currency,
+			"provider":      payment.Provider,
+			"amount":        payment.Amount,
+			"payment_dt":    payment.PaymentDt,
+			"bank":          payment.Bank,
+			"delivery_cost": payment.DeliveryCost,
+			"goods_total":   payment.GoodsTotal,
+			"custom_fee":    payment.CustomFee,
+		}).
+		Where(squirrel.Eq{"order_uid": orderUID}).
+		Suffix("RETURNING transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	result := &entity.Payment{}
+	err = dr.db.Executer(ctx).QueryRow(ctx, sql, args...).Scan(
+		&result.Transaction,
+		&result.RequestID,
+		&result.Currency,
+		&result.Provider,
+		&result.Amount,
+		&result.PaymentDt,
+		&result.Bank,
+		&result.DeliveryCost,
+		&result.GoodsTotal,
+		&result.CustomFee,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrDataNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, entity.ErrConflictingData
+		}
+		return nil, fmt.Errorf("%s: query row: %w", op, err)
+	}
+
+	return result, nil
+}
+
+// GetForUpdate reads the payment inside the transaction carried by ctx and locks it until the
+// transaction ends, serializing refunds of the same order.
+func (dr *PaymentRepository) GetForUpdate(
+	ctx context.Context,
+	orderUID uuid.UUID,
+) (*entity.Payment, error) {
+	const op = "repository.payment.GetForUpdate"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := dr.db.Builder.Select("transaction", "request_id", "currency", "provider", "amount", "payment_dt", "bank", "delivery_cost", "goods_total", "custom_fee").
+		From("payment").
+		Where(squirrel.Eq{"order_uid": orderUID}).
+		Suffix("FOR UPDATE")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	result := &entity.Payment{}
+	err = dr.db.Executer(ctx).QueryRow(ctx, sql, args...).Scan(
+		&result.Transaction,
+		&result.RequestID,
+		&result.Currency,
+		&result.Provider,
+		&result.Amount,
+		&result.PaymentDt,
+		&result.Bank,
+		&result.DeliveryCost,
+		&result.GoodsTotal,
+		&result.CustomFee,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrDataNotFound
+		}
+		return nil, fmt.Errorf("%s: query row: %w", op, err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/storage/postgres"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+type RefundRepository struct {
+	db *postgres.Postgres
+}
+
+func NewRefundRepository(db *postgres.Postgres) *RefundRepository {
+	return &RefundRepository{db}
+}
+
+func (rr *RefundRepository) Create(
+	ctx context.Context,
+	refund *entity.Refund,
+) (*entity.Refund, error) {
+	const op = "repository.refund.Create"
+	ctx = postgres.WithOperation(ctx, op)
+
+	items := refund.Items
+	if items == nil {
+		items = []uuid.UUID{}
+	}
+
+	query := rr.db.Builder.Insert("refunds").
+		Columns("refund_id", "order_uid", "amount", "currency", "items", "reason").
+		Values(
+			refund.RefundID,
+			refund.OrderUID,
+			refund.Amount,
+			refund.Currency,
+			items,
+			refund.Reason,
+		).
+		Suffix("RETURNING created_at")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	result := *refund
+	result.Items = items
+	if err = rr.db.Executer(ctx).QueryRow(ctx, sql, args...).Scan(&result.CreatedAt); err != nil {
+		return nil, fmt.Errorf("%s: query row: %w", op, err)
+	}
+
+	return &result, nil
+}
+
+// SumByOrderUID returns how much of the order has been refunded so far.
+func (rr *RefundRepository) SumByOrderUID(
+	ctx context.Context,
+	orderUID uuid.UUID,
+) (int64, error) {
+	const op = "repository.refund.SumByOrderUID"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := rr.db.Builder.Select("COALESCE(SUM(amount), 0)").
+		From("refunds").
+		Where(squirrel.Eq{"order_uid": orderUID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	var total int64
+	if err = rr.db.Executer(ctx).QueryRow(ctx, sql, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("%s: query row: %w", op, err)
+	}
+
+	return total, nil
+}
+
+// ListByOrderUID returns the order's refunds, oldest first.
+func (rr *RefundRepository) ListByOrderUID(
+	ctx context.Context,
+	orderUID uuid.UUID,
+) ([]*entity.Refund, error) {
+	const op = "repository.refund.ListByOrderUID"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := rr.db.Builder.Select("refund_id", "order_uid", "amount", "currency", "items", "reason", "created_at").
+		From("refunds").
+		Where(squirrel.Eq{"order_uid": orderUID}).
+		OrderBy("created_at", "refund_id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	rows, err := rr.db.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	result := make([]*entity.Refund, 0)
+	for rows.Next() {
+		refund := &entity.Refund{}
+		err = rows.Scan(
+			&refund.RefundID,
+			&refund.OrderUID,
+			&refund.Amount,
+			&refund.Currency,
+			&refund.Items,
+			&refund.Reason,
+			&refund.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: rows scan: %w", op, err)
+		}
+		result = append(result, refund)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return result, nil
+}
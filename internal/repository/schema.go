@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/storage/postgres"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type SchemaRepository struct {
+	db *postgres.Postgres
+}
+
+func NewSchemaRepository(db *postgres.Postgres) *SchemaRepository {
+	return &SchemaRepository{db}
+}
+
+// LockSubject serializes registrations of one subject until the transaction ends.
+func (sr *SchemaRepository) LockSubject(
+	ctx context.Context,
+	subject string,
+) error {
+	const op = "repository.schema.LockSubject"
+	ctx = postgres.WithOperation(ctx, op)
+
+	if _, err := sr.db.Executer(ctx).Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", subject); err != nil {
+		return fmt.Errorf("%s: exec: %w", op, err)
+	}
+	return nil
+}
+
+func (sr *SchemaRepository) Create(
+	ctx context.Context,
+	schema *entity.SchemaVersion,
+) (*entity.SchemaVersion, error) {
+	const op = "repository.schema.Create"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := sr.db.Builder.Insert("schemas").
+		Columns("subject", "version", "fingerprint", "definition").
+		Values(schema.Subject, schema.Version, schema.Fingerprint, schema.Definition).
+		Suffix("RETURNING subject, version, fingerprint, definition, created_at")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	result, err := scanSchemaVersion(sr.db.Executer(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, entity.ErrConflictingData
+		}
+		return nil, fmt.Errorf("%s: query row: %w", op, err)
+	}
+
+	return result, nil
+}
+
+func (sr *SchemaRepository) GetLatest(
+	ctx context.Context,
+	subject string,
+) (*entity.SchemaVersion, error) {
+	return sr.get(ctx, sr.db.Executer(ctx), "repository.schema.GetLatest", squirrel.Eq{"subject": subject})
+}
+
+func (sr *SchemaRepository) GetByFingerprint(
+	ctx context.Context,
+	subject, fingerprint string,
+) (*entity.SchemaVersion, error) {
+	return sr.get(ctx, sr.db.Executer(ctx), "repository.schema.GetByFingerprint",
+		squirrel.Eq{"subject": subject, "fingerprint": fingerprint})
+}
+
+// GetVersion returns the given version of subject, or the latest one when version is 0.
+func (sr *SchemaRepository) GetVersion(
+	ctx context.Context,
+	subject string,
+	version int,
+) (*entity.SchemaVersion, error) {
+	where := squirrel.Eq{"subject": subject}
+	if version > 0 {
+		where["version"] = version
+	}
+	return sr.get(ctx, sr.db.Reader(ctx), "repository.schema.GetVersion", where)
+}
+
+func (sr *SchemaRepository) ListVersions(
+	ctx context.Context,
+	subject string,
+) ([]*entity.SchemaVersion, error) {
+	const op = "repository.schema.ListVersions"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := sr.db.Builder.Select("subject", "version", "fingerprint", "created_at").
+		From("schemas").
+		Where(squirrel.Eq{"subject": subject}).
+		OrderBy("version")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	rows, err := sr.db.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var versions []*entity.SchemaVersion
+	for rows.Next() {
+		v := &entity.SchemaVersion{}
+		if err = rows.Scan(&v.Subject, &v.Version, &v.Fingerprint, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		versions = append(versions, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return versions, nil
+}
+
+func (sr *SchemaRepository) get(
+	ctx context.Context,
+	queryExecuter postgres.QueryExecuter,
+	op string,
+	where squirrel.Eq,
+) (*entity.SchemaVersion, error) {
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := sr.db.Builder.Select("subject", "version", "fingerprint", "definition", "created_at").
+		From("schemas").
+		Where(where).
+		OrderBy("version DESC").
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	result, err := scanSchemaVersion(queryExecuter.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrDataNotFound
+		}
+		return nil, fmt.Errorf("%s: query row: %w", op, err)
+	}
+
+	return result, nil
+}
+
+func scanSchemaVersion(row pgx.Row) (*entity.SchemaVersion, error) {
+	result := &entity.SchemaVersion{}
+	err := row.Scan(
+		&result.Subject,
+		&result.Version,
+		&result.Fingerprint,
+		&result.Definition,
+		&result.CreatedAt,
+	)
+	if err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"wbtest/pkg/storage/postgres"
+
+	"github.com/google/uuid"
+)
+
+// _searchOrdersQuery merges full-text matches on names, addresses, items and brands with
+// trigram matches on phone, email and track numbers. Trigram conditions accept both a
+// substring (ILIKE) and a fuzzy (%) match, so "7999" and a mistyped track number are found.
+const _searchOrdersQuery = `
+WITH matches AS (
+    SELECT s.order_uid, ts_rank_cd(s.document, websearch_to_tsquery('simple', $1)) AS rank
+    FROM order_search s
+    WHERE s.document @@ websearch_to_tsquery('simple', $1)
+    UNION ALL
+    SELECT d.order_uid, GREATEST(similarity(d.phone, $1), similarity(d.email, $1))
+    FROM delivery d
+    WHERE d.phone ILIKE $2 OR d.email ILIKE $2 OR d.phone % $1 OR d.email % $1
+    UNION ALL
+    SELECT o.order_uid, similarity(o.track_number, $1)
+    FROM orders o
+    WHERE o.track_number ILIKE $2 OR o.track_number % $1
+    UNION ALL
+    SELECT i.order_uid, similarity(i.track_number, $1)
+    FROM items i
+    WHERE i.track_number ILIKE $2 OR i.track_number % $1
+)
+SELECT m.order_uid, COUNT(*) OVER () AS total
+FROM matches m
+JOIN orders o ON o.order_uid = m.order_uid
+WHERE o.deleted_at IS NULL
+GROUP BY m.order_uid, o.date_created
+ORDER BY MAX(m.rank) DESC, o.date_created DESC, m.order_uid
+LIMIT $3 OFFSET $4`
+
+var _likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type SearchRepository struct {
+	db *postgres.Postgres
+}
+
+func NewSearchRepository(db *postgres.Postgres) *SearchRepository {
+	return &SearchRepository{db}
+}
+
+// SearchOrders returns one page of matching order UIDs, best match first, and the total
+// number of matches. Total is 0 when offset is past the last match.
+func (sr *SearchRepository) SearchOrders(
+	ctx context.Context,
+	query string,
+	limit, offset int,
+) ([]uuid.UUID, int64, error) {
+	const op = "repository.search.SearchOrders"
+	ctx = postgres.WithOperation(ctx, op)
+
+	pattern := "%" + _likeEscaper.Replace(query) + "%"
+
+	rows, err := sr.db.Reader(ctx).Query(ctx, _searchOrdersQuery, query, pattern, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var (
+		uids  []uuid.UUID
+		total int64
+	)
+	for rows.Next() {
+		var uid uuid.UUID
+		if err = rows.Scan(&uid, &total); err != nil {
+			return nil, 0, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		uids = append(uids, uid)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return uids, total, nil
+}
@@ -0,0 +1,454 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/storage/postgres"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const _subscriptionColumns = `subscription_id, url, event_types, secret, enabled, consecutive_failures,
+    disabled_at, created_at, updated_at`
+
+const _deliveryColumns = `d.delivery_id, d.subscription_id, d.event_id, d.event_type, d.order_uid, d.payload,
+    d.occurred_at, d.status, d.attempts, d.next_attempt_at, d.last_attempt_at, d.response_status,
+    d.last_error, d.created_at`
+
+// _enqueueDeliveriesQuery fans the given outbox events out to every enabled subscription to
+// their type. It runs before the events are deleted from the outbox.
+const _enqueueDeliveriesQuery = `
+INSERT INTO webhook_deliveries (delivery_id, subscription_id, event_id, event_type, order_uid, payload, occurred_at)
+SELECT gen_random_uuid(), s.subscription_id, e.event_id, e.type, e.order_uid, e.payload, e.created_at
+FROM order_events e
+JOIN webhook_subscriptions s ON s.enabled AND e.type = ANY(s.event_types)
+WHERE e.event_id = ANY($1)
+ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+// _claimDueDeliveriesQuery leases due deliveries of enabled subscriptions by moving their next
+// attempt to $2, so that no other dispatcher picks them up while they are being sent.
+const _claimDueDeliveriesQuery = `
+WITH due AS (
+    SELECT d.delivery_id
+    FROM webhook_deliveries d
+    JOIN webhook_subscriptions s ON s.subscription_id = d.subscription_id
+    WHERE d.status = 'pending' AND d.next_attempt_at <= NOW() AND s.enabled
+    ORDER BY d.next_attempt_at
+    LIMIT $1
+    FOR UPDATE OF d SKIP LOCKED
+)
+UPDATE webhook_deliveries d
+SET next_attempt_at = $2
+FROM due, webhook_subscriptions s
+WHERE d.delivery_id = due.delivery_id AND s.subscription_id = d.subscription_id
+RETURNING ` + _deliveryColumns + `, s.url, s.secret`
+
+const _recordFailureQuery = `
+UPDATE webhook_subscriptions
+SET consecutive_failures = consecutive_failures + 1,
+    enabled = enabled AND consecutive_failures + 1 < $2,
+    disabled_at = CASE WHEN enabled AND consecutive_failures + 1 >= $2 THEN NOW() ELSE disabled_at END,
+    updated_at = NOW()
+WHERE subscription_id = $1
+RETURNING consecutive_failures, enabled`
+
+type WebhookRepository struct {
+	db *postgres.Postgres
+}
+
+func NewWebhookRepository(db *postgres.Postgres) *WebhookRepository {
+	return &WebhookRepository{db}
+}
+
+func (wr *WebhookRepository) CreateSubscription(
+	ctx context.Context,
+	sub *entity.WebhookSubscription,
+) (*entity.WebhookSubscription, error) {
+	const op = "repository.webhook.CreateSubscription"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := wr.db.Builder.Insert("webhook_subscriptions").
+		Columns("subscription_id", "url", "event_types", "secret", "enabled").
+		Values(sub.SubscriptionID, sub.URL, sub.EventTypes, sub.Secret, sub.Enabled).
+		Suffix("RETURNING " + _subscriptionColumns)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	result, err := scanSubscription(wr.db.Executer(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		return nil, fmt.Errorf("%s: query row: %w", op, err)
+	}
+
+	return result, nil
+}
+
+// UpdateSubscription replaces the subscription's settings. Enabling it clears its failures.
+func (wr *WebhookRepository) UpdateSubscription(
+	ctx context.Context,
+	sub *entity.WebhookSubscription,
+) (*entity.WebhookSubscription, error) {
+	const op = "repository.webhook.UpdateSubscription"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := wr.db.Builder.Update("webhook_subscriptions").
+		SetMap(map[string]interface{}{
+			"url":                  sub.URL,
+			"event_types":          sub.EventTypes,
+			"secret":               sub.Secret,
+			"enabled":              sub.Enabled,
+			"consecutive_failures": squirrel.Expr("CASE WHEN ? THEN 0 ELSE consecutive_failures END", sub.Enabled),
+			"disabled_at":          squirrel.Expr("CASE WHEN ? THEN NULL ELSE COALESCE(disabled_at, NOW()) END", sub.Enabled),
+			"updated_at":           squirrel.Expr("NOW()"),
+		}).
+		Where(squirrel.Eq{"subscription_id": sub.SubscriptionID}).
+		Suffix("RETURNING " + _subscriptionColumns)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	result, err := scanSubscription(wr.db.Executer(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrDataNotFound
+		}
+		return nil, fmt.Errorf("%s: query row: %w", op, err)
+	}
+
+	return result, nil
+}
+
+// DeleteSubscription removes the subscription together with its delivery log.
+func (wr *WebhookRepository) DeleteSubscription(
+	ctx context.Context,
+	subscriptionID uuid.UUID,
+) error {
+	const op = "repository.webhook.DeleteSubscription"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := wr.db.Builder.Delete("webhook_subscriptions").
+		Where(squirrel.Eq{"subscription_id": subscriptionID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	tag, err := wr.db.Executer(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: exec: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (wr *WebhookRepository) GetSubscription(
+	ctx context.Context,
+	subscriptionID uuid.UUID,
+) (*entity.WebhookSubscription, error) {
+	const op = "repository.webhook.GetSubscription"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := wr.db.Builder.Select(_subscriptionColumns).
+		From("webhook_subscriptions").
+		Where(squirrel.Eq{"subscription_id": subscriptionID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	result, err := scanSubscription(wr.db.Reader(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrDataNotFound
+		}
+		return nil, fmt.Errorf("%s: query row: %w", op, err)
+	}
+
+	return result, nil
+}
+
+// ListSubscriptions returns every subscription, oldest first.
+func (wr *WebhookRepository) ListSubscriptions(ctx context.Context) ([]*entity.WebhookSubscription, error) {
+	const op = "repository.webhook.ListSubscriptions"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := wr.db.Builder.Select(_subscriptionColumns).
+		From("webhook_subscriptions").
+		OrderBy("created_at", "subscription_id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	rows, err := wr.db.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	result := make([]*entity.WebhookSubscription, 0)
+	for rows.Next() {
+		sub, scanErr := scanSubscription(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("%s: rows scan: %w", op, scanErr)
+		}
+		result = append(result, sub)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return result, nil
+}
+
+// EnqueueDeliveries creates a pending delivery of every given outbox event for each enabled
+// subscription to its type and returns how many were created.
+func (wr *WebhookRepository) EnqueueDeliveries(
+	ctx context.Context,
+	eventIDs []uuid.UUID,
+) (int64, error) {
+	const op = "repository.webhook.EnqueueDeliveries"
+	ctx = postgres.WithOperation(ctx, op)
+
+	tag, err := wr.db.Executer(ctx).Exec(ctx, _enqueueDeliveriesQuery, eventIDs)
+	if err != nil {
+		return 0, fmt.Errorf("%s: exec: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// ClaimDue returns up to limit due deliveries with where to send them and leases them until
+// leaseUntil. A delivery whose outcome is never recorded is retried once the lease expires.
+func (wr *WebhookRepository) ClaimDue(
+	ctx context.Context,
+	limit int,
+	leaseUntil time.Time,
+) ([]*entity.OutgoingWebhook, error) {
+	const op = "repository.webhook.ClaimDue"
+	ctx = postgres.WithOperation(ctx, op)
+
+	rows, err := wr.db.Executer(ctx).Query(ctx, _claimDueDeliveriesQuery, limit, leaseUntil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	result := make([]*entity.OutgoingWebhook, 0, limit)
+	for rows.Next() {
+		outgoing := &entity.OutgoingWebhook{}
+		outgoing.Delivery, err = scanDelivery(rows, &outgoing.URL, &outgoing.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("%s: rows scan: %w", op, err)
+		}
+		result = append(result, outgoing)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return result, nil
+}
+
+// UpdateDelivery stores the outcome of an attempt: status, attempts, next attempt and the
+// last response.
+func (wr *WebhookRepository) UpdateDelivery(
+	ctx context.Context,
+	delivery *entity.WebhookDelivery,
+) error {
+	const op = "repository.webhook.UpdateDelivery"
+	ctx = postgres.WithOperation(ctx, op)
+
+	var responseStatus *int
+	if delivery.ResponseStatus != 0 {
+		responseStatus = &delivery.ResponseStatus
+	}
+
+	query := wr.db.Builder.Update("webhook_deliveries").
+		SetMap(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"last_attempt_at": delivery.LastAttemptAt,
+			"response_status": responseStatus,
+			"last_error":      delivery.LastError,
+		}).
+		Where(squirrel.Eq{"delivery_id": delivery.DeliveryID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	if _, err = wr.db.Executer(ctx).Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("%s: exec: %w", op, err)
+	}
+
+	return nil
+}
+
+func (wr *WebhookRepository) ResetFailures(
+	ctx context.Context,
+	subscriptionID uuid.UUID,
+) error {
+	const op = "repository.webhook.ResetFailures"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := wr.db.Builder.Update("webhook_subscriptions").
+		Set("consecutive_failures", 0).
+		Where(squirrel.Eq{"subscription_id": subscriptionID}).
+		Where(squirrel.NotEq{"consecutive_failures": 0})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	if _, err = wr.db.Executer(ctx).Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("%s: exec: %w", op, err)
+	}
+
+	return nil
+}
+
+// RecordFailure counts a failed attempt against the subscription and disables it once
+// disableAfter attempts in a row have failed. It returns the failure count and whether the
+// subscription is still enabled.
+func (wr *WebhookRepository) RecordFailure(
+	ctx context.Context,
+	subscriptionID uuid.UUID,
+	disableAfter int,
+) (int, bool, error) {
+	const op = "repository.webhook.RecordFailure"
+	ctx = postgres.WithOperation(ctx, op)
+
+	var (
+		failures int
+		enabled  bool
+	)
+	err := wr.db.Executer(ctx).QueryRow(ctx, _recordFailureQuery, subscriptionID, disableAfter).Scan(&failures, &enabled)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, entity.ErrDataNotFound
+		}
+		return 0, false, fmt.Errorf("%s: query row: %w", op, err)
+	}
+
+	return failures, enabled, nil
+}
+
+// ListDeliveries returns one page of the subscription's deliveries, newest first, optionally
+// only those with status, and the total number of matches.
+func (wr *WebhookRepository) ListDeliveries(
+	ctx context.Context,
+	subscriptionID uuid.UUID,
+	status string,
+	limit, offset int,
+) ([]*entity.WebhookDelivery, int64, error) {
+	const op = "repository.webhook.ListDeliveries"
+	ctx = postgres.WithOperation(ctx, op)
+
+	query := wr.db.Builder.Select(_deliveryColumns, "COUNT(*) OVER () AS total").
+		From("webhook_deliveries d").
+		Where(squirrel.Eq{"d.subscription_id": subscriptionID}).
+		OrderBy("d.created_at DESC", "d.delivery_id").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+	if status != "" {
+		query = query.Where(squirrel.Eq{"d.status": status})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: building query: %w", op, err)
+	}
+
+	rows, err := wr.db.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var total int64
+	result := make([]*entity.WebhookDelivery, 0, limit)
+	for rows.Next() {
+		delivery, scanErr := scanDelivery(rows, &total)
+		if scanErr != nil {
+			return nil, 0, fmt.Errorf("%s: rows scan: %w", op, scanErr)
+		}
+		result = append(result, delivery)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return result, total, nil
+}
+
+func scanSubscription(row pgx.Row) (*entity.WebhookSubscription, error) {
+	sub := &entity.WebhookSubscription{}
+	err := row.Scan(
+		&sub.SubscriptionID,
+		&sub.URL,
+		&sub.EventTypes,
+		&sub.Secret,
+		&sub.Enabled,
+		&sub.ConsecutiveFailures,
+		&sub.DisabledAt,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+	)
+	if err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+	return sub, nil
+}
+
+// scanDelivery scans _deliveryColumns followed by extra.
+func scanDelivery(row pgx.Row, extra ...any) (*entity.WebhookDelivery, error) {
+	delivery := &entity.WebhookDelivery{}
+	var responseStatus *int
+
+	dest := []any{
+		&delivery.DeliveryID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.OrderUID,
+		&delivery.Payload,
+		&delivery.OccurredAt,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastAttemptAt,
+		&responseStatus,
+		&delivery.LastError,
+		&delivery.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	if responseStatus != nil {
+		delivery.ResponseStatus = *responseStatus
+	}
+	return delivery, nil
+}
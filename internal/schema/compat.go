@@ -0,0 +1,118 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+type Mode string
+
+const (
+	// ModeBackward: consumers using the new schema can read data written with the old one.
+	ModeBackward Mode = "backward"
+	// ModeForward: consumers still using the old schema can read data written with the new one.
+	ModeForward Mode = "forward"
+	// ModeFull requires both directions.
+	ModeFull Mode = "full"
+)
+
+var ErrUnknownMode = errors.New("unknown compatibility mode")
+
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(s); mode {
+	case ModeBackward, ModeForward, ModeFull:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("schema.ParseMode: %q: %w", s, ErrUnknownMode)
+	}
+}
+
+// Check returns the reasons why next is not compatible with prev under mode; an empty
+// result means the change is safe.
+func Check(prev, next *Schema, mode Mode) []string {
+	var problems []string
+	if mode == ModeBackward || mode == ModeFull {
+		checkRead(next, prev, "$", "backward", &problems)
+	}
+	if mode == ModeForward || mode == ModeFull {
+		checkRead(prev, next, "$", "forward", &problems)
+	}
+	return problems
+}
+
+// checkRead reports changes that let a document valid under writer fail validation under reader.
+func checkRead(reader, writer *Schema, path, direction string, problems *[]string) {
+	report := func(format string, args ...any) {
+		*problems = append(*problems, fmt.Sprintf("%s: %s: ", direction, path)+fmt.Sprintf(format, args...))
+	}
+
+	if reader.Type != writer.Type {
+		report("type changed between %q and %q", writer.Type, reader.Type)
+		return
+	}
+
+	if reader.Format != "" && reader.Format != writer.Format {
+		report("format %q is not guaranteed by writer (%q)", reader.Format, writer.Format)
+	}
+	if reader.Pattern != "" && reader.Pattern != writer.Pattern {
+		report("pattern %q is not guaranteed by writer", reader.Pattern)
+	}
+	if tighterMin(reader.MinLength, writer.MinLength) {
+		report("minLength raised to %d", *reader.MinLength)
+	}
+	if tighterMax(reader.MaxLength, writer.MaxLength) {
+		report("maxLength lowered to %d", *reader.MaxLength)
+	}
+	if tighterMin(reader.MinItems, writer.MinItems) {
+		report("minItems raised to %d", *reader.MinItems)
+	}
+	if tighterMax(reader.MaxItems, writer.MaxItems) {
+		report("maxItems lowered to %d", *reader.MaxItems)
+	}
+	if tighterMin(reader.Minimum, writer.Minimum) {
+		report("minimum raised to %v", *reader.Minimum)
+	}
+	if tighterMax(reader.Maximum, writer.Maximum) {
+		report("maximum lowered to %v", *reader.Maximum)
+	}
+
+	if reader.Items != nil && writer.Items != nil {
+		checkRead(reader.Items, writer.Items, path+"[]", direction, problems)
+	}
+
+	for _, name := range reader.Required {
+		if !slices.Contains(writer.Required, name) {
+			report("property %q is required but writer may omit it", name)
+		}
+	}
+
+	closed := reader.AdditionalProperties != nil && !*reader.AdditionalProperties
+	for _, name := range sortedKeys(writer.Properties) {
+		readerProp, ok := reader.Properties[name]
+		if !ok {
+			if closed {
+				report("property %q written by writer is rejected as unknown", name)
+			}
+			continue
+		}
+		checkRead(readerProp, writer.Properties[name], path+"."+name, direction, problems)
+	}
+}
+
+func tighterMin[T int | float64](reader, writer *T) bool {
+	return reader != nil && (writer == nil || *reader > *writer)
+}
+
+func tighterMax[T int | float64](reader, writer *T) bool {
+	return reader != nil && (writer == nil || *reader < *writer)
+}
+
+func sortedKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,170 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const _e164Pattern = `^\+[1-9][0-9]{1,14}$`
+
+var (
+	ErrUnsupportedType = errors.New("unsupported type")
+
+	_timeType = reflect.TypeFor[time.Time]()
+	_uuidType = reflect.TypeFor[uuid.UUID]()
+)
+
+// Generate builds a schema for v from its json and validate tags. Objects reject unknown
+// properties, so a renamed field fails validation instead of being silently dropped.
+func Generate(v any, id, title string) (*Schema, error) {
+	s, err := generateType(reflect.TypeOf(v))
+	if err != nil {
+		return nil, fmt.Errorf("schema.Generate: %w", err)
+	}
+	s.Schema = _draft
+	s.ID = id
+	s.Title = title
+	return s, nil
+}
+
+func generateType(t reflect.Type) (*Schema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == _timeType:
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case t == _uuidType:
+		return &Schema{Type: "string", Format: "uuid"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: ptr(0.0)}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := generateType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Struct:
+		return generateStruct(t)
+	default:
+		return nil, fmt.Errorf("%s: %w", t, ErrUnsupportedType)
+	}
+}
+
+func generateStruct(t reflect.Type) (*Schema, error) {
+	s := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: ptr(false),
+	}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop, err := generateType(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+		if applyRules(prop, field.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+
+	slices.Sort(s.Required)
+	return s, nil
+}
+
+// applyRules maps validator rules onto s and reports whether the field is required.
+// Rules after "dive" apply to slice elements, which carry their own tags.
+func applyRules(s *Schema, tag string) bool {
+	var required bool
+
+	for rule := range strings.SplitSeq(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			break
+		}
+
+		n, numErr := strconv.ParseFloat(param, 64)
+		hasNum := numErr == nil
+
+		switch {
+		case name == "required":
+			required = true
+		case name == "email":
+			s.Format = "email"
+		case name == "e164":
+			s.Pattern = _e164Pattern
+		case name == "uuid_strict":
+			s.Format = "uuid"
+		case name == "unix_timestamp":
+			s.Minimum = ptr(0.0)
+		case name == "len" && hasNum:
+			applyMin(s, n)
+			applyMax(s, n)
+		case (name == "min" || name == "gte") && hasNum:
+			applyMin(s, n)
+		case (name == "max" || name == "lte") && hasNum:
+			applyMax(s, n)
+		}
+	}
+
+	return required
+}
+
+func applyMin(s *Schema, n float64) {
+	switch s.Type {
+	case "string":
+		s.MinLength = ptr(int(n))
+	case "array":
+		s.MinItems = ptr(int(n))
+	default:
+		s.Minimum = ptr(n)
+	}
+}
+
+func applyMax(s *Schema, n float64) {
+	switch s.Type {
+	case "string":
+		s.MaxLength = ptr(int(n))
+	case "array":
+		s.MaxItems = ptr(int(n))
+	default:
+		s.Maximum = ptr(n)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,252 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"wbtest/internal/entity"
+	"wbtest/internal/schema"
+
+	"github.com/google/uuid"
+)
+
+func validOrderJSON(t *testing.T) map[string]any {
+	t.Helper()
+
+	orderUID := uuid.New()
+	order := &entity.Order{
+		OrderUID:    orderUID,
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: &entity.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: &entity.Payment{
+			Transaction:  orderUID,
+			RequestID:    uuid.New(),
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDt:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []*entity.Item{{
+			ChrtID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       453,
+			Rid:         uuid.New(),
+			Name:        "Mascaras",
+			Sale:        30,
+			Size:        "0",
+			TotalPrice:  317,
+			NMID:        2389212,
+			Brand:       "Vivienne Sabo",
+			Status:      202,
+		}},
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		Shardkey:        "9",
+		SmID:            99,
+		DateCreated:     time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OofShard:        "1",
+	}
+
+	data, err := json.Marshal(order)
+	if err != nil {
+		t.Fatalf("marshal order: %v", err)
+	}
+	var doc map[string]any
+	if err = json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal order: %v", err)
+	}
+	return doc
+}
+
+func orderSchema(t *testing.T) *schema.Schema {
+	t.Helper()
+
+	s, err := schema.Generate(entity.Order{}, "urn:wbtest:order", "Order")
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	return s
+}
+
+func TestGenerate_Order(t *testing.T) {
+	t.Parallel()
+
+	s := orderSchema(t)
+
+	if _, ok := s.Properties["PayloadHash"]; ok {
+		t.Error("fields tagged json:\"-\" must be skipped")
+	}
+	if !slices.Contains(s.Required, "order_uid") || slices.Contains(s.Required, "internal_signature") {
+		t.Errorf("unexpected required list: %v", s.Required)
+	}
+	if got := s.Properties["locale"]; got.MinLength == nil || *got.MinLength != 2 || *got.MaxLength != 2 {
+		t.Errorf("len=2 must map to minLength and maxLength 2, got %+v", got)
+	}
+	if got := s.Properties["items"]; got.Type != "array" || got.MinItems == nil || *got.MinItems != 1 {
+		t.Errorf("items must be a non-empty array, got %+v", got)
+	}
+	if got := s.Properties["delivery"].Properties["email"]; got.Format != "email" {
+		t.Errorf("email rule must map to format, got %+v", got)
+	}
+
+	again := orderSchema(t)
+	first, _ := s.Fingerprint()
+	second, _ := again.Fingerprint()
+	if first != second {
+		t.Error("fingerprint must be stable")
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc     string
+		mutate   func(doc map[string]any)
+		wantPart string
+	}{
+		{desc: "Valid", mutate: func(map[string]any) {}},
+		{
+			desc: "RenamedField",
+			mutate: func(doc map[string]any) {
+				doc["uid"] = doc["order_uid"]
+				delete(doc, "order_uid")
+			},
+			wantPart: `missing required property "order_uid"`,
+		},
+		{
+			desc:     "WrongType",
+			mutate:   func(doc map[string]any) { doc["sm_id"] = "99" },
+			wantPart: "$.sm_id: expected integer",
+		},
+		{
+			desc: "NestedConstraint",
+			mutate: func(doc map[string]any) {
+				doc["delivery"].(map[string]any)["email"] = "not-an-email"
+			},
+			wantPart: "$.delivery.email: not a valid email",
+		},
+		{
+			desc:     "EmptyItems",
+			mutate:   func(doc map[string]any) { doc["items"] = []any{} },
+			wantPart: "expected at least 1 items",
+		},
+	}
+
+	s := orderSchema(t)
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			doc := validOrderJSON(t)
+			tc.mutate(doc)
+			data, _ := json.Marshal(doc)
+
+			err := s.Validate(data)
+			if tc.wantPart == "" {
+				if err != nil {
+					t.Fatalf("expected valid document, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, schema.ErrValidation) || !strings.Contains(err.Error(), tc.wantPart) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantPart, err)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc         string
+		change       func(s *schema.Schema)
+		mode         schema.Mode
+		wantProblems bool
+	}{
+		{desc: "Unchanged", change: func(*schema.Schema) {}, mode: schema.ModeFull},
+		{
+			desc: "OptionalFieldAddedBackward",
+			change: func(s *schema.Schema) {
+				s.Properties["gift_note"] = &schema.Schema{Type: "string"}
+			},
+			mode: schema.ModeBackward,
+		},
+		{
+			desc: "OptionalFieldAddedForward",
+			change: func(s *schema.Schema) {
+				s.Properties["gift_note"] = &schema.Schema{Type: "string"}
+			},
+			mode:         schema.ModeForward,
+			wantProblems: true,
+		},
+		{
+			desc: "RequiredFieldAdded",
+			change: func(s *schema.Schema) {
+				s.Properties["gift_note"] = &schema.Schema{Type: "string"}
+				s.Required = append(s.Required, "gift_note")
+			},
+			mode:         schema.ModeBackward,
+			wantProblems: true,
+		},
+		{
+			desc: "FieldRenamed",
+			change: func(s *schema.Schema) {
+				s.Properties["uid"] = s.Properties["order_uid"]
+				delete(s.Properties, "order_uid")
+				s.Required[slices.Index(s.Required, "order_uid")] = "uid"
+			},
+			mode:         schema.ModeBackward,
+			wantProblems: true,
+		},
+		{
+			desc: "ConstraintRelaxed",
+			change: func(s *schema.Schema) {
+				limit := 100
+				s.Properties["entry"].MaxLength = &limit
+			},
+			mode: schema.ModeBackward,
+		},
+		{
+			desc: "ConstraintTightened",
+			change: func(s *schema.Schema) {
+				limit := 5
+				s.Properties["entry"].MaxLength = &limit
+			},
+			mode:         schema.ModeBackward,
+			wantProblems: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			prev, next := orderSchema(t), orderSchema(t)
+			tc.change(next)
+
+			problems := schema.Check(prev, next, tc.mode)
+			if tc.wantProblems != (len(problems) > 0) {
+				t.Fatalf("wantProblems=%t, got %v", tc.wantProblems, problems)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const _maxReportedProblems = 20
+
+var (
+	ErrValidation = errors.New("document does not match schema")
+
+	_patterns sync.Map
+)
+
+// ValidationError lists every violation found, up to _maxReportedProblems.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrValidation, strings.Join(e.Problems, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+func (s *Schema) Validate(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return &ValidationError{Problems: []string{"invalid JSON: " + err.Error()}}
+	}
+
+	var problems []string
+	s.validate(doc, "$", &problems)
+	if len(problems) == 0 {
+		return nil
+	}
+	if len(problems) > _maxReportedProblems {
+		problems = append(problems[:_maxReportedProblems], fmt.Sprintf("and %d more", len(problems)-_maxReportedProblems))
+	}
+	return &ValidationError{Problems: problems}
+}
+
+func (s *Schema) validate(v any, path string, problems *[]string) {
+	report := func(format string, args ...any) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			report("expected object")
+			return
+		}
+		s.validateObject(obj, path, problems)
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			report("expected array")
+			return
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			report("expected at least %d items, got %d", *s.MinItems, len(arr))
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			report("expected at most %d items, got %d", *s.MaxItems, len(arr))
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			report("expected string")
+			return
+		}
+		s.validateString(str, report)
+	case "integer", "number":
+		num, ok := v.(json.Number)
+		if !ok {
+			report("expected %s", s.Type)
+			return
+		}
+		s.validateNumber(num, report)
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			report("expected boolean")
+		}
+	}
+}
+
+func (s *Schema) validateObject(obj map[string]any, path string, problems *[]string) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: missing required property %q", path, name))
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		prop, ok := s.Properties[name]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				*problems = append(*problems, fmt.Sprintf("%s: unknown property %q", path, name))
+			}
+			continue
+		}
+		prop.validate(obj[name], path+"."+name, problems)
+	}
+}
+
+func (s *Schema) validateString(str string, report func(string, ...any)) {
+	length := utf8.RuneCountInString(str)
+	if s.MinLength != nil && length < *s.MinLength {
+		report("shorter than %d characters", *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		report("longer than %d characters", *s.MaxLength)
+	}
+
+	if s.Pattern != "" {
+		re, err := compilePattern(s.Pattern)
+		if err != nil {
+			report("invalid pattern %q in schema", s.Pattern)
+		} else if !re.MatchString(str) {
+			report("does not match pattern %q", s.Pattern)
+		}
+	}
+
+	switch s.Format {
+	case "uuid":
+		if len(str) != 36 || uuid.Validate(str) != nil {
+			report("not a valid uuid")
+		}
+	case "email":
+		if _, err := mail.ParseAddress(str); err != nil {
+			report("not a valid email")
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			report("not an RFC 3339 date-time")
+		}
+	}
+}
+
+func (s *Schema) validateNumber(num json.Number, report func(string, ...any)) {
+	if s.Type == "integer" && strings.ContainsAny(num.String(), ".eE") {
+		report("expected integer, got %s", num)
+		return
+	}
+
+	f, err := num.Float64()
+	if err != nil {
+		report("not a valid number")
+		return
+	}
+	if s.Minimum != nil && f < *s.Minimum {
+		report("less than minimum %v", *s.Minimum)
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		report("greater than maximum %v", *s.Maximum)
+	}
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := _patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("schema.compilePattern: %w", err)
+	}
+	_patterns.Store(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	_defaultAnalyticsRange = 7 * 24 * time.Hour
+	_maxAnalyticsBuckets   = 1000
+	_defaultAnalyticsTop   = 10
+	_maxAnalyticsTop       = 100
+)
+
+var _bucketSizes = map[string]time.Duration{
+	entity.BucketHour: time.Hour,
+	entity.BucketDay:  24 * time.Hour,
+	entity.BucketWeek: 7 * 24 * time.Hour,
+}
+
+// GetOrderAnalytics returns order counts and revenue per bucket together with the best
+// selling items and brands. Bounds are widened to whole hours, the rollup granularity.
+func (os *OrderService) GetOrderAnalytics(
+	ctx context.Context,
+	filter entity.AnalyticsFilter,
+) (*entity.OrderAnalytics, error) {
+	ctx, span := _tracer.Start(ctx, "OrderService.GetOrderAnalytics",
+		trace.WithAttributes(attribute.String("analytics.bucket", filter.Bucket)),
+	)
+	defer span.End()
+
+	analytics, err := os.getOrderAnalytics(ctx, filter)
+	tracing.RecordError(span, err)
+
+	return analytics, err
+}
+
+func (os *OrderService) getOrderAnalytics(
+	ctx context.Context,
+	filter entity.AnalyticsFilter,
+) (*entity.OrderAnalytics, error) {
+	const op = "service.GetOrderAnalytics"
+
+	reporting, err := os.resolveReportingCurrency(filter.ReportingCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	filter.ReportingCurrency = reporting
+
+	if err = normalizeAnalyticsFilter(&filter, time.Now()); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	analytics, err := os.analyticsRepo.GetOrderAnalytics(ctx, &filter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if reporting != "" {
+		if analytics.Series, err = os.mergeSeries(ctx, analytics.Series, reporting); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		analytics.ReportingCurrency = reporting
+	}
+
+	return analytics, nil
+}
+
+// mergeSeries converts revenue to currency to and merges the points of each bucket. Current
+// rates are applied to every bucket, so past buckets move with the rates.
+func (os *OrderService) mergeSeries(
+	ctx context.Context,
+	series []entity.AnalyticsPoint,
+	to string,
+) ([]entity.AnalyticsPoint, error) {
+	merged := make([]entity.AnalyticsPoint, 0, len(series))
+	for _, point := range series {
+		revenue, err := os.convert(ctx, entity.Money{Currency: point.Currency, Amount: point.Revenue}, to)
+		if err != nil {
+			return nil, err
+		}
+
+		// series are ordered by bucket, so points of one bucket are adjacent
+		if n := len(merged); n > 0 && merged[n-1].Bucket.Equal(point.Bucket) {
+			last := &merged[n-1]
+			total, err := entity.Money{Currency: to, Amount: last.Revenue}.Add(revenue)
+			if err != nil {
+				// nolint: wrapcheck
+				return nil, err
+			}
+			last.Orders += point.Orders
+			last.Revenue = total.Amount
+			continue
+		}
+
+		merged = append(merged, entity.AnalyticsPoint{
+			Bucket:   point.Bucket,
+			Currency: to,
+			Orders:   point.Orders,
+			Revenue:  revenue.Amount,
+		})
+	}
+	return merged, nil
+}
+
+func normalizeAnalyticsFilter(filter *entity.AnalyticsFilter, now time.Time) error {
+	if filter.Bucket == "" {
+		filter.Bucket = entity.BucketDay
+	}
+	size, ok := _bucketSizes[filter.Bucket]
+	if !ok {
+		return fmt.Errorf("bucket must be hour, day or week: %w", entity.ErrInvalidData)
+	}
+
+	if filter.To.IsZero() {
+		filter.To = now
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.Add(-_defaultAnalyticsRange)
+	}
+	if !filter.To.After(filter.From) {
+		return fmt.Errorf("from must be before to: %w", entity.ErrInvalidData)
+	}
+
+	// to is exclusive, so rounding it up keeps the hour it falls into
+	filter.From = filter.From.UTC().Truncate(time.Hour)
+	if to := filter.To.UTC().Truncate(time.Hour); to.Equal(filter.To) {
+		filter.To = to
+	} else {
+		filter.To = to.Add(time.Hour)
+	}
+	if filter.To.Sub(filter.From) > size*_maxAnalyticsBuckets {
+		return fmt.Errorf("range spans more than %d %s buckets: %w", _maxAnalyticsBuckets, filter.Bucket, entity.ErrInvalidData)
+	}
+
+	if filter.Currency != "" && !entity.IsCurrency(filter.Currency) {
+		return fmt.Errorf("unknown currency %q: %w", filter.Currency, entity.ErrInvalidData)
+	}
+
+	if filter.Top == 0 {
+		filter.Top = _defaultAnalyticsTop
+	}
+	if filter.Top < 0 || filter.Top > _maxAnalyticsTop {
+		return fmt.Errorf("top must be 1 to %d: %w", _maxAnalyticsTop, entity.ErrInvalidData)
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"wbtest/internal/entity"
+	mock_repository "wbtest/internal/repository/mock"
+	"wbtest/internal/service"
+	mock_cache "wbtest/pkg/cache/mock"
+	mock_logger "wbtest/pkg/logger/mock"
+	mock_transaction "wbtest/pkg/storage/postgres/transaction/mock"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOrderService_GetOrderAnalytics(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 2, 8, 15, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc    string
+		filter  entity.AnalyticsFilter
+		want    *entity.AnalyticsFilter
+		wantErr error
+	}{
+		{
+			desc:   "RoundsToWholeHours",
+			filter: entity.AnalyticsFilter{From: from, To: to, Bucket: entity.BucketHour, Currency: "USD"},
+			want: &entity.AnalyticsFilter{
+				From:     time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+				To:       time.Date(2025, 3, 2, 9, 0, 0, 0, time.UTC),
+				Bucket:   entity.BucketHour,
+				Currency: "USD",
+				Top:      10,
+			},
+		},
+		{
+			desc:   "DefaultBucketAndRange",
+			filter: entity.AnalyticsFilter{To: time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC), Top: 3},
+			want: &entity.AnalyticsFilter{
+				From:   time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+				To:     time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC),
+				Bucket: entity.BucketDay,
+				Top:    3,
+			},
+		},
+		{
+			desc:    "UnknownBucket",
+			filter:  entity.AnalyticsFilter{From: from, To: to, Bucket: "month"},
+			wantErr: entity.ErrInvalidData,
+		},
+		{
+			desc:    "FromAfterTo",
+			filter:  entity.AnalyticsFilter{From: to, To: from},
+			wantErr: entity.ErrInvalidData,
+		},
+		{
+			desc:    "TooManyBuckets",
+			filter:  entity.AnalyticsFilter{From: from, To: from.AddDate(0, 3, 0), Bucket: entity.BucketHour},
+			wantErr: entity.ErrInvalidData,
+		},
+		{
+			desc:    "TopTooLarge",
+			filter:  entity.AnalyticsFilter{From: from, To: to, Top: 101},
+			wantErr: entity.ErrInvalidData,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			analyticsRepo := mock_repository.NewMockAnalyticsRepository(ctrl)
+			cache := mock_cache.NewMockCache[uuid.UUID, *entity.Order](ctrl)
+			cache.EXPECT().SetOnEvicted(gomock.Any()).AnyTimes()
+
+			result := &entity.OrderAnalytics{}
+			if tc.want != nil {
+				analyticsRepo.EXPECT().GetOrderAnalytics(gomock.Any(), tc.want).Return(result, nil)
+			}
+
+			s := service.NewOrderService(service.OrderServiceDeps{
+				DeliveryRepo:  mock_repository.NewMockDeliveryRepository(ctrl),
+				ItemRepo:      mock_repository.NewMockItemRepository(ctrl),
+				OrderRepo:     mock_repository.NewMockOrderRepository(ctrl),
+				PaymentRepo:   mock_repository.NewMockPaymentRepository(ctrl),
+				AuditRepo:     mock_repository.NewMockAuditRepository(ctrl),
+				ArchiveRepo:   mock_repository.NewMockArchiveRepository(ctrl),
+				SearchRepo:    mock_repository.NewMockSearchRepository(ctrl),
+				CustomerRepo:  mock_repository.NewMockCustomerRepository(ctrl),
+				AnalyticsRepo: analyticsRepo,
+				RefundRepo:    mock_repository.NewMockRefundRepository(ctrl),
+				EventRepo:     newEventRepoMock(ctrl),
+				WebhookQueue:  mock_repository.NewMockWebhookQueue(ctrl),
+				TxManager:     mock_transaction.NewMockManager(ctrl),
+				Logger:        mock_logger.NewMockLogger(ctrl),
+				Cache:         cache,
+				CacheTTL:      time.Minute,
+				SummaryCache:  mock_cache.NewMockCache[string, *entity.CustomerSummary](ctrl),
+				SummaryTTL:    time.Minute,
+			})
+
+			got, err := s.GetOrderAnalytics(context.Background(), tc.filter)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got != result {
+				t.Fatalf("expected %+v, got %+v", result, got)
+			}
+		})
+	}
+}
+
+func TestOrderService_GetOrderAnalytics_ReportingCurrency(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	analyticsRepo := mock_repository.NewMockAnalyticsRepository(ctrl)
+	rates := mock_repository.NewMockRateProvider(ctrl)
+	cache := mock_cache.NewMockCache[uuid.UUID, *entity.Order](ctrl)
+	cache.EXPECT().SetOnEvicted(gomock.Any()).AnyTimes()
+
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	analyticsRepo.EXPECT().GetOrderAnalytics(gomock.Any(), gomock.Any()).Return(&entity.OrderAnalytics{
+		Series: []entity.AnalyticsPoint{
+			{Bucket: day1, Currency: "EUR", Orders: 2, Revenue: 1000},
+			{Bucket: day1, Currency: "JPY", Orders: 1, Revenue: 1500},
+			{Bucket: day2, Currency: "EUR", Orders: 1, Revenue: 200},
+		},
+	}, nil)
+	rates.EXPECT().Rate(gomock.Any(), "EUR", "EUR").Return(big.NewRat(1, 1), nil).Times(2)
+	rates.EXPECT().Rate(gomock.Any(), "JPY", "EUR").Return(big.NewRat(1, 160), nil)
+
+	s := service.NewOrderService(service.OrderServiceDeps{
+		DeliveryRepo:      mock_repository.NewMockDeliveryRepository(ctrl),
+		ItemRepo:          mock_repository.NewMockItemRepository(ctrl),
+		OrderRepo:         mock_repository.NewMockOrderRepository(ctrl),
+		PaymentRepo:       mock_repository.NewMockPaymentRepository(ctrl),
+		AuditRepo:         mock_repository.NewMockAuditRepository(ctrl),
+		ArchiveRepo:       mock_repository.NewMockArchiveRepository(ctrl),
+		SearchRepo:        mock_repository.NewMockSearchRepository(ctrl),
+		CustomerRepo:      mock_repository.NewMockCustomerRepository(ctrl),
+		AnalyticsRepo:     analyticsRepo,
+		RefundRepo:        mock_repository.NewMockRefundRepository(ctrl),
+		EventRepo:         newEventRepoMock(ctrl),
+		WebhookQueue:      mock_repository.NewMockWebhookQueue(ctrl),
+		TxManager:         mock_transaction.NewMockManager(ctrl),
+		Logger:            mock_logger.NewMockLogger(ctrl),
+		Cache:             cache,
+		CacheTTL:          time.Minute,
+		SummaryCache:      mock_cache.NewMockCache[string, *entity.CustomerSummary](ctrl),
+		SummaryTTL:        time.Minute,
+		Rates:             rates,
+		ReportingCurrency: "EUR",
+	})
+
+	got, err := s.GetOrderAnalytics(context.Background(), entity.AnalyticsFilter{From: day1, To: day2.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := []entity.AnalyticsPoint{
+		{Bucket: day1, Currency: "EUR", Orders: 3, Revenue: 1938},
+		{Bucket: day2, Currency: "EUR", Orders: 1, Revenue: 200},
+	}
+	if got.ReportingCurrency != "EUR" || !slices.Equal(got.Series, want) {
+		t.Fatalf("expected %+v in EUR, got %+v in %q", want, got.Series, got.ReportingCurrency)
+	}
+}
+
+// newAnalyticsRepoMock accepts any rollup update, for tests that are not about analytics.
+func newAnalyticsRepoMock(ctrl *gomock.Controller) *mock_repository.MockAnalyticsRepository {
+	analyticsRepo := mock_repository.NewMockAnalyticsRepository(ctrl)
+	analyticsRepo.EXPECT().RecordOrder(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	analyticsRepo.EXPECT().RemoveOrder(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	return analyticsRepo
+}
+
+func newEventRepoMock(ctrl *gomock.Controller) *mock_repository.MockEventRepository {
+	eventRepo := mock_repository.NewMockEventRepository(ctrl)
+	eventRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	return eventRepo
+}
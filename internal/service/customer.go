@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"sync/atomic"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/logger"
+	"wbtest/pkg/storage/postgres"
+	"wbtest/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	_maxCustomerIDLength = 50
+	_summaryTopBrands    = 5
+
+	// _summaryVersionStripes is the number of counters customer summary invalidations are
+	// spread over; customers sharing one only cost each other a cache miss.
+	_summaryVersionStripes = 64
+)
+
+var _summaryVersionSeed = maphash.MakeSeed()
+
+// ListCustomerOrders returns the customer's orders, newest first.
+func (os *OrderService) ListCustomerOrders(
+	ctx context.Context,
+	customerID string,
+	limit, offset int,
+) (*entity.OrderPage, error) {
+	const op = "service.ListCustomerOrders"
+
+	if err := validateCustomerID(customerID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	limit, err := pageLimit(limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	uids, total, err := os.customerRepo.ListOrders(ctx, customerID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	orders, err := os.hydrateOrders(ctx, uids)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &entity.OrderPage{
+		Orders: orders,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+// GetCustomerSummary returns the customer's aggregated order history. Summaries are cached
+// and dropped whenever one of the customer's orders is created, updated, refunded or
+// deleted. With a reporting currency, requested or configured, the summary also carries the
+// total spent converted to it.
+func (os *OrderService) GetCustomerSummary(
+	ctx context.Context,
+	customerID, reportingCurrency string,
+) (*entity.CustomerSummary, error) {
+	ctx, span := _tracer.Start(ctx, "OrderService.GetCustomerSummary")
+	defer span.End()
+
+	summary, err := os.getCustomerSummary(ctx, customerID, reportingCurrency)
+	tracing.RecordError(span, err)
+
+	return summary, err
+}
+
+func (os *OrderService) getCustomerSummary(
+	ctx context.Context,
+	customerID, reportingCurrency string,
+) (*entity.CustomerSummary, error) {
+	const op = "service.GetCustomerSummary"
+
+	if err := validateCustomerID(customerID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	reporting, err := os.resolveReportingCurrency(reportingCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	summary, err := os.loadCustomerSummary(ctx, customerID)
+	if err != nil || reporting == "" {
+		return summary, err
+	}
+
+	total, err := os.sumIn(ctx, summary.TotalSpent, reporting)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// the cached summary is shared, so the total goes on a copy
+	converted := *summary
+	converted.Total = &total
+	return &converted, nil
+}
+
+func (os *OrderService) loadCustomerSummary(
+	ctx context.Context,
+	customerID string,
+) (*entity.CustomerSummary, error) {
+	const op = "service.GetCustomerSummary"
+	span := trace.SpanFromContext(ctx)
+	log := os.logger.Ctx(ctx)
+
+	if cached, found := os.summaryCache.Get(customerID); found {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		return cached, nil
+	}
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+
+	// A replica may not have the change that dropped the cached summary yet, and an order
+	// committed during the read must not be hidden for the whole TTL: the summary is read
+	// from the primary and only cached if no invalidation happened meanwhile.
+	version := os.summaryVersion(customerID)
+	seen := version.Load()
+
+	summary, err := os.customerRepo.GetSummary(postgres.WithPrimary(ctx), customerID, _summaryTopBrands)
+	if err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	if version.Load() == seen {
+		os.summaryCache.Put(customerID, summary, os.summaryTTL)
+	}
+
+	log.LogAttrs(ctx, logger.DebugLevel, "customer summary computed",
+		logger.String("op", op),
+		logger.Int64("order_count", summary.OrderCount),
+	)
+
+	return summary, nil
+}
+
+// invalidateSummary drops the customer's cached summary once one of their orders changed.
+func (os *OrderService) invalidateSummary(customerID string) {
+	os.summaryVersion(customerID).Add(1)
+	os.summaryCache.Delete(customerID)
+}
+
+func (os *OrderService) summaryVersion(customerID string) *atomic.Uint64 {
+	return &os.summaryVersions[maphash.String(_summaryVersionSeed, customerID)%_summaryVersionStripes]
+}
+
+func validateCustomerID(customerID string) error {
+	if customerID == "" || len(customerID) > _maxCustomerIDLength {
+		return fmt.Errorf("customer_id must be 1 to %d characters: %w", _maxCustomerIDLength, entity.ErrInvalidData)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"wbtest/internal/entity"
+	mock_repository "wbtest/internal/repository/mock"
+	"wbtest/internal/service"
+	mock_cache "wbtest/pkg/cache/mock"
+	mock_logger "wbtest/pkg/logger/mock"
+	mock_transaction "wbtest/pkg/storage/postgres/transaction/mock"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+)
+
+type summaryCacheMock = mock_cache.MockCache[string, *entity.CustomerSummary]
+
+func TestOrderService_GetCustomerSummary(t *testing.T) {
+	t.Parallel()
+
+	summary := &entity.CustomerSummary{
+		CustomerID: "customer-1",
+		OrderCount: 2,
+		TotalSpent: []entity.Money{{Currency: "EUR", Amount: 1000}, {Currency: "USD", Amount: 3000}},
+	}
+
+	testCases := []struct {
+		desc      string
+		reporting string
+		mocks     func(
+			repo *mock_repository.MockCustomerRepository,
+			rates *mock_repository.MockRateProvider,
+			summaryCache *summaryCacheMock,
+		)
+		wantTotal *entity.Money
+		wantErr   error
+	}{
+		{
+			desc: "CacheHit",
+			mocks: func(_ *mock_repository.MockCustomerRepository, _ *mock_repository.MockRateProvider, summaryCache *summaryCacheMock) {
+				summaryCache.EXPECT().Get("customer-1").Return(summary, true)
+			},
+		},
+		{
+			desc: "CacheMiss",
+			mocks: func(repo *mock_repository.MockCustomerRepository, _ *mock_repository.MockRateProvider, summaryCache *summaryCacheMock) {
+				summaryCache.EXPECT().Get("customer-1").Return(nil, false)
+				repo.EXPECT().GetSummary(gomock.Any(), "customer-1", 5).Return(summary, nil)
+				summaryCache.EXPECT().Put("customer-1", summary, time.Minute)
+			},
+		},
+		{
+			desc:      "ReportingCurrency",
+			reporting: "USD",
+			mocks: func(_ *mock_repository.MockCustomerRepository, rates *mock_repository.MockRateProvider, summaryCache *summaryCacheMock) {
+				summaryCache.EXPECT().Get("customer-1").Return(summary, true)
+				rates.EXPECT().Rate(gomock.Any(), "EUR", "USD").Return(big.NewRat(115, 100), nil)
+				rates.EXPECT().Rate(gomock.Any(), "USD", "USD").Return(big.NewRat(1, 1), nil)
+			},
+			wantTotal: &entity.Money{Currency: "USD", Amount: 4150},
+		},
+		{
+			desc:      "NoExchangeRate",
+			reporting: "USD",
+			mocks: func(_ *mock_repository.MockCustomerRepository, rates *mock_repository.MockRateProvider, summaryCache *summaryCacheMock) {
+				summaryCache.EXPECT().Get("customer-1").Return(summary, true)
+				rates.EXPECT().Rate(gomock.Any(), "EUR", "USD").Return(nil, entity.ErrNoExchangeRate)
+			},
+			wantErr: entity.ErrNoExchangeRate,
+		},
+		{
+			desc:      "UnknownReportingCurrency",
+			reporting: "usd",
+			mocks: func(*mock_repository.MockCustomerRepository, *mock_repository.MockRateProvider, *summaryCacheMock) {
+			},
+			wantErr: entity.ErrInvalidData,
+		},
+		{
+			desc: "NoOrders",
+			mocks: func(repo *mock_repository.MockCustomerRepository, _ *mock_repository.MockRateProvider, summaryCache *summaryCacheMock) {
+				summaryCache.EXPECT().Get("customer-1").Return(nil, false)
+				repo.EXPECT().GetSummary(gomock.Any(), "customer-1", 5).Return(nil, entity.ErrDataNotFound)
+			},
+			wantErr: entity.ErrDataNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			customerRepo := mock_repository.NewMockCustomerRepository(ctrl)
+			rates := mock_repository.NewMockRateProvider(ctrl)
+			logger := mock_logger.NewMockLogger(ctrl)
+			cache := mock_cache.NewMockCache[uuid.UUID, *entity.Order](ctrl)
+			summaryCache := mock_cache.NewMockCache[string, *entity.CustomerSummary](ctrl)
+
+			cache.EXPECT().SetOnEvicted(gomock.Any()).AnyTimes()
+			logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+			logger.EXPECT().LogAttrs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			tc.mocks(customerRepo, rates, summaryCache)
+
+			s := newCustomerTestService(ctrl, customerRepo, rates, logger, cache, summaryCache)
+
+			got, err := s.GetCustomerSummary(context.Background(), "customer-1", tc.reporting)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if tc.wantTotal == nil {
+				if got != summary {
+					t.Fatalf("expected %+v, got %+v", summary, got)
+				}
+				return
+			}
+			if got.Total == nil || *got.Total != *tc.wantTotal {
+				t.Fatalf("expected total %+v, got %+v", tc.wantTotal, got.Total)
+			}
+			if summary.Total != nil {
+				t.Fatal("expected the cached summary to stay unchanged")
+			}
+		})
+	}
+}
+
+func TestOrderService_ListCustomerOrders(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	order := generateFakeOrder()
+
+	customerRepo := mock_repository.NewMockCustomerRepository(ctrl)
+	logger := mock_logger.NewMockLogger(ctrl)
+	cache := mock_cache.NewMockCache[uuid.UUID, *entity.Order](ctrl)
+
+	cache.EXPECT().SetOnEvicted(gomock.Any()).AnyTimes()
+	customerRepo.EXPECT().ListOrders(gomock.Any(), order.CustomerID, 10, 20).
+		Return([]uuid.UUID{order.OrderUID}, int64(21), nil)
+	cache.EXPECT().Get(order.OrderUID).Return(order, true)
+
+	s := newCustomerTestService(ctrl, customerRepo, mock_repository.NewMockRateProvider(ctrl), logger, cache,
+		mock_cache.NewMockCache[string, *entity.CustomerSummary](ctrl))
+
+	page, err := s.ListCustomerOrders(context.Background(), order.CustomerID, 10, 20)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if page.Total != 21 || len(page.Orders) != 1 || page.Orders[0] != order {
+		t.Fatalf("unexpected page %+v", page)
+	}
+
+	if _, err = s.ListCustomerOrders(context.Background(), "", 10, 0); !errors.Is(err, entity.ErrInvalidData) {
+		t.Fatalf("expected ErrInvalidData for an empty customer_id, got %v", err)
+	}
+}
+
+func TestOrderService_GetCustomerSummary_InvalidatedDuringRead(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	customerRepo := mock_repository.NewMockCustomerRepository(ctrl)
+	logger := mock_logger.NewMockLogger(ctrl)
+	cache := mock_cache.NewMockCache[uuid.UUID, *entity.Order](ctrl)
+	summaryCache := mock_cache.NewMockCache[string, *entity.CustomerSummary](ctrl)
+
+	cache.EXPECT().SetOnEvicted(gomock.Any()).AnyTimes()
+	logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+	logger.EXPECT().LogAttrs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	rates := mock_repository.NewMockRateProvider(ctrl)
+	s := newCustomerTestService(ctrl, customerRepo, rates, logger, cache, summaryCache)
+
+	// an order of the customer is committed while the summary is being read, so the summary
+	// is returned but not cached
+	stale := &entity.CustomerSummary{CustomerID: "customer-1", OrderCount: 1}
+	summaryCache.EXPECT().Get("customer-1").Return(nil, false)
+	customerRepo.EXPECT().GetSummary(gomock.Any(), "customer-1", 5).
+		DoAndReturn(func(context.Context, string, int) (*entity.CustomerSummary, error) {
+			s.InvalidateSummary("customer-1")
+			return stale, nil
+		})
+	summaryCache.EXPECT().Delete("customer-1")
+
+	got, err := s.GetCustomerSummary(context.Background(), "customer-1", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got != stale {
+		t.Fatalf("expected %+v, got %+v", stale, got)
+	}
+}
+
+func newCustomerTestService(
+	ctrl *gomock.Controller,
+	customerRepo service.CustomerRepository,
+	rates service.RateProvider,
+	logger *mock_logger.MockLogger,
+	cache *mock_cache.MockCache[uuid.UUID, *entity.Order],
+	summaryCache *summaryCacheMock,
+) *service.OrderService {
+	return service.NewOrderService(service.OrderServiceDeps{
+		DeliveryRepo:  mock_repository.NewMockDeliveryRepository(ctrl),
+		ItemRepo:      mock_repository.NewMockItemRepository(ctrl),
+		OrderRepo:     mock_repository.NewMockOrderRepository(ctrl),
+		PaymentRepo:   mock_repository.NewMockPaymentRepository(ctrl),
+		AuditRepo:     mock_repository.NewMockAuditRepository(ctrl),
+		ArchiveRepo:   mock_repository.NewMockArchiveRepository(ctrl),
+		SearchRepo:    mock_repository.NewMockSearchRepository(ctrl),
+		CustomerRepo:  customerRepo,
+		AnalyticsRepo: newAnalyticsRepoMock(ctrl),
+		RefundRepo:    mock_repository.NewMockRefundRepository(ctrl),
+		EventRepo:     newEventRepoMock(ctrl),
+		WebhookQueue:  mock_repository.NewMockWebhookQueue(ctrl),
+		TxManager:     mock_transaction.NewMockManager(ctrl),
+		Logger:        logger,
+		Cache:         cache,
+		CacheTTL:      time.Minute,
+		SummaryCache:  summaryCache,
+		SummaryTTL:    time.Minute,
+		Rates:         rates,
+	})
+}
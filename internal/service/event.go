@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/logger"
+	"wbtest/pkg/storage/postgres/transaction"
+
+	"github.com/google/uuid"
+)
+
+// RelayEvents leases up to limit pending outbox events, oldest first, for lease, hands them to
+// publish outside of any transaction and then queues their webhook deliveries and deletes
+// them. Leased events are skipped by relays on other instances; if publish fails or takes
+// longer than lease, or the events cannot be deleted, they are published again.
+func (os *OrderService) RelayEvents(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+	publish func(ctx context.Context, events []*entity.Event) error,
+) (int, error) {
+	const op = "service.RelayEvents"
+	log := os.logger.Ctx(ctx)
+
+	var events []*entity.Event
+	err := os.txManager.ExecuteInTransaction(
+		ctx,
+		"ClaimEvents",
+		func(ctx context.Context) error {
+			var txErr error
+			events, txErr = os.eventRepo.ClaimPending(ctx, limit, time.Now().Add(lease))
+			return transaction.HandleError("ClaimEvents", "claim events", txErr)
+		},
+	)
+	if err != nil {
+		// nolint: wrapcheck
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	if err = publish(ctx, events); err != nil {
+		return 0, fmt.Errorf("%s: publish: %w", op, err)
+	}
+
+	ids := make([]uuid.UUID, len(events))
+	for i, event := range events {
+		ids[i] = event.EventID
+	}
+
+	err = os.txManager.ExecuteInTransaction(
+		ctx,
+		"RelayEvents",
+		func(ctx context.Context) error {
+			if _, txErr := os.webhookQueue.EnqueueDeliveries(ctx, ids); txErr != nil {
+				return transaction.HandleError("RelayEvents", "enqueue webhook deliveries", txErr)
+			}
+			if txErr := os.eventRepo.DeleteByIDs(ctx, ids); txErr != nil {
+				return transaction.HandleError("RelayEvents", "delete events", txErr)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		// nolint: wrapcheck
+		return 0, err
+	}
+
+	log.LogAttrs(ctx, logger.DebugLevel, "order events relayed",
+		logger.String("op", op),
+		logger.Int("events", len(events)),
+	)
+
+	return len(events), nil
+}
+
+// recordEvent stores an event about the order in the outbox as part of tx. The payload is the
+// order as created, updated or last seen before deletion, or the refund.
+func (os *OrderService) recordEvent(
+	ctx context.Context,
+	eventType string,
+	orderUID uuid.UUID,
+	payload any,
+) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s payload: %w", eventType, err)
+	}
+
+	// nolint: wrapcheck
+	return os.eventRepo.Create(ctx, &entity.Event{
+		EventID:  uuid.New(),
+		Type:     eventType,
+		OrderUID: orderUID,
+		Payload:  data,
+	})
+}
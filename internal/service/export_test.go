@@ -0,0 +1,7 @@
+package service
+
+var PayloadHash = payloadHash
+
+func (os *OrderService) InvalidateSummary(customerID string) {
+	os.invalidateSummary(customerID)
+}
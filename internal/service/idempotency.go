@@ -0,0 +1,124 @@
+package service
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"wbtest/internal/entity"
+)
+
+const (
+	_maxDiffFields = 10
+)
+
+func payloadHash(order *entity.Order) (string, error) {
+	data, err := json.Marshal(canonicalOrder(order))
+	if err != nil {
+		return "", fmt.Errorf("service.payloadHash: marshal order: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func diffOrders(stored, incoming *entity.Order) string {
+	left, leftErr := toJSONMap(canonicalOrder(stored))
+	right, rightErr := toJSONMap(canonicalOrder(incoming))
+	if leftErr != nil || rightErr != nil {
+		return "diff unavailable"
+	}
+
+	var paths []string
+	collectDiff("", left, right, &paths)
+	if len(paths) == 0 {
+		return "no field differences"
+	}
+
+	sort.Strings(paths)
+	if len(paths) > _maxDiffFields {
+		return fmt.Sprintf("changed fields: %s (and %d more)",
+			strings.Join(paths[:_maxDiffFields], ", "),
+			len(paths)-_maxDiffFields,
+		)
+	}
+	return "changed fields: " + strings.Join(paths, ", ")
+}
+
+// canonicalOrder keeps date_created in UTC with the microsecond precision stored by PostgreSQL
+// and sorts items by rid, then chrt_id, so a replay listing the same items in another order
+// is not a conflict.
+func canonicalOrder(order *entity.Order) *entity.Order {
+	canonical := *order
+	canonical.DateCreated = order.DateCreated.UTC().Truncate(time.Microsecond)
+	canonical.Items = slices.Clone(order.Items)
+	slices.SortStableFunc(canonical.Items, func(a, b *entity.Item) int {
+		return cmp.Or(
+			bytes.Compare(a.Rid[:], b.Rid[:]),
+			cmp.Compare(a.ChrtID, b.ChrtID),
+		)
+	})
+	return &canonical
+}
+
+func toJSONMap(order *entity.Order) (map[string]any, error) {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return nil, fmt.Errorf("service.toJSONMap: marshal: %w", err)
+	}
+
+	var result map[string]any
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("service.toJSONMap: unmarshal: %w", err)
+	}
+	return result, nil
+}
+
+func collectDiff(prefix string, left, right any, paths *[]string) {
+	switch l := left.(type) {
+	case map[string]any:
+		r, ok := right.(map[string]any)
+		if !ok {
+			*paths = append(*paths, prefix)
+			return
+		}
+		keys := make(map[string]struct{}, len(l)+len(r))
+		for k := range l {
+			keys[k] = struct{}{}
+		}
+		for k := range r {
+			keys[k] = struct{}{}
+		}
+		for k := range keys {
+			collectDiff(joinPath(prefix, k), l[k], r[k], paths)
+		}
+	case []any:
+		r, ok := right.([]any)
+		if !ok || len(l) != len(r) {
+			*paths = append(*paths, prefix)
+			return
+		}
+		for i := range l {
+			collectDiff(fmt.Sprintf("%s[%d]", prefix, i), l[i], r[i], paths)
+		}
+	default:
+		if !reflect.DeepEqual(left, right) {
+			*paths = append(*paths, prefix)
+		}
+	}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"wbtest/internal/entity"
+)
+
+// resolveReportingCurrency returns requested, or the configured reporting currency if it is
+// empty. An empty result means amounts are reported in their own currencies.
+func (os *OrderService) resolveReportingCurrency(requested string) (string, error) {
+	if requested == "" {
+		return os.reportingCurrency, nil
+	}
+	if !entity.IsCurrency(requested) {
+		return "", fmt.Errorf("unknown reporting currency %q: %w", requested, entity.ErrInvalidData)
+	}
+	return requested, nil
+}
+
+// convert returns amount in currency to at the provider's current rate.
+func (os *OrderService) convert(ctx context.Context, amount entity.Money, to string) (entity.Money, error) {
+	rate, err := os.rates.Rate(ctx, amount.Currency, to)
+	if err != nil {
+		// nolint: wrapcheck
+		return entity.Money{}, err
+	}
+	// nolint: wrapcheck
+	return amount.Convert(rate, to)
+}
+
+// sumIn converts amounts to currency to and adds them up.
+func (os *OrderService) sumIn(ctx context.Context, amounts []entity.Money, to string) (entity.Money, error) {
+	total := entity.Money{Currency: to}
+	for _, amount := range amounts {
+		converted, err := os.convert(ctx, amount, to)
+		if err != nil {
+			return entity.Money{}, err
+		}
+		if total, err = total.Add(converted); err != nil {
+			// nolint: wrapcheck
+			return entity.Money{}, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/logger"
+	"wbtest/pkg/storage/postgres"
+	"wbtest/pkg/storage/postgres/transaction"
+	"wbtest/pkg/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const _maxRefundReasonLength = 255
+
+// CreateRefund refunds the requested items, for their total price, or the requested amount
+// of the order's payment. Refunds of an order never add up to more than its payment amount.
+// Each refund bumps the order version, is audited and publishes a RefundCreated event.
+func (os *OrderService) CreateRefund(
+	ctx context.Context,
+	orderUID uuid.UUID,
+	req *entity.RefundRequest,
+) (*entity.Refund, error) {
+	ctx, span := _tracer.Start(ctx, "OrderService.CreateRefund",
+		trace.WithAttributes(attribute.String("order.uid", orderUID.String())),
+	)
+	defer span.End()
+
+	refund, err := os.createRefund(ctx, orderUID, req)
+	tracing.RecordError(span, err)
+
+	return refund, err
+}
+
+func (os *OrderService) createRefund(
+	ctx context.Context,
+	orderUID uuid.UUID,
+	req *entity.RefundRequest,
+) (*entity.Refund, error) {
+	const op = "service.CreateRefund"
+	log := os.logger.Ctx(ctx)
+
+	if err := validateRefundRequest(req); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	currentOrder, err := os.fetchOrderFromDB(postgres.WithPrimary(ctx), orderUID)
+	if err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	updatedOrder, err := refundItems(currentOrder, req.Items)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	snapshot, err := json.Marshal(currentOrder)
+	if err != nil {
+		return nil, fmt.Errorf("%s: marshal snapshot: %w", op, err)
+	}
+
+	var created *entity.Refund
+	err = os.txManager.ExecuteInTransaction(
+		ctx,
+		"CreateRefund",
+		func(ctx context.Context) error {
+			var txErr error
+			created, txErr = os.createRefundInTx(ctx, currentOrder, req)
+			if txErr != nil {
+				return transaction.HandleError("CreateRefund", "create refund", txErr)
+			}
+
+			// A refund is not a new payload: a replay of the order from Kafka has to stay a no-op.
+			version, txErr := os.orderRepo.IncrementVersion(
+				ctx, orderUID, currentOrder.Version, currentOrder.PayloadHash,
+			)
+			if txErr != nil {
+				return transaction.HandleError("CreateRefund", "increment version", txErr)
+			}
+			updatedOrder.Version = version
+
+			if txErr = os.recordEvent(ctx, entity.EventRefundCreated, orderUID, created); txErr != nil {
+				return transaction.HandleError("CreateRefund", "record event", txErr)
+			}
+
+			txErr = os.auditRepo.Create(ctx, &entity.AuditRecord{
+				OrderUID: orderUID,
+				Version:  version,
+				Action:   entity.AuditActionRefund,
+				Diff:     diffOrders(currentOrder, updatedOrder),
+				Snapshot: snapshot,
+			})
+			if txErr != nil {
+				return transaction.HandleError("CreateRefund", "create audit record", txErr)
+			}
+
+			transaction.AfterCommit(ctx, func() {
+				os.cache.Put(orderUID, updatedOrder, os.cacheTTL)
+				os.invalidateSummary(updatedOrder.CustomerID)
+			})
+
+			return nil
+		},
+	)
+	if err != nil {
+		log.LogAttrs(ctx, logger.ErrorLevel, "refund failed",
+			logger.String("op", op),
+			logger.Any("error", err),
+			logger.String("order_uid", orderUID.String()),
+		)
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "refund created",
+		logger.String("op", op),
+		logger.String("order_uid", orderUID.String()),
+		logger.String("refund_id", created.RefundID.String()),
+		logger.Int64("amount", created.Amount),
+		logger.Int("items", len(created.Items)),
+	)
+
+	return created, nil
+}
+
+// createRefundInTx locks the payment so concurrent refunds of the order are serialized, marks
+// the items refunded and checks the new total against the payment amount. Items are marked
+// again under the lock, so an item refunded concurrently is a conflict rather than refunded
+// twice.
+func (os *OrderService) createRefundInTx(
+	ctx context.Context,
+	order *entity.Order,
+	req *entity.RefundRequest,
+) (*entity.Refund, error) {
+	payment, err := os.paymentRepo.GetForUpdate(ctx, order.OrderUID)
+	if err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	amount := req.Amount
+	if len(req.Items) > 0 {
+		items, updateErr := os.itemRepo.UpdateStatus(ctx, order.OrderUID, req.Items, entity.ItemStatusRefunded)
+		if updateErr != nil {
+			// nolint: wrapcheck
+			return nil, updateErr
+		}
+
+		refunded := make(map[uuid.UUID]struct{}, len(items))
+		amount = 0
+		for _, item := range items {
+			refunded[item.Rid] = struct{}{}
+			amount += item.TotalPrice
+		}
+		if len(refunded) != len(req.Items) {
+			return nil, fmt.Errorf("item already refunded: %w", entity.ErrConflictingData)
+		}
+	}
+
+	total, err := os.refundRepo.SumByOrderUID(ctx, order.OrderUID)
+	if err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+	if amount > payment.Amount-total {
+		return nil, fmt.Errorf("refund of %d with %d of %d already refunded: %w",
+			amount, total, payment.Amount, entity.ErrRefundExceeded)
+	}
+
+	// nolint: wrapcheck
+	return os.refundRepo.Create(ctx, &entity.Refund{
+		RefundID: uuid.New(),
+		OrderUID: order.OrderUID,
+		Amount:   amount,
+		Currency: payment.Currency,
+		Items:    req.Items,
+		Reason:   req.Reason,
+	})
+}
+
+// GetOrderDetails returns the order together with its refunds and the amount left after them.
+func (os *OrderService) GetOrderDetails(ctx context.Context, orderUID uuid.UUID) (*entity.OrderDetails, error) {
+	ctx, span := _tracer.Start(ctx, "OrderService.GetOrderDetails",
+		trace.WithAttributes(attribute.String("order.uid", orderUID.String())),
+	)
+	defer span.End()
+
+	details, err := os.getOrderDetails(ctx, orderUID)
+	tracing.RecordError(span, err)
+
+	return details, err
+}
+
+func (os *OrderService) getOrderDetails(ctx context.Context, orderUID uuid.UUID) (*entity.OrderDetails, error) {
+	const op = "service.GetOrderDetails"
+
+	order, err := os.getOrder(ctx, orderUID)
+	if err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	refunds, err := os.refundRepo.ListByOrderUID(ctx, orderUID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	details := &entity.OrderDetails{Order: order, Refunds: refunds}
+	for _, refund := range refunds {
+		details.RefundedAmount += refund.Amount
+	}
+	details.NetAmount = order.Payment.Amount - details.RefundedAmount
+
+	return details, nil
+}
+
+func validateRefundRequest(req *entity.RefundRequest) error {
+	if req == nil {
+		return entity.ErrInvalidData
+	}
+	if (len(req.Items) > 0) == (req.Amount != 0) {
+		return fmt.Errorf("exactly one of items and amount must be set: %w", entity.ErrInvalidData)
+	}
+	if req.Amount < 0 {
+		return fmt.Errorf("amount must be positive: %w", entity.ErrInvalidData)
+	}
+	if utf8.RuneCountInString(req.Reason) > _maxRefundReasonLength {
+		return fmt.Errorf("reason must be at most %d characters: %w", _maxRefundReasonLength, entity.ErrInvalidData)
+	}
+
+	seen := make(map[uuid.UUID]struct{}, len(req.Items))
+	for _, rid := range req.Items {
+		if _, dup := seen[rid]; dup {
+			return fmt.Errorf("item %s listed twice: %w", rid, entity.ErrInvalidData)
+		}
+		seen[rid] = struct{}{}
+	}
+	return nil
+}
+
+// refundItems returns a copy of order with the items with the given rids marked refunded. An
+// unknown rid is invalid and an item refunded before is a conflict.
+func refundItems(order *entity.Order, rids []uuid.UUID) (*entity.Order, error) {
+	updated := *order
+	if len(rids) == 0 {
+		return &updated, nil
+	}
+
+	requested := make(map[uuid.UUID]bool, len(rids))
+	for _, rid := range rids {
+		requested[rid] = false
+	}
+
+	updated.Items = make([]*entity.Item, len(order.Items))
+	for i, item := range order.Items {
+		updated.Items[i] = item
+		if _, ok := requested[item.Rid]; !ok {
+			continue
+		}
+		if item.Status == entity.ItemStatusRefunded {
+			return nil, fmt.Errorf("item %s already refunded: %w", item.Rid, entity.ErrConflictingData)
+		}
+		requested[item.Rid] = true
+
+		refunded := *item
+		refunded.Status = entity.ItemStatusRefunded
+		updated.Items[i] = &refunded
+	}
+
+	for rid, found := range requested {
+		if !found {
+			return nil, fmt.Errorf("order has no item %s: %w", rid, entity.ErrInvalidData)
+		}
+	}
+	return &updated, nil
+}
@@ -0,0 +1,498 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"wbtest/internal/entity"
+	mock_repository "wbtest/internal/repository/mock"
+	"wbtest/internal/service"
+	mock_cache "wbtest/pkg/cache/mock"
+	mock_logger "wbtest/pkg/logger/mock"
+	"wbtest/pkg/storage/postgres/transaction"
+	mock_transaction "wbtest/pkg/storage/postgres/transaction/mock"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+)
+
+type refundMocks struct {
+	orderRepo    *mock_repository.MockOrderRepository
+	deliveryRepo *mock_repository.MockDeliveryRepository
+	paymentRepo  *mock_repository.MockPaymentRepository
+	itemRepo     *mock_repository.MockItemRepository
+	auditRepo    *mock_repository.MockAuditRepository
+	refundRepo   *mock_repository.MockRefundRepository
+	eventRepo    *mock_repository.MockEventRepository
+	webhookQueue *mock_repository.MockWebhookQueue
+	txManager    *mock_transaction.MockManager
+	logger       *mock_logger.MockLogger
+	cache        *mock_cache.MockCache[uuid.UUID, *entity.Order]
+	summaryCache *mock_cache.MockCache[string, *entity.CustomerSummary]
+}
+
+func newRefundMocks(ctrl *gomock.Controller) *refundMocks {
+	m := &refundMocks{
+		orderRepo:    mock_repository.NewMockOrderRepository(ctrl),
+		deliveryRepo: mock_repository.NewMockDeliveryRepository(ctrl),
+		paymentRepo:  mock_repository.NewMockPaymentRepository(ctrl),
+		itemRepo:     mock_repository.NewMockItemRepository(ctrl),
+		auditRepo:    mock_repository.NewMockAuditRepository(ctrl),
+		refundRepo:   mock_repository.NewMockRefundRepository(ctrl),
+		eventRepo:    mock_repository.NewMockEventRepository(ctrl),
+		webhookQueue: mock_repository.NewMockWebhookQueue(ctrl),
+		txManager:    mock_transaction.NewMockManager(ctrl),
+		logger:       mock_logger.NewMockLogger(ctrl),
+		cache:        mock_cache.NewMockCache[uuid.UUID, *entity.Order](ctrl),
+		summaryCache: mock_cache.NewMockCache[string, *entity.CustomerSummary](ctrl),
+	}
+
+	m.cache.EXPECT().SetOnEvicted(gomock.Any()).AnyTimes()
+	m.logger.EXPECT().Ctx(gomock.Any()).Return(m.logger).AnyTimes()
+	m.logger.EXPECT().LogAttrs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	return m
+}
+
+func (m *refundMocks) service(ctrl *gomock.Controller) *service.OrderService {
+	return service.NewOrderService(service.OrderServiceDeps{
+		DeliveryRepo:  m.deliveryRepo,
+		ItemRepo:      m.itemRepo,
+		OrderRepo:     m.orderRepo,
+		PaymentRepo:   m.paymentRepo,
+		AuditRepo:     m.auditRepo,
+		ArchiveRepo:   mock_repository.NewMockArchiveRepository(ctrl),
+		SearchRepo:    mock_repository.NewMockSearchRepository(ctrl),
+		CustomerRepo:  mock_repository.NewMockCustomerRepository(ctrl),
+		AnalyticsRepo: newAnalyticsRepoMock(ctrl),
+		RefundRepo:    m.refundRepo,
+		EventRepo:     m.eventRepo,
+		WebhookQueue:  m.webhookQueue,
+		TxManager:     m.txManager,
+		Logger:        m.logger,
+		Cache:         m.cache,
+		CacheTTL:      time.Minute,
+		SummaryCache:  m.summaryCache,
+		SummaryTTL:    time.Minute,
+	})
+}
+
+func (m *refundMocks) expectFetch(order *entity.Order) {
+	m.orderRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).Return(order, nil)
+	m.deliveryRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).Return(order.Delivery, nil)
+	m.paymentRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).Return(order.Payment, nil)
+	m.itemRepo.EXPECT().GetListByOrderUID(gomock.Any(), order.OrderUID).Return(order.Items, nil)
+}
+
+func (m *refundMocks) expectTx() {
+	m.txManager.EXPECT().ExecuteInTransaction(gomock.Any(), "CreateRefund", gomock.Any()).
+		DoAndReturn(func(
+			txCtx context.Context,
+			_ string,
+			txFunc func(context.Context) error,
+			_ ...transaction.TxOption,
+		) error {
+			return txFunc(txCtx)
+		})
+}
+
+func TestOrderService_CreateRefund_Items(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	m := newRefundMocks(ctrl)
+
+	order := generateFakeOrder()
+	order.Version = 1
+	order.Payment.Amount = 10000
+	item := order.Items[0]
+	item.TotalPrice = 700
+	item.Status = 202
+
+	m.expectFetch(order)
+	m.expectTx()
+	m.paymentRepo.EXPECT().GetForUpdate(gomock.Any(), order.OrderUID).Return(order.Payment, nil)
+	m.itemRepo.EXPECT().
+		UpdateStatus(gomock.Any(), order.OrderUID, []uuid.UUID{item.Rid}, entity.ItemStatusRefunded).
+		Return([]*entity.Item{{Rid: item.Rid, TotalPrice: 700, Status: entity.ItemStatusRefunded}}, nil)
+	m.refundRepo.EXPECT().SumByOrderUID(gomock.Any(), order.OrderUID).Return(int64(9000), nil)
+	m.refundRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, refund *entity.Refund) (*entity.Refund, error) {
+			if refund.Amount != 700 || refund.Currency != order.Payment.Currency || refund.Reason != "damaged" {
+				t.Errorf("unexpected refund %+v", refund)
+			}
+			return refund, nil
+		})
+	m.orderRepo.EXPECT().IncrementVersion(gomock.Any(), order.OrderUID, 1, gomock.Any()).Return(2, nil)
+	m.eventRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, event *entity.Event) error {
+			var payload entity.Refund
+			if err := json.Unmarshal(event.Payload, &payload); err != nil {
+				t.Errorf("expected a refund payload, got %s", event.Payload)
+			}
+			if event.Type != entity.EventRefundCreated || event.OrderUID != order.OrderUID || payload.Amount != 700 {
+				t.Errorf("unexpected event %+v", event)
+			}
+			return nil
+		})
+	m.auditRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, record *entity.AuditRecord) error {
+			if record.Action != entity.AuditActionRefund || record.Version != 2 {
+				t.Errorf("unexpected audit record %+v", record)
+			}
+			return nil
+		})
+	m.cache.EXPECT().Put(order.OrderUID, gomock.Any(), time.Minute).
+		Do(func(_ uuid.UUID, cached *entity.Order, _ time.Duration) {
+			if cached.Version != 2 || cached.Items[0].Status != entity.ItemStatusRefunded {
+				t.Errorf("expected the refunded order in the cache, got %+v", cached)
+			}
+		})
+	m.summaryCache.EXPECT().Delete(order.CustomerID)
+
+	refund, err := m.service(ctrl).CreateRefund(context.Background(), order.OrderUID, &entity.RefundRequest{
+		Items:  []uuid.UUID{item.Rid},
+		Reason: "damaged",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if refund.Amount != 700 {
+		t.Fatalf("expected amount 700, got %d", refund.Amount)
+	}
+	if item.Status != 202 {
+		t.Fatal("expected the fetched order to stay unchanged")
+	}
+}
+
+func TestOrderService_CreateRefund_ReplayIsNoOp(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	m := newRefundMocks(ctrl)
+
+	payload, err := json.Marshal(generateFakeOrder())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	decode := func() *entity.Order {
+		order := &entity.Order{}
+		if err := json.Unmarshal(payload, order); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return order
+	}
+
+	// the order is created from the producer payload
+	var stored *entity.Order
+	m.orderRepo.EXPECT().GetAnyByOrderUID(gomock.Any(), gomock.Any()).Return(nil, false, entity.ErrDataNotFound)
+	m.txManager.EXPECT().ExecuteInTransaction(gomock.Any(), "CreateOrder", gomock.Any()).
+		DoAndReturn(func(
+			txCtx context.Context,
+			_ string,
+			txFunc func(context.Context) error,
+			_ ...transaction.TxOption,
+		) error {
+			return txFunc(txCtx)
+		})
+	m.orderRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, order *entity.Order) (*entity.Order, error) {
+			created := *order
+			created.Version = 1
+			stored = &created
+			return order, nil
+		})
+	m.deliveryRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, d *entity.Delivery) (*entity.Delivery, error) {
+			return d, nil
+		})
+	m.paymentRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, p *entity.Payment) (*entity.Payment, error) {
+			return p, nil
+		})
+	m.itemRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	m.eventRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	m.cache.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+	m.summaryCache.EXPECT().Delete(gomock.Any()).AnyTimes()
+
+	svc := m.service(ctrl)
+	if _, err = svc.CreateOrder(context.Background(), decode()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// then one of its items is refunded
+	item := stored.Items[0]
+	m.expectFetch(stored)
+	m.expectTx()
+	m.paymentRepo.EXPECT().GetForUpdate(gomock.Any(), stored.OrderUID).Return(stored.Payment, nil)
+	m.itemRepo.EXPECT().UpdateStatus(gomock.Any(), stored.OrderUID, []uuid.UUID{item.Rid}, entity.ItemStatusRefunded).
+		Return([]*entity.Item{{Rid: item.Rid, TotalPrice: 0, Status: entity.ItemStatusRefunded}}, nil)
+	m.refundRepo.EXPECT().SumByOrderUID(gomock.Any(), stored.OrderUID).Return(int64(0), nil)
+	m.refundRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, refund *entity.Refund) (*entity.Refund, error) {
+			return refund, nil
+		})
+	m.orderRepo.EXPECT().IncrementVersion(gomock.Any(), stored.OrderUID, 1, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, _ int, hash string) (int, error) {
+			refunded := *stored
+			refunded.Version = 2
+			refunded.PayloadHash = hash
+			stored = &refunded
+			return 2, nil
+		})
+	m.auditRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	if _, err = svc.CreateRefund(context.Background(), stored.OrderUID, &entity.RefundRequest{
+		Items: []uuid.UUID{item.Rid},
+	}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// and Kafka redelivers the original payload, which is answered with the stored order
+	m.orderRepo.EXPECT().GetAnyByOrderUID(gomock.Any(), stored.OrderUID).
+		DoAndReturn(func(context.Context, uuid.UUID) (*entity.Order, bool, error) {
+			return stored, false, nil
+		})
+
+	got, err := svc.CreateOrder(context.Background(), decode())
+	if err != nil {
+		t.Fatalf("expected the replay to be a no-op, got %v", err)
+	}
+	if got.Version != 2 {
+		t.Fatalf("expected the stored order at version 2, got %d", got.Version)
+	}
+}
+
+func TestOrderService_CreateRefund_Rejected(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc  string
+		req   func(order *entity.Order) *entity.RefundRequest
+		mocks func(m *refundMocks, order *entity.Order)
+		want  error
+	}{
+		{
+			desc: "ItemsAndAmount",
+			req: func(order *entity.Order) *entity.RefundRequest {
+				return &entity.RefundRequest{Items: []uuid.UUID{order.Items[0].Rid}, Amount: 100}
+			},
+			mocks: func(*refundMocks, *entity.Order) {},
+			want:  entity.ErrInvalidData,
+		},
+		{
+			desc:  "NothingToRefund",
+			req:   func(*entity.Order) *entity.RefundRequest { return &entity.RefundRequest{} },
+			mocks: func(*refundMocks, *entity.Order) {},
+			want:  entity.ErrInvalidData,
+		},
+		{
+			desc:  "NegativeAmount",
+			req:   func(*entity.Order) *entity.RefundRequest { return &entity.RefundRequest{Amount: -1} },
+			mocks: func(*refundMocks, *entity.Order) {},
+			want:  entity.ErrInvalidData,
+		},
+		{
+			desc: "DuplicateItem",
+			req: func(order *entity.Order) *entity.RefundRequest {
+				return &entity.RefundRequest{Items: []uuid.UUID{order.Items[0].Rid, order.Items[0].Rid}}
+			},
+			mocks: func(*refundMocks, *entity.Order) {},
+			want:  entity.ErrInvalidData,
+		},
+		{
+			desc: "UnknownItem",
+			req: func(*entity.Order) *entity.RefundRequest {
+				return &entity.RefundRequest{Items: []uuid.UUID{uuid.New()}}
+			},
+			mocks: func(m *refundMocks, order *entity.Order) {
+				m.expectFetch(order)
+			},
+			want: entity.ErrInvalidData,
+		},
+		{
+			desc: "ItemAlreadyRefunded",
+			req: func(order *entity.Order) *entity.RefundRequest {
+				order.Items[0].Status = entity.ItemStatusRefunded
+				return &entity.RefundRequest{Items: []uuid.UUID{order.Items[0].Rid}}
+			},
+			mocks: func(m *refundMocks, order *entity.Order) {
+				m.expectFetch(order)
+			},
+			want: entity.ErrConflictingData,
+		},
+		{
+			desc: "ItemRefundedConcurrently",
+			req: func(order *entity.Order) *entity.RefundRequest {
+				return &entity.RefundRequest{Items: []uuid.UUID{order.Items[0].Rid}}
+			},
+			mocks: func(m *refundMocks, order *entity.Order) {
+				m.expectFetch(order)
+				m.expectTx()
+				m.paymentRepo.EXPECT().GetForUpdate(gomock.Any(), order.OrderUID).Return(order.Payment, nil)
+				m.itemRepo.EXPECT().UpdateStatus(gomock.Any(), order.OrderUID, gomock.Any(), entity.ItemStatusRefunded).
+					Return([]*entity.Item{}, nil)
+			},
+			want: entity.ErrConflictingData,
+		},
+		{
+			desc: "ExceedsPayment",
+			req: func(*entity.Order) *entity.RefundRequest {
+				return &entity.RefundRequest{Amount: 501}
+			},
+			mocks: func(m *refundMocks, order *entity.Order) {
+				m.expectFetch(order)
+				m.expectTx()
+				m.paymentRepo.EXPECT().GetForUpdate(gomock.Any(), order.OrderUID).Return(order.Payment, nil)
+				m.refundRepo.EXPECT().SumByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order.Payment.Amount-500, nil)
+			},
+			want: entity.ErrRefundExceeded,
+		},
+		{
+			desc: "AmountOverflow",
+			req: func(*entity.Order) *entity.RefundRequest {
+				return &entity.RefundRequest{Amount: 1<<63 - 1}
+			},
+			mocks: func(m *refundMocks, order *entity.Order) {
+				m.expectFetch(order)
+				m.expectTx()
+				m.paymentRepo.EXPECT().GetForUpdate(gomock.Any(), order.OrderUID).Return(order.Payment, nil)
+				m.refundRepo.EXPECT().SumByOrderUID(gomock.Any(), order.OrderUID).Return(int64(100), nil)
+			},
+			want: entity.ErrRefundExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			m := newRefundMocks(ctrl)
+			order := generateFakeOrder()
+			req := tc.req(order)
+			tc.mocks(m, order)
+
+			refund, err := m.service(ctrl).CreateRefund(context.Background(), order.OrderUID, req)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			if refund != nil {
+				t.Fatal("expected no refund on error")
+			}
+		})
+	}
+}
+
+func TestOrderService_GetOrderDetails(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	m := newRefundMocks(ctrl)
+
+	order := generateFakeOrder()
+	order.Payment.Amount = 5000
+	refunds := []*entity.Refund{
+		{RefundID: uuid.New(), OrderUID: order.OrderUID, Amount: 700},
+		{RefundID: uuid.New(), OrderUID: order.OrderUID, Amount: 300},
+	}
+
+	m.cache.EXPECT().Get(order.OrderUID).Return(order, true)
+	m.refundRepo.EXPECT().ListByOrderUID(gomock.Any(), order.OrderUID).Return(refunds, nil)
+
+	details, err := m.service(ctrl).GetOrderDetails(context.Background(), order.OrderUID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if details.Order != order || len(details.Refunds) != 2 {
+		t.Fatalf("unexpected details %+v", details)
+	}
+	if details.RefundedAmount != 1000 || details.NetAmount != 4000 {
+		t.Fatalf("expected refunded 1000 and net 4000, got %d and %d", details.RefundedAmount, details.NetAmount)
+	}
+}
+
+func TestOrderService_RelayEvents(t *testing.T) {
+	t.Parallel()
+
+	events := []*entity.Event{
+		{EventID: uuid.New(), Type: entity.EventRefundCreated},
+		{EventID: uuid.New(), Type: entity.EventRefundCreated},
+	}
+	ids := []uuid.UUID{events[0].EventID, events[1].EventID}
+	errPublish := errors.New("broker unavailable")
+
+	testCases := []struct {
+		desc       string
+		publishErr error
+		wantDelete bool
+		want       int
+	}{
+		{desc: "Published", wantDelete: true, want: 2},
+		{desc: "PublishFailed", publishErr: errPublish},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			m := newRefundMocks(ctrl)
+
+			var inTx bool
+			runInTx := func(
+				txCtx context.Context,
+				_ string,
+				txFunc func(context.Context) error,
+				_ ...transaction.TxOption,
+			) error {
+				inTx = true
+				defer func() { inTx = false }()
+				return txFunc(txCtx)
+			}
+
+			start := time.Now()
+			m.txManager.EXPECT().ExecuteInTransaction(gomock.Any(), "ClaimEvents", gomock.Any()).DoAndReturn(runInTx)
+			m.eventRepo.EXPECT().ClaimPending(gomock.Any(), 10, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ int, leaseUntil time.Time) ([]*entity.Event, error) {
+					if leaseUntil.Before(start.Add(time.Minute)) {
+						t.Errorf("expected events leased for a minute, got until %v", leaseUntil)
+					}
+					return events, nil
+				})
+			if tc.wantDelete {
+				m.txManager.EXPECT().ExecuteInTransaction(gomock.Any(), "RelayEvents", gomock.Any()).
+					DoAndReturn(runInTx)
+				m.webhookQueue.EXPECT().EnqueueDeliveries(gomock.Any(), ids).Return(int64(3), nil)
+				m.eventRepo.EXPECT().DeleteByIDs(gomock.Any(), ids)
+			}
+
+			var published []*entity.Event
+			relayed, err := m.service(ctrl).RelayEvents(context.Background(), 10, time.Minute,
+				func(_ context.Context, batch []*entity.Event) error {
+					if inTx {
+						t.Error("expected events published outside of a transaction")
+					}
+					published = batch
+					return tc.publishErr
+				})
+
+			if tc.publishErr != nil {
+				if !errors.Is(err, tc.publishErr) {
+					t.Fatalf("expected %v, got %v", tc.publishErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if relayed != tc.want || len(published) != len(events) {
+				t.Fatalf("expected %d events relayed, got %d", tc.want, relayed)
+			}
+		})
+	}
+}
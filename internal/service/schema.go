@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"wbtest/internal/entity"
+	"wbtest/internal/schema"
+	"wbtest/pkg/logger"
+	"wbtest/pkg/storage/postgres/transaction"
+)
+
+const _orderSchemaID = "urn:wbtest:schema:order"
+
+type schemaKey struct {
+	subject string
+	version int
+}
+
+// SchemaService stores versioned JSON Schemas and validates payloads against them.
+// Registered versions never change, so parsed schemas are cached for the process lifetime.
+type SchemaService struct {
+	repo      SchemaRepository
+	txManager transaction.Manager
+	logger    logger.Logger
+
+	mu     sync.RWMutex
+	parsed map[schemaKey]*schema.Schema
+}
+
+func NewSchemaService(
+	repo SchemaRepository,
+	txManager transaction.Manager,
+	logger logger.Logger,
+) *SchemaService {
+	return &SchemaService{
+		repo:      repo,
+		txManager: txManager,
+		logger:    logger,
+		parsed:    make(map[schemaKey]*schema.Schema),
+	}
+}
+
+// GenerateOrderSchema derives the current entity.Order schema from its struct tags.
+func GenerateOrderSchema() (*schema.Schema, error) {
+	s, err := schema.Generate(entity.Order{}, _orderSchemaID, "Order")
+	if err != nil {
+		return nil, fmt.Errorf("service.GenerateOrderSchema: %w", err)
+	}
+	return s, nil
+}
+
+// Register stores s as the next version of subject after checking it against the latest one.
+// Registering a schema identical to an existing version returns that version and created=false.
+func (ss *SchemaService) Register(
+	ctx context.Context,
+	subject string,
+	s *schema.Schema,
+	mode schema.Mode,
+) (*entity.SchemaVersion, bool, error) {
+	const op = "service.SchemaService.Register"
+	log := ss.logger.Ctx(ctx)
+
+	definition, err := s.Marshal()
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+	fingerprint, err := s.Fingerprint()
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var (
+		result  *entity.SchemaVersion
+		created bool
+	)
+	err = ss.txManager.ExecuteInTransaction(
+		ctx,
+		"RegisterSchema",
+		func(ctx context.Context) error {
+			if txErr := ss.repo.LockSubject(ctx, subject); txErr != nil {
+				return transaction.HandleError("RegisterSchema", "lock subject", txErr)
+			}
+
+			existing, txErr := ss.repo.GetByFingerprint(ctx, subject, fingerprint)
+			if txErr == nil {
+				result = existing
+				return nil
+			}
+			if !errors.Is(txErr, entity.ErrDataNotFound) {
+				return transaction.HandleError("RegisterSchema", "get by fingerprint", txErr)
+			}
+
+			nextVersion := 1
+			latest, txErr := ss.repo.GetLatest(ctx, subject)
+			switch {
+			case txErr == nil:
+				if problems := checkAgainst(latest, s, mode); len(problems) > 0 {
+					return fmt.Errorf("%w with version %d: %s",
+						entity.ErrIncompatible, latest.Version, strings.Join(problems, "; "))
+				}
+				nextVersion = latest.Version + 1
+			case !errors.Is(txErr, entity.ErrDataNotFound):
+				return transaction.HandleError("RegisterSchema", "get latest", txErr)
+			}
+
+			result, txErr = ss.repo.Create(ctx, &entity.SchemaVersion{
+				Subject:     subject,
+				Version:     nextVersion,
+				Fingerprint: fingerprint,
+				Definition:  definition,
+			})
+			if txErr != nil {
+				return transaction.HandleError("RegisterSchema", "create schema", txErr)
+			}
+			created = true
+
+			return nil
+		},
+	)
+	if err != nil {
+		log.LogAttrs(ctx, logger.ErrorLevel, "schema registration failed",
+			logger.String("op", op),
+			logger.String("subject", subject),
+			logger.Any("error", err),
+		)
+		// nolint: wrapcheck
+		return nil, false, err
+	}
+
+	if created {
+		log.LogAttrs(ctx, logger.InfoLevel, "schema registered",
+			logger.String("op", op),
+			logger.String("subject", subject),
+			logger.Int("version", result.Version),
+		)
+	}
+
+	return result, created, nil
+}
+
+// CheckCompatibility compares s with the latest registered version of subject. It returns
+// a nil version and no problems when nothing is registered yet.
+func (ss *SchemaService) CheckCompatibility(
+	ctx context.Context,
+	subject string,
+	s *schema.Schema,
+	mode schema.Mode,
+) (*entity.SchemaVersion, []string, error) {
+	const op = "service.SchemaService.CheckCompatibility"
+
+	latest, err := ss.repo.GetVersion(ctx, subject, 0)
+	if errors.Is(err, entity.ErrDataNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return latest, checkAgainst(latest, s, mode), nil
+}
+
+// GetVersion returns a registered version; version 0 means the latest.
+func (ss *SchemaService) GetVersion(
+	ctx context.Context,
+	subject string,
+	version int,
+) (*entity.SchemaVersion, error) {
+	result, err := ss.repo.GetVersion(ctx, subject, version)
+	if err != nil {
+		return nil, fmt.Errorf("service.SchemaService.GetVersion: %w", err)
+	}
+	return result, nil
+}
+
+func (ss *SchemaService) ListVersions(ctx context.Context, subject string) ([]*entity.SchemaVersion, error) {
+	versions, err := ss.repo.ListVersions(ctx, subject)
+	if err != nil {
+		return nil, fmt.Errorf("service.SchemaService.ListVersions: %w", err)
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("service.SchemaService.ListVersions: %s: %w", subject, entity.ErrDataNotFound)
+	}
+	return versions, nil
+}
+
+// Validate checks a JSON payload against the given version of subject.
+func (ss *SchemaService) Validate(ctx context.Context, subject string, version int, payload []byte) error {
+	const op = "service.SchemaService.Validate"
+
+	s, err := ss.load(ctx, subject, version)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = s.Validate(payload); err != nil {
+		return fmt.Errorf("%s: %s v%d: %w", op, subject, version, err)
+	}
+	return nil
+}
+
+func (ss *SchemaService) load(ctx context.Context, subject string, version int) (*schema.Schema, error) {
+	key := schemaKey{subject: subject, version: version}
+
+	ss.mu.RLock()
+	s, ok := ss.parsed[key]
+	ss.mu.RUnlock()
+	if ok {
+		return s, nil
+	}
+
+	stored, err := ss.repo.GetVersion(ctx, subject, version)
+	if err != nil {
+		return nil, fmt.Errorf("%s v%d: %w", subject, version, err)
+	}
+	if s, err = schema.Parse(stored.Definition); err != nil {
+		return nil, fmt.Errorf("%s v%d: %w", subject, version, err)
+	}
+
+	ss.mu.Lock()
+	ss.parsed[key] = s
+	ss.mu.Unlock()
+
+	return s, nil
+}
+
+func checkAgainst(latest *entity.SchemaVersion, s *schema.Schema, mode schema.Mode) []string {
+	prev, err := schema.Parse(latest.Definition)
+	if err != nil {
+		return []string{fmt.Sprintf("stored version %d is unreadable: %v", latest.Version, err)}
+	}
+	return schema.Check(prev, s, mode)
+}
@@ -0,0 +1,206 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"wbtest/internal/entity"
+	mock_repository "wbtest/internal/repository/mock"
+	"wbtest/internal/schema"
+	"wbtest/internal/service"
+	mock_logger "wbtest/pkg/logger/mock"
+	"wbtest/pkg/storage/postgres/transaction"
+	mock_transaction "wbtest/pkg/storage/postgres/transaction/mock"
+
+	"go.uber.org/mock/gomock"
+)
+
+func storedSchema(t *testing.T, s *schema.Schema, version int) *entity.SchemaVersion {
+	t.Helper()
+
+	definition, err := s.Marshal()
+	if err != nil {
+		t.Fatalf("marshal schema: %v", err)
+	}
+	fingerprint, err := s.Fingerprint()
+	if err != nil {
+		t.Fatalf("fingerprint: %v", err)
+	}
+	return &entity.SchemaVersion{
+		Subject:     entity.SchemaSubjectOrder,
+		Version:     version,
+		Fingerprint: fingerprint,
+		Definition:  definition,
+	}
+}
+
+func generateOrderSchema(t *testing.T) *schema.Schema {
+	t.Helper()
+
+	s, err := schema.Generate(entity.Order{}, "", "Order")
+	if err != nil {
+		t.Fatalf("generate schema: %v", err)
+	}
+	return s
+}
+
+func TestSchemaService_Register(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	subject := entity.SchemaSubjectOrder
+
+	current := generateOrderSchema(t)
+
+	withOptionalField := generateOrderSchema(t)
+	withOptionalField.Properties["gift_note"] = &schema.Schema{Type: "string"}
+
+	renamed := generateOrderSchema(t)
+	renamed.Properties["uid"] = renamed.Properties["order_uid"]
+	delete(renamed.Properties, "order_uid")
+
+	testCases := []struct {
+		desc        string
+		schema      *schema.Schema
+		mocks       func(repo *mock_repository.MockSchemaRepository, s *schema.Schema)
+		wantVersion int
+		wantCreated bool
+		wantErr     error
+	}{
+		{
+			desc:   "FirstVersion",
+			schema: current,
+			mocks: func(repo *mock_repository.MockSchemaRepository, s *schema.Schema) {
+				fingerprint, _ := s.Fingerprint()
+				repo.EXPECT().GetByFingerprint(ctx, subject, fingerprint).
+					Return(nil, entity.ErrDataNotFound)
+				repo.EXPECT().GetLatest(ctx, subject).Return(nil, entity.ErrDataNotFound)
+				repo.EXPECT().Create(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, v *entity.SchemaVersion) (*entity.SchemaVersion, error) {
+						return v, nil
+					})
+			},
+			wantVersion: 1,
+			wantCreated: true,
+		},
+		{
+			desc:   "SameSchemaReturnsExistingVersion",
+			schema: current,
+			mocks: func(repo *mock_repository.MockSchemaRepository, s *schema.Schema) {
+				fingerprint, _ := s.Fingerprint()
+				repo.EXPECT().GetByFingerprint(ctx, subject, fingerprint).
+					Return(storedSchema(t, s, 2), nil)
+			},
+			wantVersion: 2,
+		},
+		{
+			desc:   "CompatibleChangeBumpsVersion",
+			schema: withOptionalField,
+			mocks: func(repo *mock_repository.MockSchemaRepository, s *schema.Schema) {
+				fingerprint, _ := s.Fingerprint()
+				repo.EXPECT().GetByFingerprint(ctx, subject, fingerprint).
+					Return(nil, entity.ErrDataNotFound)
+				repo.EXPECT().GetLatest(ctx, subject).Return(storedSchema(t, current, 3), nil)
+				repo.EXPECT().Create(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, v *entity.SchemaVersion) (*entity.SchemaVersion, error) {
+						return v, nil
+					})
+			},
+			wantVersion: 4,
+			wantCreated: true,
+		},
+		{
+			desc:   "IncompatibleChangeRejected",
+			schema: renamed,
+			mocks: func(repo *mock_repository.MockSchemaRepository, s *schema.Schema) {
+				fingerprint, _ := s.Fingerprint()
+				repo.EXPECT().GetByFingerprint(ctx, subject, fingerprint).
+					Return(nil, entity.ErrDataNotFound)
+				repo.EXPECT().GetLatest(ctx, subject).Return(storedSchema(t, current, 1), nil)
+			},
+			wantErr: entity.ErrIncompatible,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repository.NewMockSchemaRepository(ctrl)
+			txManager := mock_transaction.NewMockManager(ctrl)
+			logger := mock_logger.NewMockLogger(ctrl)
+
+			logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+			logger.EXPECT().LogAttrs(ctx, gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+			txManager.EXPECT().ExecuteInTransaction(
+				ctx, "RegisterSchema", gomock.Any(),
+			).DoAndReturn(func(
+				txCtx context.Context,
+				_ string,
+				txFunc func(context.Context) error,
+				_ ...transaction.TxOption,
+			) error {
+				return txFunc(txCtx)
+			}).Times(1)
+
+			repo.EXPECT().LockSubject(ctx, subject).Return(nil).Times(1)
+			tc.mocks(repo, tc.schema)
+
+			s := service.NewSchemaService(repo, txManager, logger)
+			version, created, err := s.Register(ctx, subject, tc.schema, schema.ModeBackward)
+
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if version.Version != tc.wantVersion || created != tc.wantCreated {
+				t.Fatalf("expected version %d created=%t, got %d created=%t",
+					tc.wantVersion, tc.wantCreated, version.Version, created)
+			}
+		})
+	}
+}
+
+func TestSchemaService_Validate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_repository.NewMockSchemaRepository(ctrl)
+	repo.EXPECT().GetVersion(ctx, entity.SchemaSubjectOrder, 1).
+		Return(storedSchema(t, generateOrderSchema(t), 1), nil).
+		Times(1)
+	repo.EXPECT().GetVersion(ctx, entity.SchemaSubjectOrder, 9).
+		Return(nil, entity.ErrDataNotFound).
+		Times(1)
+
+	s := service.NewSchemaService(repo, mock_transaction.NewMockManager(ctrl), mock_logger.NewMockLogger(ctrl))
+
+	err := s.Validate(ctx, entity.SchemaSubjectOrder, 1, []byte(`{"uid": "x"}`))
+	if !errors.Is(err, schema.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	// the parsed schema is cached, so the repository is not asked again
+	err = s.Validate(ctx, entity.SchemaSubjectOrder, 1, []byte(`[]`))
+	if !errors.Is(err, schema.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	err = s.Validate(ctx, entity.SchemaSubjectOrder, 9, []byte(`{}`))
+	if !errors.Is(err, entity.ErrDataNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
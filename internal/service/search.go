@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/logger"
+	"wbtest/pkg/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	_defaultPageLimit = 20
+	_maxPageLimit     = 100
+
+	_maxSearchQueryLength = 200
+	_hydrateConcurrency   = 8
+)
+
+// SearchOrders finds orders by customer name, phone, email, city, address, item name,
+// brand or track number, best match first.
+func (os *OrderService) SearchOrders(
+	ctx context.Context,
+	query string,
+	limit, offset int,
+) (*entity.OrderPage, error) {
+	ctx, span := _tracer.Start(ctx, "OrderService.SearchOrders",
+		trace.WithAttributes(
+			attribute.Int("search.limit", limit),
+			attribute.Int("search.offset", offset),
+		),
+	)
+	defer span.End()
+
+	page, err := os.searchOrders(ctx, query, limit, offset)
+	tracing.RecordError(span, err)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("search.total", page.Total))
+	}
+
+	return page, err
+}
+
+func (os *OrderService) searchOrders(
+	ctx context.Context,
+	query string,
+	limit, offset int,
+) (*entity.OrderPage, error) {
+	const op = "service.SearchOrders"
+	log := os.logger.Ctx(ctx)
+
+	query = strings.TrimSpace(query)
+	if query == "" || utf8.RuneCountInString(query) > _maxSearchQueryLength {
+		return nil, fmt.Errorf("%s: query must be 1 to %d characters: %w",
+			op, _maxSearchQueryLength, entity.ErrInvalidData)
+	}
+	limit, err := pageLimit(limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	uids, total, err := os.searchRepo.SearchOrders(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	orders, err := os.hydrateOrders(ctx, uids)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "order search finished",
+		logger.String("op", op),
+		logger.Int("results", len(orders)),
+		logger.Int64("total", total),
+	)
+
+	return &entity.OrderPage{
+		Orders: orders,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+// pageLimit applies _defaultPageLimit to an unset limit and rejects out-of-range values.
+func pageLimit(limit, offset int) (int, error) {
+	if limit == 0 {
+		limit = _defaultPageLimit
+	}
+	if limit < 0 || limit > _maxPageLimit || offset < 0 {
+		return 0, fmt.Errorf("limit must be 1 to %d and offset >= 0: %w", _maxPageLimit, entity.ErrInvalidData)
+	}
+	return limit, nil
+}
+
+// hydrateOrders loads complete orders for uids, keeping their order. Complete orders are
+// taken from the cache; the rest go through fetchOrderFromDB like GetOrder. An order deleted
+// since uids were listed is skipped.
+func (os *OrderService) hydrateOrders(ctx context.Context, uids []uuid.UUID) ([]*entity.Order, error) {
+	orders := make([]*entity.Order, len(uids))
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(_hydrateConcurrency)
+
+	for i, uid := range uids {
+		if cached, found := os.cache.Get(uid); found &&
+			cached.Delivery != nil && cached.Payment != nil && len(cached.Items) > 0 {
+			orders[i] = cached
+			continue
+		}
+
+		g.Go(func() error {
+			order, err := os.fetchOrderFromDB(gCtx, uid)
+			if errors.Is(err, entity.ErrDataNotFound) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("hydrate order %s: %w", uid, err)
+			}
+			orders[i] = order
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	hydrated := orders[:0]
+	for _, order := range orders {
+		if order != nil {
+			hydrated = append(hydrated, order)
+		}
+	}
+	return hydrated, nil
+}
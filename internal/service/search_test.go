@@ -0,0 +1,147 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"wbtest/internal/entity"
+	mock_repository "wbtest/internal/repository/mock"
+	"wbtest/internal/service"
+	mock_cache "wbtest/pkg/cache/mock"
+	mock_logger "wbtest/pkg/logger/mock"
+	mock_transaction "wbtest/pkg/storage/postgres/transaction/mock"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOrderService_SearchOrders(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	cached := generateFakeOrder()
+	stored := generateFakeOrder()
+	deleted := generateFakeOrder()
+
+	orderRepo := mock_repository.NewMockOrderRepository(ctrl)
+	deliveryRepo := mock_repository.NewMockDeliveryRepository(ctrl)
+	paymentRepo := mock_repository.NewMockPaymentRepository(ctrl)
+	itemRepo := mock_repository.NewMockItemRepository(ctrl)
+	searchRepo := mock_repository.NewMockSearchRepository(ctrl)
+	logger := mock_logger.NewMockLogger(ctrl)
+	cache := mock_cache.NewMockCache[uuid.UUID, *entity.Order](ctrl)
+	summaryCache := mock_cache.NewMockCache[string, *entity.CustomerSummary](ctrl)
+
+	cache.EXPECT().SetOnEvicted(gomock.Any()).AnyTimes()
+	logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+	logger.EXPECT().LogAttrs(gomock.Any(), gomock.Any(), "order search finished", gomock.Any())
+
+	searchRepo.EXPECT().SearchOrders(gomock.Any(), "ivan petrov", 20, 0).
+		Return([]uuid.UUID{stored.OrderUID, cached.OrderUID, deleted.OrderUID}, int64(3), nil)
+
+	cache.EXPECT().Get(cached.OrderUID).Return(cached, true)
+	cache.EXPECT().Get(stored.OrderUID).Return(nil, false)
+	cache.EXPECT().Get(deleted.OrderUID).Return(nil, false)
+
+	orderRepo.EXPECT().GetByOrderUID(gomock.Any(), stored.OrderUID).Return(stored, nil)
+	deliveryRepo.EXPECT().GetByOrderUID(gomock.Any(), stored.OrderUID).Return(stored.Delivery, nil)
+	paymentRepo.EXPECT().GetByOrderUID(gomock.Any(), stored.OrderUID).Return(stored.Payment, nil)
+	itemRepo.EXPECT().GetListByOrderUID(gomock.Any(), stored.OrderUID).Return(stored.Items, nil)
+	orderRepo.EXPECT().GetByOrderUID(gomock.Any(), deleted.OrderUID).Return(nil, entity.ErrDataNotFound)
+
+	s := service.NewOrderService(service.OrderServiceDeps{
+		DeliveryRepo:  deliveryRepo,
+		ItemRepo:      itemRepo,
+		OrderRepo:     orderRepo,
+		PaymentRepo:   paymentRepo,
+		AuditRepo:     mock_repository.NewMockAuditRepository(ctrl),
+		ArchiveRepo:   mock_repository.NewMockArchiveRepository(ctrl),
+		SearchRepo:    searchRepo,
+		CustomerRepo:  mock_repository.NewMockCustomerRepository(ctrl),
+		AnalyticsRepo: newAnalyticsRepoMock(ctrl),
+		RefundRepo:    mock_repository.NewMockRefundRepository(ctrl),
+		EventRepo:     newEventRepoMock(ctrl),
+		WebhookQueue:  mock_repository.NewMockWebhookQueue(ctrl),
+		TxManager:     mock_transaction.NewMockManager(ctrl),
+		Logger:        logger,
+		Cache:         cache,
+		CacheTTL:      time.Minute,
+		SummaryCache:  summaryCache,
+		SummaryTTL:    time.Minute,
+	})
+
+	page, err := s.SearchOrders(context.Background(), "  ivan petrov ", 0, 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if page.Total != 3 || page.Limit != 20 || page.Offset != 0 {
+		t.Fatalf("unexpected page metadata %+v", page)
+	}
+	if len(page.Orders) != 2 {
+		t.Fatalf("expected 2 orders, got %d", len(page.Orders))
+	}
+	if page.Orders[0].OrderUID != stored.OrderUID || page.Orders[1].OrderUID != cached.OrderUID {
+		t.Fatal("expected orders in ranking order")
+	}
+}
+
+func TestOrderService_SearchOrders_InvalidInput(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc   string
+		query  string
+		limit  int
+		offset int
+	}{
+		{desc: "EmptyQuery", query: "   "},
+		{desc: "LongQuery", query: strings.Repeat("a", 201)},
+		{desc: "LimitTooLarge", query: "ivan", limit: 101},
+		{desc: "NegativeOffset", query: "ivan", offset: -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			cache := mock_cache.NewMockCache[uuid.UUID, *entity.Order](ctrl)
+			summaryCache := mock_cache.NewMockCache[string, *entity.CustomerSummary](ctrl)
+			logger := mock_logger.NewMockLogger(ctrl)
+
+			cache.EXPECT().SetOnEvicted(gomock.Any()).AnyTimes()
+			logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+
+			s := service.NewOrderService(service.OrderServiceDeps{
+				DeliveryRepo:  mock_repository.NewMockDeliveryRepository(ctrl),
+				ItemRepo:      mock_repository.NewMockItemRepository(ctrl),
+				OrderRepo:     mock_repository.NewMockOrderRepository(ctrl),
+				PaymentRepo:   mock_repository.NewMockPaymentRepository(ctrl),
+				AuditRepo:     mock_repository.NewMockAuditRepository(ctrl),
+				ArchiveRepo:   mock_repository.NewMockArchiveRepository(ctrl),
+				SearchRepo:    mock_repository.NewMockSearchRepository(ctrl),
+				CustomerRepo:  mock_repository.NewMockCustomerRepository(ctrl),
+				AnalyticsRepo: newAnalyticsRepoMock(ctrl),
+				RefundRepo:    mock_repository.NewMockRefundRepository(ctrl),
+				EventRepo:     newEventRepoMock(ctrl),
+				WebhookQueue:  mock_repository.NewMockWebhookQueue(ctrl),
+				TxManager:     mock_transaction.NewMockManager(ctrl),
+				Logger:        logger,
+				Cache:         cache,
+				CacheTTL:      time.Minute,
+				SummaryCache:  summaryCache,
+				SummaryTTL:    time.Minute,
+			})
+
+			_, err := s.SearchOrders(context.Background(), tc.query, tc.limit, tc.offset)
+			if !errors.Is(err, entity.ErrInvalidData) {
+				t.Fatalf("expected ErrInvalidData, got %v", err)
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
+	"sync/atomic"
 	"time"
 
 	"wbtest/internal/entity"
@@ -11,20 +14,33 @@ import (
 	"wbtest/pkg/logger"
 	"wbtest/pkg/storage/postgres"
 	"wbtest/pkg/storage/postgres/transaction"
+	"wbtest/pkg/tracing"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 )
 
 const (
-	_defaultContextTimeout = 500 * time.Millisecond
+	_defaultContextTimeout  = 500 * time.Millisecond
+	_defaultExportFetchSize = 500
+
+	_tracerName = "wbtest/internal/service"
 )
 
+var _tracer = otel.Tracer(_tracerName)
+
 type (
 	DeliveryRepository interface {
 		Create(
 			ctx context.Context,
-			queryExecuter postgres.QueryExecuter,
+			orderUID uuid.UUID,
+			delivery *entity.Delivery,
+		) (*entity.Delivery, error)
+		Update(
+			ctx context.Context,
 			orderUID uuid.UUID,
 			delivery *entity.Delivery,
 		) (*entity.Delivery, error)
@@ -34,71 +50,268 @@ type (
 	ItemRepository interface {
 		Create(
 			ctx context.Context,
-			queryExecuter postgres.QueryExecuter,
 			orderUID uuid.UUID,
 			items []*entity.Item,
 		) error
+		ReplaceByOrderUID(
+			ctx context.Context,
+			orderUID uuid.UUID,
+			items []*entity.Item,
+		) error
+		UpdateStatus(
+			ctx context.Context,
+			orderUID uuid.UUID,
+			rids []uuid.UUID,
+			status int,
+		) ([]*entity.Item, error)
 		GetListByOrderUID(ctx context.Context, orderUID uuid.UUID) ([]*entity.Item, error)
 	}
 
 	OrderRepository interface {
 		Create(
 			ctx context.Context,
-			queryExecuter postgres.QueryExecuter,
 			order *entity.Order,
 		) (*entity.Order, error)
+		IncrementVersion(
+			ctx context.Context,
+			orderUID uuid.UUID,
+			expectedVersion int,
+			payloadHash string,
+		) (int, error)
+		SoftDelete(
+			ctx context.Context,
+			orderUID uuid.UUID,
+		) (int, error)
+		ListExpired(
+			ctx context.Context,
+			cutoff time.Time,
+			limit int,
+		) ([]uuid.UUID, error)
+		DeleteByOrderUIDs(
+			ctx context.Context,
+			orderUIDs []uuid.UUID,
+		) (int64, error)
+		StreamByDateRange(
+			ctx context.Context,
+			from, to time.Time,
+			fetchSize int,
+			fn func(order *entity.Order) error,
+		) error
 		GetByOrderUID(ctx context.Context, orderUID uuid.UUID) (*entity.Order, error)
+		GetAnyByOrderUID(ctx context.Context, orderUID uuid.UUID) (*entity.Order, bool, error)
 		GetAllOrderUIDs(ctx context.Context) ([]uuid.UUID, error)
 	}
 
 	PaymentRepository interface {
 		Create(
 			ctx context.Context,
-			queryExecuter postgres.QueryExecuter,
 			orderUID uuid.UUID,
 			payment *entity.Payment,
 		) (*entity.Payment, error)
+		Update(
+			ctx context.Context,
+			orderUID uuid.UUID,
+			payment *entity.Payment,
+		) (*entity.Payment, error)
+		GetForUpdate(
+			ctx context.Context,
+			orderUID uuid.UUID,
+		) (*entity.Payment, error)
 		GetByOrderUID(ctx context.Context, orderUID uuid.UUID) (*entity.Payment, error)
 	}
 
+	AuditRepository interface {
+		Create(
+			ctx context.Context,
+			record *entity.AuditRecord,
+		) error
+	}
+
+	ArchiveRepository interface {
+		ArchiveOrders(
+			ctx context.Context,
+			orderUIDs []uuid.UUID,
+		) (int64, error)
+	}
+
+	SearchRepository interface {
+		SearchOrders(ctx context.Context, query string, limit, offset int) ([]uuid.UUID, int64, error)
+	}
+
+	CustomerRepository interface {
+		ListOrders(ctx context.Context, customerID string, limit, offset int) ([]uuid.UUID, int64, error)
+		GetSummary(ctx context.Context, customerID string, topBrands int) (*entity.CustomerSummary, error)
+	}
+
+	AnalyticsRepository interface {
+		RecordOrder(ctx context.Context, order *entity.Order) error
+		RemoveOrder(ctx context.Context, order *entity.Order) error
+		GetOrderAnalytics(ctx context.Context, filter *entity.AnalyticsFilter) (*entity.OrderAnalytics, error)
+	}
+
+	RefundRepository interface {
+		Create(
+			ctx context.Context,
+			refund *entity.Refund,
+		) (*entity.Refund, error)
+		SumByOrderUID(ctx context.Context, orderUID uuid.UUID) (int64, error)
+		ListByOrderUID(ctx context.Context, orderUID uuid.UUID) ([]*entity.Refund, error)
+	}
+
+	EventRepository interface {
+		Create(ctx context.Context, event *entity.Event) error
+		ClaimPending(ctx context.Context, limit int, leaseUntil time.Time) ([]*entity.Event, error)
+		DeleteByIDs(ctx context.Context, eventIDs []uuid.UUID) error
+	}
+
+	// WebhookQueue queues deliveries of outbox events to the subscriptions interested in them.
+	WebhookQueue interface {
+		EnqueueDeliveries(ctx context.Context, eventIDs []uuid.UUID) (int64, error)
+	}
+
+	WebhookRepository interface {
+		WebhookQueue
+		CreateSubscription(
+			ctx context.Context,
+			sub *entity.WebhookSubscription,
+		) (*entity.WebhookSubscription, error)
+		UpdateSubscription(
+			ctx context.Context,
+			sub *entity.WebhookSubscription,
+		) (*entity.WebhookSubscription, error)
+		DeleteSubscription(ctx context.Context, subscriptionID uuid.UUID) error
+		GetSubscription(ctx context.Context, subscriptionID uuid.UUID) (*entity.WebhookSubscription, error)
+		ListSubscriptions(ctx context.Context) ([]*entity.WebhookSubscription, error)
+		ClaimDue(
+			ctx context.Context,
+			limit int,
+			leaseUntil time.Time,
+		) ([]*entity.OutgoingWebhook, error)
+		UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+		ResetFailures(ctx context.Context, subscriptionID uuid.UUID) error
+		RecordFailure(
+			ctx context.Context,
+			subscriptionID uuid.UUID,
+			disableAfter int,
+		) (int, bool, error)
+		ListDeliveries(
+			ctx context.Context,
+			subscriptionID uuid.UUID,
+			status string,
+			limit, offset int,
+		) ([]*entity.WebhookDelivery, int64, error)
+	}
+
+	// OrderPublisher hands newly created orders to live feed clients. It must not block. Without
+	// one, as in the CLI, orders are not published.
+	OrderPublisher interface {
+		Publish(order *entity.Order)
+	}
+
+	// RateProvider returns the price of one unit of currency from in units of to.
+	RateProvider interface {
+		Rate(ctx context.Context, from, to string) (*big.Rat, error)
+	}
+
+	SchemaRepository interface {
+		LockSubject(ctx context.Context, subject string) error
+		Create(
+			ctx context.Context,
+			schema *entity.SchemaVersion,
+		) (*entity.SchemaVersion, error)
+		GetLatest(
+			ctx context.Context,
+			subject string,
+		) (*entity.SchemaVersion, error)
+		GetByFingerprint(
+			ctx context.Context,
+			subject, fingerprint string,
+		) (*entity.SchemaVersion, error)
+		GetVersion(ctx context.Context, subject string, version int) (*entity.SchemaVersion, error)
+		ListVersions(ctx context.Context, subject string) ([]*entity.SchemaVersion, error)
+	}
+
 	OrderService struct {
-		deliveryRepo DeliveryRepository
-		itemRepo     ItemRepository
-		orderRepo    OrderRepository
-		paymentRepo  PaymentRepository
-		txManager    transaction.Manager
-		logger       logger.Logger
-		cache        cache.Cache[uuid.UUID, *entity.Order]
-		cacheTTL     time.Duration
+		deliveryRepo      DeliveryRepository
+		itemRepo          ItemRepository
+		orderRepo         OrderRepository
+		paymentRepo       PaymentRepository
+		auditRepo         AuditRepository
+		archiveRepo       ArchiveRepository
+		searchRepo        SearchRepository
+		customerRepo      CustomerRepository
+		analyticsRepo     AnalyticsRepository
+		refundRepo        RefundRepository
+		eventRepo         EventRepository
+		webhookQueue      WebhookQueue
+		publisher         OrderPublisher
+		txManager         transaction.Manager
+		logger            logger.Logger
+		cache             cache.Cache[uuid.UUID, *entity.Order]
+		cacheTTL          time.Duration
+		summaryCache      cache.Cache[string, *entity.CustomerSummary]
+		summaryTTL        time.Duration
+		summaryVersions   [_summaryVersionStripes]atomic.Uint64
+		rates             RateProvider
+		reportingCurrency string
 	}
 )
 
-func NewOrderService(
-	deliveryRepo DeliveryRepository,
-	itemRepo ItemRepository,
-	orderRepo OrderRepository,
-	paymentRepo PaymentRepository,
-	txManager transaction.Manager,
-	logger logger.Logger,
-	cache cache.Cache[uuid.UUID, *entity.Order],
-	cacheTTL time.Duration,
-) *OrderService {
-	cache.SetOnEvicted(func(key uuid.UUID, value *entity.Order) {
-		logger.Infow("cache eviction",
+// OrderServiceDeps holds the dependencies of OrderService. Publisher may be nil.
+type OrderServiceDeps struct {
+	DeliveryRepo      DeliveryRepository
+	ItemRepo          ItemRepository
+	OrderRepo         OrderRepository
+	PaymentRepo       PaymentRepository
+	AuditRepo         AuditRepository
+	ArchiveRepo       ArchiveRepository
+	SearchRepo        SearchRepository
+	CustomerRepo      CustomerRepository
+	AnalyticsRepo     AnalyticsRepository
+	RefundRepo        RefundRepository
+	EventRepo         EventRepository
+	WebhookQueue      WebhookQueue
+	Publisher         OrderPublisher
+	TxManager         transaction.Manager
+	Logger            logger.Logger
+	Cache             cache.Cache[uuid.UUID, *entity.Order]
+	CacheTTL          time.Duration
+	SummaryCache      cache.Cache[string, *entity.CustomerSummary]
+	SummaryTTL        time.Duration
+	Rates             RateProvider
+	ReportingCurrency string
+}
+
+func NewOrderService(deps OrderServiceDeps) *OrderService {
+	deps.Cache.SetOnEvicted(func(key uuid.UUID, value *entity.Order) {
+		deps.Logger.Infow("cache eviction",
 			"key", key.String(),
 			"type", fmt.Sprintf("%T", value),
 		)
 	})
 
 	return &OrderService{
-		deliveryRepo: deliveryRepo,
-		itemRepo:     itemRepo,
-		orderRepo:    orderRepo,
-		paymentRepo:  paymentRepo,
-		txManager:    txManager,
-		logger:       logger,
-		cache:        cache,
-		cacheTTL:     cacheTTL,
+		deliveryRepo:      deps.DeliveryRepo,
+		itemRepo:          deps.ItemRepo,
+		orderRepo:         deps.OrderRepo,
+		paymentRepo:       deps.PaymentRepo,
+		auditRepo:         deps.AuditRepo,
+		archiveRepo:       deps.ArchiveRepo,
+		searchRepo:        deps.SearchRepo,
+		customerRepo:      deps.CustomerRepo,
+		analyticsRepo:     deps.AnalyticsRepo,
+		refundRepo:        deps.RefundRepo,
+		eventRepo:         deps.EventRepo,
+		webhookQueue:      deps.WebhookQueue,
+		publisher:         deps.Publisher,
+		txManager:         deps.TxManager,
+		logger:            deps.Logger,
+		cache:             deps.Cache,
+		cacheTTL:          deps.CacheTTL,
+		summaryCache:      deps.SummaryCache,
+		summaryTTL:        deps.SummaryTTL,
+		rates:             deps.Rates,
+		reportingCurrency: deps.ReportingCurrency,
 	}
 }
 
@@ -154,13 +367,45 @@ func (os *OrderService) RestoreCache(ctx context.Context) error {
 func (os *OrderService) CreateOrder(
 	ctx context.Context,
 	order *entity.Order,
+) (*entity.Order, error) {
+	ctx, span := _tracer.Start(ctx, "OrderService.CreateOrder",
+		trace.WithAttributes(
+			attribute.String("order.uid", order.OrderUID.String()),
+			attribute.Int("order.items", len(order.Items)),
+		),
+	)
+	defer span.End()
+
+	createdOrder, err := os.createOrder(ctx, order)
+	tracing.RecordError(span, err)
+
+	return createdOrder, err
+}
+
+func (os *OrderService) createOrder(
+	ctx context.Context,
+	order *entity.Order,
 ) (*entity.Order, error) {
 	const op = "service.CreateOrder"
 	log := os.logger.Ctx(ctx)
 
-	existingOrder, err := os.orderRepo.GetByOrderUID(ctx, order.OrderUID)
+	// The duplicate check must not miss an order that has not reached the replicas yet.
+	ctx = postgres.WithPrimary(ctx)
+
+	hash, err := payloadHash(order)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	order.PayloadHash = hash
+
+	// Deleted orders count too: their uid stays taken until retention purges them.
+	existingOrder, deleted, err := os.orderRepo.GetAnyByOrderUID(ctx, order.OrderUID)
 	if err == nil {
-		return existingOrder, nil
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("order.duplicate", true))
+		if deleted {
+			return nil, fmt.Errorf("%s: %w", op, entity.ErrOrderDeleted)
+		}
+		return os.resolveDuplicate(ctx, existingOrder, order)
 	}
 	if !errors.Is(err, entity.ErrDataNotFound) {
 		return nil, fmt.Errorf("%s: check duplicate: %w", op, err)
@@ -184,7 +429,7 @@ func (os *OrderService) CreateOrder(
 		}
 	}()
 
-	if err = os.validateOrder(order); err != nil {
+	if err = ValidateOrder(order); err != nil {
 		log.LogAttrs(ctx, logger.ErrorLevel, "order validation failed",
 			logger.String("op", op),
 			logger.Any("error", err),
@@ -203,8 +448,6 @@ func (os *OrderService) CreateOrder(
 		return nil, err
 	}
 
-	os.cache.Put(createdOrder.OrderUID, createdOrder, os.cacheTTL)
-
 	duration := time.Since(startTime)
 	log.LogAttrs(ctx, logger.InfoLevel, "order created successfully",
 		logger.String("op", op),
@@ -215,34 +458,83 @@ func (os *OrderService) CreateOrder(
 	return createdOrder, nil
 }
 
+func (os *OrderService) resolveDuplicate(
+	ctx context.Context,
+	existingOrder *entity.Order,
+	order *entity.Order,
+) (*entity.Order, error) {
+	const op = "service.resolveDuplicate"
+	log := os.logger.Ctx(ctx)
+
+	if existingOrder.PayloadHash == "" || existingOrder.PayloadHash == order.PayloadHash {
+		return existingOrder, nil
+	}
+
+	diff := "stored order is incomplete"
+	if storedOrder, err := os.fetchOrderFromDB(ctx, order.OrderUID); err == nil {
+		diff = diffOrders(storedOrder, order)
+	}
+
+	log.LogAttrs(ctx, logger.WarnLevel, "conflicting payload for existing order",
+		logger.String("op", op),
+		logger.String("order_uid", order.OrderUID.String()),
+		logger.String("stored_hash", existingOrder.PayloadHash),
+		logger.String("incoming_hash", order.PayloadHash),
+		logger.String("diff", diff),
+	)
+
+	return nil, fmt.Errorf("%s: payload hash mismatch: %s: %w", op, diff, entity.ErrConflictingData)
+}
+
 func (os *OrderService) createOrderWithTransaction(
 	ctx context.Context,
 	order *entity.Order,
 ) (*entity.Order, error) {
 	var createdOrder *entity.Order
+	span := trace.SpanFromContext(ctx)
 
 	err := os.txManager.ExecuteInTransaction(
 		ctx,
 		"CreateOrder",
-		func(tx postgres.QueryExecuter) error {
+		func(ctx context.Context) error {
 			var err error
-			createdOrder, err = os.createOrderInTx(ctx, tx, order)
+			createdOrder, err = os.createOrderInTx(ctx, order)
 			if err != nil {
 				return transaction.HandleError("CreateOrder", "create order", err)
 			}
 
-			if _, err = os.createDeliveryInTx(ctx, tx, createdOrder.OrderUID, order.Delivery); err != nil {
+			if _, err = os.createDeliveryInTx(ctx, createdOrder.OrderUID, order.Delivery); err != nil {
 				return transaction.HandleError("CreateOrder", "create delivery", err)
 			}
 
-			if _, err = os.createPaymentInTx(ctx, tx, createdOrder.OrderUID, order.Payment); err != nil {
+			if _, err = os.createPaymentInTx(ctx, createdOrder.OrderUID, order.Payment); err != nil {
 				return transaction.HandleError("CreateOrder", "create payment", err)
 			}
 
-			if err = os.createItemsInTx(ctx, tx, createdOrder.OrderUID, order.Items); err != nil {
+			if err = os.createItemsInTx(ctx, createdOrder.OrderUID, order.Items); err != nil {
 				return transaction.HandleError("CreateOrder", "create items", err)
 			}
 
+			if err = os.analyticsRepo.RecordOrder(ctx, order); err != nil {
+				return transaction.HandleError("CreateOrder", "record analytics", err)
+			}
+
+			if err = os.recordEvent(ctx, entity.EventOrderCreated, order.OrderUID, order); err != nil {
+				return transaction.HandleError("CreateOrder", "record event", err)
+			}
+
+			// Caching and publishing are deferred so that an outer transaction rolling back
+			// never leaves an order in the cache or the live feed that is not in the database.
+			cached := createdOrder
+			transaction.AfterCommit(ctx, func() {
+				os.cache.Put(cached.OrderUID, cached, os.cacheTTL)
+				os.invalidateSummary(cached.CustomerID)
+				span.SetAttributes(attribute.Bool("cache.stored", true))
+				if os.publisher != nil {
+					os.publisher.Publish(cached)
+				}
+			})
+
 			return nil
 		},
 	)
@@ -256,10 +548,9 @@ func (os *OrderService) createOrderWithTransaction(
 
 func (os *OrderService) createOrderInTx(
 	ctx context.Context,
-	tx postgres.QueryExecuter,
 	order *entity.Order,
 ) (*entity.Order, error) {
-	order, err := os.orderRepo.Create(ctx, tx, order)
+	order, err := os.orderRepo.Create(ctx, order)
 	if err != nil {
 		// nolint: wrapcheck
 		return nil, err
@@ -269,11 +560,10 @@ func (os *OrderService) createOrderInTx(
 
 func (os *OrderService) createDeliveryInTx(
 	ctx context.Context,
-	tx postgres.QueryExecuter,
 	orderUID uuid.UUID,
 	delivery *entity.Delivery,
 ) (*entity.Delivery, error) {
-	delivery, err := os.deliveryRepo.Create(ctx, tx, orderUID, delivery)
+	delivery, err := os.deliveryRepo.Create(ctx, orderUID, delivery)
 	if err != nil {
 		// nolint: wrapcheck
 		return nil, err
@@ -283,11 +573,10 @@ func (os *OrderService) createDeliveryInTx(
 
 func (os *OrderService) createPaymentInTx(
 	ctx context.Context,
-	tx postgres.QueryExecuter,
 	orderUID uuid.UUID,
 	payment *entity.Payment,
 ) (*entity.Payment, error) {
-	payment, err := os.paymentRepo.Create(ctx, tx, orderUID, payment)
+	payment, err := os.paymentRepo.Create(ctx, orderUID, payment)
 	if err != nil {
 		// nolint: wrapcheck
 		return nil, err
@@ -297,19 +586,330 @@ func (os *OrderService) createPaymentInTx(
 
 func (os *OrderService) createItemsInTx(
 	ctx context.Context,
-	tx postgres.QueryExecuter,
 	orderUID uuid.UUID,
 	items []*entity.Item,
 ) error {
-	if err := os.itemRepo.Create(ctx, tx, orderUID, items); err != nil {
+	if err := os.itemRepo.Create(ctx, orderUID, items); err != nil {
 		// nolint: wrapcheck
 		return err
 	}
 	return nil
 }
 
+func (os *OrderService) UpdateOrder(
+	ctx context.Context,
+	orderUID uuid.UUID,
+	update *entity.OrderUpdate,
+	expectedVersion int,
+) (*entity.Order, error) {
+	const op = "service.UpdateOrder"
+	log := os.logger.Ctx(ctx)
+
+	if err := os.validateOrderUpdate(update); err != nil {
+		return nil, fmt.Errorf("%s: validate update: %w", op, err)
+	}
+
+	currentOrder, err := os.fetchOrderFromDB(postgres.WithPrimary(ctx), orderUID)
+	if err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+	if currentOrder.Version != expectedVersion {
+		return nil, fmt.Errorf(
+			"%s: expected version %d, current %d: %w",
+			op, expectedVersion, currentOrder.Version, entity.ErrVersionMismatch,
+		)
+	}
+
+	// The stored hash follows the order's content, so a replay of the payload the order was
+	// created from is reported as conflicting once the order has been changed.
+	updatedOrder := applyOrderUpdate(currentOrder, update)
+	if updatedOrder.PayloadHash, err = payloadHash(updatedOrder); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	snapshot, err := json.Marshal(currentOrder)
+	if err != nil {
+		return nil, fmt.Errorf("%s: marshal snapshot: %w", op, err)
+	}
+
+	err = os.txManager.ExecuteInTransaction(
+		ctx,
+		"UpdateOrder",
+		func(ctx context.Context) error {
+			version, txErr := os.orderRepo.IncrementVersion(
+				ctx, orderUID, expectedVersion, updatedOrder.PayloadHash,
+			)
+			if txErr != nil {
+				return transaction.HandleError("UpdateOrder", "increment version", txErr)
+			}
+			updatedOrder.Version = version
+
+			if update.Delivery != nil {
+				if _, txErr = os.deliveryRepo.Update(ctx, orderUID, update.Delivery); txErr != nil {
+					return transaction.HandleError("UpdateOrder", "update delivery", txErr)
+				}
+			}
+
+			if update.Payment != nil {
+				if _, txErr = os.paymentRepo.Update(ctx, orderUID, update.Payment); txErr != nil {
+					return transaction.HandleError("UpdateOrder", "update payment", txErr)
+				}
+			}
+
+			if update.Items != nil {
+				if txErr = os.itemRepo.ReplaceByOrderUID(ctx, orderUID, update.Items); txErr != nil {
+					return transaction.HandleError("UpdateOrder", "replace items", txErr)
+				}
+			}
+
+			if update.Payment != nil || update.Items != nil {
+				if txErr = os.analyticsRepo.RemoveOrder(ctx, currentOrder); txErr != nil {
+					return transaction.HandleError("UpdateOrder", "remove analytics", txErr)
+				}
+				if txErr = os.analyticsRepo.RecordOrder(ctx, updatedOrder); txErr != nil {
+					return transaction.HandleError("UpdateOrder", "record analytics", txErr)
+				}
+			}
+
+			txErr = os.auditRepo.Create(ctx, &entity.AuditRecord{
+				OrderUID: orderUID,
+				Version:  version,
+				Action:   entity.AuditActionUpdate,
+				Diff:     diffOrders(currentOrder, updatedOrder),
+				Snapshot: snapshot,
+			})
+			if txErr != nil {
+				return transaction.HandleError("UpdateOrder", "create audit record", txErr)
+			}
+
+			if txErr = os.recordEvent(ctx, entity.EventOrderUpdated, orderUID, updatedOrder); txErr != nil {
+				return transaction.HandleError("UpdateOrder", "record event", txErr)
+			}
+
+			transaction.AfterCommit(ctx, func() {
+				os.cache.Put(orderUID, updatedOrder, os.cacheTTL)
+				os.invalidateSummary(updatedOrder.CustomerID)
+			})
+
+			return nil
+		},
+	)
+	if err != nil {
+		log.LogAttrs(ctx, logger.ErrorLevel, "order update failed",
+			logger.String("op", op),
+			logger.Any("error", err),
+			logger.String("order_uid", orderUID.String()),
+		)
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "order updated successfully",
+		logger.String("op", op),
+		logger.String("order_uid", orderUID.String()),
+		logger.Int("version", updatedOrder.Version),
+	)
+
+	return updatedOrder, nil
+}
+
+func (os *OrderService) DeleteOrder(ctx context.Context, orderUID uuid.UUID) error {
+	const op = "service.DeleteOrder"
+	log := os.logger.Ctx(ctx)
+
+	currentOrder, err := os.fetchOrderFromDB(postgres.WithPrimary(ctx), orderUID)
+	if err != nil {
+		// nolint: wrapcheck
+		return err
+	}
+
+	snapshot, err := json.Marshal(currentOrder)
+	if err != nil {
+		return fmt.Errorf("%s: marshal snapshot: %w", op, err)
+	}
+
+	err = os.txManager.ExecuteInTransaction(
+		ctx,
+		"DeleteOrder",
+		func(ctx context.Context) error {
+			version, txErr := os.orderRepo.SoftDelete(ctx, orderUID)
+			if txErr != nil {
+				return transaction.HandleError("DeleteOrder", "soft delete order", txErr)
+			}
+
+			if txErr = os.analyticsRepo.RemoveOrder(ctx, currentOrder); txErr != nil {
+				return transaction.HandleError("DeleteOrder", "remove analytics", txErr)
+			}
+
+			txErr = os.auditRepo.Create(ctx, &entity.AuditRecord{
+				OrderUID: orderUID,
+				Version:  version,
+				Action:   entity.AuditActionDelete,
+				Snapshot: snapshot,
+			})
+			if txErr != nil {
+				return transaction.HandleError("DeleteOrder", "create audit record", txErr)
+			}
+
+			if txErr = os.recordEvent(ctx, entity.EventOrderDeleted, orderUID, currentOrder); txErr != nil {
+				return transaction.HandleError("DeleteOrder", "record event", txErr)
+			}
+
+			transaction.AfterCommit(ctx, func() {
+				os.cache.Delete(orderUID)
+				os.invalidateSummary(currentOrder.CustomerID)
+			})
+
+			return nil
+		},
+	)
+	if err != nil {
+		log.LogAttrs(ctx, logger.ErrorLevel, "order deletion failed",
+			logger.String("op", op),
+			logger.Any("error", err),
+			logger.String("order_uid", orderUID.String()),
+		)
+		// nolint: wrapcheck
+		return err
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "order deleted successfully",
+		logger.String("op", op),
+		logger.String("order_uid", orderUID.String()),
+	)
+
+	return nil
+}
+
+// PurgeExpiredOrders removes orders soft-deleted before cutoff; live orders are never purged.
+// Analytics rollups and summaryCache are left alone: DeleteOrder already subtracted the orders
+// from the rollups and invalidated the cached summaries.
+func (os *OrderService) PurgeExpiredOrders(
+	ctx context.Context,
+	cutoff time.Time,
+	batchSize int,
+	archive bool,
+) (int64, error) {
+	const op = "service.PurgeExpiredOrders"
+	log := os.logger.Ctx(ctx)
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, fmt.Errorf("%s: %w", op, err)
+		}
+
+		var uids []uuid.UUID
+		var deleted int64
+		err := os.txManager.ExecuteInTransaction(
+			ctx,
+			"PurgeExpiredOrders",
+			func(ctx context.Context) error {
+				var txErr error
+				uids, txErr = os.orderRepo.ListExpired(ctx, cutoff, batchSize)
+				if txErr != nil {
+					return transaction.HandleError("PurgeExpiredOrders", "list expired orders", txErr)
+				}
+				if len(uids) == 0 {
+					return nil
+				}
+
+				if archive {
+					if _, txErr = os.archiveRepo.ArchiveOrders(ctx, uids); txErr != nil {
+						return transaction.HandleError("PurgeExpiredOrders", "archive orders", txErr)
+					}
+				}
+
+				deleted, txErr = os.orderRepo.DeleteByOrderUIDs(ctx, uids)
+				if txErr != nil {
+					return transaction.HandleError("PurgeExpiredOrders", "delete orders", txErr)
+				}
+
+				purged := uids
+				transaction.AfterCommit(ctx, func() {
+					for _, uid := range purged {
+						os.cache.Delete(uid)
+					}
+				})
+
+				return nil
+			},
+		)
+		if err != nil {
+			log.LogAttrs(ctx, logger.ErrorLevel, "order purge batch failed",
+				logger.String("op", op),
+				logger.Any("error", err),
+				logger.Int64("purged", total),
+			)
+			// nolint: wrapcheck
+			return total, err
+		}
+
+		total += deleted
+
+		if len(uids) < batchSize {
+			break
+		}
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "expired orders purged",
+		logger.String("op", op),
+		logger.Int64("purged", total),
+		logger.Time("cutoff", cutoff),
+		logger.Bool("archive", archive),
+	)
+
+	return total, nil
+}
+
+func (os *OrderService) ExportOrders(
+	ctx context.Context,
+	from, to time.Time,
+	fn func(order *entity.Order) error,
+) error {
+	const op = "service.ExportOrders"
+
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return fmt.Errorf("%s: from must be before to: %w", op, entity.ErrInvalidData)
+	}
+
+	if err := os.orderRepo.StreamByDateRange(ctx, from, to, _defaultExportFetchSize, fn); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func applyOrderUpdate(order *entity.Order, update *entity.OrderUpdate) *entity.Order {
+	updated := *order
+	if update.Delivery != nil {
+		updated.Delivery = update.Delivery
+	}
+	if update.Payment != nil {
+		updated.Payment = update.Payment
+	}
+	if update.Items != nil {
+		updated.Items = update.Items
+	}
+	return &updated
+}
+
 func (os *OrderService) GetOrder(ctx context.Context, orderUID uuid.UUID) (*entity.Order, error) {
+	ctx, span := _tracer.Start(ctx, "OrderService.GetOrder",
+		trace.WithAttributes(attribute.String("order.uid", orderUID.String())),
+	)
+	defer span.End()
+
+	order, err := os.getOrder(ctx, orderUID)
+	tracing.RecordError(span, err)
+
+	return order, err
+}
+
+func (os *OrderService) getOrder(ctx context.Context, orderUID uuid.UUID) (*entity.Order, error) {
 	const op = "service.GetOrder"
+	span := trace.SpanFromContext(ctx)
 	log := os.logger.Ctx(ctx)
 
 	log.LogAttrs(ctx, logger.InfoLevel, "get order requested",
@@ -338,6 +938,7 @@ func (os *OrderService) GetOrder(ctx context.Context, orderUID uuid.UUID) (*enti
 				logger.String("order_uid", orderUID.String()),
 				logger.String("duration", duration.String()),
 			)
+			span.SetAttributes(attribute.Bool("cache.hit", true))
 			return cached, nil
 		}
 		log.LogAttrs(ctx, logger.DebugLevel, "cache contains incomplete order, fetching from DB",
@@ -349,6 +950,7 @@ func (os *OrderService) GetOrder(ctx context.Context, orderUID uuid.UUID) (*enti
 		logger.String("op", op),
 		logger.String("order_uid", orderUID.String()),
 	)
+	span.SetAttributes(attribute.Bool("cache.hit", false))
 
 	order, err := os.fetchOrderFromDB(ctx, orderUID)
 	if err != nil {
@@ -415,6 +1017,10 @@ func (os *OrderService) fetchOrderComponents(
 	var payment *entity.Payment
 	var items []*entity.Item
 	g, gCtx := errgroup.WithContext(ctx)
+	if transaction.InTransaction(ctx) {
+		// A transaction is bound to one connection, which cannot run queries concurrently.
+		g.SetLimit(1)
+	}
 
 	g.Go(func() error {
 		var err error
@@ -494,18 +1100,42 @@ func (os *OrderService) getItems(ctx context.Context, orderUID uuid.UUID) ([]*en
 	return items, nil
 }
 
-func (os *OrderService) validateOrder(order *entity.Order) error {
+func ValidateOrder(order *entity.Order) error {
 	if order.OrderUID == uuid.Nil {
 		return entity.ErrInvalidData
 	}
 	if order.Delivery == nil {
 		return entity.ErrInvalidData
 	}
-	if order.Payment == nil {
+	if order.Payment == nil || !entity.IsCurrency(order.Payment.Currency) {
 		return entity.ErrInvalidData
 	}
 	if len(order.Items) == 0 {
 		return entity.ErrInvalidData
 	}
+	for _, item := range order.Items {
+		if item == nil {
+			return entity.ErrInvalidData
+		}
+		if item.Status == entity.ItemStatusRefunded {
+			return fmt.Errorf("item status %d is reserved for refunds: %w", item.Status, entity.ErrInvalidData)
+		}
+	}
+	return nil
+}
+
+func (os *OrderService) validateOrderUpdate(update *entity.OrderUpdate) error {
+	if update == nil {
+		return entity.ErrInvalidData
+	}
+	if update.Delivery == nil && update.Payment == nil && update.Items == nil {
+		return entity.ErrInvalidData
+	}
+	if update.Items != nil && len(update.Items) == 0 {
+		return entity.ErrInvalidData
+	}
+	if update.Payment != nil && !entity.IsCurrency(update.Payment.Currency) {
+		return entity.ErrInvalidData
+	}
 	return nil
 }
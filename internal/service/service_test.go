@@ -4,6 +4,7 @@ package service_test
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -12,7 +13,7 @@ import (
 	"wbtest/internal/service"
 	mock_cache "wbtest/pkg/cache/mock"
 	mock_logger "wbtest/pkg/logger/mock"
-	"wbtest/pkg/storage/postgres"
+	"wbtest/pkg/storage/postgres/transaction"
 	mock_transaction "wbtest/pkg/storage/postgres/transaction/mock"
 
 	"github.com/brianvoe/gofakeit/v7"
@@ -38,12 +39,12 @@ func generateFakePayment() *entity.Payment {
 		RequestID:    uuid.New(),
 		Currency:     gofakeit.CurrencyShort(),
 		Provider:     gofakeit.Word(),
-		Amount:       uint64(gofakeit.UintRange(1000, 10000)),
+		Amount:       int64(gofakeit.UintRange(1000, 10000)),
 		PaymentDt:    gofakeit.DateRange(time.Now().AddDate(-1, 0, 0), time.Now()).Unix(),
 		Bank:         gofakeit.BS(),
-		DeliveryCost: uint64(gofakeit.UintRange(100, 500)),
-		GoodsTotal:   uint64(gofakeit.UintRange(500, 9000)),
-		CustomFee:    uint64(gofakeit.UintRange(0, 100)),
+		DeliveryCost: int64(gofakeit.UintRange(100, 500)),
+		GoodsTotal:   int64(gofakeit.UintRange(500, 9000)),
+		CustomFee:    int64(gofakeit.UintRange(0, 100)),
 	}
 }
 
@@ -51,12 +52,12 @@ func generateFakeItem() *entity.Item {
 	return &entity.Item{
 		ChrtID:      uint64(gofakeit.UintRange(10000, 99999)),
 		TrackNumber: gofakeit.UUID(),
-		Price:       uint64(gofakeit.UintRange(100, 1000)),
+		Price:       int64(gofakeit.UintRange(100, 1000)),
 		Rid:         uuid.New(),
 		Name:        gofakeit.ProductName(),
 		Sale:        gofakeit.Number(0, 50),
 		Size:        gofakeit.Word(),
-		TotalPrice:  uint64(gofakeit.UintRange(50, 950)),
+		TotalPrice:  int64(gofakeit.UintRange(50, 950)),
 		NMID:        uint64(gofakeit.UintRange(1000000, 9999999)),
 		Brand:       gofakeit.Company(),
 		Status:      gofakeit.Number(1, 5),
@@ -95,15 +96,14 @@ type createOrderTestInput struct {
 }
 
 type createOrderTestExpected struct {
-	order *entity.Order
-	err   error
+	order     *entity.Order
+	err       error
+	published bool
 }
 
 func TestOrderService_CreateOrder(t *testing.T) {
 	t.Parallel()
 
-	ctx := context.Background()
-
 	testCases := []struct {
 		desc  string
 		setup func() *entity.Order
@@ -135,50 +135,52 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			) {
 				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
 
-				orderRepo.EXPECT().GetByOrderUID(ctx, order.OrderUID).
-					Return(nil, entity.ErrDataNotFound).Times(1)
+				orderRepo.EXPECT().GetAnyByOrderUID(gomock.Any(), order.OrderUID).
+					Return(nil, false, entity.ErrDataNotFound).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "create order started", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "create order started", gomock.Any()).
 					Times(1)
 
 				txManager.EXPECT().ExecuteInTransaction(
-					ctx, "CreateOrder", gomock.Any(),
+					gomock.Any(), "CreateOrder", gomock.Any(),
 				).DoAndReturn(func(
-					_ context.Context,
+					txCtx context.Context,
 					_ string,
-					txFunc func(postgres.QueryExecuter) error,
+					txFunc func(context.Context) error,
+					_ ...transaction.TxOption,
 				) error {
-					return txFunc(nil)
+					return txFunc(txCtx)
 				}).Times(1)
 
-				orderRepo.EXPECT().Create(ctx, nil, gomock.Eq(order)).
+				orderRepo.EXPECT().Create(gomock.Any(), gomock.Eq(order)).
 					Return(order, nil).Times(1)
 
 				deliveryRepo.EXPECT().
-					Create(ctx, nil, order.OrderUID, gomock.Eq(order.Delivery)).
+					Create(gomock.Any(), order.OrderUID, gomock.Eq(order.Delivery)).
 					Return(order.Delivery, nil).Times(1)
 
 				paymentRepo.EXPECT().
-					Create(ctx, nil, order.OrderUID, gomock.Eq(order.Payment)).
+					Create(gomock.Any(), order.OrderUID, gomock.Eq(order.Payment)).
 					Return(order.Payment, nil).Times(1)
 
 				itemRepo.EXPECT().Create(
-					ctx, nil, gomock.Eq(order.OrderUID), gomock.Eq(order.Items),
+					gomock.Any(), gomock.Eq(order.OrderUID), gomock.Eq(order.Items),
 				).Return(nil).Times(1)
 
 				cache.EXPECT().Put(order.OrderUID, gomock.Eq(order), gomock.Any()).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "order created successfully", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "order created successfully", gomock.Any()).
 					Times(1)
 			},
 			input: createOrderTestInput{
 				order: nil,
 			},
 			expected: createOrderTestExpected{
-				order: nil,
-				err:   nil,
+				order:     nil,
+				err:       nil,
+				published: true,
 			},
 		},
 		{
@@ -196,8 +198,8 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			) {
 				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
 
-				orderRepo.EXPECT().GetByOrderUID(ctx, order.OrderUID).
-					Return(order, nil).Times(1)
+				orderRepo.EXPECT().GetAnyByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order, false, nil).Times(1)
 			},
 			input: createOrderTestInput{order: nil},
 			expected: createOrderTestExpected{
@@ -205,6 +207,104 @@ func TestOrderService_CreateOrder(t *testing.T) {
 				err:   nil,
 			},
 		},
+		{
+			desc: "DuplicateOrder_ReorderedItems",
+			setup: func() *entity.Order {
+				order := generateFakeOrder()
+				order.Items = append(order.Items, generateFakeItem())
+				return order
+			},
+			mocks: func(
+				orderRepo *mock_repository.MockOrderRepository,
+				_ *mock_repository.MockDeliveryRepository,
+				_ *mock_repository.MockPaymentRepository,
+				_ *mock_repository.MockItemRepository,
+				_ *mock_transaction.MockManager,
+				logger *mock_logger.MockLogger,
+				_ *mock_cache.MockCache[uuid.UUID, *entity.Order],
+				order *entity.Order,
+			) {
+				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+
+				stored := *order
+				stored.Items = slices.Clone(order.Items)
+				slices.Reverse(stored.Items)
+				stored.PayloadHash, _ = service.PayloadHash(&stored)
+
+				orderRepo.EXPECT().GetAnyByOrderUID(gomock.Any(), order.OrderUID).
+					Return(&stored, false, nil).Times(1)
+			},
+			input: createOrderTestInput{order: nil},
+			expected: createOrderTestExpected{
+				order: nil,
+				err:   nil,
+			},
+		},
+		{
+			desc:  "DuplicateOrder_Deleted",
+			setup: generateFakeOrder,
+			mocks: func(
+				orderRepo *mock_repository.MockOrderRepository,
+				_ *mock_repository.MockDeliveryRepository,
+				_ *mock_repository.MockPaymentRepository,
+				_ *mock_repository.MockItemRepository,
+				_ *mock_transaction.MockManager,
+				logger *mock_logger.MockLogger,
+				_ *mock_cache.MockCache[uuid.UUID, *entity.Order],
+				order *entity.Order,
+			) {
+				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+
+				orderRepo.EXPECT().GetAnyByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order, true, nil).Times(1)
+			},
+			input: createOrderTestInput{order: nil},
+			expected: createOrderTestExpected{
+				order: nil,
+				err:   entity.ErrOrderDeleted,
+			},
+		},
+		{
+			desc:  "DuplicateOrder_ConflictingPayload",
+			setup: generateFakeOrder,
+			mocks: func(
+				orderRepo *mock_repository.MockOrderRepository,
+				deliveryRepo *mock_repository.MockDeliveryRepository,
+				paymentRepo *mock_repository.MockPaymentRepository,
+				itemRepo *mock_repository.MockItemRepository,
+				_ *mock_transaction.MockManager,
+				logger *mock_logger.MockLogger,
+				_ *mock_cache.MockCache[uuid.UUID, *entity.Order],
+				order *entity.Order,
+			) {
+				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+
+				stored := *order
+				stored.PayloadHash = "stored-hash"
+				storedPayment := *order.Payment
+				storedPayment.Amount++
+
+				orderRepo.EXPECT().GetAnyByOrderUID(gomock.Any(), order.OrderUID).
+					Return(&stored, false, nil).Times(1)
+				orderRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
+					Return(&stored, nil).Times(1)
+				deliveryRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order.Delivery, nil).Times(1)
+				paymentRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
+					Return(&storedPayment, nil).Times(1)
+				itemRepo.EXPECT().GetListByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order.Items, nil).Times(1)
+
+				logger.EXPECT().
+					LogAttrs(gomock.Any(), gomock.Any(), "conflicting payload for existing order", gomock.Any()).
+					Times(1)
+			},
+			input: createOrderTestInput{order: nil},
+			expected: createOrderTestExpected{
+				order: nil,
+				err:   entity.ErrConflictingData,
+			},
+		},
 		{
 			desc: "InvalidOrder_MissingDelivery",
 			setup: func() *entity.Order {
@@ -224,15 +324,15 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			) {
 				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
 
-				orderRepo.EXPECT().GetByOrderUID(ctx, order.OrderUID).
-					Return(nil, entity.ErrDataNotFound).Times(1)
+				orderRepo.EXPECT().GetAnyByOrderUID(gomock.Any(), order.OrderUID).
+					Return(nil, false, entity.ErrDataNotFound).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "create order started", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "create order started", gomock.Any()).
 					Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "order validation failed", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "order validation failed", gomock.Any()).
 					Times(1)
 			},
 			input: createOrderTestInput{
@@ -262,15 +362,15 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			) {
 				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
 
-				orderRepo.EXPECT().GetByOrderUID(ctx, order.OrderUID).
-					Return(nil, entity.ErrDataNotFound).Times(1)
+				orderRepo.EXPECT().GetAnyByOrderUID(gomock.Any(), order.OrderUID).
+					Return(nil, false, entity.ErrDataNotFound).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "create order started", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "create order started", gomock.Any()).
 					Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "order validation failed", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "order validation failed", gomock.Any()).
 					Times(1)
 			},
 			input: createOrderTestInput{
@@ -300,15 +400,53 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			) {
 				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
 
-				orderRepo.EXPECT().GetByOrderUID(ctx, order.OrderUID).
-					Return(nil, entity.ErrDataNotFound).Times(1)
+				orderRepo.EXPECT().GetAnyByOrderUID(gomock.Any(), order.OrderUID).
+					Return(nil, false, entity.ErrDataNotFound).Times(1)
+
+				logger.EXPECT().
+					LogAttrs(gomock.Any(), gomock.Any(), "create order started", gomock.Any()).
+					Times(1)
+
+				logger.EXPECT().
+					LogAttrs(gomock.Any(), gomock.Any(), "order validation failed", gomock.Any()).
+					Times(1)
+			},
+			input: createOrderTestInput{
+				order: nil,
+			},
+			expected: createOrderTestExpected{
+				order: nil,
+				err:   entity.ErrInvalidData,
+			},
+		},
+		{
+			desc: "InvalidOrder_ReservedItemStatus",
+			setup: func() *entity.Order {
+				order := generateFakeOrder()
+				order.Items[0].Status = entity.ItemStatusRefunded
+				return order
+			},
+			mocks: func(
+				orderRepo *mock_repository.MockOrderRepository,
+				_ *mock_repository.MockDeliveryRepository,
+				_ *mock_repository.MockPaymentRepository,
+				_ *mock_repository.MockItemRepository,
+				_ *mock_transaction.MockManager,
+				logger *mock_logger.MockLogger,
+				_ *mock_cache.MockCache[uuid.UUID, *entity.Order],
+				order *entity.Order,
+			) {
+				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+
+				orderRepo.EXPECT().GetAnyByOrderUID(gomock.Any(), order.OrderUID).
+					Return(nil, false, entity.ErrDataNotFound).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "create order started", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "create order started", gomock.Any()).
 					Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "order validation failed", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "order validation failed", gomock.Any()).
 					Times(1)
 			},
 			input: createOrderTestInput{
@@ -338,15 +476,15 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			) {
 				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
 
-				orderRepo.EXPECT().GetByOrderUID(ctx, order.OrderUID).
-					Return(nil, entity.ErrDataNotFound).Times(1)
+				orderRepo.EXPECT().GetAnyByOrderUID(gomock.Any(), order.OrderUID).
+					Return(nil, false, entity.ErrDataNotFound).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "create order started", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "create order started", gomock.Any()).
 					Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "order validation failed", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "order validation failed", gomock.Any()).
 					Times(1)
 			},
 			input: createOrderTestInput{
@@ -372,19 +510,19 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			) {
 				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
 
-				orderRepo.EXPECT().GetByOrderUID(ctx, order.OrderUID).
-					Return(nil, entity.ErrDataNotFound).Times(1)
+				orderRepo.EXPECT().GetAnyByOrderUID(gomock.Any(), order.OrderUID).
+					Return(nil, false, entity.ErrDataNotFound).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "create order started", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "create order started", gomock.Any()).
 					Times(1)
 
 				txManager.EXPECT().ExecuteInTransaction(
-					ctx, "CreateOrder", gomock.Any(),
+					gomock.Any(), "CreateOrder", gomock.Any(),
 				).Return(errors.New("transaction error")).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "order creation failed", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "order creation failed", gomock.Any()).
 					Times(1)
 			},
 			input: createOrderTestInput{order: nil},
@@ -408,37 +546,38 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			) {
 				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
 
-				orderRepo.EXPECT().GetByOrderUID(ctx, order.OrderUID).
-					Return(nil, entity.ErrDataNotFound).Times(1)
+				orderRepo.EXPECT().GetAnyByOrderUID(gomock.Any(), order.OrderUID).
+					Return(nil, false, entity.ErrDataNotFound).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "create order started", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "create order started", gomock.Any()).
 					Times(1)
 
 				txManager.EXPECT().ExecuteInTransaction(
-					ctx, "CreateOrder", gomock.Any(),
+					gomock.Any(), "CreateOrder", gomock.Any(),
 				).DoAndReturn(func(
-					_ context.Context,
+					txCtx context.Context,
 					_ string,
-					txFunc func(postgres.QueryExecuter) error,
+					txFunc func(context.Context) error,
+					_ ...transaction.TxOption,
 				) error {
 					time.Sleep(300 * time.Millisecond)
-					return txFunc(nil)
+					return txFunc(txCtx)
 				}).Times(1)
 
-				orderRepo.EXPECT().Create(ctx, nil, gomock.Eq(order)).
+				orderRepo.EXPECT().Create(gomock.Any(), gomock.Eq(order)).
 					Return(order, nil).Times(1)
 
 				deliveryRepo.EXPECT().
-					Create(ctx, nil, order.OrderUID, gomock.Eq(order.Delivery)).
+					Create(gomock.Any(), order.OrderUID, gomock.Eq(order.Delivery)).
 					Return(order.Delivery, nil).Times(1)
 
 				paymentRepo.EXPECT().
-					Create(ctx, nil, order.OrderUID, gomock.Eq(order.Payment)).
+					Create(gomock.Any(), order.OrderUID, gomock.Eq(order.Payment)).
 					Return(order.Payment, nil).Times(1)
 
 				itemRepo.EXPECT().Create(
-					ctx, nil, gomock.Eq(order.OrderUID), gomock.Eq(order.Items),
+					gomock.Any(), gomock.Eq(order.OrderUID), gomock.Eq(order.Items),
 				).Return(nil).Times(1)
 
 				cache.EXPECT().Put(order.OrderUID, gomock.Eq(order), gomock.Any()).Times(1)
@@ -448,15 +587,16 @@ func TestOrderService_CreateOrder(t *testing.T) {
 					Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "order created successfully", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "order created successfully", gomock.Any()).
 					Times(1)
 			},
 			input: createOrderTestInput{
 				order: nil,
 			},
 			expected: createOrderTestExpected{
-				order: nil,
-				err:   nil,
+				order:     nil,
+				err:       nil,
+				published: true,
 			},
 		},
 	}
@@ -475,11 +615,14 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			deliveryRepo := mock_repository.NewMockDeliveryRepository(ctrl)
 			paymentRepo := mock_repository.NewMockPaymentRepository(ctrl)
 			itemRepo := mock_repository.NewMockItemRepository(ctrl)
+			auditRepo := mock_repository.NewMockAuditRepository(ctrl)
 			txManager := mock_transaction.NewMockManager(ctrl)
 			logger := mock_logger.NewMockLogger(ctrl)
 			cache := mock_cache.NewMockCache[uuid.UUID, *entity.Order](ctrl)
+			summaryCache := mock_cache.NewMockCache[string, *entity.CustomerSummary](ctrl)
 
 			cache.EXPECT().SetOnEvicted(gomock.Any()).AnyTimes()
+			summaryCache.EXPECT().Delete(gomock.Any()).Return(true).AnyTimes()
 
 			tc.mocks(
 				orderRepo,
@@ -492,16 +635,32 @@ func TestOrderService_CreateOrder(t *testing.T) {
 				order,
 			)
 
-			s := service.NewOrderService(
-				deliveryRepo,
-				itemRepo,
-				orderRepo,
-				paymentRepo,
-				txManager,
-				logger,
-				cache,
-				time.Minute*5,
-			)
+			publisher := mock_repository.NewMockOrderPublisher(ctrl)
+			if tc.expected.published {
+				publisher.EXPECT().Publish(gomock.Eq(order)).Times(1)
+			}
+
+			s := service.NewOrderService(service.OrderServiceDeps{
+				DeliveryRepo:  deliveryRepo,
+				ItemRepo:      itemRepo,
+				OrderRepo:     orderRepo,
+				PaymentRepo:   paymentRepo,
+				AuditRepo:     auditRepo,
+				ArchiveRepo:   mock_repository.NewMockArchiveRepository(ctrl),
+				SearchRepo:    mock_repository.NewMockSearchRepository(ctrl),
+				CustomerRepo:  mock_repository.NewMockCustomerRepository(ctrl),
+				AnalyticsRepo: newAnalyticsRepoMock(ctrl),
+				RefundRepo:    mock_repository.NewMockRefundRepository(ctrl),
+				EventRepo:     newEventRepoMock(ctrl),
+				WebhookQueue:  mock_repository.NewMockWebhookQueue(ctrl),
+				Publisher:     publisher,
+				TxManager:     txManager,
+				Logger:        logger,
+				Cache:         cache,
+				CacheTTL:      time.Minute * 5,
+				SummaryCache:  summaryCache,
+				SummaryTTL:    time.Minute,
+			})
 
 			resultOrder, err := s.CreateOrder(context.Background(), tc.input.order)
 
@@ -549,8 +708,6 @@ type getOrderTestExpected struct {
 func TestOrderService_GetOrder(t *testing.T) {
 	t.Parallel()
 
-	ctx := context.Background()
-
 	testCases := []struct {
 		desc  string
 		setup func() *entity.Order
@@ -583,14 +740,14 @@ func TestOrderService_GetOrder(t *testing.T) {
 				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "get order requested", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "get order requested", gomock.Any()).
 					Times(1)
 
 				cache.EXPECT().Get(order.OrderUID).
 					Return(order, true).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "order served from cache", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "order served from cache", gomock.Any()).
 					Times(1)
 			},
 			input: func() getOrderTestInput {
@@ -617,14 +774,14 @@ func TestOrderService_GetOrder(t *testing.T) {
 				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "get order requested", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "get order requested", gomock.Any()).
 					Times(1)
 
 				cache.EXPECT().Get(order.OrderUID).
 					Return(nil, false).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "cache miss", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "cache miss", gomock.Any()).
 					Times(1)
 
 				orderRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
@@ -639,7 +796,7 @@ func TestOrderService_GetOrder(t *testing.T) {
 				cache.EXPECT().Put(order.OrderUID, gomock.Eq(order), gomock.Any()).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "order served from database", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "order served from database", gomock.Any()).
 					Times(1)
 			},
 			input: func() getOrderTestInput {
@@ -670,14 +827,14 @@ func TestOrderService_GetOrder(t *testing.T) {
 				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "get order requested", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "get order requested", gomock.Any()).
 					Times(1)
 
 				cache.EXPECT().Get(order.OrderUID).
 					Return(nil, false).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "cache miss", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "cache miss", gomock.Any()).
 					Times(1)
 
 				orderRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
@@ -693,7 +850,7 @@ func TestOrderService_GetOrder(t *testing.T) {
 					Return(order.Items, nil).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "failed to get order from database", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "failed to get order from database", gomock.Any()).
 					Times(1)
 			},
 			input: func() getOrderTestInput {
@@ -720,21 +877,21 @@ func TestOrderService_GetOrder(t *testing.T) {
 				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "get order requested", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "get order requested", gomock.Any()).
 					Times(1)
 
 				cache.EXPECT().Get(order.OrderUID).
 					Return(nil, false).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "cache miss", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "cache miss", gomock.Any()).
 					Times(1)
 
 				orderRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
 					Return(nil, entity.ErrDataNotFound).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "failed to get order from database", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "failed to get order from database", gomock.Any()).
 					Times(1)
 			},
 			input: func() getOrderTestInput {
@@ -761,21 +918,21 @@ func TestOrderService_GetOrder(t *testing.T) {
 				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "get order requested", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "get order requested", gomock.Any()).
 					Times(1)
 
 				cache.EXPECT().Get(order.OrderUID).
 					Return(nil, false).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "cache miss", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "cache miss", gomock.Any()).
 					Times(1)
 
 				orderRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
 					Return(nil, errors.New("database error")).Times(1)
 
 				logger.EXPECT().
-					LogAttrs(ctx, gomock.Any(), "failed to get order from database", gomock.Any()).
+					LogAttrs(gomock.Any(), gomock.Any(), "failed to get order from database", gomock.Any()).
 					Times(1)
 			},
 			input: func() getOrderTestInput {
@@ -862,11 +1019,14 @@ func TestOrderService_GetOrder(t *testing.T) {
 			deliveryRepo := mock_repository.NewMockDeliveryRepository(ctrl)
 			paymentRepo := mock_repository.NewMockPaymentRepository(ctrl)
 			itemRepo := mock_repository.NewMockItemRepository(ctrl)
+			auditRepo := mock_repository.NewMockAuditRepository(ctrl)
 			txManager := mock_transaction.NewMockManager(ctrl)
 			logger := mock_logger.NewMockLogger(ctrl)
 			cache := mock_cache.NewMockCache[uuid.UUID, *entity.Order](ctrl)
+			summaryCache := mock_cache.NewMockCache[string, *entity.CustomerSummary](ctrl)
 
 			cache.EXPECT().SetOnEvicted(gomock.Any()).AnyTimes()
+			summaryCache.EXPECT().Delete(gomock.Any()).Return(true).AnyTimes()
 
 			tc.mocks(
 				orderRepo,
@@ -879,16 +1039,26 @@ func TestOrderService_GetOrder(t *testing.T) {
 				order,
 			)
 
-			s := service.NewOrderService(
-				deliveryRepo,
-				itemRepo,
-				orderRepo,
-				paymentRepo,
-				txManager,
-				logger,
-				cache,
-				time.Minute*5,
-			)
+			s := service.NewOrderService(service.OrderServiceDeps{
+				DeliveryRepo:  deliveryRepo,
+				ItemRepo:      itemRepo,
+				OrderRepo:     orderRepo,
+				PaymentRepo:   paymentRepo,
+				AuditRepo:     auditRepo,
+				ArchiveRepo:   mock_repository.NewMockArchiveRepository(ctrl),
+				SearchRepo:    mock_repository.NewMockSearchRepository(ctrl),
+				CustomerRepo:  mock_repository.NewMockCustomerRepository(ctrl),
+				AnalyticsRepo: newAnalyticsRepoMock(ctrl),
+				RefundRepo:    mock_repository.NewMockRefundRepository(ctrl),
+				EventRepo:     newEventRepoMock(ctrl),
+				WebhookQueue:  mock_repository.NewMockWebhookQueue(ctrl),
+				TxManager:     txManager,
+				Logger:        logger,
+				Cache:         cache,
+				CacheTTL:      time.Minute * 5,
+				SummaryCache:  summaryCache,
+				SummaryTTL:    time.Minute,
+			})
 
 			resultOrder, err := s.GetOrder(context.Background(), tc.input.orderUID)
 
@@ -914,3 +1084,548 @@ func TestOrderService_GetOrder(t *testing.T) {
 		})
 	}
 }
+
+type updateOrderTestInput struct {
+	update          *entity.OrderUpdate
+	expectedVersion int
+}
+
+type updateOrderTestExpected struct {
+	version int
+	err     error
+}
+
+func TestOrderService_UpdateOrder(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	testCases := []struct {
+		desc  string
+		setup func() *entity.Order
+		mocks func(
+			orderRepo *mock_repository.MockOrderRepository,
+			deliveryRepo *mock_repository.MockDeliveryRepository,
+			paymentRepo *mock_repository.MockPaymentRepository,
+			itemRepo *mock_repository.MockItemRepository,
+			auditRepo *mock_repository.MockAuditRepository,
+			txManager *mock_transaction.MockManager,
+			logger *mock_logger.MockLogger,
+			cache *mock_cache.MockCache[uuid.UUID, *entity.Order],
+			order *entity.Order,
+			input updateOrderTestInput,
+		)
+		input    updateOrderTestInput
+		expected updateOrderTestExpected
+	}{
+		{
+			desc: "Success",
+			setup: func() *entity.Order {
+				order := generateFakeOrder()
+				order.Version = 1
+				return order
+			},
+			mocks: func(
+				orderRepo *mock_repository.MockOrderRepository,
+				deliveryRepo *mock_repository.MockDeliveryRepository,
+				paymentRepo *mock_repository.MockPaymentRepository,
+				itemRepo *mock_repository.MockItemRepository,
+				auditRepo *mock_repository.MockAuditRepository,
+				txManager *mock_transaction.MockManager,
+				logger *mock_logger.MockLogger,
+				cache *mock_cache.MockCache[uuid.UUID, *entity.Order],
+				order *entity.Order,
+				input updateOrderTestInput,
+			) {
+				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+
+				orderRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order, nil).Times(1)
+				deliveryRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order.Delivery, nil).Times(1)
+				paymentRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order.Payment, nil).Times(1)
+				itemRepo.EXPECT().GetListByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order.Items, nil).Times(1)
+
+				txManager.EXPECT().ExecuteInTransaction(
+					ctx, "UpdateOrder", gomock.Any(),
+				).DoAndReturn(func(
+					txCtx context.Context,
+					_ string,
+					txFunc func(context.Context) error,
+					_ ...transaction.TxOption,
+				) error {
+					return txFunc(txCtx)
+				}).Times(1)
+
+				updated := *order
+				updated.Delivery = input.update.Delivery
+				updated.Items = input.update.Items
+				hash, _ := service.PayloadHash(&updated)
+
+				orderRepo.EXPECT().IncrementVersion(ctx, order.OrderUID, 1, hash).
+					Return(2, nil).Times(1)
+				deliveryRepo.EXPECT().
+					Update(ctx, order.OrderUID, gomock.Eq(input.update.Delivery)).
+					Return(input.update.Delivery, nil).Times(1)
+				itemRepo.EXPECT().
+					ReplaceByOrderUID(ctx, order.OrderUID, gomock.Eq(input.update.Items)).
+					Return(nil).Times(1)
+				auditRepo.EXPECT().Create(ctx, gomock.Any()).
+					DoAndReturn(func(
+						_ context.Context,
+						record *entity.AuditRecord,
+					) error {
+						if record.Version != 2 || record.Action != entity.AuditActionUpdate {
+							t.Errorf("unexpected audit record: %+v", record)
+						}
+						return nil
+					}).Times(1)
+
+				cache.EXPECT().Put(order.OrderUID, gomock.Any(), gomock.Any()).Times(1)
+
+				logger.EXPECT().
+					LogAttrs(ctx, gomock.Any(), "order updated successfully", gomock.Any()).
+					Times(1)
+			},
+			input: updateOrderTestInput{
+				update: &entity.OrderUpdate{
+					Delivery: generateFakeDelivery(),
+					Items:    []*entity.Item{generateFakeItem()},
+				},
+				expectedVersion: 1,
+			},
+			expected: updateOrderTestExpected{
+				version: 2,
+				err:     nil,
+			},
+		},
+		{
+			desc: "StaleVersion",
+			setup: func() *entity.Order {
+				order := generateFakeOrder()
+				order.Version = 3
+				return order
+			},
+			mocks: func(
+				orderRepo *mock_repository.MockOrderRepository,
+				deliveryRepo *mock_repository.MockDeliveryRepository,
+				paymentRepo *mock_repository.MockPaymentRepository,
+				itemRepo *mock_repository.MockItemRepository,
+				_ *mock_repository.MockAuditRepository,
+				_ *mock_transaction.MockManager,
+				logger *mock_logger.MockLogger,
+				_ *mock_cache.MockCache[uuid.UUID, *entity.Order],
+				order *entity.Order,
+				_ updateOrderTestInput,
+			) {
+				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+
+				orderRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order, nil).Times(1)
+				deliveryRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order.Delivery, nil).Times(1)
+				paymentRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order.Payment, nil).Times(1)
+				itemRepo.EXPECT().GetListByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order.Items, nil).Times(1)
+			},
+			input: updateOrderTestInput{
+				update:          &entity.OrderUpdate{Payment: generateFakePayment()},
+				expectedVersion: 2,
+			},
+			expected: updateOrderTestExpected{
+				err: entity.ErrVersionMismatch,
+			},
+		},
+		{
+			desc:  "EmptyUpdate",
+			setup: generateFakeOrder,
+			mocks: func(
+				_ *mock_repository.MockOrderRepository,
+				_ *mock_repository.MockDeliveryRepository,
+				_ *mock_repository.MockPaymentRepository,
+				_ *mock_repository.MockItemRepository,
+				_ *mock_repository.MockAuditRepository,
+				_ *mock_transaction.MockManager,
+				logger *mock_logger.MockLogger,
+				_ *mock_cache.MockCache[uuid.UUID, *entity.Order],
+				_ *entity.Order,
+				_ updateOrderTestInput,
+			) {
+				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+			},
+			input: updateOrderTestInput{
+				update:          &entity.OrderUpdate{},
+				expectedVersion: 1,
+			},
+			expected: updateOrderTestExpected{
+				err: entity.ErrInvalidData,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			order := tc.setup()
+
+			orderRepo := mock_repository.NewMockOrderRepository(ctrl)
+			deliveryRepo := mock_repository.NewMockDeliveryRepository(ctrl)
+			paymentRepo := mock_repository.NewMockPaymentRepository(ctrl)
+			itemRepo := mock_repository.NewMockItemRepository(ctrl)
+			auditRepo := mock_repository.NewMockAuditRepository(ctrl)
+			txManager := mock_transaction.NewMockManager(ctrl)
+			logger := mock_logger.NewMockLogger(ctrl)
+			cache := mock_cache.NewMockCache[uuid.UUID, *entity.Order](ctrl)
+			summaryCache := mock_cache.NewMockCache[string, *entity.CustomerSummary](ctrl)
+
+			cache.EXPECT().SetOnEvicted(gomock.Any()).AnyTimes()
+			summaryCache.EXPECT().Delete(gomock.Any()).Return(true).AnyTimes()
+
+			tc.mocks(
+				orderRepo,
+				deliveryRepo,
+				paymentRepo,
+				itemRepo,
+				auditRepo,
+				txManager,
+				logger,
+				cache,
+				order,
+				tc.input,
+			)
+
+			s := service.NewOrderService(service.OrderServiceDeps{
+				DeliveryRepo:  deliveryRepo,
+				ItemRepo:      itemRepo,
+				OrderRepo:     orderRepo,
+				PaymentRepo:   paymentRepo,
+				AuditRepo:     auditRepo,
+				ArchiveRepo:   mock_repository.NewMockArchiveRepository(ctrl),
+				SearchRepo:    mock_repository.NewMockSearchRepository(ctrl),
+				CustomerRepo:  mock_repository.NewMockCustomerRepository(ctrl),
+				AnalyticsRepo: newAnalyticsRepoMock(ctrl),
+				RefundRepo:    mock_repository.NewMockRefundRepository(ctrl),
+				EventRepo:     newEventRepoMock(ctrl),
+				WebhookQueue:  mock_repository.NewMockWebhookQueue(ctrl),
+				TxManager:     txManager,
+				Logger:        logger,
+				Cache:         cache,
+				CacheTTL:      time.Minute * 5,
+				SummaryCache:  summaryCache,
+				SummaryTTL:    time.Minute,
+			})
+
+			resultOrder, err := s.UpdateOrder(ctx, order.OrderUID, tc.input.update, tc.input.expectedVersion)
+
+			if tc.expected.err != nil {
+				if !errors.Is(err, tc.expected.err) {
+					t.Fatalf("expected error %v, got %v", tc.expected.err, err)
+				}
+				if resultOrder != nil {
+					t.Error("expected nil order on error, got non-nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if resultOrder.Version != tc.expected.version {
+				t.Fatalf("expected version %d, got %d", tc.expected.version, resultOrder.Version)
+			}
+			if resultOrder.Delivery != tc.input.update.Delivery {
+				t.Error("expected delivery to be replaced")
+			}
+		})
+	}
+}
+
+func TestOrderService_DeleteOrder(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	testCases := []struct {
+		desc  string
+		mocks func(
+			orderRepo *mock_repository.MockOrderRepository,
+			deliveryRepo *mock_repository.MockDeliveryRepository,
+			paymentRepo *mock_repository.MockPaymentRepository,
+			itemRepo *mock_repository.MockItemRepository,
+			auditRepo *mock_repository.MockAuditRepository,
+			txManager *mock_transaction.MockManager,
+			logger *mock_logger.MockLogger,
+			cache *mock_cache.MockCache[uuid.UUID, *entity.Order],
+			order *entity.Order,
+		)
+		expectedErr error
+	}{
+		{
+			desc: "Success",
+			mocks: func(
+				orderRepo *mock_repository.MockOrderRepository,
+				deliveryRepo *mock_repository.MockDeliveryRepository,
+				paymentRepo *mock_repository.MockPaymentRepository,
+				itemRepo *mock_repository.MockItemRepository,
+				auditRepo *mock_repository.MockAuditRepository,
+				txManager *mock_transaction.MockManager,
+				logger *mock_logger.MockLogger,
+				cache *mock_cache.MockCache[uuid.UUID, *entity.Order],
+				order *entity.Order,
+			) {
+				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+
+				orderRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order, nil).Times(1)
+				deliveryRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order.Delivery, nil).Times(1)
+				paymentRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order.Payment, nil).Times(1)
+				itemRepo.EXPECT().GetListByOrderUID(gomock.Any(), order.OrderUID).
+					Return(order.Items, nil).Times(1)
+
+				txManager.EXPECT().ExecuteInTransaction(
+					ctx, "DeleteOrder", gomock.Any(),
+				).DoAndReturn(func(
+					txCtx context.Context,
+					_ string,
+					txFunc func(context.Context) error,
+					_ ...transaction.TxOption,
+				) error {
+					return txFunc(txCtx)
+				}).Times(1)
+
+				orderRepo.EXPECT().SoftDelete(ctx, order.OrderUID).
+					Return(2, nil).Times(1)
+				auditRepo.EXPECT().Create(ctx, gomock.Any()).
+					DoAndReturn(func(
+						_ context.Context,
+						record *entity.AuditRecord,
+					) error {
+						if record.Version != 2 || record.Action != entity.AuditActionDelete {
+							t.Errorf("unexpected audit record: %+v", record)
+						}
+						return nil
+					}).Times(1)
+
+				cache.EXPECT().Delete(order.OrderUID).Return(true).Times(1)
+
+				logger.EXPECT().
+					LogAttrs(ctx, gomock.Any(), "order deleted successfully", gomock.Any()).
+					Times(1)
+			},
+			expectedErr: nil,
+		},
+		{
+			desc: "OrderNotFound",
+			mocks: func(
+				orderRepo *mock_repository.MockOrderRepository,
+				_ *mock_repository.MockDeliveryRepository,
+				_ *mock_repository.MockPaymentRepository,
+				_ *mock_repository.MockItemRepository,
+				_ *mock_repository.MockAuditRepository,
+				_ *mock_transaction.MockManager,
+				logger *mock_logger.MockLogger,
+				_ *mock_cache.MockCache[uuid.UUID, *entity.Order],
+				order *entity.Order,
+			) {
+				logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+
+				orderRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).
+					Return(nil, entity.ErrDataNotFound).Times(1)
+			},
+			expectedErr: entity.ErrDataNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			order := generateFakeOrder()
+
+			orderRepo := mock_repository.NewMockOrderRepository(ctrl)
+			deliveryRepo := mock_repository.NewMockDeliveryRepository(ctrl)
+			paymentRepo := mock_repository.NewMockPaymentRepository(ctrl)
+			itemRepo := mock_repository.NewMockItemRepository(ctrl)
+			auditRepo := mock_repository.NewMockAuditRepository(ctrl)
+			txManager := mock_transaction.NewMockManager(ctrl)
+			logger := mock_logger.NewMockLogger(ctrl)
+			cache := mock_cache.NewMockCache[uuid.UUID, *entity.Order](ctrl)
+			summaryCache := mock_cache.NewMockCache[string, *entity.CustomerSummary](ctrl)
+
+			cache.EXPECT().SetOnEvicted(gomock.Any()).AnyTimes()
+			summaryCache.EXPECT().Delete(gomock.Any()).Return(true).AnyTimes()
+
+			tc.mocks(
+				orderRepo,
+				deliveryRepo,
+				paymentRepo,
+				itemRepo,
+				auditRepo,
+				txManager,
+				logger,
+				cache,
+				order,
+			)
+
+			s := service.NewOrderService(service.OrderServiceDeps{
+				DeliveryRepo:  deliveryRepo,
+				ItemRepo:      itemRepo,
+				OrderRepo:     orderRepo,
+				PaymentRepo:   paymentRepo,
+				AuditRepo:     auditRepo,
+				ArchiveRepo:   mock_repository.NewMockArchiveRepository(ctrl),
+				SearchRepo:    mock_repository.NewMockSearchRepository(ctrl),
+				CustomerRepo:  mock_repository.NewMockCustomerRepository(ctrl),
+				AnalyticsRepo: newAnalyticsRepoMock(ctrl),
+				RefundRepo:    mock_repository.NewMockRefundRepository(ctrl),
+				EventRepo:     newEventRepoMock(ctrl),
+				WebhookQueue:  mock_repository.NewMockWebhookQueue(ctrl),
+				TxManager:     txManager,
+				Logger:        logger,
+				Cache:         cache,
+				CacheTTL:      time.Minute * 5,
+				SummaryCache:  summaryCache,
+				SummaryTTL:    time.Minute,
+			})
+
+			err := s.DeleteOrder(ctx, order.OrderUID)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestOrderService_PurgeExpiredOrders(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cutoff := time.Now().Add(-24 * time.Hour)
+
+	testCases := []struct {
+		desc      string
+		batchSize int
+		archive   bool
+		batches   [][]uuid.UUID
+		expected  int64
+	}{
+		{
+			desc:      "ArchiveInBatches",
+			batchSize: 2,
+			archive:   true,
+			batches: [][]uuid.UUID{
+				{uuid.New(), uuid.New()},
+				{uuid.New()},
+			},
+			expected: 3,
+		},
+		{
+			desc:      "DeleteWithoutArchive",
+			batchSize: 10,
+			archive:   false,
+			batches: [][]uuid.UUID{
+				{uuid.New(), uuid.New()},
+			},
+			expected: 2,
+		},
+		{
+			desc:      "NothingExpired",
+			batchSize: 10,
+			archive:   true,
+			batches:   [][]uuid.UUID{{}},
+			expected:  0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			orderRepo := mock_repository.NewMockOrderRepository(ctrl)
+			archiveRepo := mock_repository.NewMockArchiveRepository(ctrl)
+			txManager := mock_transaction.NewMockManager(ctrl)
+			logger := mock_logger.NewMockLogger(ctrl)
+			cache := mock_cache.NewMockCache[uuid.UUID, *entity.Order](ctrl)
+			summaryCache := mock_cache.NewMockCache[string, *entity.CustomerSummary](ctrl)
+
+			cache.EXPECT().SetOnEvicted(gomock.Any()).AnyTimes()
+			summaryCache.EXPECT().Delete(gomock.Any()).Return(true).AnyTimes()
+			logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+			logger.EXPECT().
+				LogAttrs(ctx, gomock.Any(), "expired orders purged", gomock.Any()).
+				Times(1)
+
+			txManager.EXPECT().ExecuteInTransaction(
+				ctx, "PurgeExpiredOrders", gomock.Any(),
+			).DoAndReturn(func(
+				txCtx context.Context,
+				_ string,
+				txFunc func(context.Context) error,
+				_ ...transaction.TxOption,
+			) error {
+				return txFunc(txCtx)
+			}).Times(len(tc.batches))
+
+			for _, batch := range tc.batches {
+				orderRepo.EXPECT().ListExpired(ctx, cutoff, tc.batchSize).
+					Return(batch, nil).Times(1)
+				if len(batch) == 0 {
+					continue
+				}
+				if tc.archive {
+					archiveRepo.EXPECT().ArchiveOrders(ctx, batch).
+						Return(int64(len(batch)), nil).Times(1)
+				}
+				orderRepo.EXPECT().DeleteByOrderUIDs(ctx, batch).
+					Return(int64(len(batch)), nil).Times(1)
+				for _, uid := range batch {
+					cache.EXPECT().Delete(uid).Return(false).Times(1)
+				}
+			}
+
+			s := service.NewOrderService(service.OrderServiceDeps{
+				DeliveryRepo:  mock_repository.NewMockDeliveryRepository(ctrl),
+				ItemRepo:      mock_repository.NewMockItemRepository(ctrl),
+				OrderRepo:     orderRepo,
+				PaymentRepo:   mock_repository.NewMockPaymentRepository(ctrl),
+				AuditRepo:     mock_repository.NewMockAuditRepository(ctrl),
+				ArchiveRepo:   archiveRepo,
+				SearchRepo:    mock_repository.NewMockSearchRepository(ctrl),
+				CustomerRepo:  mock_repository.NewMockCustomerRepository(ctrl),
+				AnalyticsRepo: newAnalyticsRepoMock(ctrl),
+				RefundRepo:    mock_repository.NewMockRefundRepository(ctrl),
+				EventRepo:     newEventRepoMock(ctrl),
+				WebhookQueue:  mock_repository.NewMockWebhookQueue(ctrl),
+				TxManager:     txManager,
+				Logger:        logger,
+				Cache:         cache,
+				CacheTTL:      time.Minute * 5,
+				SummaryCache:  summaryCache,
+				SummaryTTL:    time.Minute,
+			})
+
+			purged, err := s.PurgeExpiredOrders(ctx, cutoff, tc.batchSize, tc.archive)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if purged != tc.expected {
+				t.Fatalf("expected %d purged orders, got %d", tc.expected, purged)
+			}
+		})
+	}
+}
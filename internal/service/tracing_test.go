@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"wbtest/internal/entity"
+	mock_repository "wbtest/internal/repository/mock"
+	"wbtest/internal/service"
+	mock_cache "wbtest/pkg/cache/mock"
+	mock_logger "wbtest/pkg/logger/mock"
+	mock_transaction "wbtest/pkg/storage/postgres/transaction/mock"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	spanExporter     *tracetest.InMemoryExporter
+	spanExporterOnce sync.Once
+)
+
+// tracedSpans installs a global provider backed by an in-memory exporter once per test binary,
+// since tracers created from the global provider stay bound to the first one installed.
+func tracedSpans() *tracetest.InMemoryExporter {
+	spanExporterOnce.Do(func() {
+		spanExporter = tracetest.NewInMemoryExporter()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanExporter)))
+	})
+	return spanExporter
+}
+
+func findSpan(t *testing.T, exporter *tracetest.InMemoryExporter, name, orderUID string) tracetest.SpanStub {
+	t.Helper()
+
+	for _, span := range exporter.GetSpans() {
+		if span.Name != name {
+			continue
+		}
+		for _, attr := range span.Attributes {
+			if attr.Key == "order.uid" && attr.Value.AsString() == orderUID {
+				return span
+			}
+		}
+	}
+	t.Fatalf("span %q for order %s not recorded", name, orderUID)
+	return tracetest.SpanStub{}
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestOrderService_GetOrder_Tracing(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracedSpans()
+
+	testCases := []struct {
+		desc      string
+		cacheHit  bool
+		dbErr     error
+		wantError bool
+	}{
+		{desc: "CacheHit", cacheHit: true},
+		{desc: "CacheMiss"},
+		{desc: "NotFound", dbErr: entity.ErrDataNotFound, wantError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			order := generateFakeOrder()
+
+			orderRepo := mock_repository.NewMockOrderRepository(ctrl)
+			deliveryRepo := mock_repository.NewMockDeliveryRepository(ctrl)
+			paymentRepo := mock_repository.NewMockPaymentRepository(ctrl)
+			itemRepo := mock_repository.NewMockItemRepository(ctrl)
+			logger := mock_logger.NewMockLogger(ctrl)
+			cache := mock_cache.NewMockCache[uuid.UUID, *entity.Order](ctrl)
+			summaryCache := mock_cache.NewMockCache[string, *entity.CustomerSummary](ctrl)
+
+			cache.EXPECT().SetOnEvicted(gomock.Any()).AnyTimes()
+			summaryCache.EXPECT().Delete(gomock.Any()).Return(true).AnyTimes()
+			logger.EXPECT().Ctx(gomock.Any()).Return(logger).AnyTimes()
+			logger.EXPECT().LogAttrs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+			switch {
+			case tc.cacheHit:
+				cache.EXPECT().Get(order.OrderUID).Return(order, true)
+			case tc.dbErr != nil:
+				cache.EXPECT().Get(order.OrderUID).Return(nil, false)
+				orderRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).Return(nil, tc.dbErr)
+				deliveryRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).Return(order.Delivery, nil).AnyTimes()
+				paymentRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).Return(order.Payment, nil).AnyTimes()
+				itemRepo.EXPECT().GetListByOrderUID(gomock.Any(), order.OrderUID).Return(order.Items, nil).AnyTimes()
+			default:
+				cache.EXPECT().Get(order.OrderUID).Return(nil, false)
+				orderRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).Return(order, nil)
+				deliveryRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).Return(order.Delivery, nil)
+				paymentRepo.EXPECT().GetByOrderUID(gomock.Any(), order.OrderUID).Return(order.Payment, nil)
+				itemRepo.EXPECT().GetListByOrderUID(gomock.Any(), order.OrderUID).Return(order.Items, nil)
+				cache.EXPECT().Put(order.OrderUID, order, gomock.Any())
+			}
+
+			s := service.NewOrderService(service.OrderServiceDeps{
+				DeliveryRepo:  deliveryRepo,
+				ItemRepo:      itemRepo,
+				OrderRepo:     orderRepo,
+				PaymentRepo:   paymentRepo,
+				AuditRepo:     mock_repository.NewMockAuditRepository(ctrl),
+				ArchiveRepo:   mock_repository.NewMockArchiveRepository(ctrl),
+				SearchRepo:    mock_repository.NewMockSearchRepository(ctrl),
+				CustomerRepo:  mock_repository.NewMockCustomerRepository(ctrl),
+				AnalyticsRepo: newAnalyticsRepoMock(ctrl),
+				RefundRepo:    mock_repository.NewMockRefundRepository(ctrl),
+				EventRepo:     newEventRepoMock(ctrl),
+				WebhookQueue:  mock_repository.NewMockWebhookQueue(ctrl),
+				TxManager:     mock_transaction.NewMockManager(ctrl),
+				Logger:        logger,
+				Cache:         cache,
+				CacheTTL:      time.Minute,
+				SummaryCache:  summaryCache,
+				SummaryTTL:    time.Minute,
+			})
+
+			_, err := s.GetOrder(context.Background(), order.OrderUID)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			span := findSpan(t, exporter, "OrderService.GetOrder", order.OrderUID.String())
+
+			hit, ok := spanAttribute(span, "cache.hit")
+			if !ok || hit.AsBool() != tc.cacheHit {
+				t.Fatalf("expected cache.hit=%t, got %v (set=%t)", tc.cacheHit, hit.AsBool(), ok)
+			}
+			if tc.wantError && span.Status.Code != codes.Error {
+				t.Fatalf("expected error status, got %v", span.Status.Code)
+			}
+			if !tc.wantError && span.Status.Code == codes.Error {
+				t.Fatalf("unexpected error status: %s", span.Status.Description)
+			}
+		})
+	}
+}
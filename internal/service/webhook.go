@@ -0,0 +1,428 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/logger"
+	"wbtest/pkg/storage/postgres"
+	"wbtest/pkg/storage/postgres/transaction"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	_maxWebhookURLLength = 2048
+	_minSecretLength     = 16
+	_maxSecretLength     = 256
+	_generatedSecretSize = 32
+)
+
+// WebhookPolicy controls retries. A failed delivery is retried after BaseRetryDelay, doubling
+// up to MaxRetryDelay, until it has been attempted MaxAttempts times. A subscription is
+// disabled after DisableAfter failed attempts in a row. Claimed deliveries are leased for
+// Lease, which has to cover sending a whole batch.
+type WebhookPolicy struct {
+	MaxAttempts    int
+	BaseRetryDelay time.Duration
+	MaxRetryDelay  time.Duration
+	DisableAfter   int
+	Lease          time.Duration
+}
+
+// WebhookService manages webhook subscriptions and sends the deliveries queued for them by
+// OrderService.RelayEvents.
+type WebhookService struct {
+	repo      WebhookRepository
+	txManager transaction.Manager
+	logger    logger.Logger
+	policy    WebhookPolicy
+}
+
+func NewWebhookService(
+	repo WebhookRepository,
+	txManager transaction.Manager,
+	logger logger.Logger,
+	policy WebhookPolicy,
+) *WebhookService {
+	return &WebhookService{
+		repo:      repo,
+		txManager: txManager,
+		logger:    logger,
+		policy:    policy,
+	}
+}
+
+// CreateSubscription stores a new subscription. The result carries the secret, generated when
+// none was given; later reads never return it.
+func (ws *WebhookService) CreateSubscription(
+	ctx context.Context,
+	req *entity.WebhookSubscriptionRequest,
+) (*entity.WebhookSubscription, error) {
+	const op = "service.WebhookService.CreateSubscription"
+	log := ws.logger.Ctx(ctx)
+
+	if err := validateSubscriptionRequest(req); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateSecret(); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	sub := &entity.WebhookSubscription{
+		SubscriptionID: uuid.New(),
+		URL:            req.URL,
+		EventTypes:     req.EventTypes,
+		Secret:         secret,
+		Enabled:        req.Enabled == nil || *req.Enabled,
+	}
+
+	var created *entity.WebhookSubscription
+	err := ws.txManager.ExecuteInTransaction(
+		ctx,
+		"CreateWebhookSubscription",
+		func(ctx context.Context) error {
+			var txErr error
+			created, txErr = ws.repo.CreateSubscription(ctx, sub)
+			return transaction.HandleError("CreateWebhookSubscription", "create subscription", txErr)
+		},
+	)
+	if err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "webhook subscription created",
+		logger.String("op", op),
+		logger.String("subscription_id", created.SubscriptionID.String()),
+		logger.Any("event_types", created.EventTypes),
+	)
+
+	return created, nil
+}
+
+// UpdateSubscription replaces the subscription's URL, event types and state. The secret is
+// rotated only when a new one is given, and only then returned.
+func (ws *WebhookService) UpdateSubscription(
+	ctx context.Context,
+	subscriptionID uuid.UUID,
+	req *entity.WebhookSubscriptionRequest,
+) (*entity.WebhookSubscription, error) {
+	const op = "service.WebhookService.UpdateSubscription"
+	log := ws.logger.Ctx(ctx)
+
+	if err := validateSubscriptionRequest(req); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	current, err := ws.repo.GetSubscription(postgres.WithPrimary(ctx), subscriptionID)
+	if err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	sub := &entity.WebhookSubscription{
+		SubscriptionID: subscriptionID,
+		URL:            req.URL,
+		EventTypes:     req.EventTypes,
+		Secret:         current.Secret,
+		Enabled:        req.Enabled == nil || *req.Enabled,
+	}
+	if req.Secret != "" {
+		sub.Secret = req.Secret
+	}
+
+	var updated *entity.WebhookSubscription
+	err = ws.txManager.ExecuteInTransaction(
+		ctx,
+		"UpdateWebhookSubscription",
+		func(ctx context.Context) error {
+			var txErr error
+			updated, txErr = ws.repo.UpdateSubscription(ctx, sub)
+			return transaction.HandleError("UpdateWebhookSubscription", "update subscription", txErr)
+		},
+	)
+	if err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	if req.Secret == "" {
+		updated.Secret = ""
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "webhook subscription updated",
+		logger.String("op", op),
+		logger.String("subscription_id", subscriptionID.String()),
+		logger.Bool("enabled", updated.Enabled),
+		logger.Bool("secret_rotated", req.Secret != ""),
+	)
+
+	return updated, nil
+}
+
+func (ws *WebhookService) DeleteSubscription(ctx context.Context, subscriptionID uuid.UUID) error {
+	const op = "service.WebhookService.DeleteSubscription"
+	log := ws.logger.Ctx(ctx)
+
+	err := ws.txManager.ExecuteInTransaction(
+		ctx,
+		"DeleteWebhookSubscription",
+		func(ctx context.Context) error {
+			txErr := ws.repo.DeleteSubscription(ctx, subscriptionID)
+			return transaction.HandleError("DeleteWebhookSubscription", "delete subscription", txErr)
+		},
+	)
+	if err != nil {
+		// nolint: wrapcheck
+		return err
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "webhook subscription deleted",
+		logger.String("op", op),
+		logger.String("subscription_id", subscriptionID.String()),
+	)
+
+	return nil
+}
+
+func (ws *WebhookService) GetSubscription(
+	ctx context.Context,
+	subscriptionID uuid.UUID,
+) (*entity.WebhookSubscription, error) {
+	sub, err := ws.repo.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	sub.Secret = ""
+	return sub, nil
+}
+
+func (ws *WebhookService) ListSubscriptions(ctx context.Context) ([]*entity.WebhookSubscription, error) {
+	const op = "service.WebhookService.ListSubscriptions"
+
+	subs, err := ws.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, sub := range subs {
+		sub.Secret = ""
+	}
+	return subs, nil
+}
+
+// ListDeliveries returns the subscription's delivery log, newest first, optionally only the
+// deliveries with status.
+func (ws *WebhookService) ListDeliveries(
+	ctx context.Context,
+	subscriptionID uuid.UUID,
+	status string,
+	limit, offset int,
+) (*entity.WebhookDeliveryPage, error) {
+	const op = "service.WebhookService.ListDeliveries"
+
+	switch status {
+	case "", entity.DeliveryStatusPending, entity.DeliveryStatusSucceeded, entity.DeliveryStatusFailed:
+	default:
+		return nil, fmt.Errorf("%s: unknown status %q: %w", op, status, entity.ErrInvalidData)
+	}
+	limit, err := pageLimit(limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err = ws.repo.GetSubscription(ctx, subscriptionID); err != nil {
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	deliveries, total, err := ws.repo.ListDeliveries(ctx, subscriptionID, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &entity.WebhookDeliveryPage{
+		Deliveries: deliveries,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+	}, nil
+}
+
+// DeliverDue claims up to limit due deliveries, hands them to send, at most concurrency at a
+// time, and records each outcome. It returns how many deliveries were attempted.
+func (ws *WebhookService) DeliverDue(
+	ctx context.Context,
+	limit, concurrency int,
+	send func(ctx context.Context, outgoing *entity.OutgoingWebhook) entity.DeliveryAttempt,
+) (int, error) {
+	const op = "service.WebhookService.DeliverDue"
+
+	var claimed []*entity.OutgoingWebhook
+	err := ws.txManager.ExecuteInTransaction(
+		ctx,
+		"ClaimWebhookDeliveries",
+		func(ctx context.Context) error {
+			var txErr error
+			claimed, txErr = ws.repo.ClaimDue(ctx, limit, time.Now().Add(ws.policy.Lease))
+			return transaction.HandleError("ClaimWebhookDeliveries", "claim deliveries", txErr)
+		},
+	)
+	if err != nil {
+		// nolint: wrapcheck
+		return 0, err
+	}
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+
+	for _, outgoing := range claimed {
+		g.Go(func() error {
+			attempt := send(gCtx, outgoing)
+			if recordErr := ws.recordAttempt(gCtx, outgoing.Delivery, attempt); recordErr != nil {
+				return fmt.Errorf("%s: delivery %s: %w", op, outgoing.Delivery.DeliveryID, recordErr)
+			}
+			return nil
+		})
+	}
+
+	if err = g.Wait(); err != nil {
+		// nolint: wrapcheck
+		return 0, err
+	}
+
+	return len(claimed), nil
+}
+
+// recordAttempt stores the outcome of one attempt and updates the subscription's run of
+// failures with it.
+func (ws *WebhookService) recordAttempt(
+	ctx context.Context,
+	delivery *entity.WebhookDelivery,
+	attempt entity.DeliveryAttempt,
+) error {
+	const op = "service.WebhookService.recordAttempt"
+	log := ws.logger.Ctx(ctx)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = attempt.ResponseStatus
+	delivery.LastError = ""
+
+	switch {
+	case attempt.Err == nil:
+		delivery.Status = entity.DeliveryStatusSucceeded
+		delivery.NextAttemptAt = now
+	case delivery.Attempts >= ws.policy.MaxAttempts:
+		delivery.Status = entity.DeliveryStatusFailed
+		delivery.NextAttemptAt = now
+		delivery.LastError = attempt.Err.Error()
+	default:
+		delivery.Status = entity.DeliveryStatusPending
+		delivery.NextAttemptAt = now.Add(ws.retryDelay(delivery.Attempts))
+		delivery.LastError = attempt.Err.Error()
+	}
+
+	return ws.txManager.ExecuteInTransaction(
+		ctx,
+		"RecordWebhookAttempt",
+		func(ctx context.Context) error {
+			if txErr := ws.repo.UpdateDelivery(ctx, delivery); txErr != nil {
+				return transaction.HandleError("RecordWebhookAttempt", "update delivery", txErr)
+			}
+
+			if attempt.Err == nil {
+				txErr := ws.repo.ResetFailures(ctx, delivery.SubscriptionID)
+				return transaction.HandleError("RecordWebhookAttempt", "reset failures", txErr)
+			}
+
+			failures, enabled, txErr := ws.repo.RecordFailure(ctx, delivery.SubscriptionID, ws.policy.DisableAfter)
+			if txErr != nil {
+				return transaction.HandleError("RecordWebhookAttempt", "record failure", txErr)
+			}
+
+			log.LogAttrs(ctx, logger.WarnLevel, "webhook delivery failed",
+				logger.String("op", op),
+				logger.String("delivery_id", delivery.DeliveryID.String()),
+				logger.String("subscription_id", delivery.SubscriptionID.String()),
+				logger.Int("attempts", delivery.Attempts),
+				logger.String("status", delivery.Status),
+				logger.String("error", delivery.LastError),
+			)
+			if !enabled && failures == ws.policy.DisableAfter {
+				log.LogAttrs(ctx, logger.ErrorLevel, "webhook subscription disabled after repeated failures",
+					logger.String("op", op),
+					logger.String("subscription_id", delivery.SubscriptionID.String()),
+					logger.Int("consecutive_failures", failures),
+				)
+			}
+			return nil
+		},
+	)
+}
+
+// retryDelay is BaseRetryDelay doubled for every attempt after the first, capped at
+// MaxRetryDelay.
+func (ws *WebhookService) retryDelay(attempts int) time.Duration {
+	delay := ws.policy.BaseRetryDelay
+	for i := 1; i < attempts && delay < ws.policy.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, ws.policy.MaxRetryDelay)
+}
+
+func validateSubscriptionRequest(req *entity.WebhookSubscriptionRequest) error {
+	if req == nil {
+		return entity.ErrInvalidData
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		len(req.URL) > _maxWebhookURLLength {
+		return fmt.Errorf("url must be an absolute http(s) URL of at most %d characters: %w",
+			_maxWebhookURLLength, entity.ErrInvalidData)
+	}
+
+	if len(req.EventTypes) == 0 {
+		return fmt.Errorf("event_types must not be empty: %w", entity.ErrInvalidData)
+	}
+	for i, eventType := range req.EventTypes {
+		if !slices.Contains(entity.EventTypes, eventType) {
+			return fmt.Errorf("unknown event type %q: %w", eventType, entity.ErrInvalidData)
+		}
+		if slices.Contains(req.EventTypes[:i], eventType) {
+			return fmt.Errorf("event type %q listed twice: %w", eventType, entity.ErrInvalidData)
+		}
+	}
+
+	if n := utf8.RuneCountInString(req.Secret); req.Secret != "" && (n < _minSecretLength || n > _maxSecretLength) {
+		return fmt.Errorf("secret must be %d to %d characters: %w",
+			_minSecretLength, _maxSecretLength, entity.ErrInvalidData)
+	}
+	return nil
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, _generatedSecretSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,348 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"wbtest/internal/entity"
+	mock_repository "wbtest/internal/repository/mock"
+	"wbtest/internal/service"
+	"wbtest/internal/webhook"
+	mock_logger "wbtest/pkg/logger/mock"
+	"wbtest/pkg/storage/postgres/transaction"
+	mock_transaction "wbtest/pkg/storage/postgres/transaction/mock"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+)
+
+var _webhookPolicy = service.WebhookPolicy{
+	MaxAttempts:    3,
+	BaseRetryDelay: 10 * time.Second,
+	MaxRetryDelay:  15 * time.Second,
+	DisableAfter:   5,
+	Lease:          time.Minute,
+}
+
+func newWebhookService(ctrl *gomock.Controller) (*service.WebhookService, *mock_repository.MockWebhookRepository) {
+	repo := mock_repository.NewMockWebhookRepository(ctrl)
+	txManager := mock_transaction.NewMockManager(ctrl)
+	log := mock_logger.NewMockLogger(ctrl)
+
+	txManager.EXPECT().ExecuteInTransaction(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			txCtx context.Context,
+			_ string,
+			txFunc func(context.Context) error,
+			_ ...transaction.TxOption,
+		) error {
+			return txFunc(txCtx)
+		}).AnyTimes()
+	log.EXPECT().Ctx(gomock.Any()).Return(log).AnyTimes()
+	log.EXPECT().LogAttrs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	return service.NewWebhookService(repo, txManager, log, _webhookPolicy), repo
+}
+
+func TestWebhookService_CreateSubscription_Invalid(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc string
+		req  *entity.WebhookSubscriptionRequest
+	}{
+		{desc: "Nil"},
+		{
+			desc: "RelativeURL",
+			req:  &entity.WebhookSubscriptionRequest{URL: "/hooks", EventTypes: []string{entity.EventOrderCreated}},
+		},
+		{
+			desc: "UnsupportedScheme",
+			req: &entity.WebhookSubscriptionRequest{
+				URL:        "ftp://partner.example/hooks",
+				EventTypes: []string{entity.EventOrderCreated},
+			},
+		},
+		{
+			desc: "NoEventTypes",
+			req:  &entity.WebhookSubscriptionRequest{URL: "https://partner.example/hooks"},
+		},
+		{
+			desc: "UnknownEventType",
+			req: &entity.WebhookSubscriptionRequest{
+				URL:        "https://partner.example/hooks",
+				EventTypes: []string{"OrderShipped"},
+			},
+		},
+		{
+			desc: "DuplicateEventType",
+			req: &entity.WebhookSubscriptionRequest{
+				URL:        "https://partner.example/hooks",
+				EventTypes: []string{entity.EventOrderCreated, entity.EventOrderCreated},
+			},
+		},
+		{
+			desc: "ShortSecret",
+			req: &entity.WebhookSubscriptionRequest{
+				URL:        "https://partner.example/hooks",
+				EventTypes: []string{entity.EventOrderCreated},
+				Secret:     "too-short",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			svc, _ := newWebhookService(gomock.NewController(t))
+
+			if _, err := svc.CreateSubscription(context.Background(), tc.req); !errors.Is(err, entity.ErrInvalidData) {
+				t.Fatalf("expected ErrInvalidData, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWebhookService_CreateSubscription_GeneratesSecret(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	svc, repo := newWebhookService(ctrl)
+
+	repo.EXPECT().CreateSubscription(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, sub *entity.WebhookSubscription) (
+			*entity.WebhookSubscription, error,
+		) {
+			return sub, nil
+		})
+
+	sub, err := svc.CreateSubscription(context.Background(), &entity.WebhookSubscriptionRequest{
+		URL:        "https://partner.example/hooks",
+		EventTypes: []string{entity.EventOrderCreated, entity.EventRefundCreated},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(sub.Secret) != 64 {
+		t.Fatalf("expected a 32-byte hex secret, got %q", sub.Secret)
+	}
+	if !sub.Enabled || sub.SubscriptionID == uuid.Nil {
+		t.Fatalf("expected an enabled subscription with an id, got %+v", sub)
+	}
+}
+
+func TestWebhookService_UpdateSubscription_KeepsSecret(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	svc, repo := newWebhookService(ctrl)
+	subscriptionID := uuid.New()
+	disabled := false
+
+	repo.EXPECT().GetSubscription(gomock.Any(), subscriptionID).
+		Return(&entity.WebhookSubscription{SubscriptionID: subscriptionID, Secret: "current-secret-value"}, nil)
+	repo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, sub *entity.WebhookSubscription) (
+			*entity.WebhookSubscription, error,
+		) {
+			if sub.Secret != "current-secret-value" || sub.Enabled {
+				t.Errorf("expected the current secret and a disabled subscription, got %+v", sub)
+			}
+			updated := *sub
+			return &updated, nil
+		})
+
+	sub, err := svc.UpdateSubscription(context.Background(), subscriptionID, &entity.WebhookSubscriptionRequest{
+		URL:        "https://partner.example/v2/hooks",
+		EventTypes: []string{entity.EventOrderDeleted},
+		Enabled:    &disabled,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if sub.Secret != "" {
+		t.Fatalf("expected the secret not to be returned, got %q", sub.Secret)
+	}
+}
+
+func TestWebhookService_DeliverDue(t *testing.T) {
+	t.Parallel()
+
+	errRefused := errors.New("connection refused")
+
+	testCases := []struct {
+		desc         string
+		attempts     int
+		attempt      entity.DeliveryAttempt
+		failures     int
+		wantStatus   string
+		wantRetryIn  time.Duration
+		wantResetRun bool
+	}{
+		{
+			desc:         "Succeeded",
+			attempt:      entity.DeliveryAttempt{ResponseStatus: http.StatusOK},
+			wantStatus:   entity.DeliveryStatusSucceeded,
+			wantResetRun: true,
+		},
+		{
+			desc:        "FirstRetry",
+			attempt:     entity.DeliveryAttempt{Err: errRefused},
+			failures:    1,
+			wantStatus:  entity.DeliveryStatusPending,
+			wantRetryIn: 10 * time.Second,
+		},
+		{
+			desc:        "RetryDelayCapped",
+			attempts:    1,
+			attempt:     entity.DeliveryAttempt{ResponseStatus: http.StatusBadGateway, Err: errRefused},
+			failures:    2,
+			wantStatus:  entity.DeliveryStatusPending,
+			wantRetryIn: 15 * time.Second,
+		},
+		{
+			desc:       "AttemptsExhausted",
+			attempts:   2,
+			attempt:    entity.DeliveryAttempt{Err: errRefused},
+			failures:   5,
+			wantStatus: entity.DeliveryStatusFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			svc, repo := newWebhookService(ctrl)
+
+			delivery := &entity.WebhookDelivery{
+				DeliveryID:     uuid.New(),
+				SubscriptionID: uuid.New(),
+				Status:         entity.DeliveryStatusPending,
+				Attempts:       tc.attempts,
+			}
+			repo.EXPECT().ClaimDue(gomock.Any(), 10, gomock.Any()).
+				Return([]*entity.OutgoingWebhook{{Delivery: delivery}}, nil)
+
+			var updated entity.WebhookDelivery
+			repo.EXPECT().UpdateDelivery(gomock.Any(), delivery).
+				DoAndReturn(func(_ context.Context, d *entity.WebhookDelivery) error {
+					updated = *d
+					return nil
+				})
+			if tc.wantResetRun {
+				repo.EXPECT().ResetFailures(gomock.Any(), delivery.SubscriptionID)
+			} else {
+				repo.EXPECT().RecordFailure(gomock.Any(), delivery.SubscriptionID, _webhookPolicy.DisableAfter).
+					Return(tc.failures, tc.failures < _webhookPolicy.DisableAfter, nil)
+			}
+
+			before := time.Now()
+			sent, err := svc.DeliverDue(context.Background(), 10, 2,
+				func(context.Context, *entity.OutgoingWebhook) entity.DeliveryAttempt {
+					return tc.attempt
+				})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if sent != 1 {
+				t.Fatalf("expected 1 delivery sent, got %d", sent)
+			}
+
+			if updated.Status != tc.wantStatus || updated.Attempts != tc.attempts+1 {
+				t.Fatalf("expected %s after %d attempts, got %s after %d",
+					tc.wantStatus, tc.attempts+1, updated.Status, updated.Attempts)
+			}
+			if updated.ResponseStatus != tc.attempt.ResponseStatus {
+				t.Fatalf("expected response status %d, got %d", tc.attempt.ResponseStatus, updated.ResponseStatus)
+			}
+			if (tc.attempt.Err != nil) != (updated.LastError != "") {
+				t.Fatalf("unexpected last error %q", updated.LastError)
+			}
+			if tc.wantRetryIn > 0 {
+				retryIn := updated.NextAttemptAt.Sub(before)
+				if retryIn < tc.wantRetryIn || retryIn > tc.wantRetryIn+time.Second {
+					t.Fatalf("expected a retry in %s, got %s", tc.wantRetryIn, retryIn)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookService_DeliverDue_ToReceiver(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	verified := make(chan bool, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event entity.Event
+		body, err := io.ReadAll(r.Body)
+		if err == nil {
+			err = json.Unmarshal(body, &event)
+		}
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		verified <- err == nil && event.Type == entity.EventOrderCreated &&
+			webhook.Verify("receiver-secret-value", timestamp, body, r.Header.Get(webhook.HeaderSignature))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	ctrl := gomock.NewController(t)
+	svc, repo := newWebhookService(ctrl)
+
+	delivery := &entity.WebhookDelivery{
+		DeliveryID:     uuid.New(),
+		SubscriptionID: uuid.New(),
+		EventID:        uuid.New(),
+		EventType:      entity.EventOrderCreated,
+		OrderUID:       uuid.New(),
+		Payload:        json.RawMessage(`{"order_uid":"b563feb7-b2b8-4b6a-9f5d-1f9c5e6a7d8e"}`),
+		Status:         entity.DeliveryStatusPending,
+	}
+	outgoing := &entity.OutgoingWebhook{Delivery: delivery, URL: receiver.URL, Secret: "receiver-secret-value"}
+
+	var statuses []string
+	repo.EXPECT().ClaimDue(gomock.Any(), 10, gomock.Any()).
+		Return([]*entity.OutgoingWebhook{outgoing}, nil).Times(2)
+	repo.EXPECT().UpdateDelivery(gomock.Any(), delivery).
+		DoAndReturn(func(_ context.Context, d *entity.WebhookDelivery) error {
+			statuses = append(statuses, d.Status)
+			return nil
+		}).Times(2)
+	repo.EXPECT().RecordFailure(gomock.Any(), delivery.SubscriptionID, _webhookPolicy.DisableAfter).
+		Return(1, true, nil)
+	repo.EXPECT().ResetFailures(gomock.Any(), delivery.SubscriptionID)
+
+	sender := webhook.NewSender(time.Second, "order-service", []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")})
+	for range 2 {
+		if _, err := svc.DeliverDue(context.Background(), 10, 1, sender.Send); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if !<-verified {
+		t.Fatal("expected a signed OrderCreated envelope")
+	}
+	if len(statuses) != 2 || statuses[0] != entity.DeliveryStatusPending || statuses[1] != entity.DeliveryStatusSucceeded {
+		t.Fatalf("expected a retry then a success, got %v", statuses)
+	}
+	if delivery.Attempts != 2 || delivery.ResponseStatus != http.StatusNoContent {
+		t.Fatalf("expected 2 attempts ending in 204, got %d and %d", delivery.Attempts, delivery.ResponseStatus)
+	}
+}
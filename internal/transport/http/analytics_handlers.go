@@ -0,0 +1,79 @@
+package httpt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"wbtest/internal/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Аналитика заказов
+// @Description Возвращает число заказов и выручку по периодам (в минимальных единицах валюты, отдельно по каждой валюте), а также самые продаваемые товары и бренды. Границы периода округляются до целых часов в UTC, недели начинаются с понедельника
+// @Tags Analytics
+// @Produce json
+// @Param bucket query string false "Размер периода" Enums(hour, day, week) default(day)
+// @Param from query string false "Начало интервала (RFC3339), по умолчанию за 7 дней до to"
+// @Param to query string false "Конец интервала (RFC3339), не включительно, по умолчанию текущее время"
+// @Param delivery_service query string false "Служба доставки"
+// @Param locale query string false "Локаль заказа"
+// @Param currency query string false "Валюта оплаты"
+// @Param provider query string false "Платёжный провайдер"
+// @Param bank query string false "Банк"
+// @Param top query int false "Размер топов товаров и брендов (1–100)" default(10)
+// @Param reporting_currency query string false "Валюта отчётности (ISO 4217): выручка пересчитывается по текущему курсу и ряды объединяются; по умолчанию EXCHANGE_REPORTING_CURRENCY"
+// @Success 200 {object} entity.OrderAnalytics "Аналитика заказов"
+// @Failure 400 {object} httpt.ErrorResponse "Неверные параметры запроса"
+// @Failure 422 {object} httpt.ErrorResponse "Нет курса для пересчёта в валюту отчётности"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /analytics/orders [get]
+func (h *OrderHandler) getOrderAnalyticsHandler(c *gin.Context) {
+	const op = "transport.getOrderAnalyticsHandler"
+
+	from, to, err := parseExportRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from/to, expected RFC3339"})
+		return
+	}
+
+	top := 0
+	if topStr := c.Query("top"); topStr != "" {
+		if top, err = strconv.Atoi(topStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid top"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _listTimeout)
+	defer cancel()
+
+	analytics, err := h.svc.GetOrderAnalytics(ctx, entity.AnalyticsFilter{
+		From:            from,
+		To:              to,
+		Bucket:          c.Query("bucket"),
+		DeliveryService: c.Query("delivery_service"),
+		Locale:          c.Query("locale"),
+		Currency:        c.Query("currency"),
+		Provider:        c.Query("provider"),
+		Bank:            c.Query("bank"),
+		Top:             top,
+
+		ReportingCurrency: c.Query("reporting_currency"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidData):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bucket, range, top or currency"})
+		case errors.Is(err, entity.ErrNoExchangeRate):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "No exchange rate to the reporting currency"})
+		default:
+			h.handleServiceError(c, err, op)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
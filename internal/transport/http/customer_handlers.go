@@ -0,0 +1,88 @@
+package httpt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Заказы покупателя
+// @Description Возвращает заказы покупателя, начиная с последнего
+// @Tags Customers
+// @Produce json
+// @Param customer_id path string true "Идентификатор покупателя"
+// @Param limit query int false "Размер страницы (1–100)" default(20)
+// @Param offset query int false "Смещение" default(0)
+// @Success 200 {object} entity.OrderPage "Страница заказов покупателя"
+// @Failure 400 {object} httpt.ErrorResponse "Неверный customer_id или параметры пагинации"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /customers/{customer_id}/orders [get]
+func (h *OrderHandler) listCustomerOrdersHandler(c *gin.Context) {
+	const op = "transport.listCustomerOrdersHandler"
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit/offset"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _listTimeout)
+	defer cancel()
+
+	page, err := h.svc.ListCustomerOrders(ctx, c.Param("customer_id"), limit, offset)
+	if err != nil {
+		h.handleCustomerError(c, err, op)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary Сводка по покупателю
+// @Description Возвращает число заказов, сумму оплат по валютам, даты первого и последнего заказа, самые частые бренды и города доставки
+// @Tags Customers
+// @Produce json
+// @Param customer_id path string true "Идентификатор покупателя"
+// @Param reporting_currency query string false "Валюта отчётности (ISO 4217), в которую пересчитывается total; по умолчанию EXCHANGE_REPORTING_CURRENCY"
+// @Success 200 {object} entity.CustomerSummary "Сводка по покупателю"
+// @Failure 400 {object} httpt.ErrorResponse "Неверный customer_id или reporting_currency"
+// @Failure 404 {object} httpt.ErrorResponse "У покупателя нет заказов"
+// @Failure 422 {object} httpt.ErrorResponse "Нет курса для пересчёта в валюту отчётности"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /customers/{customer_id}/summary [get]
+func (h *OrderHandler) getCustomerSummaryHandler(c *gin.Context) {
+	const op = "transport.getCustomerSummaryHandler"
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	summary, err := h.svc.GetCustomerSummary(ctx, c.Param("customer_id"), c.Query("reporting_currency"))
+	if err != nil {
+		h.handleCustomerError(c, err, op)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *OrderHandler) handleCustomerError(c *gin.Context, err error, op string) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidData):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid customer_id, limit/offset or reporting_currency"})
+	case errors.Is(err, entity.ErrNoExchangeRate):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "No exchange rate to the reporting currency"})
+	case errors.Is(err, entity.ErrDataNotFound):
+		h.log.Ctx(c.Request.Context()).LogAttrs(c.Request.Context(), logger.WarnLevel, "customer not found",
+			logger.String("op", op),
+			logger.String("customer_id", c.Param("customer_id")),
+		)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer has no orders"})
+	default:
+		h.handleServiceError(c, err, op)
+	}
+}
@@ -32,6 +32,12 @@ func (h *OrderHandler) handleServiceError(c *gin.Context, err error, op string)
 			logger.String("client_ip", c.ClientIP()),
 		)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	case errors.Is(err, entity.ErrVersionMismatch):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Order version is stale"})
+	case errors.Is(err, entity.ErrOrderDeleted):
+		c.JSON(http.StatusConflict, gin.H{"error": "Order has been deleted"})
+	case errors.Is(err, entity.ErrConflictingData):
+		c.JSON(http.StatusConflict, gin.H{"error": "Order data conflicts with existing data"})
 	case errors.Is(err, context.DeadlineExceeded):
 		log.LogAttrs(c.Request.Context(), logger.WarnLevel, "request timeout",
 			logger.String("path", c.Request.URL.Path),
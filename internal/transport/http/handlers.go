@@ -2,9 +2,14 @@ package httpt
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"wbtest/internal/entity"
+	"wbtest/internal/export"
 	"wbtest/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -13,15 +18,16 @@ import (
 
 const (
 	_defaultContextTimeout = 500 * time.Millisecond
+	_exportFlushEvery      = 100
 )
 
 // @Summary Получить заказ
-// @Description Возвращает заказ по уникальному идентификатору
+// @Description Возвращает заказ по уникальному идентификатору вместе с его возвратами и суммой к оплате за вычетом возвратов
 // @Tags Orders
 // @Accept json
 // @Produce json
 // @Param order_uid path string true "Уникальный идентификатор заказа"
-// @Success 200 {object} entity.Order "Успешный ответ с данными заказа"
+// @Success 200 {object} entity.OrderDetails "Успешный ответ с данными заказа"
 // @Failure 400 {object} httpt.ErrorResponse "Неверный формат order_uid"
 // @Failure 404 {object} httpt.ErrorResponse "Заказ не найден"
 // @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
@@ -41,7 +47,7 @@ func (h *OrderHandler) getOrderHandler(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
 	defer cancel()
 
-	order, err := h.svc.GetOrder(ctx, orderUID)
+	details, err := h.svc.GetOrderDetails(ctx, orderUID)
 	if err != nil {
 		h.handleServiceError(c, err, op)
 		return
@@ -51,5 +57,223 @@ func (h *OrderHandler) getOrderHandler(c *gin.Context) {
 		logger.String("order_uid", orderUIDStr),
 	)
 
+	c.Header("ETag", formatETag(details.Version))
+	c.JSON(http.StatusOK, details)
+}
+
+// @Summary Изменить заказ
+// @Description Заменяет доставку, оплату и/или состав заказа. Требует заголовок If-Match с ETag текущей версии
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param order_uid path string true "Уникальный идентификатор заказа"
+// @Param If-Match header string true "ETag текущей версии заказа"
+// @Param update body entity.OrderUpdate true "Новые данные заказа"
+// @Success 200 {object} entity.Order "Обновленный заказ"
+// @Failure 400 {object} httpt.ErrorResponse "Неверный формат запроса"
+// @Failure 404 {object} httpt.ErrorResponse "Заказ не найден"
+// @Failure 409 {object} httpt.ErrorResponse "Конфликт уникальных данных"
+// @Failure 412 {object} httpt.ErrorResponse "Версия заказа устарела"
+// @Failure 428 {object} httpt.ErrorResponse "Не передан заголовок If-Match"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /orders/{order_uid} [put]
+func (h *OrderHandler) updateOrderHandler(c *gin.Context) {
+	const op = "transport.updateOrderHandler"
+
+	log := h.log.Ctx(c.Request.Context())
+	orderUIDStr := c.Param("order_uid")
+
+	orderUID, err := uuid.Parse(orderUIDStr)
+	if err != nil {
+		h.handleInvalidUUID(c, op, orderUIDStr)
+		return
+	}
+
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+		return
+	}
+
+	expectedVersion, err := parseETag(ifMatch)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header"})
+		return
+	}
+
+	var update entity.OrderUpdate
+	if err = c.ShouldBindJSON(&update); err != nil {
+		h.handleServiceError(c, entity.ErrInvalidData, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	order, err := h.svc.UpdateOrder(ctx, orderUID, &update, expectedVersion)
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "order updated successfully",
+		logger.String("order_uid", orderUIDStr),
+		logger.Int("version", order.Version),
+	)
+
+	c.Header("ETag", formatETag(order.Version))
 	c.JSON(http.StatusOK, order)
 }
+
+// @Summary Удалить заказ
+// @Description Помечает заказ как удаленный (мягкое удаление). Удаленный заказ больше не возвращается API
+// @Tags Orders
+// @Param order_uid path string true "Уникальный идентификатор заказа"
+// @Success 204 "Заказ удален"
+// @Failure 400 {object} httpt.ErrorResponse "Неверный формат order_uid"
+// @Failure 404 {object} httpt.ErrorResponse "Заказ не найден"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /orders/{order_uid} [delete]
+func (h *OrderHandler) deleteOrderHandler(c *gin.Context) {
+	const op = "transport.deleteOrderHandler"
+
+	log := h.log.Ctx(c.Request.Context())
+	orderUIDStr := c.Param("order_uid")
+
+	orderUID, err := uuid.Parse(orderUIDStr)
+	if err != nil {
+		h.handleInvalidUUID(c, op, orderUIDStr)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	if err = h.svc.DeleteOrder(ctx, orderUID); err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "order deleted successfully",
+		logger.String("order_uid", orderUIDStr),
+	)
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Выгрузить заказы
+// @Description Потоково выгружает заказы за период в формате JSON Lines (формат entity.Order) или CSV (по строке на товар)
+// @Tags Orders
+// @Produce application/x-ndjson
+// @Produce text/csv
+// @Produce application/gzip
+// @Param format query string false "Формат выгрузки" Enums(jsonl, csv) default(jsonl)
+// @Param from query string false "Начало периода по date_created (RFC3339), включительно"
+// @Param to query string false "Конец периода по date_created (RFC3339), не включительно"
+// @Param gzip query bool false "Сжать выгрузку gzip"
+// @Success 200 {file} file "Файл выгрузки"
+// @Failure 400 {object} httpt.ErrorResponse "Неверные параметры выгрузки"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /orders/export [get]
+func (h *OrderHandler) exportOrdersHandler(c *gin.Context) {
+	const op = "transport.exportOrdersHandler"
+
+	ctx := c.Request.Context()
+	log := h.log.Ctx(ctx)
+
+	format := c.DefaultQuery("format", export.FormatJSONL)
+	compress := c.Query("gzip") == "true"
+
+	from, to, err := parseExportRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from/to, expected RFC3339"})
+		return
+	}
+
+	encoder, err := export.NewEncoder(c.Writer, format, compress)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported export format"})
+		return
+	}
+
+	if err = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.LogAttrs(ctx, logger.WarnLevel, "failed to reset write deadline",
+			logger.String("op", op),
+			logger.Any("error", err),
+		)
+	}
+
+	c.Header("Content-Type", export.ContentType(format, compress))
+	c.Header("Content-Disposition", `attachment; filename="`+export.FileName(format, compress)+`"`)
+
+	exported := 0
+	err = h.svc.ExportOrders(ctx, from, to, func(order *entity.Order) error {
+		if encErr := encoder.Encode(order); encErr != nil {
+			return encErr
+		}
+		exported++
+		if exported%_exportFlushEvery == 0 {
+			if flushErr := encoder.Flush(); flushErr != nil {
+				return flushErr
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = encoder.Close()
+	}
+	if err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			h.handleServiceError(c, err, op)
+			return
+		}
+		log.LogAttrs(ctx, logger.ErrorLevel, "order export interrupted",
+			logger.String("op", op),
+			logger.Any("error", err),
+			logger.Int("exported", exported),
+			logger.Bool("client_gone", ctx.Err() != nil),
+		)
+		c.Abort()
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "orders exported successfully",
+		logger.String("format", format),
+		logger.Int("exported", exported),
+	)
+}
+
+func parseExportRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+
+	if fromStr != "" {
+		if from, err = time.Parse(time.RFC3339, fromStr); err != nil {
+			return from, to, fmt.Errorf("transport.parseExportRange: from: %w", err)
+		}
+	}
+	if toStr != "" {
+		if to, err = time.Parse(time.RFC3339, toStr); err != nil {
+			return from, to, fmt.Errorf("transport.parseExportRange: to: %w", err)
+		}
+	}
+	return from, to, nil
+}
+
+func formatETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+func parseETag(value string) (int, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "W/")
+	value = strings.Trim(value, `"`)
+
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("transport.parseETag: %w", err)
+	}
+	return version, nil
+}
@@ -27,15 +27,18 @@ func NewHTTPServer(
 	cfg *config.HTTP,
 	log logger.Logger,
 ) (*HTTPServer, error) {
+	server := &http.Server{
+		Addr:              net.JoinHostPort(cfg.Host, cfg.Port),
+		Handler:           handler.Engine(),
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+	}
+	server.RegisterOnShutdown(handler.closeStreams)
+
 	return &HTTPServer{
-		server: &http.Server{
-			Addr:              net.JoinHostPort(cfg.Host, cfg.Port),
-			Handler:           handler.Engine(),
-			ReadTimeout:       cfg.ReadTimeout,
-			WriteTimeout:      cfg.WriteTimeout,
-			IdleTimeout:       cfg.IdleTimeout,
-			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
-		},
+		server:          server,
 		shutdownTimeout: cfg.ShutdownTimeout,
 		log:             log,
 	}, nil
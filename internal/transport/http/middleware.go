@@ -1,13 +1,59 @@
 package httpt
 
 import (
+	"fmt"
+	"net/http"
 	"time"
 
 	"wbtest/pkg/logger"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const _tracerName = "wbtest/internal/transport/http"
+
+// tracingMiddleware starts a server span per request, continuing the caller's trace when the
+// request carries a traceparent header. Spans are named after the route template, not the path.
+func (h *OrderHandler) tracingMiddleware() gin.HandlerFunc {
+	tracer := otel.Tracer(_tracerName)
+
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+	}
+}
+
 func (h *OrderHandler) requestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestID := h.log.GenerateRequestID()
@@ -1,6 +1,9 @@
 package httpt
 
 import (
+	"time"
+
+	"wbtest/internal/feed"
 	"wbtest/internal/service"
 	"wbtest/pkg/logger"
 	"wbtest/pkg/metric"
@@ -9,25 +12,40 @@ import (
 )
 
 type OrderHandler struct {
-	svc     *service.OrderService
-	log     logger.Logger
-	metrics metric.HTTP
-	router  *gin.Engine
+	svc      *service.OrderService
+	schemas  *service.SchemaService
+	webhooks *service.WebhookService
+	feed     *feed.Hub
+	log      logger.Logger
+	metrics  metric.HTTP
+	router   *gin.Engine
+
+	feedKeepAlive time.Duration
 }
 
 func NewOrderHandler(
 	svc *service.OrderService,
+	schemas *service.SchemaService,
+	webhooks *service.WebhookService,
+	feedHub *feed.Hub,
+	feedKeepAlive time.Duration,
 	log logger.Logger,
 	metrics metric.HTTP,
 ) *OrderHandler {
 	h := &OrderHandler{
-		svc:     svc,
-		log:     log,
-		metrics: metrics,
+		svc:      svc,
+		schemas:  schemas,
+		webhooks: webhooks,
+		feed:     feedHub,
+		log:      log,
+		metrics:  metrics,
+
+		feedKeepAlive: feedKeepAlive,
 	}
 
 	router := gin.New()
 
+	router.Use(h.tracingMiddleware())
 	router.Use(h.requestIDMiddleware())
 	router.Use(h.loggingMiddleware())
 	router.Use(gin.Recovery())
@@ -45,3 +63,9 @@ func NewOrderHandler(
 func (h *OrderHandler) Engine() *gin.Engine {
 	return h.router
 }
+
+// closeStreams disconnects live feed clients, whose requests would otherwise keep a graceful
+// shutdown waiting.
+func (h *OrderHandler) closeStreams() {
+	h.feed.Close()
+}
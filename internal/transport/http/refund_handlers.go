@@ -0,0 +1,77 @@
+package httpt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Оформить возврат
+// @Description Возвращает товары заказа по rid (на сумму их total_price) либо произвольную сумму в валюте оплаты. Сумма всех возвратов не может превышать сумму оплаты
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param order_uid path string true "Уникальный идентификатор заказа"
+// @Param refund body entity.RefundRequest true "Товары (items) или сумма (amount) возврата"
+// @Success 201 {object} entity.Refund "Созданный возврат"
+// @Failure 400 {object} httpt.ErrorResponse "Неверный формат запроса или неизвестный rid"
+// @Failure 404 {object} httpt.ErrorResponse "Заказ не найден"
+// @Failure 409 {object} httpt.ErrorResponse "Товар уже возвращен или заказ изменен параллельно"
+// @Failure 422 {object} httpt.ErrorResponse "Сумма возвратов превышает сумму оплаты"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /orders/{order_uid}/refunds [post]
+func (h *OrderHandler) createRefundHandler(c *gin.Context) {
+	const op = "transport.createRefundHandler"
+
+	log := h.log.Ctx(c.Request.Context())
+	orderUIDStr := c.Param("order_uid")
+
+	orderUID, err := uuid.Parse(orderUIDStr)
+	if err != nil {
+		h.handleInvalidUUID(c, op, orderUIDStr)
+		return
+	}
+
+	var req entity.RefundRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		h.handleRefundError(c, entity.ErrInvalidData, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	refund, err := h.svc.CreateRefund(ctx, orderUID, &req)
+	if err != nil {
+		h.handleRefundError(c, err, op)
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "refund created successfully",
+		logger.String("order_uid", orderUIDStr),
+		logger.String("refund_id", refund.RefundID.String()),
+	)
+
+	c.JSON(http.StatusCreated, refund)
+}
+
+func (h *OrderHandler) handleRefundError(c *gin.Context, err error, op string) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidData):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Specify either items of the order or a positive amount"})
+	case errors.Is(err, entity.ErrConflictingData):
+		c.JSON(http.StatusConflict, gin.H{"error": "Item is already refunded"})
+	case errors.Is(err, entity.ErrVersionMismatch):
+		c.JSON(http.StatusConflict, gin.H{"error": "Order was changed concurrently, retry the refund"})
+	case errors.Is(err, entity.ErrRefundExceeded):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Refunds would exceed the payment amount"})
+	default:
+		h.handleServiceError(c, err, op)
+	}
+}
@@ -31,7 +31,40 @@ func (h *OrderHandler) setupRoutes() {
 
 	orders := h.router.Group("/orders")
 	{
+		orders.GET("/export", h.exportOrdersHandler)
+		orders.GET("/search", h.searchOrdersHandler)
+		orders.GET("/stream", h.streamOrdersHandler)
 		orders.GET("/:order_uid", h.getOrderHandler)
+		orders.PUT("/:order_uid", h.updateOrderHandler)
+		orders.DELETE("/:order_uid", h.deleteOrderHandler)
+		orders.POST("/:order_uid/refunds", h.createRefundHandler)
+	}
+
+	customers := h.router.Group("/customers")
+	{
+		customers.GET("/:customer_id/orders", h.listCustomerOrdersHandler)
+		customers.GET("/:customer_id/summary", h.getCustomerSummaryHandler)
+	}
+
+	analytics := h.router.Group("/analytics")
+	{
+		analytics.GET("/orders", h.getOrderAnalyticsHandler)
+	}
+
+	schemas := h.router.Group("/schemas")
+	{
+		schemas.GET("/:subject/versions", h.listSchemaVersionsHandler)
+		schemas.GET("/:subject/versions/:version", h.getSchemaHandler)
+	}
+
+	webhooks := h.router.Group("/webhooks")
+	{
+		webhooks.POST("", h.createWebhookHandler)
+		webhooks.GET("", h.listWebhooksHandler)
+		webhooks.GET("/:subscription_id", h.getWebhookHandler)
+		webhooks.PUT("/:subscription_id", h.updateWebhookHandler)
+		webhooks.DELETE("/:subscription_id", h.deleteWebhookHandler)
+		webhooks.GET("/:subscription_id/deliveries", h.listWebhookDeliveriesHandler)
 	}
 
 	h.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -0,0 +1,89 @@
+package httpt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const _schemaContentType = "application/schema+json"
+
+// @Summary Список версий схемы
+// @Description Возвращает зарегистрированные версии JSON Schema для subject (без самих схем)
+// @Tags Schemas
+// @Produce json
+// @Param subject path string true "Subject, например order"
+// @Success 200 {array} entity.SchemaVersion "Версии схемы"
+// @Failure 404 {object} httpt.ErrorResponse "Subject не найден"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /schemas/{subject}/versions [get]
+func (h *OrderHandler) listSchemaVersionsHandler(c *gin.Context) {
+	const op = "transport.listSchemaVersionsHandler"
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	versions, err := h.schemas.ListVersions(ctx, c.Param("subject"))
+	if err != nil {
+		h.handleSchemaError(c, err, op)
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// @Summary Получить схему
+// @Description Возвращает JSON Schema указанной версии; вместо номера можно передать latest
+// @Tags Schemas
+// @Produce json
+// @Param subject path string true "Subject, например order"
+// @Param version path string true "Номер версии или latest"
+// @Success 200 {object} object "JSON Schema"
+// @Failure 400 {object} httpt.ErrorResponse "Неверный номер версии"
+// @Failure 404 {object} httpt.ErrorResponse "Схема не найдена"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /schemas/{subject}/versions/{version} [get]
+func (h *OrderHandler) getSchemaHandler(c *gin.Context) {
+	const op = "transport.getSchemaHandler"
+
+	version := 0
+	if raw := c.Param("version"); raw != "latest" {
+		var err error
+		if version, err = strconv.Atoi(raw); err != nil || version < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Version must be a positive integer or latest"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	schema, err := h.schemas.GetVersion(ctx, c.Param("subject"), version)
+	if err != nil {
+		h.handleSchemaError(c, err, op)
+		return
+	}
+
+	c.Header("X-Schema-Version", strconv.Itoa(schema.Version))
+	c.Data(http.StatusOK, _schemaContentType, schema.Definition)
+}
+
+func (h *OrderHandler) handleSchemaError(c *gin.Context, err error, op string) {
+	if !errors.Is(err, entity.ErrDataNotFound) {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	h.log.Ctx(c.Request.Context()).LogAttrs(c.Request.Context(), logger.WarnLevel, "schema not found",
+		logger.String("op", op),
+		logger.String("subject", c.Param("subject")),
+		logger.String("version", c.Param("version")),
+	)
+	c.JSON(http.StatusNotFound, gin.H{"error": "Schema not found"})
+}
@@ -0,0 +1,78 @@
+package httpt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// _listTimeout is longer than _defaultContextTimeout: a page may need several orders hydrated.
+const _listTimeout = 2 * time.Second
+
+var errInvalidPagination = errors.New("invalid pagination")
+
+// @Summary Поиск заказов
+// @Description Ищет заказы по имени получателя, городу, адресу, названиям товаров и брендам (полнотекстово), а также по телефону, email и трек-номеру (по подстроке и с опечатками). Результаты отсортированы по релевантности
+// @Tags Orders
+// @Produce json
+// @Param q query string true "Поисковый запрос, до 200 символов"
+// @Param limit query int false "Размер страницы (1–100)" default(20)
+// @Param offset query int false "Смещение" default(0)
+// @Success 200 {object} entity.OrderPage "Страница найденных заказов"
+// @Failure 400 {object} httpt.ErrorResponse "Неверный запрос или параметры пагинации"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /orders/search [get]
+func (h *OrderHandler) searchOrdersHandler(c *gin.Context) {
+	const op = "transport.searchOrdersHandler"
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit/offset"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _listTimeout)
+	defer cancel()
+
+	page, err := h.svc.SearchOrders(ctx, c.Query("q"), limit, offset)
+	if errors.Is(err, entity.ErrInvalidData) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query must be 1 to 200 characters and limit at most 100"})
+		return
+	}
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	h.log.Ctx(ctx).LogAttrs(ctx, logger.InfoLevel, "orders searched successfully",
+		logger.Int("results", len(page.Orders)),
+		logger.Int64("total", page.Total),
+	)
+
+	c.JSON(http.StatusOK, page)
+}
+
+// parsePagination reads limit and offset; a missing limit is 0 and left to the service's default.
+func parsePagination(c *gin.Context) (int, int, error) {
+	var limit, offset int
+	var err error
+
+	if raw := c.Query("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 1 {
+			return 0, 0, errInvalidPagination
+		}
+	}
+	if raw := c.Query("offset"); raw != "" {
+		if offset, err = strconv.Atoi(raw); err != nil || offset < 0 {
+			return 0, 0, errInvalidPagination
+		}
+	}
+	return limit, offset, nil
+}
@@ -0,0 +1,113 @@
+package httpt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"wbtest/internal/feed"
+	"wbtest/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Поток новых заказов
+// @Description Server-Sent Events: каждый заказ, созданный после подключения, приходит событием order (id — order_uid, data — entity.Order). Простаивающий поток раз в FEED_KEEP_ALIVE получает комментарий-пинг. Клиент, отставший больше чем на FEED_BUFFER_SIZE заказов, получает событие disconnect с причиной slow и отключается; EventSource переподключается сам
+// @Tags Orders
+// @Produce text/event-stream
+// @Param delivery_service query string false "Только заказы этой службы доставки"
+// @Param customer_id query string false "Только заказы этого покупателя"
+// @Success 200 {object} entity.Order "Поток событий order"
+// @Failure 503 {object} httpt.ErrorResponse "Слишком много подключенных клиентов или сервис останавливается"
+// @Router /orders/stream [get]
+func (h *OrderHandler) streamOrdersHandler(c *gin.Context) {
+	const op = "transport.streamOrdersHandler"
+
+	ctx := c.Request.Context()
+	log := h.log.Ctx(ctx)
+
+	client, err := h.feed.Subscribe(feed.Filter{
+		DeliveryService: c.Query("delivery_service"),
+		CustomerID:      c.Query("customer_id"),
+	})
+	if err != nil {
+		if errors.Is(err, feed.ErrTooManyClients) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Too many live feed clients"})
+			return
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Service is shutting down"})
+		return
+	}
+	defer client.Close()
+
+	if err = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.LogAttrs(ctx, logger.WarnLevel, "failed to reset write deadline",
+			logger.String("op", op),
+			logger.Any("error", err),
+		)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(h.feedKeepAlive)
+	defer keepAlive.Stop()
+
+	sent := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-client.Done():
+			data, _ := json.Marshal(gin.H{"reason": client.Reason()})
+			_ = writeEvent(c.Writer, "disconnect", "", data)
+			c.Writer.Flush()
+
+			log.LogAttrs(ctx, logger.InfoLevel, "live feed client disconnected",
+				logger.String("op", op),
+				logger.String("reason", client.Reason()),
+				logger.Int("sent", sent),
+			)
+			return
+		case order := <-client.Orders():
+			data, marshalErr := json.Marshal(order)
+			if marshalErr != nil {
+				log.LogAttrs(ctx, logger.ErrorLevel, "failed to marshal order",
+					logger.String("op", op),
+					logger.Any("error", marshalErr),
+					logger.String("order_uid", order.OrderUID.String()),
+				)
+				continue
+			}
+			err = writeEvent(c.Writer, "order", order.OrderUID.String(), data)
+			sent++
+		case <-keepAlive.C:
+			_, err = io.WriteString(c.Writer, ": keep-alive\n\n")
+		}
+
+		if err != nil {
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeEvent writes one Server-Sent Event. data must not contain newlines.
+func writeEvent(w io.Writer, event, id string, data []byte) error {
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return fmt.Errorf("write event id: %w", err)
+		}
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package httpt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"wbtest/internal/entity"
+	"wbtest/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Создать подписку на вебхуки
+// @Description Подписывает URL на события заказов (OrderCreated, OrderUpdated, OrderDeleted, RefundCreated). Тело запроса подписывается HMAC-SHA256 с секретом подписки; если секрет не передан, он генерируется и возвращается только в этом ответе
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param subscription body entity.WebhookSubscriptionRequest true "URL, типы событий и секрет (16–256 символов)"
+// @Success 201 {object} entity.WebhookSubscription "Созданная подписка вместе с секретом"
+// @Failure 400 {object} httpt.ErrorResponse "Неверный URL, тип события или секрет"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks [post]
+func (h *OrderHandler) createWebhookHandler(c *gin.Context) {
+	const op = "transport.createWebhookHandler"
+
+	log := h.log.Ctx(c.Request.Context())
+
+	var req entity.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleWebhookError(c, entity.ErrInvalidData, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	sub, err := h.webhooks.CreateSubscription(ctx, &req)
+	if err != nil {
+		h.handleWebhookError(c, err, op)
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "webhook subscription created successfully",
+		logger.String("subscription_id", sub.SubscriptionID.String()),
+	)
+
+	c.JSON(http.StatusCreated, sub)
+}
+
+// @Summary Список подписок на вебхуки
+// @Description Возвращает все подписки (без секретов), включая отключенные
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} entity.WebhookSubscription "Подписки"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks [get]
+func (h *OrderHandler) listWebhooksHandler(c *gin.Context) {
+	const op = "transport.listWebhooksHandler"
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	subs, err := h.webhooks.ListSubscriptions(ctx)
+	if err != nil {
+		h.handleWebhookError(c, err, op)
+		return
+	}
+
+	c.JSON(http.StatusOK, subs)
+}
+
+// @Summary Получить подписку на вебхуки
+// @Description Возвращает подписку (без секрета) с числом неудачных доставок подряд
+// @Tags Webhooks
+// @Produce json
+// @Param subscription_id path string true "Идентификатор подписки"
+// @Success 200 {object} entity.WebhookSubscription "Подписка"
+// @Failure 400 {object} httpt.ErrorResponse "Неверный идентификатор подписки"
+// @Failure 404 {object} httpt.ErrorResponse "Подписка не найдена"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{subscription_id} [get]
+func (h *OrderHandler) getWebhookHandler(c *gin.Context) {
+	const op = "transport.getWebhookHandler"
+
+	subscriptionID, ok := h.parseSubscriptionID(c, op)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	sub, err := h.webhooks.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		h.handleWebhookError(c, err, op)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// @Summary Изменить подписку на вебхуки
+// @Description Заменяет URL, типы событий и состояние подписки. Секрет меняется, только если передан новый. Включение подписки сбрасывает счетчик неудачных доставок
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param subscription_id path string true "Идентификатор подписки"
+// @Param subscription body entity.WebhookSubscriptionRequest true "URL, типы событий, новый секрет и enabled"
+// @Success 200 {object} entity.WebhookSubscription "Измененная подписка"
+// @Failure 400 {object} httpt.ErrorResponse "Неверный URL, тип события или секрет"
+// @Failure 404 {object} httpt.ErrorResponse "Подписка не найдена"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{subscription_id} [put]
+func (h *OrderHandler) updateWebhookHandler(c *gin.Context) {
+	const op = "transport.updateWebhookHandler"
+
+	subscriptionID, ok := h.parseSubscriptionID(c, op)
+	if !ok {
+		return
+	}
+
+	var req entity.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleWebhookError(c, entity.ErrInvalidData, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	sub, err := h.webhooks.UpdateSubscription(ctx, subscriptionID, &req)
+	if err != nil {
+		h.handleWebhookError(c, err, op)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// @Summary Удалить подписку на вебхуки
+// @Description Удаляет подписку вместе с журналом ее доставок
+// @Tags Webhooks
+// @Param subscription_id path string true "Идентификатор подписки"
+// @Success 204 "Подписка удалена"
+// @Failure 400 {object} httpt.ErrorResponse "Неверный идентификатор подписки"
+// @Failure 404 {object} httpt.ErrorResponse "Подписка не найдена"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{subscription_id} [delete]
+func (h *OrderHandler) deleteWebhookHandler(c *gin.Context) {
+	const op = "transport.deleteWebhookHandler"
+
+	subscriptionID, ok := h.parseSubscriptionID(c, op)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	if err := h.webhooks.DeleteSubscription(ctx, subscriptionID); err != nil {
+		h.handleWebhookError(c, err, op)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Журнал доставок вебхука
+// @Description Возвращает доставки подписки, начиная с последней: статус, число попыток, время следующей попытки, код ответа и последнюю ошибку
+// @Tags Webhooks
+// @Produce json
+// @Param subscription_id path string true "Идентификатор подписки"
+// @Param status query string false "Статус доставки" Enums(pending, succeeded, failed)
+// @Param limit query int false "Размер страницы (1–100)" default(20)
+// @Param offset query int false "Смещение" default(0)
+// @Success 200 {object} entity.WebhookDeliveryPage "Страница доставок"
+// @Failure 400 {object} httpt.ErrorResponse "Неверный идентификатор, статус или параметры пагинации"
+// @Failure 404 {object} httpt.ErrorResponse "Подписка не найдена"
+// @Failure 500 {object} httpt.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{subscription_id}/deliveries [get]
+func (h *OrderHandler) listWebhookDeliveriesHandler(c *gin.Context) {
+	const op = "transport.listWebhookDeliveriesHandler"
+
+	subscriptionID, ok := h.parseSubscriptionID(c, op)
+	if !ok {
+		return
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit/offset"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _listTimeout)
+	defer cancel()
+
+	page, err := h.webhooks.ListDeliveries(ctx, subscriptionID, c.Query("status"), limit, offset)
+	if err != nil {
+		h.handleWebhookError(c, err, op)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *OrderHandler) parseSubscriptionID(c *gin.Context, op string) (uuid.UUID, bool) {
+	raw := c.Param("subscription_id")
+
+	subscriptionID, err := uuid.Parse(raw)
+	if err != nil {
+		h.log.Ctx(c.Request.Context()).LogAttrs(c.Request.Context(), logger.WarnLevel, "invalid subscription ID format",
+			logger.String("op", op),
+			logger.String("value", raw),
+			logger.String("remote_addr", c.ClientIP()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID format"})
+		return uuid.Nil, false
+	}
+	return subscriptionID, true
+}
+
+func (h *OrderHandler) handleWebhookError(c *gin.Context, err error, op string) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidData):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Specify an http(s) url, known event_types, a secret of 16-256 characters, a valid status and limit/offset",
+		})
+	case errors.Is(err, entity.ErrDataNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook subscription not found"})
+	default:
+		h.handleServiceError(c, err, op)
+	}
+}
@@ -0,0 +1,92 @@
+package kafkat
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+
+	"wbtest/internal/entity"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	HeaderContentType = "content-type"
+
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeAvro     = "application/avro"
+)
+
+var ErrUnknownContentType = errors.New("unknown content type")
+
+// Codec converts orders to and from one wire format.
+type Codec interface {
+	ContentType() string
+	Marshal(order *entity.Order) ([]byte, error)
+	Unmarshal(data []byte, order *entity.Order) error
+}
+
+// Codecs selects a codec by the content-type header; messages without the header use the default codec.
+type Codecs struct {
+	byType      map[string]Codec
+	defaultType string
+}
+
+func NewCodecs(defaultCodec Codec, codecs ...Codec) *Codecs {
+	c := &Codecs{
+		byType:      make(map[string]Codec, len(codecs)+1),
+		defaultType: defaultCodec.ContentType(),
+	}
+	c.byType[defaultCodec.ContentType()] = defaultCodec
+	for _, codec := range codecs {
+		c.byType[codec.ContentType()] = codec
+	}
+	return c
+}
+
+// DefaultCodecs registers JSON (the default), Protobuf and Avro.
+func DefaultCodecs() (*Codecs, error) {
+	avroCodec, err := NewAvroCodec()
+	if err != nil {
+		return nil, fmt.Errorf("transport.kafka.DefaultCodecs: %w", err)
+	}
+	return NewCodecs(JSONCodec{}, ProtobufCodec{}, avroCodec), nil
+}
+
+func (c *Codecs) Lookup(contentType string) (Codec, error) {
+	if strings.TrimSpace(contentType) == "" {
+		return c.byType[c.defaultType], nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("transport.kafka.Codecs.Lookup: %q: %w", contentType, ErrUnknownContentType)
+	}
+
+	codec, ok := c.byType[mediaType]
+	if !ok {
+		return nil, fmt.Errorf("transport.kafka.Codecs.Lookup: %q: %w", contentType, ErrUnknownContentType)
+	}
+	return codec, nil
+}
+
+// ContentTypeOf returns the content-type header of msg, or an empty string.
+func ContentTypeOf(msg kafka.Message) string {
+	value, _ := headerValue(msg, HeaderContentType)
+	return value
+}
+
+func headerValue(msg kafka.Message, key string) (string, bool) {
+	for _, h := range msg.Headers {
+		if strings.EqualFold(h.Key, key) {
+			return string(h.Value), true
+		}
+	}
+	return "", false
+}
+
+func ContentTypeHeader(codec Codec) kafka.Header {
+	return kafka.Header{Key: HeaderContentType, Value: []byte(codec.ContentType())}
+}
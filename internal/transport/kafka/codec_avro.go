@@ -0,0 +1,243 @@
+package kafkat
+
+import (
+	_ "embed"
+	"fmt"
+	"time"
+
+	"wbtest/internal/entity"
+
+	"github.com/hamba/avro/v2"
+)
+
+//go:embed schema/order.avsc
+var _orderAvroSchema string
+
+type AvroCodec struct {
+	schema avro.Schema
+}
+
+func NewAvroCodec() (*AvroCodec, error) {
+	schema, err := avro.Parse(_orderAvroSchema)
+	if err != nil {
+		return nil, fmt.Errorf("transport.kafka.NewAvroCodec: parse schema: %w", err)
+	}
+	return &AvroCodec{schema: schema}, nil
+}
+
+func (c *AvroCodec) ContentType() string {
+	return ContentTypeAvro
+}
+
+func (c *AvroCodec) Marshal(order *entity.Order) ([]byte, error) {
+	data, err := avro.Marshal(c.schema, orderToAvro(order))
+	if err != nil {
+		return nil, fmt.Errorf("transport.kafka.AvroCodec.Marshal: %w", err)
+	}
+	return data, nil
+}
+
+func (c *AvroCodec) Unmarshal(data []byte, order *entity.Order) error {
+	const op = "transport.kafka.AvroCodec.Unmarshal"
+
+	var record avroOrder
+	if err := avro.Unmarshal(c.schema, data, &record); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := orderFromAvro(&record, order); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// avroOrder and its nested records follow schema/order.avsc; Avro has no unsigned types,
+// so amounts and identifiers travel as long.
+type avroOrder struct {
+	OrderUID          string        `avro:"order_uid"`
+	TrackNumber       string        `avro:"track_number"`
+	Entry             string        `avro:"entry"`
+	Delivery          *avroDelivery `avro:"delivery"`
+	Payment           *avroPayment  `avro:"payment"`
+	Items             []avroItem    `avro:"items"`
+	Locale            string        `avro:"locale"`
+	InternalSignature string        `avro:"internal_signature"`
+	CustomerID        string        `avro:"customer_id"`
+	DeliveryService   string        `avro:"delivery_service"`
+	Shardkey          string        `avro:"shardkey"`
+	SmID              int64         `avro:"sm_id"`
+	DateCreated       time.Time     `avro:"date_created"`
+	OofShard          string        `avro:"oof_shard"`
+}
+
+type avroDelivery struct {
+	Name    string `avro:"name"`
+	Phone   string `avro:"phone"`
+	Zip     string `avro:"zip"`
+	City    string `avro:"city"`
+	Address string `avro:"address"`
+	Region  string `avro:"region"`
+	Email   string `avro:"email"`
+}
+
+type avroPayment struct {
+	Transaction  string `avro:"transaction"`
+	RequestID    string `avro:"request_id"`
+	Currency     string `avro:"currency"`
+	Provider     string `avro:"provider"`
+	Amount       int64  `avro:"amount"`
+	PaymentDt    int64  `avro:"payment_dt"`
+	Bank         string `avro:"bank"`
+	DeliveryCost int64  `avro:"delivery_cost"`
+	GoodsTotal   int64  `avro:"goods_total"`
+	CustomFee    int64  `avro:"custom_fee"`
+}
+
+type avroItem struct {
+	ChrtID      int64  `avro:"chrt_id"`
+	TrackNumber string `avro:"track_number"`
+	Price       int64  `avro:"price"`
+	Rid         string `avro:"rid"`
+	Name        string `avro:"name"`
+	Sale        int    `avro:"sale"`
+	Size        string `avro:"size"`
+	TotalPrice  int64  `avro:"total_price"`
+	NMID        int64  `avro:"nm_id"`
+	Brand       string `avro:"brand"`
+	Status      int    `avro:"status"`
+}
+
+//nolint:gosec
+func orderToAvro(order *entity.Order) *avroOrder {
+	record := &avroOrder{
+		OrderUID:          order.OrderUID.String(),
+		TrackNumber:       order.TrackNumber,
+		Entry:             order.Entry,
+		Locale:            order.Locale,
+		InternalSignature: order.InternalSignature,
+		CustomerID:        order.CustomerID,
+		DeliveryService:   order.DeliveryService,
+		Shardkey:          order.Shardkey,
+		SmID:              int64(order.SmID),
+		DateCreated:       order.DateCreated,
+		OofShard:          order.OofShard,
+		Items:             make([]avroItem, 0, len(order.Items)),
+	}
+
+	if d := order.Delivery; d != nil {
+		record.Delivery = &avroDelivery{
+			Name:    d.Name,
+			Phone:   d.Phone,
+			Zip:     d.Zip,
+			City:    d.City,
+			Address: d.Address,
+			Region:  d.Region,
+			Email:   d.Email,
+		}
+	}
+
+	if p := order.Payment; p != nil {
+		record.Payment = &avroPayment{
+			Transaction:  p.Transaction.String(),
+			RequestID:    p.RequestID.String(),
+			Currency:     p.Currency,
+			Provider:     p.Provider,
+			Amount:       p.Amount,
+			PaymentDt:    p.PaymentDt,
+			Bank:         p.Bank,
+			DeliveryCost: p.DeliveryCost,
+			GoodsTotal:   p.GoodsTotal,
+			CustomFee:    p.CustomFee,
+		}
+	}
+
+	for _, item := range order.Items {
+		record.Items = append(record.Items, avroItem{
+			ChrtID:      int64(item.ChrtID),
+			TrackNumber: item.TrackNumber,
+			Price:       item.Price,
+			Rid:         item.Rid.String(),
+			Name:        item.Name,
+			Sale:        item.Sale,
+			Size:        item.Size,
+			TotalPrice:  item.TotalPrice,
+			NMID:        int64(item.NMID),
+			Brand:       item.Brand,
+			Status:      item.Status,
+		})
+	}
+
+	return record
+}
+
+//nolint:gosec
+func orderFromAvro(record *avroOrder, order *entity.Order) error {
+	var err error
+
+	if order.OrderUID, err = parseUUID("order_uid", record.OrderUID); err != nil {
+		return err
+	}
+	order.TrackNumber = record.TrackNumber
+	order.Entry = record.Entry
+	order.Locale = record.Locale
+	order.InternalSignature = record.InternalSignature
+	order.CustomerID = record.CustomerID
+	order.DeliveryService = record.DeliveryService
+	order.Shardkey = record.Shardkey
+	order.SmID = int(record.SmID)
+	order.DateCreated = record.DateCreated.UTC()
+	order.OofShard = record.OofShard
+
+	if d := record.Delivery; d != nil {
+		order.Delivery = &entity.Delivery{
+			Name:    d.Name,
+			Phone:   d.Phone,
+			Zip:     d.Zip,
+			City:    d.City,
+			Address: d.Address,
+			Region:  d.Region,
+			Email:   d.Email,
+		}
+	}
+
+	if p := record.Payment; p != nil {
+		payment := &entity.Payment{
+			Currency:     p.Currency,
+			Provider:     p.Provider,
+			Amount:       p.Amount,
+			PaymentDt:    p.PaymentDt,
+			Bank:         p.Bank,
+			DeliveryCost: p.DeliveryCost,
+			GoodsTotal:   p.GoodsTotal,
+			CustomFee:    p.CustomFee,
+		}
+		if payment.Transaction, err = parseUUID("payment.transaction", p.Transaction); err != nil {
+			return err
+		}
+		if payment.RequestID, err = parseUUID("payment.request_id", p.RequestID); err != nil {
+			return err
+		}
+		order.Payment = payment
+	}
+
+	order.Items = make([]*entity.Item, 0, len(record.Items))
+	for _, i := range record.Items {
+		item := &entity.Item{
+			ChrtID:      uint64(i.ChrtID),
+			TrackNumber: i.TrackNumber,
+			Price:       i.Price,
+			Name:        i.Name,
+			Sale:        i.Sale,
+			Size:        i.Size,
+			TotalPrice:  i.TotalPrice,
+			NMID:        uint64(i.NMID),
+			Brand:       i.Brand,
+			Status:      i.Status,
+		}
+		if item.Rid, err = parseUUID("items.rid", i.Rid); err != nil {
+			return err
+		}
+		order.Items = append(order.Items, item)
+	}
+
+	return nil
+}
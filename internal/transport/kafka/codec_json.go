@@ -0,0 +1,29 @@
+package kafkat
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"wbtest/internal/entity"
+)
+
+type JSONCodec struct{}
+
+func (JSONCodec) ContentType() string {
+	return ContentTypeJSON
+}
+
+func (JSONCodec) Marshal(order *entity.Order) ([]byte, error) {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return nil, fmt.Errorf("transport.kafka.JSONCodec.Marshal: %w", err)
+	}
+	return data, nil
+}
+
+func (JSONCodec) Unmarshal(data []byte, order *entity.Order) error {
+	if err := json.Unmarshal(data, order); err != nil {
+		return fmt.Errorf("transport.kafka.JSONCodec.Unmarshal: %w", err)
+	}
+	return nil
+}
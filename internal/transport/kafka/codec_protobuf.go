@@ -0,0 +1,187 @@
+package kafkat
+
+import (
+	"fmt"
+
+	"wbtest/internal/entity"
+	"wbtest/internal/transport/kafka/orderpb"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) ContentType() string {
+	return ContentTypeProtobuf
+}
+
+func (ProtobufCodec) Marshal(order *entity.Order) ([]byte, error) {
+	data, err := proto.Marshal(orderToProto(order))
+	if err != nil {
+		return nil, fmt.Errorf("transport.kafka.ProtobufCodec.Marshal: %w", err)
+	}
+	return data, nil
+}
+
+func (ProtobufCodec) Unmarshal(data []byte, order *entity.Order) error {
+	const op = "transport.kafka.ProtobufCodec.Unmarshal"
+
+	var msg orderpb.Order
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := orderFromProto(&msg, order); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func orderToProto(order *entity.Order) *orderpb.Order {
+	msg := &orderpb.Order{
+		OrderUid:          order.OrderUID.String(),
+		TrackNumber:       order.TrackNumber,
+		Entry:             order.Entry,
+		Locale:            order.Locale,
+		InternalSignature: order.InternalSignature,
+		CustomerId:        order.CustomerID,
+		DeliveryService:   order.DeliveryService,
+		Shardkey:          order.Shardkey,
+		SmId:              int64(order.SmID),
+		DateCreated:       timestamppb.New(order.DateCreated),
+		OofShard:          order.OofShard,
+		Items:             make([]*orderpb.Item, 0, len(order.Items)),
+	}
+
+	if d := order.Delivery; d != nil {
+		msg.Delivery = &orderpb.Delivery{
+			Name:    d.Name,
+			Phone:   d.Phone,
+			Zip:     d.Zip,
+			City:    d.City,
+			Address: d.Address,
+			Region:  d.Region,
+			Email:   d.Email,
+		}
+	}
+
+	if p := order.Payment; p != nil {
+		msg.Payment = &orderpb.Payment{
+			Transaction:  p.Transaction.String(),
+			RequestId:    p.RequestID.String(),
+			Currency:     p.Currency,
+			Provider:     p.Provider,
+			Amount:       uint64(p.Amount),
+			PaymentDt:    p.PaymentDt,
+			Bank:         p.Bank,
+			DeliveryCost: uint64(p.DeliveryCost),
+			GoodsTotal:   uint64(p.GoodsTotal),
+			CustomFee:    uint64(p.CustomFee),
+		}
+	}
+
+	for _, item := range order.Items {
+		msg.Items = append(msg.Items, &orderpb.Item{
+			ChrtId:      item.ChrtID,
+			TrackNumber: item.TrackNumber,
+			Price:       uint64(item.Price),
+			Rid:         item.Rid.String(),
+			Name:        item.Name,
+			Sale:        int64(item.Sale),
+			Size:        item.Size,
+			TotalPrice:  uint64(item.TotalPrice),
+			NmId:        item.NMID,
+			Brand:       item.Brand,
+			Status:      int64(item.Status),
+		})
+	}
+
+	return msg
+}
+
+func orderFromProto(msg *orderpb.Order, order *entity.Order) error {
+	var err error
+
+	if order.OrderUID, err = parseUUID("order_uid", msg.GetOrderUid()); err != nil {
+		return err
+	}
+	order.TrackNumber = msg.GetTrackNumber()
+	order.Entry = msg.GetEntry()
+	order.Locale = msg.GetLocale()
+	order.InternalSignature = msg.GetInternalSignature()
+	order.CustomerID = msg.GetCustomerId()
+	order.DeliveryService = msg.GetDeliveryService()
+	order.Shardkey = msg.GetShardkey()
+	order.SmID = int(msg.GetSmId())
+	order.OofShard = msg.GetOofShard()
+	if msg.GetDateCreated() != nil {
+		order.DateCreated = msg.GetDateCreated().AsTime()
+	}
+
+	if d := msg.GetDelivery(); d != nil {
+		order.Delivery = &entity.Delivery{
+			Name:    d.GetName(),
+			Phone:   d.GetPhone(),
+			Zip:     d.GetZip(),
+			City:    d.GetCity(),
+			Address: d.GetAddress(),
+			Region:  d.GetRegion(),
+			Email:   d.GetEmail(),
+		}
+	}
+
+	if p := msg.GetPayment(); p != nil {
+		payment := &entity.Payment{
+			Currency:     p.GetCurrency(),
+			Provider:     p.GetProvider(),
+			Amount:       int64(p.GetAmount()),
+			PaymentDt:    p.GetPaymentDt(),
+			Bank:         p.GetBank(),
+			DeliveryCost: int64(p.GetDeliveryCost()),
+			GoodsTotal:   int64(p.GetGoodsTotal()),
+			CustomFee:    int64(p.GetCustomFee()),
+		}
+		if payment.Transaction, err = parseUUID("payment.transaction", p.GetTransaction()); err != nil {
+			return err
+		}
+		if payment.RequestID, err = parseUUID("payment.request_id", p.GetRequestId()); err != nil {
+			return err
+		}
+		order.Payment = payment
+	}
+
+	order.Items = make([]*entity.Item, 0, len(msg.GetItems()))
+	for _, i := range msg.GetItems() {
+		item := &entity.Item{
+			ChrtID:      i.GetChrtId(),
+			TrackNumber: i.GetTrackNumber(),
+			Price:       int64(i.GetPrice()),
+			Name:        i.GetName(),
+			Sale:        int(i.GetSale()),
+			Size:        i.GetSize(),
+			TotalPrice:  int64(i.GetTotalPrice()),
+			NMID:        i.GetNmId(),
+			Brand:       i.GetBrand(),
+			Status:      int(i.GetStatus()),
+		}
+		if item.Rid, err = parseUUID("items.rid", i.GetRid()); err != nil {
+			return err
+		}
+		order.Items = append(order.Items, item)
+	}
+
+	return nil
+}
+
+// parseUUID treats an empty string as uuid.Nil, matching how an absent UUID decodes from JSON.
+func parseUUID(field, value string) (uuid.UUID, error) {
+	if value == "" {
+		return uuid.Nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%s: %w", field, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,166 @@
+package kafkat_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"wbtest/internal/entity"
+	kafkat "wbtest/internal/transport/kafka"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+func generateFakeOrder(itemsCount int) *entity.Order {
+	orderUID := uuid.New()
+	items := make([]*entity.Item, 0, itemsCount)
+	for range itemsCount {
+		items = append(items, &entity.Item{
+			ChrtID:      uint64(gofakeit.UintRange(10000, 99999)),
+			TrackNumber: gofakeit.UUID(),
+			Price:       int64(gofakeit.UintRange(100, 1000)),
+			Rid:         uuid.New(),
+			Name:        gofakeit.ProductName(),
+			Sale:        gofakeit.Number(0, 50),
+			Size:        gofakeit.Word(),
+			TotalPrice:  int64(gofakeit.UintRange(50, 950)),
+			NMID:        uint64(gofakeit.UintRange(1000000, 9999999)),
+			Brand:       gofakeit.Company(),
+			Status:      gofakeit.Number(1, 5),
+		})
+	}
+
+	return &entity.Order{
+		OrderUID:    orderUID,
+		TrackNumber: gofakeit.UUID(),
+		Entry:       "WBIL",
+		Delivery: &entity.Delivery{
+			Name:    gofakeit.Name(),
+			Phone:   gofakeit.Phone(),
+			Zip:     gofakeit.Zip(),
+			City:    gofakeit.City(),
+			Address: gofakeit.Address().Address,
+			Region:  gofakeit.State(),
+			Email:   gofakeit.Email(),
+		},
+		Payment: &entity.Payment{
+			Transaction:  orderUID,
+			RequestID:    uuid.New(),
+			Currency:     gofakeit.CurrencyShort(),
+			Provider:     gofakeit.Word(),
+			Amount:       int64(gofakeit.UintRange(1000, 10000)),
+			PaymentDt:    time.Now().Unix(),
+			Bank:         gofakeit.BS(),
+			DeliveryCost: int64(gofakeit.UintRange(100, 500)),
+			GoodsTotal:   int64(gofakeit.UintRange(500, 9000)),
+			CustomFee:    int64(gofakeit.UintRange(0, 100)),
+		},
+		Items:           items,
+		Locale:          "en",
+		CustomerID:      gofakeit.Username(),
+		DeliveryService: gofakeit.Company(),
+		Shardkey:        "9",
+		SmID:            99,
+		DateCreated:     time.Now().UTC().Truncate(time.Microsecond),
+		OofShard:        "1",
+	}
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	codecs, err := kafkat.DefaultCodecs()
+	if err != nil {
+		t.Fatalf("DefaultCodecs: %v", err)
+	}
+
+	for _, contentType := range []string{
+		kafkat.ContentTypeJSON,
+		kafkat.ContentTypeProtobuf,
+		kafkat.ContentTypeAvro,
+	} {
+		t.Run(contentType, func(t *testing.T) {
+			t.Parallel()
+
+			codec, lookupErr := codecs.Lookup(contentType)
+			if lookupErr != nil {
+				t.Fatalf("Lookup: %v", lookupErr)
+			}
+
+			want := generateFakeOrder(3)
+			data, marshalErr := codec.Marshal(want)
+			if marshalErr != nil {
+				t.Fatalf("Marshal: %v", marshalErr)
+			}
+
+			var got entity.Order
+			if unmarshalErr := codec.Unmarshal(data, &got); unmarshalErr != nil {
+				t.Fatalf("Unmarshal: %v", unmarshalErr)
+			}
+			if !reflect.DeepEqual(want, &got) {
+				t.Errorf("round trip mismatch:\nwant %+v\ngot  %+v", want, &got)
+			}
+		})
+	}
+}
+
+func TestCodecs_Lookup(t *testing.T) {
+	t.Parallel()
+
+	codecs, err := kafkat.DefaultCodecs()
+	if err != nil {
+		t.Fatalf("DefaultCodecs: %v", err)
+	}
+
+	testCases := []struct {
+		desc        string
+		headers     []kafka.Header
+		wantType    string
+		wantUnknown bool
+	}{
+		{desc: "NoHeaderDefaultsToJSON", wantType: kafkat.ContentTypeJSON},
+		{
+			desc:     "HeaderWithParameters",
+			headers:  []kafka.Header{{Key: "Content-Type", Value: []byte("application/json; charset=utf-8")}},
+			wantType: kafkat.ContentTypeJSON,
+		},
+		{
+			desc:     "Protobuf",
+			headers:  []kafka.Header{{Key: kafkat.HeaderContentType, Value: []byte(kafkat.ContentTypeProtobuf)}},
+			wantType: kafkat.ContentTypeProtobuf,
+		},
+		{
+			desc:        "Unknown",
+			headers:     []kafka.Header{{Key: kafkat.HeaderContentType, Value: []byte("application/xml")}},
+			wantUnknown: true,
+		},
+		{
+			desc:        "Malformed",
+			headers:     []kafka.Header{{Key: kafkat.HeaderContentType, Value: []byte("application/json; =")}},
+			wantUnknown: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			codec, lookupErr := codecs.Lookup(kafkat.ContentTypeOf(kafka.Message{Headers: tc.headers}))
+			if tc.wantUnknown {
+				if !errors.Is(lookupErr, kafkat.ErrUnknownContentType) {
+					t.Fatalf("expected ErrUnknownContentType, got %v", lookupErr)
+				}
+				return
+			}
+			if lookupErr != nil {
+				t.Fatalf("Lookup: %v", lookupErr)
+			}
+			if codec.ContentType() != tc.wantType {
+				t.Errorf("expected %s, got %s", tc.wantType, codec.ContentType())
+			}
+		})
+	}
+}
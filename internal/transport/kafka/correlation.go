@@ -0,0 +1,71 @@
+package kafkat
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+
+	"wbtest/pkg/logger"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	HeaderRequestID   = "x-request-id"
+	HeaderTraceparent = "traceparent"
+
+	_traceparentParts   = 4
+	_traceIDLength      = 32
+	_zeroTraceID        = "00000000000000000000000000000000"
+	_maxRequestIDLength = 128
+)
+
+// RequestIDOf returns the correlation ID carried by msg: the x-request-id header, or the
+// trace ID of a W3C traceparent header. It returns an empty string when neither is usable.
+func RequestIDOf(msg kafka.Message) string {
+	if id, ok := headerValue(msg, HeaderRequestID); ok {
+		if id = strings.TrimSpace(id); id != "" && len(id) <= _maxRequestIDLength {
+			return id
+		}
+	}
+
+	if tp, ok := headerValue(msg, HeaderTraceparent); ok {
+		return traceIDOf(tp)
+	}
+
+	return ""
+}
+
+// traceIDOf extracts the trace-id field of a traceparent value (version-traceid-parentid-flags).
+func traceIDOf(traceparent string) string {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < _traceparentParts {
+		return ""
+	}
+
+	traceID := strings.ToLower(parts[1])
+	if len(traceID) != _traceIDLength || traceID == _zeroTraceID {
+		return ""
+	}
+	if _, err := hex.DecodeString(traceID); err != nil {
+		return ""
+	}
+	return traceID
+}
+
+// messageContext stores the message's correlation ID in ctx, generating one when the producer
+// did not send it. The generated ID is added to msg's headers so the DLQ forwards it. Every
+// LogAttrs call made with the returned context carries topic, partition, offset and request_id.
+func messageContext(ctx context.Context, log logger.Logger, msg *kafka.Message) context.Context {
+	requestID := RequestIDOf(*msg)
+	if requestID == "" {
+		requestID = log.GenerateRequestID()
+		msg.Headers = append(msg.Headers, kafka.Header{Key: HeaderRequestID, Value: []byte(requestID)})
+	}
+
+	return log.With(
+		"topic", msg.Topic,
+		"partition", msg.Partition,
+		"offset", msg.Offset,
+	).WithRequestID(ctx, requestID)
+}
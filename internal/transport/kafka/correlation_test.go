@@ -0,0 +1,71 @@
+package kafkat_test
+
+import (
+	"testing"
+
+	kafkat "wbtest/internal/transport/kafka"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestRequestIDOf(t *testing.T) {
+	t.Parallel()
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	testCases := []struct {
+		desc    string
+		headers []kafka.Header
+		want    string
+	}{
+		{
+			desc: "RequestIDHeader",
+			headers: []kafka.Header{
+				{Key: "X-Request-ID", Value: []byte("req-1")},
+				{Key: kafkat.HeaderTraceparent, Value: []byte("00-" + traceID + "-00f067aa0ba902b7-01")},
+			},
+			want: "req-1",
+		},
+		{
+			desc: "Traceparent",
+			headers: []kafka.Header{
+				{Key: kafkat.HeaderTraceparent, Value: []byte("00-" + traceID + "-00f067aa0ba902b7-01")},
+			},
+			want: traceID,
+		},
+		{
+			desc: "EmptyRequestIDFallsBackToTraceparent",
+			headers: []kafka.Header{
+				{Key: kafkat.HeaderRequestID, Value: []byte("  ")},
+				{Key: kafkat.HeaderTraceparent, Value: []byte("00-" + traceID + "-00f067aa0ba902b7-01")},
+			},
+			want: traceID,
+		},
+		{
+			desc: "ZeroTraceID",
+			headers: []kafka.Header{
+				{Key: kafkat.HeaderTraceparent, Value: []byte("00-00000000000000000000000000000000-00f067aa0ba902b7-01")},
+			},
+		},
+		{
+			desc: "MalformedTraceparent",
+			headers: []kafka.Header{
+				{Key: kafkat.HeaderTraceparent, Value: []byte("not-a-traceparent")},
+			},
+		},
+		{
+			desc: "NoHeaders",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := kafkat.RequestIDOf(kafka.Message{Headers: tc.headers})
+			if got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package kafkat
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"wbtest/internal/service"
 	"wbtest/pkg/kafka/dlq"
 	"wbtest/pkg/logger"
+	"wbtest/pkg/tracing"
 
 	"github.com/segmentio/kafka-go"
 )
@@ -25,6 +27,7 @@ type DLQProcessor struct {
 	dlqReader  *kafka.Reader
 	dlq        *dlq.DLQ
 	svc        *service.OrderService
+	codecs     *Codecs
 	maxRetries int
 	log        logger.Logger
 }
@@ -38,6 +41,7 @@ func NewDLQProcessor(
 	reader *kafka.Reader,
 	dlq *dlq.DLQ,
 	svc *service.OrderService,
+	codecs *Codecs,
 	maxRetries int,
 	log logger.Logger,
 ) *DLQProcessor {
@@ -45,6 +49,7 @@ func NewDLQProcessor(
 		dlqReader:  reader,
 		dlq:        dlq,
 		svc:        svc,
+		codecs:     codecs,
 		maxRetries: maxRetries,
 		log:        log,
 	}
@@ -80,44 +85,64 @@ func (p *DLQProcessor) processBatch(ctx context.Context) {
 		p.log.Errorw("read dlq message", "error", err)
 		return
 	}
+	processCtx, span := startConsumerSpan(processCtx, "dlq_process", &msg)
+	defer span.End()
+	processCtx = messageContext(processCtx, p.log, &msg)
 
 	var dlqMsg struct {
 		Metadata struct {
-			RetryCount int `json:"retry_count"`
+			RetryCount  int    `json:"retry_count"`
+			ContentType string `json:"content_type"`
 		} `json:"metadata"`
-		Payload string `json:"payload"`
+		Payload         string `json:"payload"`
+		PayloadEncoding string `json:"payload_encoding"`
 	}
 
 	if err = json.Unmarshal(msg.Value, &dlqMsg); err != nil {
-		p.log.Errorw("unmarshal dlq message",
-			"error", err,
-			"offset", msg.Offset,
+		p.log.LogAttrs(processCtx, logger.ErrorLevel, "unmarshal dlq message",
+			logger.Any("error", err),
 		)
 		return
 	}
 
 	if dlqMsg.Metadata.RetryCount >= p.maxRetries {
-		p.log.Infow("skipping dlq message after max retries",
-			"offset", msg.Offset,
-			"retry_count", dlqMsg.Metadata.RetryCount,
+		p.log.LogAttrs(processCtx, logger.InfoLevel, "skipping dlq message after max retries",
+			logger.Int("retry_count", dlqMsg.Metadata.RetryCount),
+		)
+		return
+	}
+
+	payload := []byte(dlqMsg.Payload)
+	if dlqMsg.PayloadEncoding == dlq.PayloadEncodingBase64 {
+		if payload, err = base64.StdEncoding.DecodeString(dlqMsg.Payload); err != nil {
+			p.log.LogAttrs(processCtx, logger.ErrorLevel, "decode dlq payload",
+				logger.Any("error", err),
+			)
+			return
+		}
+	}
+
+	codec, err := p.codecs.Lookup(dlqMsg.Metadata.ContentType)
+	if err != nil {
+		p.log.LogAttrs(processCtx, logger.ErrorLevel, "skipping dlq message with unknown content type",
+			logger.Any("error", err),
 		)
 		return
 	}
 
 	var order entity.Order
-	if err = json.Unmarshal([]byte(dlqMsg.Payload), &order); err != nil {
-		p.log.Errorw("unmarshal dlq payload",
-			"error", err,
-			"offset", msg.Offset,
+	if err = codec.Unmarshal(payload, &order); err != nil {
+		p.log.LogAttrs(processCtx, logger.ErrorLevel, "unmarshal dlq payload",
+			logger.Any("error", err),
 		)
 		return
 	}
 
 	_, err = p.svc.GetOrder(processCtx, order.OrderUID)
 	if err == nil {
-		p.log.Infow("order already exists, skipping",
-			"order_uid", order.OrderUID,
-			"offset", msg.Offset)
+		p.log.LogAttrs(processCtx, logger.InfoLevel, "order already exists, skipping",
+			logger.String("order_uid", order.OrderUID.String()),
+		)
 		return
 	}
 
@@ -125,10 +150,10 @@ func (p *DLQProcessor) processBatch(ctx context.Context) {
 	defer handleCancel()
 
 	if _, err = p.svc.CreateOrder(handleCtx, &order); err != nil {
-		p.log.Errorw("retry dlq message",
-			"error", err,
-			"offset", msg.Offset,
-			"retry_count", dlqMsg.Metadata.RetryCount,
+		tracing.RecordError(span, err)
+		p.log.LogAttrs(processCtx, logger.ErrorLevel, "retry dlq message",
+			logger.Any("error", err),
+			logger.Int("retry_count", dlqMsg.Metadata.RetryCount),
 		)
 
 		var dlqSendErr error
@@ -139,30 +164,30 @@ func (p *DLQProcessor) processBatch(ctx context.Context) {
 				Offset:    msg.Offset,
 				Key:       msg.Key,
 				Value:     msg.Value,
+				Headers:   msg.Headers,
 			}, err, dlqMsg.Metadata.RetryCount+1)
 
 			if dlqSendErr == nil {
 				break
 			}
 
-			p.log.Warnw("failed to send to DLQ, retrying",
-				"retry", i+1,
-				"error", dlqSendErr)
+			p.log.LogAttrs(processCtx, logger.WarnLevel, "failed to send to DLQ, retrying",
+				logger.Int("retry", i+1),
+				logger.Any("error", dlqSendErr),
+			)
 
 			time.Sleep(100 * time.Millisecond * time.Duration(i+1))
 		}
 
 		if dlqSendErr != nil {
-			p.log.Errorw("failed to send to DLQ after retries",
-				"offset", msg.Offset,
-				"retry_count", dlqMsg.Metadata.RetryCount+1,
-				"error", dlqSendErr,
+			p.log.LogAttrs(processCtx, logger.ErrorLevel, "failed to send to DLQ after retries",
+				logger.Int("retry_count", dlqMsg.Metadata.RetryCount+1),
+				logger.Any("error", dlqSendErr),
 			)
 		}
 	} else {
-		p.log.Infow("dlq message processed successfully",
-			"offset", msg.Offset,
-			"order_uid", order.OrderUID.String(),
+		p.log.LogAttrs(processCtx, logger.InfoLevel, "dlq message processed successfully",
+			logger.String("order_uid", order.OrderUID.String()),
 		)
 	}
 }
@@ -6,12 +6,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"wbtest/internal/entity"
+	"wbtest/internal/schema"
 	"wbtest/internal/service"
 	"wbtest/pkg/kafka/dlq"
 	"wbtest/pkg/logger"
 	"wbtest/pkg/metric"
+	"wbtest/pkg/tracing"
 
 	"github.com/segmentio/kafka-go"
 	"golang.org/x/sync/errgroup"
@@ -21,27 +24,37 @@ type DLQ interface {
 	Send(ctx context.Context, msg kafka.Message, err error, retryCount int) error
 }
 
+type SchemaValidator interface {
+	Validate(ctx context.Context, subject string, version int, payload []byte) error
+}
+
 type OrderConsumer struct {
-	reader *kafka.Reader
-	dlq    *dlq.DLQ
-	svc    *service.OrderService
-	metric metric.Kafka
-	log    logger.Logger
+	reader  *kafka.Reader
+	dlq     *dlq.DLQ
+	svc     *service.OrderService
+	codecs  *Codecs
+	schemas SchemaValidator
+	metric  metric.Kafka
+	log     logger.Logger
 }
 
 func NewOrderConsumer(
 	reader *kafka.Reader,
 	dlq *dlq.DLQ,
 	svc *service.OrderService,
+	codecs *Codecs,
+	schemas SchemaValidator,
 	metric metric.Kafka,
 	log logger.Logger,
 ) *OrderConsumer {
 	return &OrderConsumer{
-		reader: reader,
-		dlq:    dlq,
-		svc:    svc,
-		metric: metric,
-		log:    log,
+		reader:  reader,
+		dlq:     dlq,
+		svc:     svc,
+		codecs:  codecs,
+		schemas: schemas,
+		metric:  metric,
+		log:     log,
 	}
 }
 
@@ -91,10 +104,14 @@ func (c *OrderConsumer) run(ctx context.Context) error {
 }
 
 func (c *OrderConsumer) processMessage(ctx context.Context, msg kafka.Message) {
-	c.log.Infow("processing kafka message",
-		"topic", msg.Topic,
-		"partition", msg.Partition,
-		"offset", msg.Offset,
+	const op = "transport.kafka.order_consumer.processMessage"
+
+	ctx, span := startConsumerSpan(ctx, "process", &msg)
+	defer span.End()
+
+	ctx = messageContext(ctx, c.log, &msg)
+	c.log.LogAttrs(ctx, logger.InfoLevel, "processing kafka message",
+		logger.String("op", op),
 	)
 
 	err := dlq.ProcessWithRetry(
@@ -104,22 +121,23 @@ func (c *OrderConsumer) processMessage(ctx context.Context, msg kafka.Message) {
 		c.dlq,
 		c.log,
 	)
+	tracing.RecordError(span, err)
 	if err != nil {
 		dlqErr := c.dlq.Send(ctx, msg, err, c.dlq.MaxAttempts)
 		if dlqErr != nil {
-			c.log.Errorw("critical: failed to send to DLQ after retries",
-				"offset", msg.Offset,
-				"original_error", err,
-				"dlq_error", dlqErr,
+			c.log.LogAttrs(ctx, logger.ErrorLevel, "critical: failed to send to DLQ after retries",
+				logger.String("op", op),
+				logger.Any("original_error", err),
+				logger.Any("dlq_error", dlqErr),
 			)
-			c.log.Errorw("dlq fallback",
-				"payload_hash", sha256.Sum256(msg.Value),
-				"offset", msg.Offset,
+			c.log.LogAttrs(ctx, logger.ErrorLevel, "dlq fallback",
+				logger.String("op", op),
+				logger.Any("payload_hash", sha256.Sum256(msg.Value)),
 			)
 		} else {
-			c.log.Infow("message sent to DLQ after max retries",
-				"offset", msg.Offset,
-				"retry_count", c.dlq.MaxAttempts,
+			c.log.LogAttrs(ctx, logger.InfoLevel, "message sent to DLQ after max retries",
+				logger.String("op", op),
+				logger.Int("retry_count", c.dlq.MaxAttempts),
 			)
 		}
 		c.metric.MessageFailed(msg.Topic, msg.Partition, "retry_limit_exceeded")
@@ -128,19 +146,69 @@ func (c *OrderConsumer) processMessage(ctx context.Context, msg kafka.Message) {
 
 func (c *OrderConsumer) handleMessage(ctx context.Context, msg kafka.Message) error {
 	const op = "transport.kafka.order_consumer.handleMessage"
+	codec, err := c.codecs.Lookup(ContentTypeOf(msg))
+	if err != nil {
+		c.metric.MessageFailed(msg.Topic, msg.Partition, "unknown_content_type")
+		return fmt.Errorf("%s: %w: %w", op, dlq.ErrPermanent, err)
+	}
+
 	var order entity.Order
-	if err := json.Unmarshal(msg.Value, &order); err != nil {
+	if err = codec.Unmarshal(msg.Value, &order); err != nil {
 		return fmt.Errorf("%s: unmarshal order: %w", op, err)
 	}
 
-	if _, err := c.svc.CreateOrder(ctx, &order); err != nil {
+	if err = c.validateSchema(ctx, msg, codec, &order); err != nil {
+		c.metric.MessageFailed(msg.Topic, msg.Partition, "schema_validation")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err = c.svc.CreateOrder(ctx, &order); err != nil {
+		if errors.Is(err, entity.ErrConflictingData) {
+			c.metric.OrderConflict(msg.Topic, msg.Partition)
+			return fmt.Errorf("%s: create order: %w: %w", op, dlq.ErrPermanent, err)
+		}
 		return fmt.Errorf("%s: create order: %w", op, err)
 	}
 
-	c.log.Infow("order saved from kafka",
-		"order_uid", order.OrderUID.String(),
-		"offset", msg.Offset,
+	c.log.LogAttrs(ctx, logger.InfoLevel, "order saved from kafka",
+		logger.String("op", op),
+		logger.String("order_uid", order.OrderUID.String()),
 	)
 
 	return nil
 }
+
+// validateSchema checks messages that name a schema version in their headers; JSON payloads are
+// validated as received so renamed or unknown fields are caught, other formats via their JSON form.
+func (c *OrderConsumer) validateSchema(
+	ctx context.Context,
+	msg kafka.Message,
+	codec Codec,
+	order *entity.Order,
+) error {
+	raw, ok := headerValue(msg, entity.SchemaVersionHeader)
+	if !ok {
+		return nil
+	}
+
+	version, err := strconv.Atoi(raw)
+	if err != nil || version < 1 {
+		return fmt.Errorf("invalid %s header %q: %w", entity.SchemaVersionHeader, raw, dlq.ErrPermanent)
+	}
+
+	payload := msg.Value
+	if codec.ContentType() != ContentTypeJSON {
+		if payload, err = json.Marshal(order); err != nil {
+			return fmt.Errorf("marshal for schema validation: %w", err)
+		}
+	}
+
+	err = c.schemas.Validate(ctx, entity.SchemaSubjectOrder, version, payload)
+	if errors.Is(err, schema.ErrValidation) || errors.Is(err, entity.ErrDataNotFound) {
+		return fmt.Errorf("%w: %w", dlq.ErrPermanent, err)
+	}
+	if err != nil {
+		return fmt.Errorf("schema validation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package kafkat
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const _tracerName = "wbtest/internal/transport/kafka"
+
+var _tracer = otel.Tracer(_tracerName)
+
+// HeaderCarrier adapts Kafka message headers to the OpenTelemetry propagators, so producers can
+// inject traceparent and consumers continue the same trace.
+type HeaderCarrier []kafka.Header
+
+var _ propagation.TextMapCarrier = (*HeaderCarrier)(nil)
+
+func (hc *HeaderCarrier) Get(key string) string {
+	for _, h := range *hc {
+		if strings.EqualFold(h.Key, key) {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (hc *HeaderCarrier) Set(key, value string) {
+	for i, h := range *hc {
+		if strings.EqualFold(h.Key, key) {
+			(*hc)[i].Value = []byte(value)
+			return
+		}
+	}
+	*hc = append(*hc, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (hc *HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(*hc))
+	for _, h := range *hc {
+		keys = append(keys, h.Key)
+	}
+	return keys
+}
+
+// startConsumerSpan starts a span for processing msg as a child of the producer's span
+// when the message carries trace context headers.
+func startConsumerSpan(ctx context.Context, name string, msg *kafka.Message) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, (*HeaderCarrier)(&msg.Headers))
+
+	return _tracer.Start(ctx, msg.Topic+" "+name,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeProcess,
+			semconv.MessagingOperationName(name),
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingDestinationPartitionID(strconv.Itoa(msg.Partition)),
+			semconv.MessagingKafkaOffset(int(msg.Offset)),
+			semconv.MessagingKafkaMessageKey(string(msg.Key)),
+		),
+	)
+}
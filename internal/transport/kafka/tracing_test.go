@@ -0,0 +1,49 @@
+package kafkat_test
+
+import (
+	"context"
+	"testing"
+
+	kafkat "wbtest/internal/transport/kafka"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHeaderCarrier_PropagatesTraceContext(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	propagator := propagation.TraceContext{}
+
+	ctx, producerSpan := provider.Tracer("producer").Start(context.Background(), "orders publish")
+	producerSpan.End()
+
+	headers := kafkat.HeaderCarrier{
+		{Key: kafkat.HeaderContentType, Value: []byte(kafkat.ContentTypeJSON)},
+		{Key: "Traceparent", Value: []byte("stale")},
+	}
+	propagator.Inject(ctx, &headers)
+
+	if len(headers) != 2 {
+		t.Fatalf("expected traceparent to replace the stale header, got %d headers", len(headers))
+	}
+
+	msg := kafka.Message{Headers: headers}
+	extracted := propagator.Extract(context.Background(), (*kafkat.HeaderCarrier)(&msg.Headers))
+
+	got := trace.SpanContextFromContext(extracted)
+	want := producerSpan.SpanContext()
+	if got.TraceID() != want.TraceID() || got.SpanID() != want.SpanID() || !got.IsRemote() {
+		t.Fatalf("expected remote span context %s/%s, got %s/%s",
+			want.TraceID(), want.SpanID(), got.TraceID(), got.SpanID())
+	}
+
+	if id := kafkat.RequestIDOf(msg); id != want.TraceID().String() {
+		t.Fatalf("expected request id from traceparent %s, got %q", want.TraceID(), id)
+	}
+}
@@ -0,0 +1,163 @@
+// Package webhook signs order events and sends them to subscribers over HTTP.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"wbtest/internal/entity"
+)
+
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+
+	_signaturePrefix = "sha256="
+	_maxDrainedBody  = 64 << 10
+)
+
+var (
+	ErrUnexpectedStatus = errors.New("webhook: unexpected response status")
+	ErrForbiddenAddress = errors.New("webhook: address not allowed")
+)
+
+// _sharedAddressSpace is the carrier-grade NAT range of RFC 6598, internal like RFC 1918.
+var _sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Sign returns "sha256=" followed by the hex HMAC-SHA256, keyed with secret, of the decimal
+// Unix timestamp, a dot and body. Signing the timestamp lets receivers reject replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return _signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is Sign(secret, timestamp, body), in constant time.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, _signaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+type Sender struct {
+	client    *http.Client
+	userAgent string
+	now       func() time.Time
+}
+
+// NewSender sends deliveries with the given per-request timeout. Redirects are not followed:
+// a subscriber has to answer at its subscription URL. Connections to loopback, private,
+// link-local, multicast and unspecified addresses are refused unless the address is in one
+// of allowedNetworks. The check runs on the resolved address right before connecting, so a
+// host name resolving to an internal address is refused as well.
+func NewSender(timeout time.Duration, userAgent string, allowedNetworks []netip.Prefix) *Sender {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			return checkAddress(address, allowedNetworks)
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Sender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		userAgent: userAgent,
+		now:       time.Now,
+	}
+}
+
+func checkAddress(address string, allowedNetworks []netip.Prefix) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("parse address %q: %w", address, err)
+	}
+
+	addr := addrPort.Addr().Unmap()
+	if isPublic(addr) {
+		return nil
+	}
+	for _, network := range allowedNetworks {
+		if network.Contains(addr) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+}
+
+func isPublic(addr netip.Addr) bool {
+	return !addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!_sharedAddressSpace.Contains(addr)
+}
+
+// Send POSTs the delivery's event envelope to the subscriber. Any 2xx answer is a success.
+func (s *Sender) Send(ctx context.Context, outgoing *entity.OutgoingWebhook) entity.DeliveryAttempt {
+	body, err := json.Marshal(outgoing.Delivery.Event())
+	if err != nil {
+		return entity.DeliveryAttempt{Err: fmt.Errorf("marshal event: %w", err)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, outgoing.URL, bytes.NewReader(body))
+	if err != nil {
+		return entity.DeliveryAttempt{Err: fmt.Errorf("build request: %w", err)}
+	}
+
+	timestamp := s.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.userAgent)
+	req.Header.Set(HeaderEvent, outgoing.Delivery.EventType)
+	req.Header.Set(HeaderDelivery, outgoing.Delivery.DeliveryID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(outgoing.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return entity.DeliveryAttempt{Err: fmt.Errorf("send: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	// draining lets the connection be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, _maxDrainedBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return entity.DeliveryAttempt{
+			ResponseStatus: resp.StatusCode,
+			Err:            fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode),
+		}
+	}
+
+	return entity.DeliveryAttempt{ResponseStatus: resp.StatusCode}
+}
@@ -0,0 +1,198 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"wbtest/internal/entity"
+	"wbtest/internal/webhook"
+
+	"github.com/google/uuid"
+)
+
+const _secret = "0123456789abcdef0123456789abcdef"
+
+// _loopback lets the sender reach httptest servers.
+var _loopback = []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("::1/128")}
+
+func newOutgoing(url string) *entity.OutgoingWebhook {
+	return &entity.OutgoingWebhook{
+		Delivery: &entity.WebhookDelivery{
+			DeliveryID: uuid.New(),
+			EventID:    uuid.New(),
+			EventType:  entity.EventRefundCreated,
+			OrderUID:   uuid.New(),
+			Payload:    json.RawMessage(`{"amount":700}`),
+			OccurredAt: time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC),
+		},
+		URL:    url,
+		Secret: _secret,
+	}
+}
+
+func TestSender_Send(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer receiver.Close()
+
+	outgoing := newOutgoing(receiver.URL)
+	attempt := webhook.NewSender(time.Second, "order-service", _loopback).Send(context.Background(), outgoing)
+	if attempt.Err != nil || attempt.ResponseStatus != http.StatusAccepted {
+		t.Fatalf("expected a 202 success, got %+v", attempt)
+	}
+
+	req, body := <-received, <-bodies
+	if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected request %s %q", req.Method, req.Header.Get("Content-Type"))
+	}
+	if req.Header.Get(webhook.HeaderEvent) != entity.EventRefundCreated ||
+		req.Header.Get(webhook.HeaderDelivery) != outgoing.Delivery.DeliveryID.String() {
+		t.Fatalf("unexpected event headers %v", req.Header)
+	}
+
+	timestamp, err := strconv.ParseInt(req.Header.Get(webhook.HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("expected a unix timestamp, got %q", req.Header.Get(webhook.HeaderTimestamp))
+	}
+	if !webhook.Verify(_secret, timestamp, body, req.Header.Get(webhook.HeaderSignature)) {
+		t.Fatal("expected a valid signature")
+	}
+	if webhook.Verify("another-secret-of-some-length", timestamp, body, req.Header.Get(webhook.HeaderSignature)) {
+		t.Fatal("expected the signature to depend on the secret")
+	}
+
+	var event entity.Event
+	if err = json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("expected an event envelope, got %s", body)
+	}
+	if event.EventID != outgoing.Delivery.EventID || event.OrderUID != outgoing.Delivery.OrderUID ||
+		string(event.Payload) != `{"amount":700}` || !event.CreatedAt.Equal(outgoing.Delivery.OccurredAt) {
+		t.Fatalf("unexpected envelope %+v", event)
+	}
+}
+
+func TestSender_Send_Failures(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc       string
+		handler    http.HandlerFunc
+		wantStatus int
+	}{
+		{
+			desc:       "ServerError",
+			handler:    func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusInternalServerError) },
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			desc: "Redirect",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/elsewhere", http.StatusFound)
+			},
+			wantStatus: http.StatusFound,
+		},
+		{
+			desc: "Timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			receiver := httptest.NewServer(tc.handler)
+			defer receiver.Close()
+
+			attempt := webhook.NewSender(100*time.Millisecond, "order-service", _loopback).
+				Send(context.Background(), newOutgoing(receiver.URL))
+			if attempt.Err == nil {
+				t.Fatal("expected an error")
+			}
+			if attempt.ResponseStatus != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, attempt.ResponseStatus)
+			}
+			if tc.wantStatus != 0 && !errors.Is(attempt.Err, webhook.ErrUnexpectedStatus) {
+				t.Fatalf("expected ErrUnexpectedStatus, got %v", attempt.Err)
+			}
+		})
+	}
+}
+
+func TestSender_Send_ForbiddenAddress(t *testing.T) {
+	t.Parallel()
+
+	called := make(chan struct{}, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called <- struct{}{}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	for _, tc := range []struct {
+		desc string
+		url  string
+	}{
+		{desc: "Loopback", url: receiver.URL},
+		{desc: "LoopbackIPv6", url: "http://[::1]:1/hooks"},
+		{desc: "LinkLocal", url: "http://169.254.169.254/latest/meta-data/"},
+		{desc: "Private", url: "http://10.0.0.1/hooks"},
+		{desc: "SharedAddressSpace", url: "http://100.64.0.1/hooks"},
+		{desc: "Unspecified", url: "http://0.0.0.0:1/hooks"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			attempt := webhook.NewSender(time.Second, "order-service", nil).
+				Send(context.Background(), newOutgoing(tc.url))
+			if !errors.Is(attempt.Err, webhook.ErrForbiddenAddress) {
+				t.Fatalf("expected ErrForbiddenAddress, got %+v", attempt)
+			}
+		})
+	}
+
+	select {
+	case <-called:
+		t.Fatal("expected the receiver not to be called")
+	default:
+	}
+}
+
+func TestVerify_RejectsTampering(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"type":"OrderCreated"}`)
+	signature := webhook.Sign(_secret, 1700000000, body)
+
+	if !webhook.Verify(_secret, 1700000000, body, signature) {
+		t.Fatal("expected the signature to verify")
+	}
+	if webhook.Verify(_secret, 1700000001, body, signature) {
+		t.Fatal("expected another timestamp to fail")
+	}
+	if webhook.Verify(_secret, 1700000000, []byte(`{"type":"OrderDeleted"}`), signature) {
+		t.Fatal("expected another body to fail")
+	}
+	if webhook.Verify(_secret, 1700000000, body, signature[len("sha256="):]) {
+		t.Fatal("expected a signature without prefix to fail")
+	}
+}
@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"wbtest/internal/config"
+	"wbtest/internal/entity"
+	"wbtest/internal/service"
+	"wbtest/pkg/logger"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const _eventTypeHeader = "event-type"
+
+type EventWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// EventRelay drains the order event outbox, queueing webhook deliveries for the events and
+// publishing them to Kafka unless writer is nil.
+type EventRelay struct {
+	svc    *service.OrderService
+	writer EventWriter
+	cfg    config.Events
+	log    logger.Logger
+}
+
+func NewEventRelay(
+	svc *service.OrderService,
+	writer EventWriter,
+	cfg config.Events,
+	log logger.Logger,
+) *EventRelay {
+	return &EventRelay{
+		svc:    svc,
+		writer: writer,
+		cfg:    cfg,
+		log:    log,
+	}
+}
+
+func (r *EventRelay) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	r.log.Infow("event relay started",
+		"kafka", r.writer != nil,
+		"topic", r.cfg.Topic,
+		"interval", r.cfg.Interval.String(),
+		"batch_size", r.cfg.BatchSize,
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Infow("event relay shutting down")
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("worker.events.Start: %w", err)
+			}
+			return nil
+		case <-ticker.C:
+			r.runOnce(ctx)
+		}
+	}
+}
+
+// runOnce drains the outbox batch by batch until it is empty or a batch fails. Publishing a
+// batch is bounded by WriteTimeout and the batch is leased for twice as long, so a slow
+// publish is not picked up again by another instance.
+func (r *EventRelay) runOnce(ctx context.Context) {
+	for ctx.Err() == nil {
+		relayed, err := r.svc.RelayEvents(ctx, r.cfg.BatchSize, 2*r.cfg.WriteTimeout, r.publish)
+		if err != nil {
+			r.log.Errorw("event relay failed", "error", err)
+			return
+		}
+		if relayed < r.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+func (r *EventRelay) publish(ctx context.Context, events []*entity.Event) error {
+	if r.writer == nil {
+		return nil
+	}
+
+	msgs := make([]kafka.Message, 0, len(events))
+	for _, event := range events {
+		value, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("marshal event %s: %w", event.EventID, err)
+		}
+		msgs = append(msgs, kafka.Message{
+			Key:   []byte(event.OrderUID.String()),
+			Value: value,
+			Headers: []kafka.Header{
+				{Key: _eventTypeHeader, Value: []byte(event.Type)},
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.WriteTimeout)
+	defer cancel()
+
+	// nolint: wrapcheck
+	return r.writer.WriteMessages(ctx, msgs...)
+}
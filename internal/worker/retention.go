@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wbtest/internal/config"
+	"wbtest/internal/service"
+	"wbtest/pkg/logger"
+)
+
+const (
+	_retentionModeArchive = "archive"
+)
+
+type RetentionWorker struct {
+	svc *service.OrderService
+	cfg config.Retention
+	log logger.Logger
+}
+
+func NewRetentionWorker(
+	svc *service.OrderService,
+	cfg config.Retention,
+	log logger.Logger,
+) *RetentionWorker {
+	return &RetentionWorker{
+		svc: svc,
+		cfg: cfg,
+		log: log,
+	}
+}
+
+func (w *RetentionWorker) Start(ctx context.Context) error {
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	w.log.Infow("retention worker started",
+		"max_age", w.cfg.MaxAge.String(),
+		"interval", w.cfg.Interval.String(),
+		"batch_size", w.cfg.BatchSize,
+		"mode", w.cfg.Mode,
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.log.Infow("retention worker shutting down")
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("worker.retention.Start: %w", err)
+			}
+			return nil
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *RetentionWorker) runOnce(ctx context.Context) {
+	cutoff := time.Now().Add(-w.cfg.MaxAge)
+	archive := w.cfg.Mode == _retentionModeArchive
+
+	if _, err := w.svc.PurgeExpiredOrders(ctx, cutoff, w.cfg.BatchSize, archive); err != nil {
+		w.log.Errorw("retention purge failed",
+			"error", err,
+			"cutoff", cutoff,
+		)
+	}
+}
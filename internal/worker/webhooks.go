@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wbtest/internal/config"
+	"wbtest/internal/service"
+	"wbtest/internal/webhook"
+	"wbtest/pkg/logger"
+)
+
+// WebhookDispatcher sends due webhook deliveries to their subscribers.
+type WebhookDispatcher struct {
+	svc    *service.WebhookService
+	sender *webhook.Sender
+	cfg    config.Webhooks
+	log    logger.Logger
+}
+
+func NewWebhookDispatcher(
+	svc *service.WebhookService,
+	sender *webhook.Sender,
+	cfg config.Webhooks,
+	log logger.Logger,
+) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		svc:    svc,
+		sender: sender,
+		cfg:    cfg,
+		log:    log,
+	}
+}
+
+func (d *WebhookDispatcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	d.log.Infow("webhook dispatcher started",
+		"interval", d.cfg.Interval.String(),
+		"batch_size", d.cfg.BatchSize,
+		"concurrency", d.cfg.Concurrency,
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.log.Infow("webhook dispatcher shutting down")
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("worker.webhooks.Start: %w", err)
+			}
+			return nil
+		case <-ticker.C:
+			d.runOnce(ctx)
+		}
+	}
+}
+
+// runOnce sends due deliveries batch by batch until none are left or a batch fails.
+func (d *WebhookDispatcher) runOnce(ctx context.Context) {
+	for ctx.Err() == nil {
+		sent, err := d.svc.DeliverDue(ctx, d.cfg.BatchSize, d.cfg.Concurrency, d.sender.Send)
+		if err != nil {
+			d.log.Errorw("webhook dispatch failed", "error", err)
+			return
+		}
+		if sent < d.cfg.BatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1 @@
+ALTER TABLE orders ADD COLUMN payload_hash CHAR(64);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS order_audit CASCADE;
+ALTER TABLE orders DROP COLUMN IF EXISTS version;
\ No newline at end of file
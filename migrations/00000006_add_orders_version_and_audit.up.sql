@@ -0,0 +1,13 @@
+ALTER TABLE orders ADD COLUMN version INT NOT NULL DEFAULT 1 CHECK (version > 0);
+
+CREATE TABLE order_audit (
+    audit_id BIGSERIAL PRIMARY KEY,
+    order_uid UUID NOT NULL REFERENCES orders(order_uid) ON DELETE CASCADE,
+    version INT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    diff TEXT NOT NULL,
+    snapshot JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_order_audit_order_uid ON order_audit(order_uid, version);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS orders_archive CASCADE;
+DROP INDEX IF EXISTS idx_orders_deleted_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
@@ -0,0 +1,13 @@
+ALTER TABLE orders ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_orders_deleted_at ON orders(deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE orders_archive (
+    order_uid UUID PRIMARY KEY,
+    date_created TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    payload JSONB NOT NULL,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_orders_archive_date_created ON orders_archive(date_created DESC);
\ No newline at end of file
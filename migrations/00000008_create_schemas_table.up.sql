@@ -0,0 +1,9 @@
+CREATE TABLE schemas (
+    subject VARCHAR(100) NOT NULL,
+    version INT NOT NULL CHECK (version > 0),
+    fingerprint CHAR(64) NOT NULL,
+    definition JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (subject, version),
+    UNIQUE (subject, fingerprint)
+);
@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS trg_items_order_search ON items;
+DROP TRIGGER IF EXISTS trg_delivery_order_search ON delivery;
+
+DROP FUNCTION IF EXISTS order_search_refresh_trigger();
+DROP FUNCTION IF EXISTS refresh_order_search(UUID);
+
+DROP INDEX IF EXISTS idx_items_track_number_trgm;
+DROP INDEX IF EXISTS idx_orders_track_number_trgm;
+DROP INDEX IF EXISTS idx_delivery_email_trgm;
+DROP INDEX IF EXISTS idx_delivery_phone_trgm;
+
+DROP TABLE IF EXISTS order_search;
@@ -0,0 +1,54 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm SCHEMA public;
+
+-- One document per order, kept in sync by triggers since it spans delivery and items.
+-- The 'simple' configuration is used because names and addresses are not in one language.
+CREATE TABLE order_search (
+    order_uid UUID PRIMARY KEY REFERENCES orders(order_uid) ON DELETE CASCADE,
+    document TSVECTOR NOT NULL
+);
+
+CREATE INDEX idx_order_search_document ON order_search USING GIN (document);
+
+CREATE INDEX idx_delivery_phone_trgm ON delivery USING GIN (phone gin_trgm_ops);
+CREATE INDEX idx_delivery_email_trgm ON delivery USING GIN (email gin_trgm_ops);
+CREATE INDEX idx_orders_track_number_trgm ON orders USING GIN (track_number gin_trgm_ops);
+CREATE INDEX idx_items_track_number_trgm ON items USING GIN (track_number gin_trgm_ops);
+
+CREATE FUNCTION refresh_order_search(p_order_uid UUID) RETURNS void AS $$
+    INSERT INTO order_search (order_uid, document)
+    SELECT o.order_uid,
+        setweight(to_tsvector('simple', COALESCE(d.name, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(i.names, '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE(d.city, '') || ' ' || COALESCE(d.address, '')), 'C')
+    FROM orders o
+    LEFT JOIN delivery d ON d.order_uid = o.order_uid
+    LEFT JOIN LATERAL (
+        SELECT string_agg(name || ' ' || brand, ' ') AS names
+        FROM items
+        WHERE order_uid = o.order_uid
+    ) i ON TRUE
+    WHERE o.order_uid = p_order_uid
+    ON CONFLICT (order_uid) DO UPDATE SET document = EXCLUDED.document;
+$$ LANGUAGE sql;
+
+CREATE FUNCTION order_search_refresh_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        PERFORM refresh_order_search(OLD.order_uid);
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.order_uid <> OLD.order_uid) THEN
+        PERFORM refresh_order_search(NEW.order_uid);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_delivery_order_search
+    AFTER INSERT OR UPDATE OR DELETE ON delivery
+    FOR EACH ROW EXECUTE FUNCTION order_search_refresh_trigger();
+
+CREATE TRIGGER trg_items_order_search
+    AFTER INSERT OR UPDATE OR DELETE ON items
+    FOR EACH ROW EXECUTE FUNCTION order_search_refresh_trigger();
+
+SELECT refresh_order_search(order_uid) FROM orders;
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id ON orders(customer_id);
+
+DROP INDEX IF EXISTS idx_orders_customer_id_date_created;
@@ -0,0 +1,4 @@
+-- Customer listings page through live orders newest first, so the index covers both.
+CREATE INDEX idx_orders_customer_id_date_created ON orders(customer_id, date_created DESC) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_orders_customer_id;
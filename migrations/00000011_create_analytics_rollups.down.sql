@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS analytics_items_hourly;
+DROP TABLE IF EXISTS analytics_orders_hourly;
@@ -0,0 +1,44 @@
+-- Hourly rollups maintained by the order service in the same transaction as the order, so
+-- analytics never scan payment or items. Day and week buckets are summed from hours.
+CREATE TABLE analytics_orders_hourly (
+    bucket TIMESTAMPTZ NOT NULL,
+    delivery_service VARCHAR(50) NOT NULL,
+    locale CHAR(2) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    bank VARCHAR(50) NOT NULL,
+    orders BIGINT NOT NULL,
+    revenue BIGINT NOT NULL,
+    PRIMARY KEY (bucket, delivery_service, locale, currency, provider, bank)
+);
+
+CREATE TABLE analytics_items_hourly (
+    bucket TIMESTAMPTZ NOT NULL,
+    delivery_service VARCHAR(50) NOT NULL,
+    locale CHAR(2) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    bank VARCHAR(50) NOT NULL,
+    nm_id BIGINT NOT NULL,
+    brand VARCHAR(100) NOT NULL,
+    items BIGINT NOT NULL,
+    revenue BIGINT NOT NULL,
+    PRIMARY KEY (bucket, delivery_service, locale, currency, provider, bank, nm_id, brand)
+);
+
+INSERT INTO analytics_orders_hourly (bucket, delivery_service, locale, currency, provider, bank, orders, revenue)
+SELECT date_trunc('hour', o.date_created AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+       o.delivery_service, o.locale, p.currency, p.provider, p.bank, COUNT(*), SUM(p.amount)
+FROM orders o
+JOIN payment p ON p.order_uid = o.order_uid
+WHERE o.deleted_at IS NULL
+GROUP BY 1, 2, 3, 4, 5, 6;
+
+INSERT INTO analytics_items_hourly (bucket, delivery_service, locale, currency, provider, bank, nm_id, brand, items, revenue)
+SELECT date_trunc('hour', o.date_created AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+       o.delivery_service, o.locale, p.currency, p.provider, p.bank, i.nm_id, i.brand, COUNT(*), SUM(i.total_price)
+FROM orders o
+JOIN payment p ON p.order_uid = o.order_uid
+JOIN items i ON i.order_uid = o.order_uid
+WHERE o.deleted_at IS NULL
+GROUP BY 1, 2, 3, 4, 5, 6, 7, 8;
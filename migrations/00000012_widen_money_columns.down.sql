@@ -0,0 +1,11 @@
+-- Fails if an amount no longer fits in INT.
+ALTER TABLE items
+    ALTER COLUMN total_price TYPE INT,
+    ALTER COLUMN price TYPE INT;
+
+ALTER TABLE payment
+    DROP CONSTRAINT IF EXISTS payment_currency_iso4217,
+    ALTER COLUMN custom_fee TYPE INT,
+    ALTER COLUMN goods_total TYPE INT,
+    ALTER COLUMN delivery_cost TYPE INT,
+    ALTER COLUMN amount TYPE INT;
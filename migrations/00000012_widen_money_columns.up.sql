@@ -0,0 +1,13 @@
+-- Amounts are minor units of the payment currency and outgrow INT for large orders in
+-- currencies with small minor units. The currency check is NOT VALID so rows written
+-- before codes were validated do not block the migration.
+ALTER TABLE payment
+    ALTER COLUMN amount TYPE BIGINT,
+    ALTER COLUMN delivery_cost TYPE BIGINT,
+    ALTER COLUMN goods_total TYPE BIGINT,
+    ALTER COLUMN custom_fee TYPE BIGINT,
+    ADD CONSTRAINT payment_currency_iso4217 CHECK (currency ~ '^[A-Z]{3}$') NOT VALID;
+
+ALTER TABLE items
+    ALTER COLUMN price TYPE BIGINT,
+    ALTER COLUMN total_price TYPE BIGINT;
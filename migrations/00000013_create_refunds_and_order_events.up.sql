@@ -0,0 +1,25 @@
+-- Refunds are in minor units of the payment currency. items holds the rids of refunded
+-- items and is empty for an amount-only refund.
+CREATE TABLE refunds (
+    refund_id UUID PRIMARY KEY,
+    order_uid UUID NOT NULL REFERENCES orders(order_uid) ON DELETE CASCADE,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    items UUID[] NOT NULL DEFAULT '{}',
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_refunds_order_uid ON refunds(order_uid, created_at);
+
+-- Transactional outbox: events are written with the change that caused them and deleted
+-- once the relay has published them.
+CREATE TABLE order_events (
+    event_id UUID PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    order_uid UUID NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_order_events_created_at ON order_events(created_at);
@@ -0,0 +1 @@
+ALTER TABLE order_events DROP COLUMN IF EXISTS leased_until;
@@ -0,0 +1,3 @@
+-- A relay leases the events it publishes until leased_until instead of keeping them locked
+-- while it talks to Kafka. Events whose lease expired are picked up again.
+ALTER TABLE order_events ADD COLUMN leased_until TIMESTAMPTZ;
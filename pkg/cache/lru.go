@@ -12,14 +12,30 @@ import (
 
 const (
 	_removePreallocSize = 10
+
+	_defaultName = "order"
 )
 
+type lruOptions struct {
+	name string
+}
+
+type LRUOption func(*lruOptions)
+
+// WithName sets the cache_type label of the cache's metrics, "order" by default.
+func WithName(name string) LRUOption {
+	return func(o *lruOptions) {
+		o.name = name
+	}
+}
+
 type LRUCache[K comparable, V any] struct {
 	cache   map[K]*list.Element
 	lruList *list.List
 	mutex   sync.Mutex
 	log     logger.Logger
 	metrics metric.Cache
+	name    string
 
 	capacity        int
 	cleanupInterval time.Duration
@@ -37,17 +53,24 @@ func NewLRUCache[K comparable, V any](
 	capacity int,
 	log logger.Logger,
 	metrics metric.Cache,
+	opts ...LRUOption,
 ) (*LRUCache[K, V], error) {
 	if capacity <= 0 {
 		return nil, fmt.Errorf("cache.NewLRUCache: capacity must be positive, got %d", capacity)
 	}
 
+	options := lruOptions{name: _defaultName}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	return &LRUCache[K, V]{
 		capacity: capacity,
 		cache:    make(map[K]*list.Element),
 		lruList:  list.New(),
 		log:      log,
 		metrics:  metrics,
+		name:     options.name,
 	}, nil
 }
 
@@ -59,7 +82,7 @@ func (c *LRUCache[K, V]) Get(key K) (V, bool) {
 
 	elem, ok := c.cache[key]
 	if !ok {
-		c.metrics.Miss("order")
+		c.metrics.Miss(c.name)
 		return zero, false
 	}
 
@@ -68,19 +91,19 @@ func (c *LRUCache[K, V]) Get(key K) (V, bool) {
 		c.log.Errorw("cache contains value of unexpected type",
 			"type", fmt.Sprintf("%T", elem.Value),
 		)
-		c.removeElement(elem)
-		c.metrics.Miss("order")
+		c.removeElement(elem, "lru")
+		c.metrics.Miss(c.name)
 		return zero, false
 	}
 
 	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
-		c.removeElement(elem)
-		c.metrics.Miss("order")
+		c.removeElement(elem, "lru")
+		c.metrics.Miss(c.name)
 		return zero, false
 	}
 
 	c.lruList.MoveToFront(elem)
-	c.metrics.Hit("order")
+	c.metrics.Hit(c.name)
 
 	return entry.value, true
 }
@@ -136,6 +159,19 @@ func (c *LRUCache[K, V]) Has(key K) bool {
 	return entry.expires.IsZero() || time.Now().Before(entry.expires)
 }
 
+func (c *LRUCache[K, V]) Delete(key K) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.cache[key]
+	if !ok {
+		return false
+	}
+
+	c.removeElement(elem, "delete")
+	return true
+}
+
 func (c *LRUCache[K, V]) Len() int {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
@@ -232,7 +268,7 @@ func (c *LRUCache[K, V]) cleanupExpired() {
 	}
 
 	for _, elem := range toRemove {
-		c.removeElement(elem)
+		c.removeElement(elem, "lru")
 		removed++
 	}
 
@@ -246,11 +282,11 @@ func (c *LRUCache[K, V]) cleanupExpired() {
 
 func (c *LRUCache[K, V]) removeOldest() {
 	if elem := c.lruList.Back(); elem != nil {
-		c.removeElement(elem)
+		c.removeElement(elem, "lru")
 	}
 }
 
-func (c *LRUCache[K, V]) removeElement(elem *list.Element) {
+func (c *LRUCache[K, V]) removeElement(elem *list.Element, reason string) {
 	c.lruList.Remove(elem)
 	entry, ok := elem.Value.(*entry[K, V])
 	if !ok {
@@ -263,7 +299,7 @@ func (c *LRUCache[K, V]) removeElement(elem *list.Element) {
 	if c.onEvicted != nil {
 		c.onEvicted(entry.key, entry.value)
 	}
-	c.metrics.Eviction("order", "lru")
+	c.metrics.Eviction(c.name, reason)
 }
 
 func (c *LRUCache[K, V]) SetOnEvicted(onEvicted func(key K, value V)) {
@@ -362,6 +362,71 @@ func TestLRUCache_Has(t *testing.T) {
 	}
 }
 
+func TestLRUCache_Delete(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc        string
+		putKey      int
+		deleteKey   int
+		expected    bool
+		expectedLen int
+	}{
+		{
+			desc:        "ExistingKey",
+			putKey:      1,
+			deleteKey:   1,
+			expected:    true,
+			expectedLen: 0,
+		},
+		{
+			desc:        "NonExistentKey",
+			putKey:      1,
+			deleteKey:   99,
+			expected:    false,
+			expectedLen: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			mockLogger := mock_logger.NewMockLogger(ctrl)
+			mockMetrics := mock_metric.NewMockCache(ctrl)
+
+			mockMetrics.EXPECT().Hit(gomock.Any()).AnyTimes()
+			mockMetrics.EXPECT().Miss(gomock.Any()).AnyTimes()
+			if tc.expected {
+				mockMetrics.EXPECT().Eviction("order", "delete").Times(1)
+			}
+
+			c, _ := cache.NewLRUCache[int, string](1, mockLogger, mockMetrics)
+
+			var evicted []int
+			c.SetOnEvicted(func(key int, _ string) {
+				evicted = append(evicted, key)
+			})
+			c.Put(tc.putKey, "value", 0)
+
+			if got := c.Delete(tc.deleteKey); got != tc.expected {
+				t.Errorf("Delete() = %v; want %v", got, tc.expected)
+			}
+			if c.Has(tc.deleteKey) {
+				t.Errorf("Has(%d) = true after Delete", tc.deleteKey)
+			}
+			if c.Len() != tc.expectedLen {
+				t.Errorf("Len() = %d; want %d", c.Len(), tc.expectedLen)
+			}
+			if tc.expected && (len(evicted) != 1 || evicted[0] != tc.deleteKey) {
+				t.Errorf("evicted = %v; want [%d]", evicted, tc.deleteKey)
+			}
+		})
+	}
+}
+
 type onEvictedTestInput struct {
 	capacity  int
 	ops       []int
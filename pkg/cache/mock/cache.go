@@ -54,6 +54,20 @@ func (mr *MockCacheMockRecorder[K, V]) Capacity() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capacity", reflect.TypeOf((*MockCache[K, V])(nil).Capacity))
 }
 
+// Delete mocks base method.
+func (m *MockCache[K, V]) Delete(key K) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", key)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCacheMockRecorder[K, V]) Delete(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCache[K, V])(nil).Delete), key)
+}
+
 // Get mocks base method.
 func (m *MockCache[K, V]) Get(key K) (V, bool) {
 	m.ctrl.T.Helper()
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand/v2"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"wbtest/internal/config"
 	"wbtest/pkg/logger"
@@ -21,8 +24,17 @@ const (
 	_defaultMaxRetryDelay  = 5 * time.Second
 
 	_backoffMultiplier = 2
+
+	_contentTypeHeader = "content-type"
+	_requestIDHeader   = "x-request-id"
 )
 
+// PayloadEncodingBase64 marks envelopes whose payload is not valid UTF-8 (Protobuf, Avro)
+// and was base64-encoded to survive the JSON envelope.
+const PayloadEncodingBase64 = "base64"
+
+var ErrPermanent = errors.New("permanent failure")
+
 type DLQ struct {
 	writer  *kafka.Writer
 	log     logger.Logger
@@ -104,11 +116,32 @@ func (d *DLQ) Send(
 		"error":          err.Error(),
 		"timestamp":      time.Now().UTC().Format(time.RFC3339),
 	}
+	headers := originalMsg.Headers
+	hasRequestID := false
+	for _, h := range headers {
+		switch {
+		case strings.EqualFold(h.Key, _contentTypeHeader):
+			metadata["content_type"] = string(h.Value)
+		case strings.EqualFold(h.Key, _requestIDHeader):
+			hasRequestID = true
+		}
+	}
+	if requestID := d.log.GetRequestID(ctx); requestID != "" {
+		metadata["request_id"] = requestID
+		if !hasRequestID {
+			headers = append(headers[:len(headers):len(headers)],
+				kafka.Header{Key: _requestIDHeader, Value: []byte(requestID)})
+		}
+	}
 
 	dlqMessage := map[string]interface{}{
 		"metadata": metadata,
 		"payload":  string(originalMsg.Value),
 	}
+	if !utf8.Valid(originalMsg.Value) {
+		dlqMessage["payload"] = base64.StdEncoding.EncodeToString(originalMsg.Value)
+		dlqMessage["payload_encoding"] = PayloadEncodingBase64
+	}
 
 	value, err := json.Marshal(dlqMessage)
 	if err != nil {
@@ -153,14 +186,15 @@ func (d *DLQ) Send(
 	}
 
 	err = d.writer.WriteMessages(ctx, kafka.Message{
-		Key:   originalMsg.Key,
-		Value: value,
+		Key:     originalMsg.Key,
+		Value:   value,
+		Headers: headers,
 	})
 	if err != nil {
-		d.log.Errorw("failed to send message to dlq",
-			"op", op,
-			"error", err,
-			"offset", originalMsg.Offset,
+		d.log.LogAttrs(ctx, logger.ErrorLevel, "failed to send message to dlq",
+			logger.String("op", op),
+			logger.Any("error", err),
+			logger.Int64("offset", originalMsg.Offset),
 		)
 
 		if d.metrics != nil {
@@ -170,11 +204,11 @@ func (d *DLQ) Send(
 		return fmt.Errorf("%s: send message: %w", op, err)
 	}
 
-	d.log.Infow("message sent to dlq",
-		"op", op,
-		"topic", d.writer.Topic,
-		"offset", originalMsg.Offset,
-		"retry_count", retryCount,
+	d.log.LogAttrs(ctx, logger.InfoLevel, "message sent to dlq",
+		logger.String("op", op),
+		logger.String("dlq_topic", d.writer.Topic),
+		logger.Int64("offset", originalMsg.Offset),
+		logger.Int("retry_count", retryCount),
 	)
 
 	return nil
@@ -226,6 +260,11 @@ func ProcessWithRetry(
 			logger.Int("retry_count", attemptCount),
 			logger.Any("error", err),
 		)
+
+		if errors.Is(err, ErrPermanent) {
+			return dlq.Send(ctx, msg, err, attemptCount)
+		}
+
 		nextBackoff := currentBackoff * _backoffMultiplier
 		if nextBackoff > dlq.maxRetryDelay {
 			nextBackoff = dlq.maxRetryDelay
@@ -3,6 +3,7 @@ package kafka
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"wbtest/internal/config"
 	"wbtest/pkg/logger"
@@ -10,30 +11,23 @@ import (
 	"github.com/segmentio/kafka-go"
 )
 
-type contextKey string
-
-const kafkaMetadataKey contextKey = "kafka_metadata"
+// _writerBatchTimeout keeps synchronous writes of less than a full batch from waiting for
+// kafka-go's default of one second.
+const _writerBatchTimeout = 10 * time.Millisecond
 
 func NewKafkaReader(cfg config.Kafka, log logger.Logger) (*kafka.Reader, error) {
+	readerLog := log.With("topic", cfg.Topic, "group_id", cfg.GroupID)
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers: cfg.Brokers,
 		Topic:   cfg.Topic,
 		GroupID: cfg.GroupID,
 		Logger: kafka.LoggerFunc(func(msg string, args ...any) {
-			ctx := context.WithValue(context.Background(), kafkaMetadataKey, map[string]string{
-				"topic":    cfg.Topic,
-				"group_id": cfg.GroupID,
-			})
-			log.LogAttrs(ctx, logger.InfoLevel, "kafka reader info",
+			readerLog.LogAttrs(context.Background(), logger.InfoLevel, "kafka reader info",
 				logger.String("message", fmt.Sprintf(msg, args...)),
 			)
 		}),
 		ErrorLogger: kafka.LoggerFunc(func(msg string, args ...any) {
-			ctx := context.WithValue(context.Background(), kafkaMetadataKey, map[string]string{
-				"topic":    cfg.Topic,
-				"group_id": cfg.GroupID,
-			})
-			log.LogAttrs(ctx, logger.ErrorLevel, "kafka reader error",
+			readerLog.LogAttrs(context.Background(), logger.ErrorLevel, "kafka reader error",
 				logger.String("error", fmt.Sprintf(msg, args...)),
 			)
 		}),
@@ -65,3 +59,34 @@ func checkKafkaConnection(brokers []string, log logger.Logger) error {
 	}
 	return nil
 }
+
+// NewKafkaWriter writes order events to cfg.Topic. Messages are keyed by order, so events of
+// one order stay in one partition and in order.
+func NewKafkaWriter(brokers []string, cfg config.Events, log logger.Logger) (*kafka.Writer, error) {
+	writerLog := log.With("topic", cfg.Topic)
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        cfg.Topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchSize:    cfg.BatchSize,
+		BatchTimeout: _writerBatchTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		Logger: kafka.LoggerFunc(func(msg string, args ...any) {
+			writerLog.LogAttrs(context.Background(), logger.DebugLevel, "kafka writer info",
+				logger.String("message", fmt.Sprintf(msg, args...)),
+			)
+		}),
+		ErrorLogger: kafka.LoggerFunc(func(msg string, args ...any) {
+			writerLog.LogAttrs(context.Background(), logger.ErrorLevel, "kafka writer error",
+				logger.String("error", fmt.Sprintf(msg, args...)),
+			)
+		}),
+	}
+
+	if err := checkKafkaConnection(brokers, log); err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
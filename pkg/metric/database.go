@@ -0,0 +1,147 @@
+package metric
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ Database = (*databaseMetrics)(nil)
+
+type databaseMetrics struct {
+	queryDuration *prometheus.HistogramVec
+	slowQueries   *prometheus.CounterVec
+	pools         *poolCollector
+}
+
+func newDatabaseMetrics(registry *promRegistry) *databaseMetrics {
+	queryDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Duration of database queries in seconds by repository operation",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5},
+		},
+		[]string{"operation"},
+	)
+
+	slowQueries := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "db_slow_queries_total",
+			Help: "Total number of queries slower than the configured threshold",
+		},
+		[]string{"operation"},
+	)
+
+	pools := newPoolCollector()
+
+	registry.registry.MustRegister(queryDuration, slowQueries, pools)
+
+	return &databaseMetrics{
+		queryDuration: queryDuration,
+		slowQueries:   slowQueries,
+		pools:         pools,
+	}
+}
+
+func (m *databaseMetrics) ObserveQuery(operation string, duration time.Duration) {
+	m.queryDuration.WithLabelValues(operation).Observe(duration.Seconds())
+}
+
+func (m *databaseMetrics) IncrementSlowQueries(operation string) {
+	m.slowQueries.WithLabelValues(operation).Inc()
+}
+
+func (m *databaseMetrics) RegisterPool(pool string, stats func() PoolStats) {
+	m.pools.register(pool, stats)
+}
+
+// poolCollector reads pool statistics at scrape time instead of polling them, so the
+// exported values are never older than the scrape itself.
+type poolCollector struct {
+	mu    sync.RWMutex
+	pools map[string]func() PoolStats
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	emptyAcquireWaitTime *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+}
+
+func newPoolCollector() *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(name, help, []string{"pool"}, nil)
+	}
+
+	return &poolCollector{
+		pools: make(map[string]func() PoolStats),
+
+		acquiredConns: desc("db_pool_acquired_connections",
+			"Number of connections currently acquired from the pool"),
+		idleConns: desc("db_pool_idle_connections",
+			"Number of idle connections in the pool"),
+		totalConns: desc("db_pool_total_connections",
+			"Total number of connections in the pool, including those being established"),
+		maxConns: desc("db_pool_max_connections",
+			"Maximum size of the pool"),
+		acquireCount: desc("db_pool_acquires_total",
+			"Total number of successful connection acquires"),
+		acquireDuration: desc("db_pool_acquire_duration_seconds_total",
+			"Total time spent acquiring connections"),
+		emptyAcquireCount: desc("db_pool_empty_acquires_total",
+			"Total number of acquires that had to wait because the pool had no idle connection"),
+		emptyAcquireWaitTime: desc("db_pool_empty_acquire_wait_seconds_total",
+			"Total time spent waiting for a connection when the pool had no idle connection"),
+		canceledAcquireCount: desc("db_pool_canceled_acquires_total",
+			"Total number of acquires canceled by their context"),
+	}
+}
+
+func (c *poolCollector) register(pool string, stats func() PoolStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pools[pool] = stats
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquireCount
+	ch <- c.acquireDuration
+	ch <- c.emptyAcquireCount
+	ch <- c.emptyAcquireWaitTime
+	ch <- c.canceledAcquireCount
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for pool, stats := range c.pools {
+		s := stats()
+
+		gauge := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, pool)
+		}
+		counter := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, pool)
+		}
+
+		gauge(c.acquiredConns, float64(s.AcquiredConns))
+		gauge(c.idleConns, float64(s.IdleConns))
+		gauge(c.totalConns, float64(s.TotalConns))
+		gauge(c.maxConns, float64(s.MaxConns))
+		counter(c.acquireCount, float64(s.AcquireCount))
+		counter(c.acquireDuration, s.AcquireDuration.Seconds())
+		counter(c.emptyAcquireCount, float64(s.EmptyAcquireCount))
+		counter(c.emptyAcquireWaitTime, s.EmptyAcquireWaitTime.Seconds())
+		counter(c.canceledAcquireCount, float64(s.CanceledAcquireCount))
+	}
+}
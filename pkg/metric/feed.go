@@ -0,0 +1,57 @@
+package metric
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ Feed = (*feedMetrics)(nil)
+
+type feedMetrics struct {
+	clients        prometheus.Gauge
+	published      prometheus.Counter
+	disconnections *prometheus.CounterVec
+}
+
+func newFeedMetrics(registry *promRegistry) *feedMetrics {
+	clients := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "order_feed_clients",
+			Help: "Current number of clients connected to the live order feed",
+		},
+	)
+
+	published := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "order_feed_published_total",
+			Help: "Total number of orders published to the live order feed",
+		},
+	)
+
+	disconnections := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "order_feed_disconnections_total",
+			Help: "Total number of live order feed clients disconnected, by reason (closed, slow, shutdown)",
+		},
+		[]string{"reason"},
+	)
+
+	registry.registry.MustRegister(clients, published, disconnections)
+
+	return &feedMetrics{
+		clients:        clients,
+		published:      published,
+		disconnections: disconnections,
+	}
+}
+
+func (m *feedMetrics) Clients(count int) {
+	m.clients.Set(float64(count))
+}
+
+func (m *feedMetrics) Published() {
+	m.published.Inc()
+}
+
+func (m *feedMetrics) Disconnected(reason string) {
+	m.disconnections.WithLabelValues(reason).Inc()
+}
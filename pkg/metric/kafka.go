@@ -9,6 +9,7 @@ var _ Kafka = (*kafkaMetrics)(nil)
 type kafkaMetrics struct {
 	messagesProcessed *prometheus.CounterVec
 	messagesFailed    *prometheus.CounterVec
+	orderConflicts    *prometheus.CounterVec
 	consumerGroupLag  *prometheus.GaugeVec
 }
 
@@ -29,6 +30,14 @@ func newKafkaMetrics(registry *promRegistry) *kafkaMetrics {
 		[]string{"topic", "partition", "reason"},
 	)
 
+	conflicts := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_order_conflicts_total",
+			Help: "Total number of orders rejected because the payload conflicts with the stored order",
+		},
+		[]string{"topic", "partition"},
+	)
+
 	lag := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "kafka_consumer_group_lag",
@@ -37,11 +46,12 @@ func newKafkaMetrics(registry *promRegistry) *kafkaMetrics {
 		[]string{"topic", "partition"},
 	)
 
-	registry.registry.MustRegister(processed, failed, lag)
+	registry.registry.MustRegister(processed, failed, conflicts, lag)
 
 	return &kafkaMetrics{
 		messagesProcessed: processed,
 		messagesFailed:    failed,
+		orderConflicts:    conflicts,
 		consumerGroupLag:  lag,
 	}
 }
@@ -54,6 +64,10 @@ func (m *kafkaMetrics) MessageFailed(topic string, partition int, reason string)
 	m.messagesFailed.WithLabelValues(topic, partitionString(partition), reason).Add(1)
 }
 
+func (m *kafkaMetrics) OrderConflict(topic string, partition int) {
+	m.orderConflicts.WithLabelValues(topic, partitionString(partition)).Add(1)
+}
+
 func (m *kafkaMetrics) ConsumerGroupLag(topic string, partition int, lag int64) {
 	m.consumerGroupLag.WithLabelValues(topic, partitionString(partition)).Set(float64(lag))
 }
@@ -9,9 +9,11 @@ type (
 	Factory interface {
 		HTTP() HTTP
 		Transaction() Transaction
+		Database() Database
 		Cache() Cache
 		Kafka() Kafka
 		DLQ() DLQ
+		Feed() Feed
 		Handler() http.Handler
 	}
 
@@ -26,6 +28,26 @@ type (
 		IncrementFailures(operation string)
 	}
 
+	Database interface {
+		ObserveQuery(operation string, duration time.Duration)
+		IncrementSlowQueries(operation string)
+		// RegisterPool exports the connection pool statistics returned by stats on every scrape.
+		RegisterPool(pool string, stats func() PoolStats)
+	}
+
+	// PoolStats mirrors pgxpool.Stat so that this package does not depend on the driver.
+	PoolStats struct {
+		AcquiredConns        int32
+		IdleConns            int32
+		TotalConns           int32
+		MaxConns             int32
+		AcquireCount         int64
+		AcquireDuration      time.Duration
+		EmptyAcquireCount    int64
+		EmptyAcquireWaitTime time.Duration
+		CanceledAcquireCount int64
+	}
+
 	Cache interface {
 		Hit(cacheType string)
 		Miss(cacheType string)
@@ -36,6 +58,7 @@ type (
 	Kafka interface {
 		MessageProcessed(topic string, partition int)
 		MessageFailed(topic string, partition int, reason string)
+		OrderConflict(topic string, partition int)
 		ConsumerGroupLag(topic string, partition int, lag int64)
 	}
 
@@ -44,4 +67,10 @@ type (
 		DLError(topic string, reason string)
 		DLRetryCount(originalTopic string, retryCount int)
 	}
+
+	Feed interface {
+		Clients(count int)
+		Published()
+		Disconnected(reason string)
+	}
 )
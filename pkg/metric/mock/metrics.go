@@ -70,6 +70,34 @@ func (mr *MockFactoryMockRecorder) DLQ() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DLQ", reflect.TypeOf((*MockFactory)(nil).DLQ))
 }
 
+// Database mocks base method.
+func (m *MockFactory) Database() metric.Database {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Database")
+	ret0, _ := ret[0].(metric.Database)
+	return ret0
+}
+
+// Database indicates an expected call of Database.
+func (mr *MockFactoryMockRecorder) Database() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Database", reflect.TypeOf((*MockFactory)(nil).Database))
+}
+
+// Feed mocks base method.
+func (m *MockFactory) Feed() metric.Feed {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Feed")
+	ret0, _ := ret[0].(metric.Feed)
+	return ret0
+}
+
+// Feed indicates an expected call of Feed.
+func (mr *MockFactoryMockRecorder) Feed() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Feed", reflect.TypeOf((*MockFactory)(nil).Feed))
+}
+
 // HTTP mocks base method.
 func (m *MockFactory) HTTP() metric.HTTP {
 	m.ctrl.T.Helper()
@@ -234,6 +262,66 @@ func (mr *MockTransactionMockRecorder) ObserveDuration(operation, duration any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveDuration", reflect.TypeOf((*MockTransaction)(nil).ObserveDuration), operation, duration)
 }
 
+// MockDatabase is a mock of Database interface.
+type MockDatabase struct {
+	ctrl     *gomock.Controller
+	recorder *MockDatabaseMockRecorder
+	isgomock struct{}
+}
+
+// MockDatabaseMockRecorder is the mock recorder for MockDatabase.
+type MockDatabaseMockRecorder struct {
+	mock *MockDatabase
+}
+
+// NewMockDatabase creates a new mock instance.
+func NewMockDatabase(ctrl *gomock.Controller) *MockDatabase {
+	mock := &MockDatabase{ctrl: ctrl}
+	mock.recorder = &MockDatabaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDatabase) EXPECT() *MockDatabaseMockRecorder {
+	return m.recorder
+}
+
+// IncrementSlowQueries mocks base method.
+func (m *MockDatabase) IncrementSlowQueries(operation string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncrementSlowQueries", operation)
+}
+
+// IncrementSlowQueries indicates an expected call of IncrementSlowQueries.
+func (mr *MockDatabaseMockRecorder) IncrementSlowQueries(operation any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementSlowQueries", reflect.TypeOf((*MockDatabase)(nil).IncrementSlowQueries), operation)
+}
+
+// ObserveQuery mocks base method.
+func (m *MockDatabase) ObserveQuery(operation string, duration time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveQuery", operation, duration)
+}
+
+// ObserveQuery indicates an expected call of ObserveQuery.
+func (mr *MockDatabaseMockRecorder) ObserveQuery(operation, duration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveQuery", reflect.TypeOf((*MockDatabase)(nil).ObserveQuery), operation, duration)
+}
+
+// RegisterPool mocks base method.
+func (m *MockDatabase) RegisterPool(pool string, stats func() metric.PoolStats) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RegisterPool", pool, stats)
+}
+
+// RegisterPool indicates an expected call of RegisterPool.
+func (mr *MockDatabaseMockRecorder) RegisterPool(pool, stats any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterPool", reflect.TypeOf((*MockDatabase)(nil).RegisterPool), pool, stats)
+}
+
 // MockCache is a mock of Cache interface.
 type MockCache struct {
 	ctrl     *gomock.Controller
@@ -366,6 +454,18 @@ func (mr *MockKafkaMockRecorder) MessageProcessed(topic, partition any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessageProcessed", reflect.TypeOf((*MockKafka)(nil).MessageProcessed), topic, partition)
 }
 
+// OrderConflict mocks base method.
+func (m *MockKafka) OrderConflict(topic string, partition int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OrderConflict", topic, partition)
+}
+
+// OrderConflict indicates an expected call of OrderConflict.
+func (mr *MockKafkaMockRecorder) OrderConflict(topic, partition any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderConflict", reflect.TypeOf((*MockKafka)(nil).OrderConflict), topic, partition)
+}
+
 // MockDLQ is a mock of DLQ interface.
 type MockDLQ struct {
 	ctrl     *gomock.Controller
@@ -425,3 +525,63 @@ func (mr *MockDLQMockRecorder) DLSent(topic, originalTopic, retryCount any) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DLSent", reflect.TypeOf((*MockDLQ)(nil).DLSent), topic, originalTopic, retryCount)
 }
+
+// MockFeed is a mock of Feed interface.
+type MockFeed struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedMockRecorder
+	isgomock struct{}
+}
+
+// MockFeedMockRecorder is the mock recorder for MockFeed.
+type MockFeedMockRecorder struct {
+	mock *MockFeed
+}
+
+// NewMockFeed creates a new mock instance.
+func NewMockFeed(ctrl *gomock.Controller) *MockFeed {
+	mock := &MockFeed{ctrl: ctrl}
+	mock.recorder = &MockFeedMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeed) EXPECT() *MockFeedMockRecorder {
+	return m.recorder
+}
+
+// Clients mocks base method.
+func (m *MockFeed) Clients(count int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Clients", count)
+}
+
+// Clients indicates an expected call of Clients.
+func (mr *MockFeedMockRecorder) Clients(count any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clients", reflect.TypeOf((*MockFeed)(nil).Clients), count)
+}
+
+// Disconnected mocks base method.
+func (m *MockFeed) Disconnected(reason string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Disconnected", reason)
+}
+
+// Disconnected indicates an expected call of Disconnected.
+func (mr *MockFeedMockRecorder) Disconnected(reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disconnected", reflect.TypeOf((*MockFeed)(nil).Disconnected), reason)
+}
+
+// Published mocks base method.
+func (m *MockFeed) Published() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Published")
+}
+
+// Published indicates an expected call of Published.
+func (mr *MockFeedMockRecorder) Published() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Published", reflect.TypeOf((*MockFeed)(nil).Published))
+}
@@ -14,9 +14,11 @@ type prometheusFactory struct {
 	registry    *promRegistry
 	http        *httpMetrics
 	transaction *transactionMetrics
+	database    *databaseMetrics
 	cache       *cacheMetrics
 	kafka       *kafkaMetrics
 	dlq         *dlqMetrics
+	feed        *feedMetrics
 }
 
 func NewFactory() Factory {
@@ -26,9 +28,11 @@ func NewFactory() Factory {
 		registry:    registry,
 		http:        newHTTPMetrics(registry),
 		transaction: newTransactionMetrics(registry),
+		database:    newDatabaseMetrics(registry),
 		cache:       newCacheMetrics(registry),
 		kafka:       newKafkaMetrics(registry),
 		dlq:         newDLQMetrics(registry),
+		feed:        newFeedMetrics(registry),
 	}
 }
 
@@ -40,6 +44,10 @@ func (f *prometheusFactory) Transaction() Transaction {
 	return f.transaction
 }
 
+func (f *prometheusFactory) Database() Database {
+	return f.database
+}
+
 func (f *prometheusFactory) Cache() Cache {
 	return f.cache
 }
@@ -52,6 +60,10 @@ func (f *prometheusFactory) DLQ() DLQ {
 	return f.dlq
 }
 
+func (f *prometheusFactory) Feed() Feed {
+	return f.feed
+}
+
 func (f *prometheusFactory) Handler() http.Handler {
 	return promhttp.HandlerFor(f.registry.registry,
 		promhttp.HandlerOpts{
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type txKey struct{}
+
+// ContextWithTx returns a copy of ctx carrying tx, so Executer resolves to it.
+func ContextWithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext returns the transaction stored by ContextWithTx, if any.
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return tx, ok
+}
+
+// Executer returns the transaction carried by ctx, falling back to the pool, so queries
+// issued inside ExecuteInTransaction run in its transaction without it being passed around.
+func (p *Postgres) Executer(ctx context.Context) QueryExecuter {
+	if tx, ok := TxFromContext(ctx); ok {
+		return &TxQueryExecuter{Tx: tx}
+	}
+	return p.Pool
+}
+
+type operationKey struct{}
+
+// WithOperation names the queries issued with ctx, typically after the repository method,
+// so that query metrics and slow-query logs can be grouped by it.
+func WithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+// OperationFromContext returns the name set by WithOperation, or "" when there is none.
+func OperationFromContext(ctx context.Context) string {
+	operation, _ := ctx.Value(operationKey{}).(string)
+	return operation
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"wbtest/internal/config"
+)
+
+const _redacted = "xxxxx"
+
+var (
+	_quotedPassword = regexp.MustCompile(`password\s*=\s*'(?:[^'\\]|\\.)*'`)
+	_plainPassword  = regexp.MustCompile(`password\s*=\s*[^\s']\S*`)
+)
+
+// connString builds the primary connection string from its parts. The password is left out on
+// purpose: it is set on the parsed config instead, so it never has to be escaped and can never
+// show up in a parse error.
+func connString(cfg *config.Postgres) string {
+	query := url.Values{}
+	query.Set("sslmode", cfg.SSLMode)
+	if cfg.SSLRootCert != "" {
+		query.Set("sslrootcert", cfg.SSLRootCert)
+	}
+	if cfg.SSLCert != "" {
+		query.Set("sslcert", cfg.SSLCert)
+	}
+	if cfg.SSLKey != "" {
+		query.Set("sslkey", cfg.SSLKey)
+	}
+	if cfg.TargetSessionAttrs != "" {
+		query.Set("target_session_attrs", cfg.TargetSessionAttrs)
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.User(cfg.User),
+		Host:     net.JoinHostPort(cfg.Host, cfg.Port),
+		Path:     "/" + cfg.Name,
+		RawQuery: query.Encode(),
+	}
+	return dsn.String()
+}
+
+// password returns DB_PASSWORD_FILE's content when it is set, so the secret can be mounted
+// instead of passed through the environment, and DB_PASSWORD otherwise.
+func password(cfg *config.Postgres) (string, error) {
+	if cfg.PasswordFile == "" {
+		return cfg.Password, nil
+	}
+
+	content, err := os.ReadFile(cfg.PasswordFile)
+	if err != nil {
+		return "", fmt.Errorf("read password file: %w", err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// RedactDSN hides the password of a connection string in either URL or keyword/value form,
+// so it can be logged or put into an error.
+func RedactDSN(dsn string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			// an unparsable URL may still hold a password, keep only the scheme
+			return dsn[:strings.Index(dsn, "://")+3] + _redacted
+		}
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), _redacted)
+		}
+		query := u.Query()
+		if query.Has("password") {
+			query.Set("password", _redacted)
+			u.RawQuery = query.Encode()
+		}
+		return u.String()
+	}
+
+	dsn = _quotedPassword.ReplaceAllLiteralString(dsn, "password="+_redacted)
+	return _plainPassword.ReplaceAllLiteralString(dsn, "password="+_redacted)
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wbtest/internal/config"
+)
+
+func TestPoolConfig_SpecialCharactersInCredentials(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Postgres{
+		Host:               "db.internal",
+		Port:               "5433",
+		Name:               "orders/db",
+		User:               "svc@orders",
+		Password:           "p@ss/w%rd:'x",
+		SSLMode:            "disable",
+		TargetSessionAttrs: "read-write",
+	}
+
+	pg := &Postgres{maxPoolSize: 1, applicationName: "order-service"}
+	poolConfig, err := pg.poolConfig(connString(cfg))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	conn := poolConfig.ConnConfig
+	if conn.Host != cfg.Host || conn.Port != 5433 || conn.User != cfg.User || conn.Database != cfg.Name {
+		t.Fatalf("unexpected config host=%q port=%d user=%q database=%q",
+			conn.Host, conn.Port, conn.User, conn.Database)
+	}
+	if conn.Password != "" {
+		t.Fatalf("expected the password to stay out of the connection string, got %q", conn.Password)
+	}
+	if conn.RuntimeParams["application_name"] != "order-service" {
+		t.Fatalf("expected application_name order-service, got %q", conn.RuntimeParams["application_name"])
+	}
+	if conn.ValidateConnect == nil {
+		t.Fatal("expected target_session_attrs to install a connect validator")
+	}
+}
+
+func TestPoolConfig_KeepsApplicationNameFromDSN(t *testing.T) {
+	t.Parallel()
+
+	pg := &Postgres{maxPoolSize: 1, applicationName: "order-service"}
+	poolConfig, err := pg.poolConfig("postgres://user@replica:5432/db?application_name=reporting")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := poolConfig.ConnConfig.RuntimeParams["application_name"]; got != "reporting" {
+		t.Fatalf("expected application_name reporting, got %q", got)
+	}
+}
+
+func TestPoolConfig_RedactsParseErrors(t *testing.T) {
+	t.Parallel()
+
+	pg := &Postgres{maxPoolSize: 1}
+	_, err := pg.poolConfig("postgres://user:s3cr@t@replica:5432/db?sslmode=bogus")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if strings.Contains(err.Error(), "s3cr") {
+		t.Fatalf("password leaked into error: %v", err)
+	}
+}
+
+func TestPassword(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got, err := password(&config.Postgres{Password: "from-env", PasswordFile: path})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got != "from-file" {
+		t.Fatalf("expected from-file, got %q", got)
+	}
+
+	got, err = password(&config.Postgres{Password: "from-env"})
+	if err != nil || got != "from-env" {
+		t.Fatalf("expected from-env, got %q (%v)", got, err)
+	}
+
+	if _, err = password(&config.Postgres{PasswordFile: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Fatal("expected error for a missing password file")
+	}
+}
+
+func TestRedactDSN(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc string
+		dsn  string
+		want string
+	}{
+		{
+			desc: "URL",
+			dsn:  "postgres://user:secret@db:5432/orders?sslmode=disable",
+			want: "postgres://user:xxxxx@db:5432/orders?sslmode=disable",
+		},
+		{
+			desc: "URLWithoutPassword",
+			dsn:  "postgres://user@db:5432/orders",
+			want: "postgres://user@db:5432/orders",
+		},
+		{
+			desc: "URLPasswordParameter",
+			dsn:  "postgresql://db/orders?password=secret&user=user",
+			want: "postgresql://db/orders?password=xxxxx&user=user",
+		},
+		{
+			desc: "BrokenURL",
+			dsn:  "postgres://user:se%cret@db/orders",
+			want: "postgres://xxxxx",
+		},
+		{
+			desc: "KeywordValue",
+			dsn:  "host=db user=user password=secret dbname=orders",
+			want: "host=db user=user password=xxxxx dbname=orders",
+		},
+		{
+			desc: "QuotedKeywordValue",
+			dsn:  `host=db password='se cr\'et' dbname=orders`,
+			want: "host=db password=xxxxx dbname=orders",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if got := RedactDSN(tc.dsn); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+// Package migrate applies SQL migrations named like golang-migrate's
+// (<version>_<name>.up.sql / .down.sql) and records progress in the same
+// schema_migrations table, so it can take over from the migrate CLI on an existing database.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"wbtest/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	_defaultLockID = 72_616_873
+
+	_createTableSQL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version BIGINT NOT NULL PRIMARY KEY,
+	dirty BOOLEAN NOT NULL
+)`
+)
+
+var (
+	ErrDirty             = errors.New("database is dirty, fix it manually and use force")
+	ErrUnexpectedVersion = errors.New("unexpected schema version")
+	ErrUnknownVersion    = errors.New("unknown migration version")
+	ErrNoDownMigration   = errors.New("no down migration")
+
+	_fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+
+	up   string
+	down string
+}
+
+type Status struct {
+	Version int64
+	Dirty   bool
+	Latest  int64
+	Applied []Migration
+	Pending []Migration
+}
+
+type Migrator struct {
+	pool       *pgxpool.Pool
+	fsys       fs.FS
+	log        logger.Logger
+	migrations []Migration
+
+	lockID int64
+}
+
+// New reads the migrations at the root of fsys. The database is not touched until a
+// command runs.
+func New(pool *pgxpool.Pool, fsys fs.FS, log logger.Logger, opts ...Option) (*Migrator, error) {
+	const op = "storage.postgres.migrate.New"
+
+	m := &Migrator{
+		pool:   pool,
+		fsys:   fsys,
+		log:    log,
+		lockID: _defaultLockID,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	migrations, err := readMigrations(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	m.migrations = migrations
+
+	return m, nil
+}
+
+func readMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := _fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, parseErr := strconv.ParseInt(match[1], 10, 64)
+		if parseErr != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s",
+				version, mig.Name, match[2])
+		}
+
+		target := &mig.up
+		if match[3] == "down" {
+			target = &mig.down
+		}
+		if *target != "" {
+			return nil, fmt.Errorf("duplicate %s migration for version %d", match[3], version)
+		}
+		*target = entry.Name()
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	return migrations, nil
+}
+
+// Migrations returns the known migrations in version order.
+func (m *Migrator) Migrations() []Migration {
+	return slices.Clone(m.migrations)
+}
+
+// Latest returns the version the database should be at after Up, or 0 with no migrations.
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies all pending migrations and returns how many were applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	const op = "storage.postgres.migrate.Up"
+
+	applied := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		version, dirty, err := readVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("version %d: %w", version, ErrDirty)
+		}
+		if version > m.Latest() {
+			return fmt.Errorf("database is at %d, newest known migration is %d: %w",
+				version, m.Latest(), ErrUnexpectedVersion)
+		}
+
+		for _, mig := range m.migrations {
+			if mig.Version <= version {
+				continue
+			}
+			if err = m.apply(ctx, conn, mig.up, mig.Version, mig); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	if err != nil {
+		return applied, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return applied, nil
+}
+
+// Down reverts the last n applied migrations and returns how many were reverted.
+func (m *Migrator) Down(ctx context.Context, n int) (int, error) {
+	const op = "storage.postgres.migrate.Down"
+
+	if n <= 0 {
+		return 0, fmt.Errorf("%s: n must be > 0", op)
+	}
+
+	reverted := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		version, dirty, err := readVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("version %d: %w", version, ErrDirty)
+		}
+
+		for ; reverted < n && version > 0; reverted++ {
+			idx := m.index(version)
+			if idx < 0 {
+				return fmt.Errorf("version %d: %w", version, ErrUnknownVersion)
+			}
+			mig := m.migrations[idx]
+			if mig.down == "" {
+				return fmt.Errorf("version %d: %w", version, ErrNoDownMigration)
+			}
+
+			var previous int64
+			if idx > 0 {
+				previous = m.migrations[idx-1].Version
+			}
+			if err = m.apply(ctx, conn, mig.down, previous, mig); err != nil {
+				return err
+			}
+			version = previous
+		}
+		return nil
+	})
+	if err != nil {
+		return reverted, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return reverted, nil
+}
+
+// Force records version as applied and clean without running any SQL. Version 0 means no
+// migrations are applied.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	const op = "storage.postgres.migrate.Force"
+
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("%s: version %d: %w", op, version, ErrUnknownVersion)
+	}
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			return setVersion(ctx, tx, version, false)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	const op = "storage.postgres.migrate.Status"
+
+	status := &Status{Latest: m.Latest()}
+	err := m.withConn(ctx, func(conn *pgxpool.Conn) error {
+		var err error
+		status.Version, status.Dirty, err = readVersion(ctx, conn)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, mig := range m.migrations {
+		if mig.Version <= status.Version {
+			status.Applied = append(status.Applied, mig)
+		} else {
+			status.Pending = append(status.Pending, mig)
+		}
+	}
+
+	return status, nil
+}
+
+// Check fails unless the database is clean and exactly at the newest known migration, so
+// the service never runs against a schema it was not built for.
+func (m *Migrator) Check(ctx context.Context) error {
+	const op = "storage.postgres.migrate.Check"
+
+	status, err := m.Status(ctx)
+	if err != nil {
+		// nolint: wrapcheck
+		return err
+	}
+	if status.Dirty {
+		return fmt.Errorf("%s: version %d: %w", op, status.Version, ErrDirty)
+	}
+	if status.Version != status.Latest {
+		return fmt.Errorf("%s: database is at %d, expected %d: %w",
+			op, status.Version, status.Latest, ErrUnexpectedVersion)
+	}
+
+	return nil
+}
+
+func (m *Migrator) index(version int64) int {
+	return slices.IndexFunc(m.migrations, func(mig Migration) bool {
+		return mig.Version == version
+	})
+}
+
+// apply runs one migration file and moves the recorded version to target in the same
+// transaction, so a failed migration leaves neither a partial schema nor a dirty flag.
+func (m *Migrator) apply(
+	ctx context.Context,
+	conn *pgxpool.Conn,
+	file string,
+	target int64,
+	mig Migration,
+) error {
+	body, err := fs.ReadFile(m.fsys, file)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", file, err)
+	}
+
+	start := time.Now()
+	err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, execErr := tx.Exec(ctx, string(body)); execErr != nil {
+			return fmt.Errorf("run %s: %w", file, execErr)
+		}
+		return setVersion(ctx, tx, target, false)
+	})
+	if err != nil {
+		// nolint: wrapcheck
+		return err
+	}
+
+	m.log.Infow("migration applied",
+		"version", mig.Version,
+		"name", mig.Name,
+		"file", file,
+		"duration", time.Since(start).String(),
+	)
+	return nil
+}
+
+// withLock serializes migrations across instances starting at the same time.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	return m.withConn(ctx, func(conn *pgxpool.Conn) error {
+		if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", m.lockID); err != nil {
+			return fmt.Errorf("acquire advisory lock: %w", err)
+		}
+		defer func() {
+			_, _ = conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", m.lockID)
+		}()
+
+		if _, err := conn.Exec(ctx, _createTableSQL); err != nil {
+			return fmt.Errorf("create schema_migrations: %w", err)
+		}
+		return fn(conn)
+	})
+}
+
+func (m *Migrator) withConn(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	return fn(conn)
+}
+
+func readVersion(ctx context.Context, conn *pgxpool.Conn) (int64, bool, error) {
+	var exists bool
+	err := conn.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists)
+	if err != nil {
+		return 0, false, fmt.Errorf("check schema_migrations: %w", err)
+	}
+	if !exists {
+		return 0, false, nil
+	}
+
+	var (
+		version int64
+		dirty   bool
+	)
+	err = conn.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read version: %w", err)
+	}
+
+	return version, dirty, nil
+}
+
+func setVersion(ctx context.Context, tx pgx.Tx, version int64, dirty bool) error {
+	if _, err := tx.Exec(ctx, "TRUNCATE schema_migrations"); err != nil {
+		return fmt.Errorf("reset version: %w", err)
+	}
+	if version == 0 {
+		return nil
+	}
+	if _, err := tx.Exec(ctx,
+		"INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)", version, dirty); err != nil {
+		return fmt.Errorf("set version: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package migrate_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"wbtest/migrations"
+	"wbtest/pkg/storage/postgres/migrate"
+)
+
+func TestNew_ReadsMigrations(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"00000010_add_index.up.sql":      {Data: []byte("CREATE INDEX ...")},
+		"00000002_create_table.up.sql":   {Data: []byte("CREATE TABLE ...")},
+		"00000002_create_table.down.sql": {Data: []byte("DROP TABLE ...")},
+		"README.md":                      {Data: []byte("not a migration")},
+	}
+
+	m, err := migrate.New(nil, fsys, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got := m.Migrations()
+	if len(got) != 2 || got[0].Version != 2 || got[1].Version != 10 {
+		t.Fatalf("expected versions [2 10], got %+v", got)
+	}
+	if got[0].Name != "create_table" {
+		t.Fatalf("expected name create_table, got %q", got[0].Name)
+	}
+	if m.Latest() != 10 {
+		t.Fatalf("expected latest 10, got %d", m.Latest())
+	}
+}
+
+func TestNew_RejectsInconsistentFiles(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc string
+		fsys fstest.MapFS
+	}{
+		{
+			desc: "MismatchedNames",
+			fsys: fstest.MapFS{
+				"00000002_create_delivey_table.up.sql":    {},
+				"00000002_create_delivery_table.down.sql": {},
+			},
+		},
+		{
+			desc: "DownWithoutUp",
+			fsys: fstest.MapFS{"00000003_create_payment_table.down.sql": {}},
+		},
+		{
+			desc: "DuplicateVersion",
+			fsys: fstest.MapFS{
+				"1_init.up.sql":   {},
+				"001_init.up.sql": {},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := migrate.New(nil, tc.fsys, nil); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	t.Parallel()
+
+	m, err := migrate.New(nil, migrations.FS, nil)
+	if err != nil {
+		t.Fatalf("expected embedded migrations to be consistent, got %v", err)
+	}
+
+	for i, mig := range m.Migrations() {
+		if mig.Version != int64(i+1) {
+			t.Fatalf("expected contiguous versions, got %d at position %d", mig.Version, i)
+		}
+	}
+}
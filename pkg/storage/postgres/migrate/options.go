@@ -0,0 +1,10 @@
+package migrate
+
+type Option func(*Migrator)
+
+// LockID sets the advisory lock key that serializes concurrent migrators.
+func LockID(id int64) Option {
+	return func(m *Migrator) {
+		m.lockID = id
+	}
+}
@@ -2,7 +2,12 @@ package postgres
 
 import (
 	"errors"
+	"fmt"
 	"time"
+
+	"wbtest/pkg/metric"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type Option func(*Postgres)
@@ -31,11 +36,94 @@ func MaxRetryDelay(delay time.Duration) Option {
 	}
 }
 
+// ApplicationName is reported to the server as application_name, unless a connection string
+// sets its own, so the service's sessions can be told apart in pg_stat_activity.
+func ApplicationName(name string) Option {
+	return func(p *Postgres) {
+		p.applicationName = name
+	}
+}
+
+// MinPoolSize keeps at least size connections open, even when they are idle.
+func MinPoolSize(size int32) Option {
+	return func(p *Postgres) {
+		p.minPoolSize = size
+	}
+}
+
+func MaxConnLifetime(lifetime time.Duration) Option {
+	return func(p *Postgres) {
+		p.maxConnLifetime = lifetime
+	}
+}
+
+func MaxConnIdleTime(idle time.Duration) Option {
+	return func(p *Postgres) {
+		p.maxConnIdleTime = idle
+	}
+}
+
+func HealthCheckPeriod(period time.Duration) Option {
+	return func(p *Postgres) {
+		p.healthCheckPeriod = period
+	}
+}
+
+// Tracer attaches a pgx query tracer, such as NewQueryTracer, to every pool connection.
+// It may be given several times; the tracers are then called in order.
+func Tracer(tracer pgx.QueryTracer) Option {
+	return func(p *Postgres) {
+		p.tracers = append(p.tracers, tracer)
+	}
+}
+
+// PoolMetrics exports the statistics of the primary pool and of every replica pool.
+func PoolMetrics(metrics metric.Database) Option {
+	return func(p *Postgres) {
+		p.poolMetrics = metrics
+	}
+}
+
+// Replicas adds read replicas, given as connection strings, that Reader routes queries to.
+func Replicas(dsns ...string) Option {
+	return func(p *Postgres) {
+		p.replicaDSNs = dsns
+	}
+}
+
+// ReplicaSelection picks the replica strategy: SelectRoundRobin or SelectLeastConn.
+func ReplicaSelection(strategy string) Option {
+	return func(p *Postgres) {
+		p.replicaSelection = strategy
+	}
+}
+
+// ReplicaMaxLag takes replicas lagging behind the primary by more than lag out of rotation.
+func ReplicaMaxLag(lag time.Duration) Option {
+	return func(p *Postgres) {
+		p.replicaMaxLag = lag
+	}
+}
+
+func ReplicaCheckInterval(interval time.Duration) Option {
+	return func(p *Postgres) {
+		p.replicaCheckInterval = interval
+	}
+}
+
 func (p *Postgres) validate() error {
 	if p.maxPoolSize <= 0 {
 		return errors.New("invalid maxPoolSize: must be > 0")
 	}
 
+	if p.minPoolSize < 0 || p.minPoolSize > p.maxPoolSize {
+		return errors.New("invalid minPoolSize: must be between 0 and maxPoolSize")
+	}
+
+	if p.maxConnLifetime < 0 || p.maxConnIdleTime < 0 || p.healthCheckPeriod < 0 {
+		return errors.New("invalid connection lifetime settings: must be >= 0")
+	}
+
 	if p.connAttempts <= 0 {
 		return errors.New("invalid connAttempts: must be > 0")
 	}
@@ -51,5 +139,13 @@ func (p *Postgres) validate() error {
 	if p.baseRetryDelay > p.maxRetryDelay {
 		return errors.New("baseRetryDelay cannot exceed maxRetryDelay")
 	}
+
+	if p.replicaSelection != SelectRoundRobin && p.replicaSelection != SelectLeastConn {
+		return fmt.Errorf("invalid replica selection %q", p.replicaSelection)
+	}
+
+	if p.replicaMaxLag <= 0 || p.replicaCheckInterval <= 0 {
+		return errors.New("invalid replica lag settings: must be > 0")
+	}
 	return nil
 }
@@ -2,15 +2,20 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand/v2"
-	"net"
+	"sync/atomic"
 	"time"
 
 	"wbtest/internal/config"
 	"wbtest/pkg/logger"
+	"wbtest/pkg/metric"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -27,52 +32,72 @@ type Postgres struct {
 	Builder squirrel.StatementBuilderType
 	Pool    *pgxpool.Pool
 
-	connAttempts   int
-	baseRetryDelay time.Duration
-	maxRetryDelay  time.Duration
-	maxPoolSize    int32
+	connAttempts    int
+	baseRetryDelay  time.Duration
+	maxRetryDelay   time.Duration
+	maxPoolSize     int32
+	password        string
+	applicationName string
+	tracers         []pgx.QueryTracer
+	poolMetrics     metric.Database
+	log             logger.Logger
+
+	minPoolSize       int32
+	maxConnLifetime   time.Duration
+	maxConnIdleTime   time.Duration
+	healthCheckPeriod time.Duration
+
+	replicaDSNs          []string
+	replicaSelection     string
+	replicaMaxLag        time.Duration
+	replicaCheckInterval time.Duration
+	replicas             []*replica
+	nextReplica          atomic.Uint64
+	stopMonitor          context.CancelFunc
 }
 
 func NewPostgres(config *config.Postgres, log logger.Logger, opts ...Option) (*Postgres, error) {
 	const op = "storage.postgres.NewPostgres"
 
-	hostPort := net.JoinHostPort(config.Host, config.Port)
-	url := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=%s",
-		config.User,
-		config.Password,
-		hostPort,
-		config.Name,
-		config.SSLMode,
-	)
-
 	pg := &Postgres{
 		connAttempts:   _defaultConnAttempts,
 		baseRetryDelay: _defaultBaseRetryDelay,
 		maxRetryDelay:  _defaultMaxRetryDelay,
 		maxPoolSize:    _defaultMaxPoolSize,
+		log:            log,
+
+		replicaSelection:     SelectRoundRobin,
+		replicaMaxLag:        _defaultReplicaMaxLag,
+		replicaCheckInterval: _defaultReplicaCheckInterval,
 	}
 
 	for _, opt := range opts {
 		opt(pg)
 	}
-	if err := pg.validate(); err != nil {
+	err := pg.validate()
+	if err != nil {
 		return nil, fmt.Errorf("%s: validation: %w", op, err)
 	}
 
 	pg.Builder = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 
-	poolConfig, err := pgxpool.ParseConfig(url)
+	pg.password, err = password(config)
 	if err != nil {
-		return nil, fmt.Errorf("%s: parse pool config: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	poolConfig.MaxConns = pg.maxPoolSize
+	dsn := connString(config)
+	poolConfig, err := pg.poolConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%s: parse pool config: %w", op, err)
+	}
+	poolConfig.ConnConfig.Password = pg.password
 
 	currentBackoff := pg.baseRetryDelay
 	for attemptCount := 1; attemptCount <= pg.connAttempts; attemptCount++ {
 		pg.Pool, err = pgxpool.NewWithConfig(context.Background(), poolConfig)
 		if err == nil {
-			return pg, nil
+			break
 		}
 		//nolint:gosec
 		jitter := time.Duration(
@@ -84,6 +109,7 @@ func NewPostgres(config *config.Postgres, log logger.Logger, opts ...Option) (*P
 
 		log.Infow("PostgreSQL connection attempt failed",
 			"operation", op,
+			"dsn", RedactDSN(dsn),
 			"attempt", attemptCount,
 			"retry_after", jitter.String(),
 			"error", err,
@@ -101,11 +127,83 @@ func NewPostgres(config *config.Postgres, log logger.Logger, opts ...Option) (*P
 		return nil, fmt.Errorf("%s: create new pool: %w", op, err)
 	}
 
+	if pg.poolMetrics != nil {
+		pg.poolMetrics.RegisterPool("primary", poolStats(pg.Pool))
+	}
+
+	if len(pg.replicaDSNs) > 0 {
+		if err = pg.connectReplicas(pg.replicaDSNs); err != nil {
+			pg.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		monitorCtx, cancel := context.WithCancel(context.Background())
+		pg.stopMonitor = cancel
+		go pg.monitorReplicas(monitorCtx)
+	}
+
 	return pg, nil
 }
 
+func (p *Postgres) poolConfig(dsn string) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		// pgx redacts the password itself, but cannot for URLs that a stray '@' or '/' broke
+		var parseErr *pgconn.ParseConfigError
+		if errors.As(err, &parseErr) {
+			if cause := parseErr.Unwrap(); cause != nil {
+				return nil, fmt.Errorf("cannot parse %s: %w", RedactDSN(dsn), cause)
+			}
+			return nil, fmt.Errorf("cannot parse %s", RedactDSN(dsn))
+		}
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	if p.applicationName != "" {
+		if _, ok := poolConfig.ConnConfig.RuntimeParams["application_name"]; !ok {
+			poolConfig.ConnConfig.RuntimeParams["application_name"] = p.applicationName
+		}
+	}
+
+	poolConfig.MaxConns = p.maxPoolSize
+	poolConfig.MinConns = p.minPoolSize
+	if p.maxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = p.maxConnLifetime
+	}
+	if p.maxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = p.maxConnIdleTime
+	}
+	if p.healthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = p.healthCheckPeriod
+	}
+
+	switch len(p.tracers) {
+	case 0:
+	case 1:
+		poolConfig.ConnConfig.Tracer = p.tracers[0]
+	default:
+		poolConfig.ConnConfig.Tracer = multitracer.New(p.tracers...)
+	}
+	return poolConfig, nil
+}
+
 func (p *Postgres) Close() {
+	if p.stopMonitor != nil {
+		p.stopMonitor()
+	}
+	for _, r := range p.replicas {
+		r.pool.Close()
+	}
 	if p.Pool != nil {
 		p.Pool.Close()
 	}
 }
+
+func (p *Postgres) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	tx, err := p.Pool.BeginTx(ctx, txOptions)
+	if err != nil {
+		return nil, fmt.Errorf("storage.postgres.BeginTx: %w", err)
+	}
+	return tx, nil
+}
@@ -14,6 +14,11 @@ type QueryExecuter interface {
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
 }
 
+// TxBeginner starts transactions; both *Postgres and *pgxpool.Pool implement it.
+type TxBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
 type TxQueryExecuter struct {
 	Tx pgx.Tx
 }
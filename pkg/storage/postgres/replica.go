@@ -0,0 +1,258 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"wbtest/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	SelectRoundRobin = "round_robin"
+	SelectLeastConn  = "least_conn"
+
+	_defaultReplicaMaxLag        = 5 * time.Second
+	_defaultReplicaCheckInterval = 5 * time.Second
+	_replicaCheckTimeout         = 2 * time.Second
+)
+
+// _replicationLagSQL reports 0 when the replica streams WAL from the primary and has replayed
+// everything it received, so an idle primary does not make its replicas look stale. A replica
+// whose WAL receiver is not streaming may have received nothing for a long time, so its lag is
+// the age of the last replayed transaction, or NULL when nothing was replayed yet. The receiver
+// status is only visible to roles with pg_read_all_stats; without it every replica is measured
+// by the replay timestamp.
+const _replicationLagSQL = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() THEN 0
+	WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn()
+		AND EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE status = 'streaming') THEN 0
+	ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+END`
+
+var errUnknownReplicationLag = errors.New("replica has not replayed any transaction")
+
+type primaryKey struct{}
+
+// WithPrimary marks ctx so that Reader sends its queries to the primary, for reads that
+// must not observe replication lag.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+type replica struct {
+	pool *pgxpool.Pool
+	host string
+
+	healthy atomic.Bool
+}
+
+// Reader returns the executer for read-only queries. Inside a transaction it is the
+// transaction itself, since transactions always run on the primary. Otherwise queries go
+// to a healthy replica and fall back to the primary when the replica fails.
+func (p *Postgres) Reader(ctx context.Context) QueryExecuter {
+	if _, ok := TxFromContext(ctx); ok {
+		return p.Executer(ctx)
+	}
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary || len(p.replicas) == 0 {
+		return p.Pool
+	}
+	return &replicaExecuter{p: p}
+}
+
+func (p *Postgres) connectReplicas(dsns []string) error {
+	for _, dsn := range dsns {
+		poolConfig, err := p.poolConfig(dsn)
+		if err != nil {
+			return fmt.Errorf("parse replica config: %w", err)
+		}
+		// replicas usually share the primary's credentials, so the DSN may leave the password out
+		if poolConfig.ConnConfig.Password == "" {
+			poolConfig.ConnConfig.Password = p.password
+		}
+
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+		if err != nil {
+			return fmt.Errorf("create replica pool %s: %w", poolConfig.ConnConfig.Host, err)
+		}
+
+		r := &replica{pool: pool, host: poolConfig.ConnConfig.Host}
+		r.healthy.Store(true)
+		p.replicas = append(p.replicas, r)
+
+		if p.poolMetrics != nil {
+			p.poolMetrics.RegisterPool("replica:"+r.host, poolStats(pool))
+		}
+	}
+	return nil
+}
+
+// pickReplica returns a healthy replica according to the selection strategy, or nil when
+// every replica is lagging or unreachable.
+func (p *Postgres) pickReplica() *replica {
+	healthy := make([]*replica, 0, len(p.replicas))
+	for _, r := range p.replicas {
+		if r.healthy.Load() {
+			healthy = append(healthy, r)
+		}
+	}
+	if len(healthy) == 0 {
+		return nil
+	}
+
+	if p.replicaSelection == SelectLeastConn {
+		best := healthy[0]
+		for _, r := range healthy[1:] {
+			if r.pool.Stat().AcquiredConns() < best.pool.Stat().AcquiredConns() {
+				best = r
+			}
+		}
+		return best
+	}
+
+	next := p.nextReplica.Add(1) - 1
+	return healthy[next%uint64(len(healthy))]
+}
+
+func (p *Postgres) monitorReplicas(ctx context.Context) {
+	ticker := time.NewTicker(p.replicaCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		p.checkReplicas(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Postgres) checkReplicas(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, r := range p.replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.checkReplica(ctx, r)
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *Postgres) checkReplica(ctx context.Context, r *replica) {
+	ctx, cancel := context.WithTimeout(ctx, _replicaCheckTimeout)
+	defer cancel()
+
+	var lagSeconds *float64
+	err := r.pool.QueryRow(ctx, _replicationLagSQL).Scan(&lagSeconds)
+	if err == nil && lagSeconds == nil {
+		err = errUnknownReplicationLag
+	}
+
+	var lag time.Duration
+	if lagSeconds != nil {
+		lag = time.Duration(*lagSeconds * float64(time.Second))
+	}
+
+	healthy := err == nil && lag <= p.replicaMaxLag
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+
+	if healthy {
+		p.log.Infow("replica is back in rotation", "host", r.host, "lag", lag.String())
+		return
+	}
+	p.log.Warnw("replica removed from rotation",
+		"host", r.host,
+		"lag", lag.String(),
+		"max_lag", p.replicaMaxLag.String(),
+		"error", err,
+	)
+}
+
+// replicaFailed takes the replica out of rotation until the monitor finds it healthy again.
+// A query cancelled by its caller says nothing about the replica and leaves it in rotation.
+func (p *Postgres) replicaFailed(ctx context.Context, r *replica, err error) {
+	if ctx.Err() == nil {
+		r.healthy.Store(false)
+	}
+	p.log.LogAttrs(ctx, logger.WarnLevel, "replica query failed, falling back to primary",
+		logger.String("host", r.host),
+		logger.Any("error", err),
+	)
+}
+
+// replicaExecuter routes reads to a replica. Writes are not expected here, but Exec goes
+// to the primary so that a misrouted write cannot fail on a read-only server.
+type replicaExecuter struct {
+	p *Postgres
+}
+
+func (e *replicaExecuter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if r := e.p.pickReplica(); r != nil {
+		rows, err := r.pool.Query(ctx, sql, args...)
+		if err == nil {
+			return rows, nil
+		}
+		e.p.replicaFailed(ctx, r, err)
+	}
+
+	rows, err := e.p.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("storage.postgres.replicaExecuter.Query: %w", err)
+	}
+	return rows, nil
+}
+
+func (e *replicaExecuter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	r := e.p.pickReplica()
+	if r == nil {
+		return e.p.Pool.QueryRow(ctx, sql, args...)
+	}
+	return &fallbackRow{
+		ctx:     ctx,
+		e:       e,
+		replica: r,
+		row:     r.pool.QueryRow(ctx, sql, args...),
+		sql:     sql,
+		args:    args,
+	}
+}
+
+func (e *replicaExecuter) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	// nolint: wrapcheck
+	return e.p.Pool.Exec(ctx, sql, args...)
+}
+
+// fallbackRow repeats the query on the primary when scanning the replica's row fails for
+// any reason other than the row not existing.
+type fallbackRow struct {
+	ctx     context.Context //nolint:containedctx
+	e       *replicaExecuter
+	replica *replica
+	row     pgx.Row
+	sql     string
+	args    []any
+}
+
+func (f *fallbackRow) Scan(dest ...any) error {
+	err := f.row.Scan(dest...)
+	if err == nil || errors.Is(err, pgx.ErrNoRows) || f.ctx.Err() != nil {
+		// nolint: wrapcheck
+		return err
+	}
+
+	f.e.p.replicaFailed(f.ctx, f.replica, err)
+	// nolint: wrapcheck
+	return f.e.p.Pool.QueryRow(f.ctx, f.sql, f.args...).Scan(dest...)
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"wbtest/pkg/logger"
+	"wbtest/pkg/metric"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StatementTracer records the duration of every statement labeled by the repository
+// operation set with WithOperation, and logs statements slower than the threshold.
+type StatementTracer struct {
+	metrics       metric.Database
+	log           logger.Logger
+	slowThreshold time.Duration
+}
+
+var _ pgx.QueryTracer = (*StatementTracer)(nil)
+
+// NewStatementTracer creates a StatementTracer; a non-positive slowThreshold disables
+// slow-query logging.
+func NewStatementTracer(
+	metrics metric.Database,
+	log logger.Logger,
+	slowThreshold time.Duration,
+) *StatementTracer {
+	return &StatementTracer{
+		metrics:       metrics,
+		log:           log,
+		slowThreshold: slowThreshold,
+	}
+}
+
+type statementKey struct{}
+
+type statementStart struct {
+	operation string
+	sql       string
+	startedAt time.Time
+}
+
+func (t *StatementTracer) TraceQueryStart(
+	ctx context.Context,
+	_ *pgx.Conn,
+	data pgx.TraceQueryStartData,
+) context.Context {
+	operation := OperationFromContext(ctx)
+	if operation == "" {
+		operation = queryOperation(data.SQL)
+	}
+
+	return context.WithValue(ctx, statementKey{}, statementStart{
+		operation: operation,
+		sql:       data.SQL,
+		startedAt: time.Now(),
+	})
+}
+
+func (t *StatementTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(statementKey{}).(statementStart)
+	if !ok {
+		return
+	}
+
+	duration := time.Since(start.startedAt)
+	t.metrics.ObserveQuery(start.operation, duration)
+
+	if t.slowThreshold <= 0 || duration < t.slowThreshold {
+		return
+	}
+
+	t.metrics.IncrementSlowQueries(start.operation)
+	// arguments are left out on purpose, they may carry customer data
+	t.log.LogAttrs(ctx, logger.WarnLevel, "slow query",
+		logger.String("operation", start.operation),
+		logger.String("sql", start.sql),
+		logger.String("duration", duration.String()),
+		logger.String("threshold", t.slowThreshold.String()),
+	)
+}
+
+func poolStats(pool *pgxpool.Pool) func() metric.PoolStats {
+	return func() metric.PoolStats {
+		stat := pool.Stat()
+		return metric.PoolStats{
+			AcquiredConns:        stat.AcquiredConns(),
+			IdleConns:            stat.IdleConns(),
+			TotalConns:           stat.TotalConns(),
+			MaxConns:             stat.MaxConns(),
+			AcquireCount:         stat.AcquireCount(),
+			AcquireDuration:      stat.AcquireDuration(),
+			EmptyAcquireCount:    stat.EmptyAcquireCount(),
+			EmptyAcquireWaitTime: stat.EmptyAcquireWaitTime(),
+			CanceledAcquireCount: stat.CanceledAcquireCount(),
+		}
+	}
+}
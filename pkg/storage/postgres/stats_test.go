@@ -0,0 +1,83 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"wbtest/pkg/logger"
+	mock_logger "wbtest/pkg/logger/mock"
+	mock_metric "wbtest/pkg/metric/mock"
+	"wbtest/pkg/storage/postgres"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/mock/gomock"
+)
+
+func TestStatementTracer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc          string
+		ctx           context.Context
+		slowThreshold time.Duration
+		wantOperation string
+		wantSlow      bool
+	}{
+		{
+			desc:          "RepositoryOperation",
+			ctx:           postgres.WithOperation(context.Background(), "repository.order.Get"),
+			slowThreshold: time.Hour,
+			wantOperation: "repository.order.Get",
+		},
+		{
+			desc:          "FallsBackToStatementVerb",
+			ctx:           context.Background(),
+			slowThreshold: time.Hour,
+			wantOperation: "SELECT",
+		},
+		{
+			desc:          "SlowQuery",
+			ctx:           postgres.WithOperation(context.Background(), "repository.order.Get"),
+			slowThreshold: time.Nanosecond,
+			wantOperation: "repository.order.Get",
+			wantSlow:      true,
+		},
+		{
+			desc:          "SlowLoggingDisabled",
+			ctx:           context.Background(),
+			wantOperation: "SELECT",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			metrics := mock_metric.NewMockDatabase(ctrl)
+			log := mock_logger.NewMockLogger(ctrl)
+
+			metrics.EXPECT().ObserveQuery(tc.wantOperation, gomock.Any())
+			if tc.wantSlow {
+				metrics.EXPECT().IncrementSlowQueries(tc.wantOperation)
+				log.EXPECT().LogAttrs(gomock.Any(), logger.WarnLevel, "slow query", gomock.Any()).
+					Do(func(_ context.Context, _ logger.Level, _ string, attrs ...logger.Attr) {
+						for _, attr := range attrs {
+							if attr.Value == "secret-value" {
+								t.Fatalf("query argument leaked into %s", attr.Key)
+							}
+						}
+					})
+			}
+
+			tracer := postgres.NewStatementTracer(metrics, log, tc.slowThreshold)
+			ctx := tracer.TraceQueryStart(tc.ctx, nil, pgx.TraceQueryStartData{
+				SQL:  "select order_uid from orders where order_uid = $1",
+				Args: []any{"secret-value"},
+			})
+			time.Sleep(time.Millisecond)
+			tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+		})
+	}
+}
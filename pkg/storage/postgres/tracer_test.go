@@ -0,0 +1,82 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"wbtest/pkg/storage/postgres"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryTracer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc       string
+		sql        string
+		err        error
+		wantName   string
+		wantStatus codes.Code
+	}{
+		{
+			desc:     "Select",
+			sql:      "  select order_uid from orders where order_uid = $1",
+			wantName: "db SELECT",
+		},
+		{
+			desc:     "NoRowsIsNotAnError",
+			sql:      "SELECT 1",
+			err:      pgx.ErrNoRows,
+			wantName: "db SELECT",
+		},
+		{
+			desc:       "Failure",
+			sql:        "INSERT INTO orders (order_uid) VALUES ($1)",
+			err:        errors.New("duplicate key"),
+			wantName:   "db INSERT",
+			wantStatus: codes.Error,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			exporter := tracetest.NewInMemoryExporter()
+			tracer := postgres.NewQueryTracer(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+			ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+				SQL:  tc.sql,
+				Args: []any{"secret-value"},
+			})
+			tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{
+				CommandTag: pgconn.NewCommandTag("SELECT 1"),
+				Err:        tc.err,
+			})
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			span := spans[0]
+
+			if span.Name != tc.wantName {
+				t.Fatalf("expected span %q, got %q", tc.wantName, span.Name)
+			}
+			if span.Status.Code != tc.wantStatus {
+				t.Fatalf("expected status %v, got %v", tc.wantStatus, span.Status.Code)
+			}
+			for _, attr := range span.Attributes {
+				if attr.Value.Emit() == "secret-value" {
+					t.Fatalf("query argument leaked into attribute %s", attr.Key)
+				}
+			}
+		})
+	}
+}
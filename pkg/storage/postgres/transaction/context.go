@@ -0,0 +1,82 @@
+package transaction
+
+import (
+	"context"
+	"sync"
+
+	"wbtest/pkg/storage/postgres"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type stateKey struct{}
+
+// txState is what ExecuteInTransaction puts into the context. Nested calls share the
+// hooks of the outermost transaction, since only its commit makes anything durable.
+type txState struct {
+	tx    pgx.Tx
+	hooks *afterCommitHooks
+}
+
+type afterCommitHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+func (h *afterCommitHooks) add(fn func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fns = append(h.fns, fn)
+}
+
+func (h *afterCommitHooks) len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.fns)
+}
+
+// truncate drops hooks registered inside a savepoint that was rolled back.
+func (h *afterCommitHooks) truncate(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fns = h.fns[:n]
+}
+
+func (h *afterCommitHooks) run() {
+	h.mu.Lock()
+	fns := h.fns
+	h.fns = nil
+	h.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+func contextWithState(ctx context.Context, state *txState) context.Context {
+	ctx = context.WithValue(ctx, stateKey{}, state)
+	return postgres.ContextWithTx(ctx, state.tx)
+}
+
+func stateFromContext(ctx context.Context) (*txState, bool) {
+	state, ok := ctx.Value(stateKey{}).(*txState)
+	return state, ok
+}
+
+// InTransaction reports whether ctx was passed down from ExecuteInTransaction.
+func InTransaction(ctx context.Context) bool {
+	_, ok := stateFromContext(ctx)
+	return ok
+}
+
+// AfterCommit defers fn until the outermost transaction in ctx commits. Hooks of a
+// failed attempt or of a rolled-back savepoint are dropped. Outside a transaction fn
+// runs immediately.
+func AfterCommit(ctx context.Context, fn func()) {
+	state, ok := stateFromContext(ctx)
+	if !ok {
+		fn()
+		return
+	}
+	state.hooks.add(fn)
+}
@@ -7,12 +7,13 @@ import (
 
 	"wbtest/internal/entity"
 
-	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 var (
 	ErrMaxRetriesExceeded = errors.New("max retries exceeded")
 	ErrTransactionTimeout = errors.New("transaction timeout")
+	ErrNestedTxOption     = errors.New("transaction option not supported in a nested transaction")
 )
 
 func HandleError(operation, step string, err error) error {
@@ -5,14 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"math/rand/v2"
+	"strconv"
 	"time"
 
 	"wbtest/pkg/logger"
 	"wbtest/pkg/metric"
 	"wbtest/pkg/storage/postgres"
+	"wbtest/pkg/tracing"
 
-	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -21,20 +26,24 @@ const (
 	_defaultMaxRetryDelay  = 100 * time.Millisecond
 
 	_backoffMultiplier = 2
+
+	_tracerName = "wbtest/pkg/storage/postgres/transaction"
 )
 
 type Manager interface {
 	ExecuteInTransaction(
 		ctx context.Context,
 		operation string,
-		fn func(tx postgres.QueryExecuter) error,
+		fn func(ctx context.Context) error,
+		opts ...TxOption,
 	) error
 }
 
 type manager struct {
-	pool    *postgres.Postgres
+	pool    postgres.TxBeginner
 	log     logger.Logger
 	metrics metric.Transaction
+	tracer  trace.Tracer
 
 	maxAttempts    int
 	baseRetryDelay time.Duration
@@ -42,7 +51,7 @@ type manager struct {
 }
 
 func NewManager(
-	pool *postgres.Postgres,
+	pool postgres.TxBeginner,
 	log logger.Logger,
 	metrics metric.Transaction,
 	opts ...Option,
@@ -51,6 +60,7 @@ func NewManager(
 		pool:    pool,
 		log:     log,
 		metrics: metrics,
+		tracer:  otel.Tracer(_tracerName),
 
 		maxAttempts:    _defaultMaxAttempts,
 		baseRetryDelay: _defaultBaseRetryDelay,
@@ -67,31 +77,186 @@ func NewManager(
 	return tm, nil
 }
 
+// ExecuteInTransaction runs fn in a transaction, retrying the whole transaction on
+// serialization failures, deadlocks and connection errors. Without options the transaction
+// is READ COMMITTED READ WRITE with the server's default timeouts.
+//
+// The context passed to fn carries the transaction, and repositories run their queries in it
+// through Postgres.Executer. A nested call made with it runs in a SAVEPOINT of the outer
+// transaction instead and is not retried on its own. Its statement and lock timeouts last
+// until the savepoint ends, and Isolation, ReadOnly and Deferrable are rejected with
+// ErrNestedTxOption, since a savepoint keeps the outer transaction's mode.
 func (tm *manager) ExecuteInTransaction(
 	ctx context.Context,
 	operation string,
-	fn func(tx postgres.QueryExecuter) error,
+	fn func(ctx context.Context) error,
+	opts ...TxOption,
 ) error {
-	const op = "storage.postgres.transaction.ExecuteInTransaction"
+	cfg := newTxConfig(opts)
+	if err := cfg.validate(); err != nil {
+		return fmt.Errorf("storage.postgres.transaction.ExecuteInTransaction: %s: %w", operation, err)
+	}
+
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
+
+	parent, nested := stateFromContext(ctx)
+	if nested && cfg.txOptionsSet {
+		return fmt.Errorf("storage.postgres.transaction.ExecuteInTransaction: %s: %w", operation, ErrNestedTxOption)
+	}
+
+	ctx, span := tm.tracer.Start(ctx, "tx "+operation,
+		trace.WithAttributes(
+			attribute.String("db.transaction.operation", operation),
+			attribute.String("db.transaction.isolation", string(cfg.txOptions.IsoLevel)),
+			attribute.Bool("db.transaction.read_only", cfg.txOptions.AccessMode == pgx.ReadOnly),
+			attribute.Bool("db.transaction.nested", nested),
+		),
+	)
+	defer span.End()
 
-	return tm.withRetry(ctx, operation, func() error {
-		tx, err := tm.pool.Pool.BeginTx(ctx, pgx.TxOptions{
-			IsoLevel:   pgx.ReadCommitted,
-			AccessMode: pgx.ReadWrite,
+	var err error
+	if nested {
+		err = tm.executeNested(ctx, operation, parent, cfg, fn)
+	} else {
+		err = tm.withRetry(ctx, operation, func() error {
+			return tm.execute(ctx, operation, cfg, fn)
 		})
-		if err != nil {
-			return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	tracing.RecordError(span, err)
+
+	return err
+}
+
+func (tm *manager) execute(
+	ctx context.Context,
+	operation string,
+	cfg *txConfig,
+	fn func(ctx context.Context) error,
+) error {
+	const op = "storage.postgres.transaction.ExecuteInTransaction"
+
+	tx, err := tm.pool.BeginTx(ctx, cfg.txOptions)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tm.safelyRollback(ctx, tx, operation)
+
+	if err = applyLocalSettings(ctx, tx, cfg); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	state := &txState{tx: tx, hooks: &afterCommitHooks{}}
+	if err = fn(contextWithState(ctx, state)); err != nil {
+		handledErr := HandleError(operation, "execute", err)
+		return fmt.Errorf("%s: with retry function: %w", op, handledErr)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	state.hooks.run()
+	return nil
+}
+
+func (tm *manager) executeNested(
+	ctx context.Context,
+	operation string,
+	parent *txState,
+	cfg *txConfig,
+	fn func(ctx context.Context) error,
+) error {
+	const op = "storage.postgres.transaction.ExecuteInTransaction"
+
+	savepoint, err := parent.tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: savepoint: %w", op, err)
+	}
+	defer tm.safelyRollback(ctx, savepoint, operation)
+
+	// SET LOCAL outlives RELEASE SAVEPOINT, so the outer values are put back before it
+	previous, err := currentSettings(ctx, savepoint, cfg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = applyLocalSettings(ctx, savepoint, cfg); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	mark := parent.hooks.len()
+	state := &txState{tx: savepoint, hooks: parent.hooks}
+	if err = fn(contextWithState(ctx, state)); err != nil {
+		parent.hooks.truncate(mark)
+		handledErr := HandleError(operation, "execute", err)
+		return fmt.Errorf("%s: savepoint function: %w", op, handledErr)
+	}
+
+	if err = setLocal(ctx, savepoint, previous); err != nil {
+		parent.hooks.truncate(mark)
+		return fmt.Errorf("%s: restore settings: %w", op, err)
+	}
+	if err = savepoint.Commit(ctx); err != nil {
+		parent.hooks.truncate(mark)
+		return fmt.Errorf("%s: release savepoint: %w", op, err)
+	}
+	return nil
+}
+
+type localSetting struct {
+	name  string
+	value string
+}
+
+// localSettings lists the settings cfg changes, with values in milliseconds.
+func localSettings(cfg *txConfig) []localSetting {
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{name: "statement_timeout", value: cfg.statementTimeout},
+		{name: "lock_timeout", value: cfg.lockTimeout},
+	}
+
+	var settings []localSetting
+	for _, t := range timeouts {
+		if t.value > 0 {
+			ms := strconv.FormatInt(t.value.Milliseconds(), 10)
+			settings = append(settings, localSetting{name: t.name, value: ms})
 		}
-		defer tm.safelyRollback(ctx, tx, operation)
+	}
+	return settings
+}
+
+// applyLocalSettings sets the timeouts of cfg until the end of the transaction.
+func applyLocalSettings(ctx context.Context, tx pgx.Tx, cfg *txConfig) error {
+	return setLocal(ctx, tx, localSettings(cfg))
+}
 
-		txExecuter := &postgres.TxQueryExecuter{Tx: tx}
-		if err = fn(txExecuter); err != nil {
-			handledErr := HandleError(operation, "execute", err)
-			return fmt.Errorf("%s: with retry function: %w", op, handledErr)
+// currentSettings returns the values in force for the settings cfg changes.
+func currentSettings(ctx context.Context, tx pgx.Tx, cfg *txConfig) ([]localSetting, error) {
+	settings := localSettings(cfg)
+	for i := range settings {
+		err := tx.QueryRow(ctx, "SELECT current_setting($1)", settings[i].name).Scan(&settings[i].value)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", settings[i].name, err)
 		}
+	}
+	return settings, nil
+}
 
-		return tx.Commit(ctx)
-	}, _defaultMaxAttempts)
+// setLocal uses set_config(..., true), the parameterized form of SET LOCAL, so the settings
+// end with the transaction.
+func setLocal(ctx context.Context, tx pgx.Tx, settings []localSetting) error {
+	for _, s := range settings {
+		if _, err := tx.Exec(ctx, "SELECT set_config($1, $2, true)", s.name, s.value); err != nil {
+			return fmt.Errorf("set local %s: %w", s.name, err)
+		}
+	}
+	return nil
 }
 
 func (tm *manager) safelyRollback(ctx context.Context, tx pgx.Tx, operation string) {
@@ -110,10 +275,12 @@ func (tm *manager) withRetry(
 	ctx context.Context,
 	operation string,
 	fn func() error,
-	maxAttempts int,
 ) error {
 	const op = "storage.postgres.transaction.withRetry"
-	var lastErr error
+	var (
+		lastErr  error
+		attempts int
+	)
 
 	start := time.Now()
 	defer func() {
@@ -121,32 +288,47 @@ func (tm *manager) withRetry(
 		tm.metrics.ObserveDuration(operation, duration)
 	}()
 
-	currentBackoff := _defaultBaseRetryDelay
-	for i := range maxAttempts {
-		//nolint:gosec
-		jitter := time.Duration(
-			rand.Int64N(int64(currentBackoff * _backoffMultiplier)),
-		)
-		if jitter > _defaultMaxRetryDelay {
-			jitter = _defaultMaxRetryDelay
-		}
+	span := trace.SpanFromContext(ctx)
+	defer func() {
+		span.SetAttributes(attribute.Int("db.transaction.attempts", attempts))
+	}()
 
-		tm.log.LogAttrs(ctx, logger.InfoLevel, "retrying transaction",
-			logger.String("operation", op),
-			logger.String("transaction", operation),
-			logger.Int("attempt", i+1),
-			logger.Int("max_attempts", maxAttempts),
-			logger.String("retry_after", jitter.String()),
-			logger.Any("error", lastErr),
-		)
+	currentBackoff := tm.baseRetryDelay
+	for i := range tm.maxAttempts {
+		attempts = i + 1
 
-		timer := time.NewTimer(jitter)
-		defer timer.Stop()
-		select {
-		case <-timer.C:
-		case <-ctx.Done():
-			tm.metrics.IncrementFailures(operation)
-			return fmt.Errorf("%s: context canceled: %w", op, ctx.Err())
+		if i > 0 {
+			//nolint:gosec
+			jitter := time.Duration(
+				rand.Int64N(int64(currentBackoff * _backoffMultiplier)),
+			)
+			if jitter > tm.maxRetryDelay {
+				jitter = tm.maxRetryDelay
+			}
+
+			tm.log.LogAttrs(ctx, logger.InfoLevel, "retrying transaction",
+				logger.String("operation", op),
+				logger.String("transaction", operation),
+				logger.Int("attempt", attempts),
+				logger.Int("max_attempts", tm.maxAttempts),
+				logger.String("retry_after", jitter.String()),
+				logger.Any("error", lastErr),
+			)
+
+			timer := time.NewTimer(jitter)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				tm.metrics.IncrementFailures(operation)
+				return fmt.Errorf("%s: context canceled: %w", op, ctx.Err())
+			}
+
+			nextBackoff := currentBackoff * _backoffMultiplier
+			if nextBackoff > tm.maxRetryDelay {
+				nextBackoff = tm.maxRetryDelay
+			}
+			currentBackoff = nextBackoff
 		}
 
 		err := fn()
@@ -159,21 +341,22 @@ func (tm *manager) withRetry(
 			return err
 		}
 
-		tm.metrics.IncrementRetries(operation)
 		lastErr = err
-
-		nextBackoff := currentBackoff * _backoffMultiplier
-		if nextBackoff > _defaultMaxRetryDelay {
-			nextBackoff = _defaultMaxRetryDelay
+		if attempts < tm.maxAttempts {
+			tm.metrics.IncrementRetries(operation)
+			span.AddEvent("retry", trace.WithAttributes(
+				attribute.Int("attempt", attempts),
+				attribute.String("error", err.Error()),
+			))
 		}
-		currentBackoff = nextBackoff
 	}
 	tm.metrics.IncrementFailures(operation)
 	return fmt.Errorf(
-		"%s: max attempts (%d) exceeded for %s: %w",
+		"%s: max attempts (%d) exceeded for %s: %w: %w",
 		op,
-		maxAttempts,
+		tm.maxAttempts,
 		operation,
+		ErrMaxRetriesExceeded,
 		lastErr,
 	)
 }
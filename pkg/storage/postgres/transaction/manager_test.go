@@ -0,0 +1,632 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	mock_logger "wbtest/pkg/logger/mock"
+	mock_metric "wbtest/pkg/metric/mock"
+	"wbtest/pkg/storage/postgres"
+	"wbtest/pkg/storage/postgres/transaction"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/mock/gomock"
+)
+
+type execCall struct {
+	sql  string
+	args []any
+}
+
+// fakePool records every BeginTx call and hands out fakeTx values whose Commit
+// returns the next error from commitErrs. settings holds the values set with set_config.
+type fakePool struct {
+	mu         sync.Mutex
+	begins     []pgx.TxOptions
+	execs      []execCall
+	commitErrs []error
+	commits    int
+	rollbacks  int
+	savepoints int
+	settings   map[string]string
+}
+
+func (p *fakePool) BeginTx(_ context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.begins = append(p.begins, txOptions)
+
+	var commitErr error
+	if len(p.commitErrs) > 0 {
+		commitErr = p.commitErrs[0]
+		p.commitErrs = p.commitErrs[1:]
+	}
+	return &fakeTx{pool: p, commitErr: commitErr}, nil
+}
+
+type fakeTx struct {
+	pgx.Tx
+
+	pool      *fakePool
+	commitErr error
+	done      bool
+}
+
+func (t *fakeTx) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	t.pool.mu.Lock()
+	defer t.pool.mu.Unlock()
+
+	t.pool.execs = append(t.pool.execs, execCall{sql: sql, args: args})
+	if len(args) == 2 {
+		if t.pool.settings == nil {
+			t.pool.settings = make(map[string]string)
+		}
+		name, _ := args[0].(string)
+		t.pool.settings[name], _ = args[1].(string)
+	}
+	return pgconn.NewCommandTag("SELECT 1"), nil
+}
+
+// QueryRow answers current_setting with the value last set, "0" by default.
+func (t *fakeTx) QueryRow(_ context.Context, _ string, args ...any) pgx.Row {
+	t.pool.mu.Lock()
+	defer t.pool.mu.Unlock()
+
+	name, _ := args[0].(string)
+	value, ok := t.pool.settings[name]
+	if !ok {
+		value = "0"
+	}
+	return settingRow(value)
+}
+
+type settingRow string
+
+func (r settingRow) Scan(dest ...any) error {
+	*dest[0].(*string) = string(r)
+	return nil
+}
+
+func (p *fakePool) setting(name string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if value, ok := p.settings[name]; ok {
+		return value
+	}
+	return "0"
+}
+
+// Begin starts a savepoint, which shares the counters of the outer transaction.
+func (t *fakeTx) Begin(context.Context) (pgx.Tx, error) {
+	t.pool.mu.Lock()
+	defer t.pool.mu.Unlock()
+
+	t.pool.savepoints++
+	return &fakeTx{pool: t.pool}, nil
+}
+
+func (t *fakeTx) Commit(context.Context) error {
+	t.done = true
+	if t.commitErr != nil {
+		return t.commitErr
+	}
+
+	t.pool.mu.Lock()
+	defer t.pool.mu.Unlock()
+	t.pool.commits++
+	return nil
+}
+
+func (t *fakeTx) Rollback(context.Context) error {
+	if t.done {
+		return pgx.ErrTxClosed
+	}
+	t.done = true
+
+	t.pool.mu.Lock()
+	defer t.pool.mu.Unlock()
+	t.pool.rollbacks++
+	return nil
+}
+
+func newTestManager(
+	t *testing.T,
+	pool postgres.TxBeginner,
+	metrics *mock_metric.MockTransaction,
+	opts ...transaction.Option,
+) transaction.Manager {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	log := mock_logger.NewMockLogger(ctrl)
+	log.EXPECT().LogAttrs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	metrics.EXPECT().ObserveDuration(gomock.Any(), gomock.Any()).AnyTimes()
+
+	opts = append([]transaction.Option{
+		transaction.BaseRetryDelay(time.Millisecond),
+		transaction.MaxRetryDelay(time.Millisecond),
+	}, opts...)
+
+	tm, err := transaction.NewManager(pool, log, metrics, opts...)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return tm
+}
+
+func TestManager_ExecuteInTransaction_Retries(t *testing.T) {
+	t.Parallel()
+
+	serializationErr := &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+
+	testCases := []struct {
+		desc          string
+		maxAttempts   int
+		fnErrs        []error
+		wantAttempts  int
+		wantRetries   int
+		wantMaxErr    bool
+		wantErr       bool
+		wantRollbacks int
+	}{
+		{
+			desc:         "SucceedsFirstTime",
+			maxAttempts:  3,
+			wantAttempts: 1,
+		},
+		{
+			desc:          "SucceedsOnThirdAttempt",
+			maxAttempts:   3,
+			fnErrs:        []error{serializationErr, serializationErr},
+			wantAttempts:  3,
+			wantRetries:   2,
+			wantRollbacks: 2,
+		},
+		{
+			desc:          "HonorsMaxAttempts",
+			maxAttempts:   4,
+			fnErrs:        []error{serializationErr, serializationErr, serializationErr, serializationErr},
+			wantAttempts:  4,
+			wantRetries:   3,
+			wantMaxErr:    true,
+			wantErr:       true,
+			wantRollbacks: 4,
+		},
+		{
+			desc:          "SingleAttempt",
+			maxAttempts:   1,
+			fnErrs:        []error{serializationErr},
+			wantAttempts:  1,
+			wantMaxErr:    true,
+			wantErr:       true,
+			wantRollbacks: 1,
+		},
+		{
+			desc:          "NonRetryableError",
+			maxAttempts:   5,
+			fnErrs:        []error{errors.New("constraint violated")},
+			wantAttempts:  1,
+			wantErr:       true,
+			wantRollbacks: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			metrics := mock_metric.NewMockTransaction(ctrl)
+			pool := &fakePool{}
+			tm := newTestManager(t, pool, metrics, transaction.MaxAttempts(tc.maxAttempts))
+
+			metrics.EXPECT().IncrementRetries("Op").Times(tc.wantRetries)
+			if tc.wantErr {
+				metrics.EXPECT().IncrementFailures("Op").Times(1)
+			}
+
+			calls := 0
+			err := tm.ExecuteInTransaction(context.Background(), "Op", func(context.Context) error {
+				calls++
+				if calls <= len(tc.fnErrs) {
+					return tc.fnErrs[calls-1]
+				}
+				return nil
+			})
+
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if got := errors.Is(err, transaction.ErrMaxRetriesExceeded); got != tc.wantMaxErr {
+				t.Fatalf("expected ErrMaxRetriesExceeded %v, got %v", tc.wantMaxErr, err)
+			}
+			if calls != tc.wantAttempts || len(pool.begins) != tc.wantAttempts {
+				t.Fatalf("expected %d attempts, got %d calls and %d transactions",
+					tc.wantAttempts, calls, len(pool.begins))
+			}
+			if pool.rollbacks != tc.wantRollbacks {
+				t.Fatalf("expected %d rollbacks, got %d", tc.wantRollbacks, pool.rollbacks)
+			}
+		})
+	}
+}
+
+func TestManager_ExecuteInTransaction_RetriesCommitFailure(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	metrics := mock_metric.NewMockTransaction(ctrl)
+	pool := &fakePool{
+		commitErrs: []error{&pgconn.PgError{Code: "40P01", Message: "deadlock detected"}},
+	}
+	tm := newTestManager(t, pool, metrics)
+
+	metrics.EXPECT().IncrementRetries("Op").Times(1)
+
+	err := tm.ExecuteInTransaction(context.Background(), "Op", func(context.Context) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(pool.begins) != 2 {
+		t.Fatalf("expected 2 transactions, got %d", len(pool.begins))
+	}
+}
+
+func TestManager_ExecuteInTransaction_TxOptions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	metrics := mock_metric.NewMockTransaction(ctrl)
+	pool := &fakePool{}
+	tm := newTestManager(t, pool, metrics)
+
+	err := tm.ExecuteInTransaction(context.Background(), "Report",
+		func(context.Context) error { return nil },
+		transaction.Isolation(pgx.Serializable),
+		transaction.ReadOnly(),
+		transaction.Deferrable(),
+		transaction.StatementTimeout(1500*time.Millisecond),
+		transaction.LockTimeout(200*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := pgx.TxOptions{
+		IsoLevel:       pgx.Serializable,
+		AccessMode:     pgx.ReadOnly,
+		DeferrableMode: pgx.Deferrable,
+	}
+	if len(pool.begins) != 1 || pool.begins[0] != want {
+		t.Fatalf("expected BeginTx with %+v, got %+v", want, pool.begins)
+	}
+
+	wantSettings := map[string]string{
+		"statement_timeout": "1500",
+		"lock_timeout":      "200",
+	}
+	if len(pool.execs) != len(wantSettings) {
+		t.Fatalf("expected %d local settings, got %d", len(wantSettings), len(pool.execs))
+	}
+	for _, call := range pool.execs {
+		if len(call.args) != 2 {
+			t.Fatalf("expected name and value args, got %v", call.args)
+		}
+		name, _ := call.args[0].(string)
+		if value, ok := wantSettings[name]; !ok || call.args[1] != value {
+			t.Fatalf("unexpected setting %v for %q", call.args, call.sql)
+		}
+	}
+}
+
+func TestManager_ExecuteInTransaction_Defaults(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	metrics := mock_metric.NewMockTransaction(ctrl)
+	pool := &fakePool{}
+	tm := newTestManager(t, pool, metrics)
+
+	err := tm.ExecuteInTransaction(context.Background(), "Op", func(context.Context) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := pgx.TxOptions{IsoLevel: pgx.ReadCommitted, AccessMode: pgx.ReadWrite}
+	if len(pool.begins) != 1 || pool.begins[0] != want {
+		t.Fatalf("expected BeginTx with %+v, got %+v", want, pool.begins)
+	}
+	if len(pool.execs) != 0 {
+		t.Fatalf("expected no local settings, got %d", len(pool.execs))
+	}
+}
+
+func TestManager_ExecuteInTransaction_InvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	metrics := mock_metric.NewMockTransaction(ctrl)
+	pool := &fakePool{}
+	tm := newTestManager(t, pool, metrics)
+
+	fn := func(context.Context) error { return nil }
+	if err := tm.ExecuteInTransaction(context.Background(), "Op", fn,
+		transaction.Isolation("chaos")); err == nil {
+		t.Fatal("expected error for unknown isolation level")
+	}
+	if err := tm.ExecuteInTransaction(context.Background(), "Op", fn,
+		transaction.LockTimeout(-time.Second)); err == nil {
+		t.Fatal("expected error for negative timeout")
+	}
+	if len(pool.begins) != 0 {
+		t.Fatalf("expected no transactions, got %d", len(pool.begins))
+	}
+}
+
+func TestManager_ExecuteInTransaction_TimeoutStopsRetries(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	metrics := mock_metric.NewMockTransaction(ctrl)
+	pool := &fakePool{}
+	tm := newTestManager(t, pool, metrics,
+		transaction.MaxAttempts(100),
+		transaction.BaseRetryDelay(20*time.Millisecond),
+		transaction.MaxRetryDelay(20*time.Millisecond),
+	)
+
+	metrics.EXPECT().IncrementRetries("Op").AnyTimes()
+	metrics.EXPECT().IncrementFailures("Op").Times(1)
+
+	err := tm.ExecuteInTransaction(context.Background(), "Op", func(context.Context) error {
+		return &pgconn.PgError{Code: "40001"}
+	}, transaction.Timeout(50*time.Millisecond))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if len(pool.begins) >= 100 {
+		t.Fatalf("expected the deadline to stop retries, got %d attempts", len(pool.begins))
+	}
+}
+
+func TestNewManager_Validation(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	log := mock_logger.NewMockLogger(ctrl)
+	metrics := mock_metric.NewMockTransaction(ctrl)
+
+	testCases := []struct {
+		desc string
+		opts []transaction.Option
+	}{
+		{desc: "ZeroAttempts", opts: []transaction.Option{transaction.MaxAttempts(0)}},
+		{desc: "ZeroBaseDelay", opts: []transaction.Option{transaction.BaseRetryDelay(0)}},
+		{desc: "BaseAboveMax", opts: []transaction.Option{
+			transaction.BaseRetryDelay(time.Second),
+			transaction.MaxRetryDelay(time.Millisecond),
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := transaction.NewManager(&fakePool{}, log, metrics, tc.opts...); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestManager_ExecuteInTransaction_ContextCarriesTx(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	metrics := mock_metric.NewMockTransaction(ctrl)
+	tm := newTestManager(t, &fakePool{}, metrics)
+
+	ctx := context.Background()
+	if transaction.InTransaction(ctx) {
+		t.Fatal("expected no transaction outside ExecuteInTransaction")
+	}
+
+	err := tm.ExecuteInTransaction(ctx, "Op", func(txCtx context.Context) error {
+		if !transaction.InTransaction(txCtx) {
+			t.Error("expected the context to carry the transaction")
+		}
+		if _, ok := postgres.TxFromContext(txCtx); !ok {
+			t.Error("expected postgres.TxFromContext to find the transaction")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestManager_ExecuteInTransaction_NestedSavepoints(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	metrics := mock_metric.NewMockTransaction(ctrl)
+	pool := &fakePool{}
+	tm := newTestManager(t, pool, metrics)
+
+	var ran []string
+	err := tm.ExecuteInTransaction(context.Background(), "Outer", func(ctx context.Context) error {
+		transaction.AfterCommit(ctx, func() { ran = append(ran, "outer") })
+
+		innerErr := tm.ExecuteInTransaction(ctx, "Failing", func(ctx context.Context) error {
+			transaction.AfterCommit(ctx, func() { ran = append(ran, "failing") })
+			return errors.New("inner failure")
+		})
+		if innerErr == nil {
+			t.Error("expected the inner error to be returned")
+		}
+
+		return tm.ExecuteInTransaction(ctx, "Released", func(ctx context.Context) error {
+			transaction.AfterCommit(ctx, func() {
+				if pool.commits != 2 {
+					t.Errorf("expected hooks to run after the outer commit, got %d commits", pool.commits)
+				}
+				ran = append(ran, "released")
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(pool.begins) != 1 || pool.savepoints != 2 {
+		t.Fatalf("expected 1 transaction with 2 savepoints, got %d and %d",
+			len(pool.begins), pool.savepoints)
+	}
+	if pool.rollbacks != 1 {
+		t.Fatalf("expected only the failing savepoint to roll back, got %d rollbacks", pool.rollbacks)
+	}
+	if len(ran) != 2 || ran[0] != "outer" || ran[1] != "released" {
+		t.Fatalf("expected hooks [outer released], got %v", ran)
+	}
+}
+
+func TestManager_ExecuteInTransaction_NestedTimeoutsRestored(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	metrics := mock_metric.NewMockTransaction(ctrl)
+	pool := &fakePool{}
+	tm := newTestManager(t, pool, metrics)
+
+	err := tm.ExecuteInTransaction(context.Background(), "Outer", func(ctx context.Context) error {
+		err := tm.ExecuteInTransaction(ctx, "Inner", func(context.Context) error {
+			if got := pool.setting("statement_timeout"); got != "100" {
+				t.Errorf("expected the nested statement_timeout inside the savepoint, got %s", got)
+			}
+			if got := pool.setting("lock_timeout"); got != "50" {
+				t.Errorf("expected the nested lock_timeout inside the savepoint, got %s", got)
+			}
+			return nil
+		},
+			transaction.StatementTimeout(100*time.Millisecond),
+			transaction.LockTimeout(50*time.Millisecond),
+		)
+		if err != nil {
+			return err
+		}
+
+		if got := pool.setting("statement_timeout"); got != "1000" {
+			t.Errorf("expected the outer statement_timeout after the savepoint, got %s", got)
+		}
+		if got := pool.setting("lock_timeout"); got != "0" {
+			t.Errorf("expected the default lock_timeout after the savepoint, got %s", got)
+		}
+		return nil
+	}, transaction.StatementTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestManager_ExecuteInTransaction_NestedRejectsTxOptions(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		desc string
+		opt  transaction.TxOption
+	}{
+		{desc: "Isolation", opt: transaction.Isolation(pgx.Serializable)},
+		{desc: "ReadOnly", opt: transaction.ReadOnly()},
+		{desc: "Deferrable", opt: transaction.Deferrable()},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			metrics := mock_metric.NewMockTransaction(ctrl)
+			pool := &fakePool{}
+			tm := newTestManager(t, pool, metrics)
+
+			err := tm.ExecuteInTransaction(context.Background(), "Outer", func(ctx context.Context) error {
+				innerErr := tm.ExecuteInTransaction(ctx, "Inner", func(context.Context) error {
+					t.Error("expected the nested function not to run")
+					return nil
+				}, tc.opt)
+				if !errors.Is(innerErr, transaction.ErrNestedTxOption) {
+					t.Errorf("expected ErrNestedTxOption, got %v", innerErr)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if pool.savepoints != 0 {
+				t.Fatalf("expected no savepoint, got %d", pool.savepoints)
+			}
+		})
+	}
+}
+
+func TestAfterCommit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OutsideTransaction", func(t *testing.T) {
+		t.Parallel()
+
+		ran := false
+		transaction.AfterCommit(context.Background(), func() { ran = true })
+		if !ran {
+			t.Fatal("expected the hook to run immediately")
+		}
+	})
+
+	t.Run("DroppedOnRetryAndRollback", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		metrics := mock_metric.NewMockTransaction(ctrl)
+		tm := newTestManager(t, &fakePool{}, metrics)
+
+		metrics.EXPECT().IncrementRetries("Op").Times(1)
+		metrics.EXPECT().IncrementFailures("Failed").Times(1)
+
+		runs, calls := 0, 0
+		err := tm.ExecuteInTransaction(context.Background(), "Op", func(ctx context.Context) error {
+			calls++
+			transaction.AfterCommit(ctx, func() { runs++ })
+			if calls == 1 {
+				return &pgconn.PgError{Code: "40001"}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if runs != 1 {
+			t.Fatalf("expected the hook of the committed attempt only, got %d runs", runs)
+		}
+
+		err = tm.ExecuteInTransaction(context.Background(), "Failed", func(ctx context.Context) error {
+			transaction.AfterCommit(ctx, func() { runs++ })
+			return errors.New("rolled back")
+		})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if runs != 1 {
+			t.Fatalf("expected no hook after rollback, got %d runs", runs)
+		}
+	})
+}
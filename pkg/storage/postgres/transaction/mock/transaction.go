@@ -12,7 +12,7 @@ package mock_transaction
 import (
 	context "context"
 	reflect "reflect"
-	postgres "wbtest/pkg/storage/postgres"
+	transaction "wbtest/pkg/storage/postgres/transaction"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -42,15 +42,20 @@ func (m *MockManager) EXPECT() *MockManagerMockRecorder {
 }
 
 // ExecuteInTransaction mocks base method.
-func (m *MockManager) ExecuteInTransaction(ctx context.Context, operation string, fn func(postgres.QueryExecuter) error) error {
+func (m *MockManager) ExecuteInTransaction(ctx context.Context, operation string, fn func(context.Context) error, opts ...transaction.TxOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExecuteInTransaction", ctx, operation, fn)
+	varargs := []any{ctx, operation, fn}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecuteInTransaction", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ExecuteInTransaction indicates an expected call of ExecuteInTransaction.
-func (mr *MockManagerMockRecorder) ExecuteInTransaction(ctx, operation, fn any) *gomock.Call {
+func (mr *MockManagerMockRecorder) ExecuteInTransaction(ctx, operation, fn any, opts ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteInTransaction", reflect.TypeOf((*MockManager)(nil).ExecuteInTransaction), ctx, operation, fn)
+	varargs := append([]any{ctx, operation, fn}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteInTransaction", reflect.TypeOf((*MockManager)(nil).ExecuteInTransaction), varargs...)
 }
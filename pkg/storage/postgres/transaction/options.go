@@ -2,7 +2,11 @@ package transaction
 
 import (
 	"errors"
+	"fmt"
 	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Option func(*manager)
@@ -25,9 +29,16 @@ func MaxRetryDelay(delay time.Duration) Option {
 	}
 }
 
+// TracerProvider overrides the global provider used for transaction spans.
+func TracerProvider(provider trace.TracerProvider) Option {
+	return func(m *manager) {
+		m.tracer = provider.Tracer(_tracerName)
+	}
+}
+
 func (m *manager) validate() error {
 	if m.maxAttempts <= 0 {
-		return errors.New("invalid connAttempts: must be > 0")
+		return errors.New("invalid maxAttempts: must be > 0")
 	}
 
 	if m.baseRetryDelay <= 0 {
@@ -43,3 +54,85 @@ func (m *manager) validate() error {
 	}
 	return nil
 }
+
+// TxOption configures a single ExecuteInTransaction call.
+type TxOption func(*txConfig)
+
+type txConfig struct {
+	txOptions pgx.TxOptions
+	// txOptionsSet tells that txOptions were chosen by the caller, which a nested call cannot honor.
+	txOptionsSet     bool
+	statementTimeout time.Duration
+	lockTimeout      time.Duration
+	timeout          time.Duration
+}
+
+func newTxConfig(opts []TxOption) *txConfig {
+	cfg := &txConfig{
+		txOptions: pgx.TxOptions{
+			IsoLevel:   pgx.ReadCommitted,
+			AccessMode: pgx.ReadWrite,
+		},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+func Isolation(level pgx.TxIsoLevel) TxOption {
+	return func(c *txConfig) {
+		c.txOptions.IsoLevel = level
+		c.txOptionsSet = true
+	}
+}
+
+func ReadOnly() TxOption {
+	return func(c *txConfig) {
+		c.txOptions.AccessMode = pgx.ReadOnly
+		c.txOptionsSet = true
+	}
+}
+
+// Deferrable only has an effect on SERIALIZABLE READ ONLY transactions, which then wait
+// for a safe snapshot instead of risking serialization failures.
+func Deferrable() TxOption {
+	return func(c *txConfig) {
+		c.txOptions.DeferrableMode = pgx.Deferrable
+		c.txOptionsSet = true
+	}
+}
+
+// StatementTimeout sets statement_timeout for the transaction only.
+func StatementTimeout(timeout time.Duration) TxOption {
+	return func(c *txConfig) {
+		c.statementTimeout = timeout
+	}
+}
+
+// LockTimeout sets lock_timeout for the transaction only.
+func LockTimeout(timeout time.Duration) TxOption {
+	return func(c *txConfig) {
+		c.lockTimeout = timeout
+	}
+}
+
+// Timeout bounds the whole call, retries and backoff included.
+func Timeout(timeout time.Duration) TxOption {
+	return func(c *txConfig) {
+		c.timeout = timeout
+	}
+}
+
+func (c *txConfig) validate() error {
+	switch c.txOptions.IsoLevel {
+	case pgx.ReadCommitted, pgx.RepeatableRead, pgx.Serializable, pgx.ReadUncommitted:
+	default:
+		return fmt.Errorf("invalid isolation level %q", c.txOptions.IsoLevel)
+	}
+
+	if c.statementTimeout < 0 || c.lockTimeout < 0 || c.timeout < 0 {
+		return errors.New("timeouts must not be negative")
+	}
+	return nil
+}
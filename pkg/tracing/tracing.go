@@ -0,0 +1,95 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"wbtest/internal/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// NewProvider builds a tracer provider for the configured exporter and installs it, together
+// with the W3C trace-context propagator, as the global one. With the "none" exporter spans are
+// still created, so trace IDs propagate, but nothing is exported.
+// Extra options (for example sdktrace.WithSyncer with an in-memory exporter) are applied last.
+func NewProvider(
+	ctx context.Context,
+	cfg config.Tracing,
+	app config.App,
+	env string,
+	opts ...sdktrace.TracerProviderOption,
+) (*sdktrace.TracerProvider, error) {
+	const op = "tracing.NewProvider"
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(app.Name),
+		semconv.ServiceVersion(app.Version),
+		semconv.DeploymentEnvironmentName(env),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("%s: resource: %w", op, err)
+	}
+
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}
+
+	switch cfg.Exporter {
+	case ExporterNone:
+	case ExporterStdout:
+		exporter, expErr := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if expErr != nil {
+			return nil, fmt.Errorf("%s: stdout exporter: %w", op, expErr)
+		}
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	case ExporterOTLP:
+		clientOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, expErr := otlptracehttp.New(ctx, clientOpts...)
+		if expErr != nil {
+			return nil, fmt.Errorf("%s: otlp exporter: %w", op, expErr)
+		}
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	default:
+		return nil, fmt.Errorf("%s: unknown exporter %q", op, cfg.Exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(append(providerOpts, opts...)...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return provider, nil
+}
+
+// RecordError marks span as failed. Nil errors are ignored so it can be deferred unconditionally.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,63 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"wbtest/internal/config"
+	"wbtest/pkg/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+func TestNewProvider(t *testing.T) {
+	ctx := context.Background()
+	app := config.App{Name: "order-service", Version: "1.2.3"}
+
+	if _, err := tracing.NewProvider(ctx, config.Tracing{Exporter: "zipkin", SampleRatio: 1}, app, "local"); err == nil {
+		t.Fatal("expected error for unknown exporter")
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := tracing.NewProvider(ctx,
+		config.Tracing{Exporter: tracing.ExporterNone, SampleRatio: 1},
+		app,
+		"local",
+		sdktrace.WithSyncer(exporter),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	t.Cleanup(func() {
+		_ = provider.Shutdown(ctx)
+	})
+
+	_, span := otel.Tracer("test").Start(ctx, "operation")
+	tracing.RecordError(span, nil)
+	tracing.RecordError(span, errors.New("boom"))
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span from the global provider, got %d", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error || len(spans[0].Events) != 1 {
+		t.Fatalf("expected error status with one exception event, got %v with %d events",
+			spans[0].Status.Code, len(spans[0].Events))
+	}
+
+	var serviceName string
+	for _, attr := range spans[0].Resource.Attributes() {
+		if attr.Key == semconv.ServiceNameKey {
+			serviceName = attr.Value.AsString()
+		}
+	}
+	if serviceName != app.Name {
+		t.Fatalf("expected service.name %q, got %q", app.Name, serviceName)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"wbtest/internal/config"
 	"wbtest/internal/entity"
+	"wbtest/internal/exchange"
 	"wbtest/internal/repository"
 	"wbtest/internal/service"
 	"wbtest/pkg/cache"
@@ -64,6 +65,8 @@ func (s *IntegrationTestSuite) SetupSuite() {
 	deliveryRepo := repository.NewDeliveryRepository(db)
 	paymentRepo := repository.NewPaymentRepository(db)
 	itemRepo := repository.NewItemRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	archiveRepo := repository.NewArchiveRepository(db)
 
 	orderCache, err := cache.NewLRUCache[uuid.UUID, *entity.Order](
 		cfg.Cache.Capacity,
@@ -72,16 +75,39 @@ func (s *IntegrationTestSuite) SetupSuite() {
 	)
 	s.Require().NoError(err)
 
-	s.orderService = service.NewOrderService(
-		deliveryRepo,
-		itemRepo,
-		orderRepo,
-		paymentRepo,
-		txManager,
+	summaryCache, err := cache.NewLRUCache[string, *entity.CustomerSummary](
+		cfg.Cache.SummaryCapacity,
 		testLogger,
-		orderCache,
-		cfg.Cache.TTL,
+		metric.NewFactory().Cache(),
+		cache.WithName("customer_summary"),
 	)
+	s.Require().NoError(err)
+
+	rates, err := exchange.NewStaticProvider(cfg.Exchange.RatesFile)
+	s.Require().NoError(err)
+
+	s.orderService = service.NewOrderService(service.OrderServiceDeps{
+		DeliveryRepo:      deliveryRepo,
+		ItemRepo:          itemRepo,
+		OrderRepo:         orderRepo,
+		PaymentRepo:       paymentRepo,
+		AuditRepo:         auditRepo,
+		ArchiveRepo:       archiveRepo,
+		SearchRepo:        repository.NewSearchRepository(s.db),
+		CustomerRepo:      repository.NewCustomerRepository(s.db),
+		AnalyticsRepo:     repository.NewAnalyticsRepository(s.db),
+		RefundRepo:        repository.NewRefundRepository(s.db),
+		EventRepo:         repository.NewEventRepository(s.db),
+		WebhookQueue:      repository.NewWebhookRepository(s.db),
+		TxManager:         txManager,
+		Logger:            testLogger,
+		Cache:             orderCache,
+		CacheTTL:          cfg.Cache.TTL,
+		SummaryCache:      summaryCache,
+		SummaryTTL:        cfg.Cache.SummaryTTL,
+		Rates:             rates,
+		ReportingCurrency: cfg.Exchange.ReportingCurrency,
+	})
 }
 
 func (s *IntegrationTestSuite) TearDownSuite() {
@@ -157,12 +183,12 @@ func generateFakePayment() *entity.Payment {
 		RequestID:    uuid.New(),
 		Currency:     gofakeit.CurrencyShort(),
 		Provider:     gofakeit.Word(),
-		Amount:       uint64(gofakeit.UintRange(1000, 10000)),
+		Amount:       int64(gofakeit.UintRange(1000, 10000)),
 		PaymentDt:    gofakeit.DateRange(time.Now().AddDate(-1, 0, 0), time.Now()).Unix(),
 		Bank:         gofakeit.BS(),
-		DeliveryCost: uint64(gofakeit.UintRange(100, 500)),
-		GoodsTotal:   uint64(gofakeit.UintRange(500, 9000)),
-		CustomFee:    uint64(gofakeit.UintRange(0, 100)),
+		DeliveryCost: int64(gofakeit.UintRange(100, 500)),
+		GoodsTotal:   int64(gofakeit.UintRange(500, 9000)),
+		CustomFee:    int64(gofakeit.UintRange(0, 100)),
 	}
 }
 
@@ -170,12 +196,12 @@ func generateFakeItem() *entity.Item {
 	return &entity.Item{
 		ChrtID:      uint64(gofakeit.UintRange(10000, 99999)),
 		TrackNumber: gofakeit.UUID(),
-		Price:       uint64(gofakeit.UintRange(100, 1000)),
+		Price:       int64(gofakeit.UintRange(100, 1000)),
 		Rid:         uuid.New(),
 		Name:        gofakeit.ProductName(),
 		Sale:        gofakeit.Number(0, 50),
 		Size:        gofakeit.Word(),
-		TotalPrice:  uint64(gofakeit.UintRange(50, 950)),
+		TotalPrice:  int64(gofakeit.UintRange(50, 950)),
 		NMID:        uint64(gofakeit.UintRange(1000000, 9999999)),
 		Brand:       gofakeit.Company(),
 		Status:      gofakeit.Number(1, 5),